@@ -0,0 +1,112 @@
+package gopherdash
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// ABOUT SCREEN (build info for bug reports)
+// ----------------------------------------------------------------------------
+
+// The about screen (A on game over) says which build this is and where it
+// keeps its files, so a screenshot of it is enough to tell two reports
+// apart. The commit and build date come from the linker like the version:
+//
+//	-ldflags "-X github.com/krisfur/gopherdash.commit=$(git rev-parse --short HEAD)
+//	          -X github.com/krisfur/gopherdash.buildDate=$(date -u +%FT%TZ)"
+//
+// and otherwise from the VCS stamp go build embeds in a checkout.
+
+// commit and buildDate describe the build, set at link time
+var (
+	commit    = ""
+	buildDate = ""
+)
+
+// buildInfo is where and when this binary came from
+type buildInfo struct {
+	Version, Commit, Date string
+	Modified              bool // built from a checkout with uncommitted changes
+	Go, Platform          string
+}
+
+// currentBuild gathers the build metadata, preferring what the linker set
+func currentBuild() buildInfo {
+	b := buildInfo{
+		Version:  buildVersion(),
+		Commit:   commit,
+		Date:     buildDate,
+		Go:       runtime.Version(),
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if b.Commit == "" {
+					b.Commit = s.Value
+				}
+			case "vcs.time":
+				if b.Date == "" {
+					b.Date = s.Value
+				}
+			case "vcs.modified":
+				b.Modified = s.Value == "true"
+			}
+		}
+	}
+	if len(b.Commit) > 12 {
+		b.Commit = b.Commit[:12]
+	}
+	if b.Commit == "" {
+		b.Commit = "unknown"
+	}
+	if b.Modified {
+		b.Commit += " (modified)"
+	}
+	if b.Date == "" {
+		b.Date = "unknown"
+	}
+	return b
+}
+
+// savesLocation says where the store keeps the profile, stats and history
+func savesLocation(cfg config) string {
+	switch cfg.Store {
+	case "memory":
+		return "memory (nothing is written)"
+	case "sqlite":
+		return dbPath(cfg)
+	}
+	return filepath.Dir(profilePath())
+}
+
+// aboutLines is the about screen's text
+func (m model) aboutLines() []string {
+	b := currentBuild()
+	bg := "light"
+	if lipgloss.HasDarkBackground() {
+		bg = "dark"
+	}
+	row := func(k, v string) string { return fmt.Sprintf("%-9s %s", k, v) }
+	lines := []string{
+		"Gopher-Dash " + b.Version,
+		"",
+		row("commit", b.Commit),
+		row("built", b.Date),
+		row("go", b.Go+" "+b.Platform),
+		row("data", filepath.Dir(configPath())),
+		row("saves", savesLocation(m.cfg)),
+		row("terminal", fmt.Sprintf("%s, %s background, %dx%d",
+			lipgloss.ColorProfile().Name(), bg, m.w, m.h)),
+	}
+	if m.newer != nil {
+		lines = append(lines, row("update", m.newer.Tag+" is out: "+m.newer.URL))
+	}
+	return lines
+}
@@ -0,0 +1,106 @@
+package gopherdash
+
+import "fmt"
+
+// ----------------------------------------------------------------------------
+// ACORNS (throwable projectiles)
+// ----------------------------------------------------------------------------
+
+const (
+	acornChar  = "🌰"
+	acornStart = 3  // acorns at the start of a run
+	acornMax   = 5  // pockets only hold so many
+	acornSpeed = 2  // cells an acorn flies per tick
+	acornRange = 24 // cells an acorn flies before dropping
+)
+
+// acorn is a thrown acorn in flight, in world cells
+type acorn struct {
+	X    int `json:"x"`
+	From int `json:"from"` // where it was thrown
+}
+
+// acornPickup lies on the running line and refills one acorn when the
+// gopher runs through it; placeTiles scatters them with the other tiles
+type acornPickup struct{}
+
+func (acornPickup) Name() string          { return "acorn" }
+func (acornPickup) Sprite() (string, int) { return acornChar, 1 }
+func (acornPickup) Radar() string         { return "• " }
+func (acornPickup) Width() int            { return 1 }
+func (acornPickup) Height() int           { return 1 }
+func (acornPickup) Advance(x int) int     { return x }
+func (acornPickup) Weight() float64       { return 0 }
+func (acornPickup) Death(dist int) string { return fmt.Sprintf("Stopped at %d", dist) }
+func (acornPickup) Collides(p player) hit {
+	if p.height <= 1 {
+		return pickup
+	}
+	return miss
+}
+
+// throwAcorn throws an acorn forward along the running line. Lockstep
+// races only exchange jumps, so acorns stay in their pockets there.
+func (m *model) throwAcorn() {
+	if m.race.lockstep || !m.live() {
+		return
+	}
+	if m.ammo == 0 {
+		m.notify("Out of acorns")
+		return
+	}
+	m.ammo--
+	m.seats.throws++
+	x := m.camera().toWorld(playerCol)
+	m.acorns = append(m.acorns, acorn{x, x})
+	m.logDebug("acorn thrown", "ammo", m.ammo)
+}
+
+// stepAcorns flies every acorn forward; the first rock in its path is
+// knocked out along with the acorn
+func (m *model) stepAcorns() {
+	kept := m.acorns[:0]
+	for _, a := range m.acorns {
+		hitRock := false
+		for c := a.X + 1; c <= min(a.X+acornSpeed, a.From+acornRange) && !hitRock; c++ {
+			for _, ob := range m.obstacles {
+				if _, ok := ob.kind.(rock); ok && ob.x == c {
+					m.removeObstacle(ob)
+					m.seats.hits++
+					m.logInfo("acorn hit", "x", c)
+					hitRock = true
+					break
+				}
+			}
+		}
+		a.X += acornSpeed
+		if !hitRock && a.X-a.From < acornRange {
+			kept = append(kept, a)
+		}
+	}
+	m.acorns = kept
+}
+
+// removeObstacle takes ob out of the world
+func (m *model) removeObstacle(ob obstacle) {
+	for i, o := range m.obstacles {
+		if o.x == ob.x && o.kind.Name() == ob.kind.Name() {
+			m.obstacles = append(m.obstacles[:i], m.obstacles[i+1:]...)
+			return
+		}
+	}
+}
+
+// collectAcorn picks up an acorn lying at ob, counting it again for each
+// more-acorns perk
+func (m *model) collectAcorn(ob obstacle) {
+	for range 1 + m.perk(perkAcorns) {
+		m.ammo = min(m.ammo+1, acornMax)
+		m.scoreCoin()
+	}
+	m.removeObstacle(ob)
+	m.logDebug("acorn picked up", "ammo", m.ammo)
+}
+
+// acornHUD shows the acorns left
+func (m model) acornHUD() string { return fmt.Sprintf("%s×%d", acornChar, m.ammo) }
@@ -0,0 +1,74 @@
+package gopherdash
+
+import (
+	"slices"
+	"testing"
+)
+
+func acornModel(t *testing.T) model {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	m.obstacles = nil
+	return m
+}
+
+// An acorn knocks out the first rock in its path and nothing else: holes
+// and logs let it fly on, and it drops after acornRange cells.
+func TestAcornFlight(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		path []obstacle // cells ahead of the throw
+		left []int      // which of them are still there after
+	}{
+		{"two rocks", []obstacle{{5, rock{}}, {7, rock{}}}, []int{7}},
+		{"rocks side by side", []obstacle{{4, rock{}}, {5, rock{}}}, []int{5}},
+		{"hole first", []obstacle{{3, hole{}}, {9, rock{}}}, []int{3}},
+		{"log first", []obstacle{{3, fallenLog{}}, {9, rock{}}}, []int{3}},
+		{"rock at the end of its range", []obstacle{{acornRange, rock{}}}, nil},
+		{"rock past its range", []obstacle{{acornRange + 1, rock{}}}, []int{acornRange + 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := acornModel(t)
+			from := m.camera().toWorld(playerCol)
+			for _, ob := range tc.path {
+				m.obstacles = append(m.obstacles, obstacle{from + ob.x, ob.kind})
+			}
+			m.throwAcorn()
+			for i := 0; len(m.acorns) > 0; i++ {
+				if i > acornRange {
+					t.Fatalf("the acorn is still flying at %d", m.acorns[0].X-from)
+				}
+				m.stepAcorns()
+			}
+			var left []int
+			for _, ob := range m.obstacles {
+				left = append(left, ob.x-from)
+			}
+			if !slices.Equal(left, tc.left) {
+				t.Errorf("left %v, want %v", left, tc.left)
+			}
+		})
+	}
+}
+
+// Pockets hold acornMax; lockstep races don't throw at all.
+func TestAcornPockets(t *testing.T) {
+	m := acornModel(t)
+	m.ammo = acornMax - 1
+	m.obstacles = []obstacle{{m.body().lo + 1, acornPickup{}}}
+	m.step(m.now())
+	if m.ammo != acornMax || len(m.obstacles) != 0 {
+		t.Fatalf("picked up to %d acorns, %d obstacles left", m.ammo, len(m.obstacles))
+	}
+	m.collectAcorn(obstacle{0, acornPickup{}})
+	if m.ammo != acornMax {
+		t.Errorf("pockets hold %d acorns, want %d", m.ammo, acornMax)
+	}
+
+	m.race.lockstep = true
+	m.throwAcorn()
+	if m.ammo != acornMax || len(m.acorns) != 0 {
+		t.Errorf("threw in a lockstep race: %d acorns left, %d flying", m.ammo, len(m.acorns))
+	}
+}
@@ -0,0 +1,403 @@
+package gopherdash
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// PROFILE ARCHIVES (`gopherdash export` / `gopherdash import`)
+// ----------------------------------------------------------------------------
+
+// An archive is a gzipped tar of JSON files: the profile (the high score,
+// achievements and modified-run bests), lifetime stats, run history, the
+// last and best replays and config, plus the smaller saves as they are on
+// disk. Importing merges it into the saves already here; -prefer settles
+// what can't be merged, or with "newer" takes whichever was written last (as
+// cloud sync does).
+
+const (
+	archiveFormat  = 1
+	archiveMaxFile = 16 << 20 // largest entry read back from an archive
+)
+
+// archiveRaw are the saves archived as the files themselves
+var archiveRaw = []struct {
+	name string
+	path func() string
+}{
+	{"config.json", configPath},
+	{"events.json", eventsPath},
+	{"splits.json", splitsPath},
+	{"deaths.json", deathsPath},
+	{"weekly.json", weeklyPath},
+	{"streak.json", streakPath},
+	{"scoring.json", scoringPath},
+	{"scores.json", scoresPath},
+}
+
+// archiveManifest is the archive's manifest.json
+type archiveManifest struct {
+	Format   int       `json:"format"`
+	Exported time.Time `json:"exported"`
+	Profile  int       `json:"profile_version"`
+}
+
+// exportMain runs `gopherdash export FILE`; game flags such as -store pick
+// the saves to export
+func exportMain(args []string) int {
+	cfg := loadConfig()
+	fs := flag.NewFlagSet("gopherdash export", flag.ContinueOnError)
+	cfg.bindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "export: pass the archive to write, e.g. gopherdash export profile.tar.gz")
+		return exitUsage
+	}
+	saves, err := openStore(cfg)
+	if err != nil {
+		return exitCode(err)
+	}
+	if err := writeArchive(saves, fs.Arg(0)); err != nil {
+		return exitCode(err)
+	}
+	fmt.Println("Saves exported to", fs.Arg(0))
+	return 0
+}
+
+// archiveEntry is one file in an archive
+type archiveEntry struct {
+	name string
+	data []byte
+}
+
+// archiveEntries are the files of an archive of the saves in saves as they
+// are now, manifest first
+func archiveEntries(saves Store) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	for _, e := range []struct {
+		name string
+		v    any
+	}{
+		{"manifest.json", archiveManifest{archiveFormat, time.Now(), profileVersion}},
+		{"profile.json", loadProfile(saves)},
+		{"stats.json", loadStats(saves)},
+		{"history.json", loadHistory(saves)},
+	} {
+		data, err := json.MarshalIndent(e.v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, archiveEntry{e.name, data})
+	}
+	for _, name := range replayNames {
+		if data, ok := saves.readReplay(name); ok {
+			entries = append(entries, archiveEntry{name + ".replay", data})
+		}
+	}
+	for _, raw := range archiveRaw {
+		data, err := os.ReadFile(raw.path())
+		if err != nil {
+			continue // not every save file exists
+		}
+		if raw.name == "config.json" {
+			data = redactConfig(data)
+		}
+		entries = append(entries, archiveEntry{raw.name, data})
+	}
+	return entries, nil
+}
+
+// writeArchive writes every save there is, with those in saves, to an
+// archive at path
+func writeArchive(saves Store, path string) error {
+	entries, err := archiveEntries(saves)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// readArchive reads every entry of the archive at path
+func readArchive(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: not a profile archive: %w", path, err)
+	}
+	entries := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if hdr.Size > archiveMaxFile {
+			return nil, fmt.Errorf("%s: %s is too big for a save", path, hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, archiveMaxFile))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		entries[hdr.Name] = data
+	}
+	if err := checkManifest(entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// checkManifest checks that entries are an archive this build can import
+func checkManifest(entries map[string][]byte) error {
+	var man archiveManifest
+	if json.Unmarshal(entries["manifest.json"], &man) != nil || man.Format == 0 {
+		return errors.New("not a profile archive (no manifest)")
+	}
+	if man.Format > archiveFormat || man.Profile > profileVersion {
+		return errors.New("exported by a newer gopherdash; update to import it")
+	}
+	return nil
+}
+
+// importMain runs `gopherdash import [-prefer merge|mine|theirs] FILE`
+func importMain(args []string) int {
+	cfg := loadConfig()
+	fs := flag.NewFlagSet("gopherdash import", flag.ContinueOnError)
+	cfg.bindFlags(fs)
+	prefer := fs.String("prefer", "merge",
+		"on a conflict: merge where possible, keep mine or theirs outright, or whichever is newer")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 || !slices.Contains([]string{"merge", "mine", "theirs", "newer"}, *prefer) {
+		fmt.Fprintln(os.Stderr, "import: pass the archive to read, e.g. gopherdash import profile.tar.gz,"+
+			" and -prefer merge, mine, theirs or newer")
+		return exitUsage
+	}
+	saves, err := openStore(cfg)
+	if err != nil {
+		return exitCode(err)
+	}
+	entries, err := readArchive(fs.Arg(0))
+	if err != nil {
+		return exitCode(err)
+	}
+	for _, line := range importArchive(saves, entries, *prefer, cfg.SignSaves) {
+		fmt.Println(line)
+	}
+	return 0
+}
+
+// importArchive merges an archive's entries into the saves, signing the
+// profile if sign, and says what it did with each
+func importArchive(saves Store, entries map[string][]byte, prefer string, sign bool) []string {
+	var man archiveManifest
+	_ = json.Unmarshal(entries["manifest.json"], &man) // readArchive checked it
+	var report []string
+	saves.lock(func() {
+		if data, ok := entries["profile.json"]; ok {
+			report = append(report, importProfile(saves, data, prefer, sign))
+		}
+		if data, ok := entries["stats.json"]; ok {
+			report = append(report, importStats(saves, data, prefer, man.Exported))
+		}
+		if data, ok := entries["history.json"]; ok {
+			report = append(report, importHistory(saves, data))
+		}
+		for _, name := range replayNames {
+			if data, ok := entries[name+".replay"]; ok {
+				report = append(report, importReplay(saves, name, data, prefer))
+			}
+		}
+	})
+	withSaveLock(func() {
+		for _, raw := range archiveRaw {
+			if data, ok := entries[raw.name]; ok {
+				report = append(report, importRaw(raw.name, raw.path(), data, prefer, man.Exported))
+			}
+		}
+	})
+	return report
+}
+
+func importProfile(saves Store, data []byte, prefer string, sign bool) string {
+	var theirs profile
+	switch {
+	case json.Unmarshal(data, &theirs) != nil || theirs.HighScore < 0 || theirs.Version < 0:
+		return "profile: unreadable, skipped"
+	case theirs.newer():
+		return "profile: from a newer gopherdash, skipped"
+	}
+	for theirs.Version < profileVersion {
+		theirs = migrations[theirs.Version](theirs)
+		theirs.Version++
+	}
+	// any signature was made with the other install's key, so only a score
+	// of ours that was signed already is signed again
+	theirs.MAC = ""
+	trusted := false
+	mine, ok := saves.readProfile()
+	switch {
+	case ok && mine.newer():
+		return "profile: yours is from a newer gopherdash, kept"
+	case ok && prefer == "mine":
+		return "profile: kept yours"
+	case ok && (prefer == "merge" || prefer == "newer"): // scores always merge
+		if theirs.HighScore <= mine.HighScore {
+			theirs.HighScore, trusted = mine.HighScore, mine.verified()
+		}
+		theirs.Achievements = mergeAchievements(mine.Achievements, theirs.Achievements)
+		theirs.ModBests = mergeBests(mine.ModBests, theirs.ModBests)
+		if mine.LatencyMS > 0 { // measured on this machine's terminal
+			theirs.LatencyMS = mine.LatencyMS
+		}
+	}
+	if sign && trusted {
+		theirs.sign()
+	}
+	if err := saveProfile(saves, theirs); err != nil {
+		return "profile: not saved: " + err.Error()
+	}
+	return fmt.Sprintf("profile: best %d, %d achievements, %d modified-run bests",
+		theirs.HighScore, len(theirs.Achievements), len(theirs.ModBests))
+}
+
+// importStats can't add two sets of lifetime counts without counting runs
+// they share twice, so merging keeps the longer career
+func importStats(saves Store, data []byte, prefer string, exported time.Time) string {
+	var theirs stats
+	if json.Unmarshal(data, &theirs) != nil {
+		return "stats: unreadable, skipped"
+	}
+	mine := loadStats(saves)
+	var keep bool
+	switch prefer {
+	case "mine":
+		keep = true
+	case "merge":
+		keep = mine.Runs >= theirs.Runs
+	case "newer":
+		keep = !exported.After(saves.changed().stats)
+	}
+	if keep {
+		return fmt.Sprintf("stats: kept yours (%d runs)", mine.Runs)
+	}
+	saveStats(saves, theirs)
+	return fmt.Sprintf("stats: took the archive's (%d runs)", theirs.Runs)
+}
+
+// importHistory adds the archive's runs that aren't in the history yet,
+// oldest first; the history is a log, so there's nothing to prefer
+func importHistory(saves Store, data []byte) string {
+	var theirs []runRecord
+	if json.Unmarshal(data, &theirs) != nil {
+		return "history: unreadable, skipped"
+	}
+	seen := map[time.Time]bool{}
+	for _, r := range loadHistory(saves) {
+		seen[r.At] = true
+	}
+	slices.SortStableFunc(theirs, func(a, b runRecord) int { return a.At.Compare(b.At) })
+	added := 0
+	for _, r := range theirs {
+		if seen[r.At] {
+			continue
+		}
+		if saves.addRun(r) == nil {
+			added++
+		}
+	}
+	return fmt.Sprintf("history: %d runs added", added)
+}
+
+// importReplay takes the archive's tape of the last or best run over ours
+// if it's newer, or for the best if it scored more, unless -prefer says
+// otherwise
+func importReplay(saves Store, name string, data []byte, prefer string) string {
+	what := name + " replay"
+	theirs, err := parseReplay(data, what)
+	if err != nil {
+		return what + ": unreadable, skipped"
+	}
+	if data, ok := saves.readReplay(name); ok {
+		mine, err := parseReplay(data, what)
+		var keep bool
+		switch {
+		case err != nil || prefer == "theirs":
+		case prefer == "mine":
+			keep = true
+		case prefer == "merge" && name == replayBest:
+			keep = mine.Score >= theirs.Score
+		default: // the last run, or -prefer newer
+			keep = !theirs.At.After(mine.At)
+		}
+		if keep {
+			return fmt.Sprintf("%s: kept yours (score %d)", what, mine.Score)
+		}
+	}
+	if err := saves.writeReplay(name, data); err != nil {
+		return what + ": not saved: " + err.Error()
+	}
+	return fmt.Sprintf("%s: took the archive's (score %d)", what, theirs.Score)
+}
+
+// importRaw restores one of the smaller saves; where there's one already,
+// it's kept unless -prefer theirs, or newer and the archive is newer
+func importRaw(name, path string, data []byte, prefer string, exported time.Time) string {
+	if st, err := os.Stat(path); err == nil {
+		if prefer == "mine" || prefer == "merge" || prefer == "newer" && !exported.After(st.ModTime()) {
+			return name + ": kept yours"
+		}
+	}
+	if name == "config.json" {
+		// archives never carry the secrets; keep the ones set here
+		local, _ := os.ReadFile(path)
+		data = keepSecrets(data, local)
+	}
+	if !json.Valid(data) {
+		return name + ": unreadable, skipped"
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return name + ": not saved: " + err.Error()
+	}
+	return name + ": restored"
+}
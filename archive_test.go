@@ -0,0 +1,246 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// archived is an archive of the saves as they are now, as readArchive
+// would return it
+func archived(t *testing.T, saves Store) map[string][]byte {
+	list, err := archiveEntries(saves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := map[string][]byte{}
+	for _, e := range list {
+		entries[e.name] = e.data
+	}
+	return entries
+}
+
+func TestImportKeepsSecrets(t *testing.T) {
+	for _, prefer := range []string{"newer", "theirs"} {
+		t.Run(prefer, func(t *testing.T) {
+			saves := isolateSaves(t)
+			cfg := `{"sync_url": "https://dav.example/saves.tar.gz", "sync_secret": "hunter2", "gist_token": "ghp_x"}`
+			if err := os.WriteFile(configPath(), []byte(cfg), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			hourAgo := time.Now().Add(-time.Hour)
+			_ = os.Chtimes(configPath(), hourAgo, hourAgo) // older than the archive
+
+			entries := archived(t, saves)
+			var sent map[string]any
+			_ = json.Unmarshal(entries["config.json"], &sent)
+			for _, k := range secretKeys {
+				if _, ok := sent[k]; ok {
+					t.Errorf("the archive carries %s", k)
+				}
+			}
+			importArchive(saves, entries, prefer, false)
+			got := loadConfig()
+			if got.SyncSecret != "hunter2" || got.GistToken != "ghp_x" || got.SyncURL == "" {
+				t.Errorf("after an import preferring %s the config has secret %q, token %q, URL %q",
+					prefer, got.SyncSecret, got.GistToken, got.SyncURL)
+			}
+		})
+	}
+}
+
+func TestSecretsStayOutOfDumps(t *testing.T) {
+	saves := isolateSaves(t)
+	cfg := `{"sync_url": "https://dav.example/saves.tar.gz", "sync_secret": "hunter2", "gist_token": "ghp_x"}`
+	if err := os.WriteFile(configPath(), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(loadConfig(), saves)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = next.(model)
+	if m.cfg.SyncSecret == "" {
+		t.Fatal("the config's secret didn't load")
+	}
+	state, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string]string{
+		"state dump":    string(state),
+		"crash report":  string(m.crashState()),
+		"doctor report": doctorReport(),
+	} {
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "ghp_x") {
+			t.Errorf("the %s has the secrets in it", name)
+		}
+	}
+
+	// loading a dump keeps the secrets already in the config
+	if err := m.UnmarshalJSON(state); err != nil {
+		t.Fatal(err)
+	}
+	if m.cfg.SyncSecret != "hunter2" || m.cfg.GistToken != "ghp_x" {
+		t.Error("loading a state dump dropped the secrets")
+	}
+}
+
+func TestImportReplays(t *testing.T) {
+	saves := isolateSaves(t)
+	tape := func(score int, at time.Time) []byte {
+		data, _ := json.Marshal(replay{Format: replayFormat, Seed: 5, Score: score, At: at})
+		return data
+	}
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_ = saves.writeReplay(replayBest, tape(100, day))
+	_ = saves.writeReplay(replayLast, tape(40, day.Add(time.Hour)))
+	entries := archived(t, saves)
+
+	for _, tc := range []struct {
+		prefer     string
+		best, last int // scores after the import
+	}{
+		{"merge", 200, 40},  // the higher best, the later last
+		{"newer", 100, 40},  // the archive's are both newer
+		{"mine", 200, 10},   // ours, whatever they are
+		{"theirs", 100, 40}, // the archive's, whatever they are
+	} {
+		saves = &memoryStore{}
+		_ = saves.writeReplay(replayBest, tape(200, day.Add(-time.Hour)))
+		_ = saves.writeReplay(replayLast, tape(10, day.Add(-time.Hour)))
+		importArchive(saves, entries, tc.prefer, false)
+		for name, want := range map[string]int{replayBest: tc.best, replayLast: tc.last} {
+			data, _ := saves.readReplay(name)
+			if got, err := parseReplay(data, name); err != nil || got.Score != want {
+				t.Errorf("-prefer %s: %s replay scored %d (%v), want %d", tc.prefer, name, got.Score, err, want)
+			}
+		}
+	}
+}
+
+func TestImportProfile(t *testing.T) {
+	mine := profile{Version: profileVersion, HighScore: 100,
+		Achievements: map[string]string{"Halloween": "2025-10-31"},
+		ModBests:     map[string]int{"fog": 50}}
+	theirs := func(best int) profile {
+		return profile{Version: profileVersion, HighScore: best, MAC: "00ff",
+			Achievements: map[string]string{"Christmas": "2025-12-25"},
+			ModBests:     map[string]int{"fog": 70, "ice": 20}}
+	}
+	merged := func(best int) profile {
+		return profile{Version: profileVersion, HighScore: best,
+			Achievements: map[string]string{"Halloween": "2025-10-31", "Christmas": "2025-12-25"},
+			ModBests:     map[string]int{"fog": 70, "ice": 20}}
+	}
+	taken := func(best int) profile { p := theirs(best); p.MAC = ""; return p }
+
+	for _, tc := range []struct {
+		name   string
+		mine   *profile // nil: no profile here yet
+		data   string   // the archive's profile.json; "" for theirs(best)
+		best   int
+		prefer string
+		want   profile
+	}{
+		{"merge, theirs higher", &mine, "", 150, "merge", merged(150)},
+		{"merge, ours higher", &mine, "", 80, "merge", merged(100)},
+		{"newer merges too", &mine, "", 80, "newer", merged(100)},
+		{"mine", &mine, "", 150, "mine", mine},
+		{"theirs", &mine, "", 80, "theirs", taken(80)},
+		{"mine, but none here", nil, "", 80, "mine", taken(80)},
+		{"legacy version", &mine, `{"version": 0, "high_score": 150}`, 0, "merge",
+			profile{Version: profileVersion, HighScore: 150, Achievements: mine.Achievements, ModBests: mine.ModBests}},
+		{"from a newer build", &mine, `{"version": 99, "high_score": 150}`, 0, "theirs", mine},
+		{"unreadable", &mine, `{"high_score": -1}`, 0, "theirs", mine},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			saves := isolateSaves(t)
+			if tc.mine != nil {
+				_ = saves.writeProfile(*tc.mine)
+			}
+			data := []byte(tc.data)
+			if tc.data == "" {
+				data, _ = json.Marshal(theirs(tc.best))
+			}
+			report := importProfile(saves, data, tc.prefer, false)
+			if got := loadProfile(saves); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%s\nprofile %+v\nwant    %+v", report, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImportProfileSignature(t *testing.T) {
+	for _, tc := range []struct {
+		theirs int
+		signed bool
+	}{
+		{80, true},   // our signed score stands, so it's signed again
+		{150, false}, // the archive's score is nobody's to vouch for
+	} {
+		saves := isolateSaves(t)
+		p := profile{Version: profileVersion, HighScore: 100}
+		p.sign()
+		_ = saves.writeProfile(p)
+		data, _ := json.Marshal(profile{Version: profileVersion, HighScore: tc.theirs})
+		importProfile(saves, data, "merge", true)
+		if got := loadProfile(saves); got.verified() != tc.signed {
+			t.Errorf("theirs %d: merged profile %+v verified = %v, want %v", tc.theirs, got, !tc.signed, tc.signed)
+		}
+	}
+}
+
+func TestImportStats(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		prefer   string
+		theirs   int // runs in the archive
+		exported time.Time
+		want     int // runs afterwards
+	}{
+		{"merge", 20, now, 20}, // the longer career
+		{"merge", 5, now, 10},
+		{"mine", 20, now, 10},
+		{"theirs", 5, now, 5},
+		{"newer", 5, now.Add(time.Hour), 5}, // exported after ours was written
+		{"newer", 20, now.Add(-time.Hour), 10},
+	} {
+		saves := isolateSaves(t)
+		saveStats(saves, stats{Runs: 10, Deaths: 10})
+		data, _ := json.Marshal(stats{Runs: tc.theirs, Deaths: tc.theirs})
+		report := importStats(saves, data, tc.prefer, tc.exported)
+		if got := loadStats(saves).Runs; got != tc.want {
+			t.Errorf("-prefer %s with %d runs in the archive: %d runs (%s), want %d",
+				tc.prefer, tc.theirs, got, report, tc.want)
+		}
+	}
+	if report := importStats(MemoryStore(), []byte("{"), "theirs", now); !strings.Contains(report, "skipped") {
+		t.Errorf("unreadable stats: %s", report)
+	}
+}
+
+func TestImportHistory(t *testing.T) {
+	saves := isolateSaves(t)
+	runs := someRuns(5)
+	for _, r := range runs[1:3] {
+		_ = saves.addRun(r)
+	}
+	theirs := []runRecord{runs[4], runs[2], runs[0], runs[3]} // one shared, out of order
+	data, _ := json.Marshal(theirs)
+	if report := importHistory(saves, data); report != "history: 3 runs added" {
+		t.Errorf("first import: %s", report)
+	}
+	if report := importHistory(saves, data); report != "history: 0 runs added" {
+		t.Errorf("second import: %s", report)
+	}
+	got := loadHistory(saves)
+	slices.SortFunc(got, func(a, b runRecord) int { return a.At.Compare(b.At) })
+	if !reflect.DeepEqual(got, runs) {
+		t.Errorf("history %+v, want %+v", got, runs)
+	}
+}
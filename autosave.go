@@ -0,0 +1,185 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// AUTOSAVE & RESUME
+// ----------------------------------------------------------------------------
+
+const (
+	autosaveFile  = ".gopherdash_autosave"
+	autosaveEvery = 2 * time.Second
+)
+
+// snapshot is the serialisable part of a live run
+type snapshot struct {
+	Seed      int64           `json:"seed"`
+	Dist      int             `json:"dist"`
+	Jumps     int             `json:"jumps"`
+	Bonus     int             `json:"bonus,omitempty"`
+	Boost     int             `json:"boost,omitempty"` // ticks left on a speed pad
+	Fox       int             `json:"fox,omitempty"`   // cells the fox is behind; 0 = not playing with it
+	FoxCalm   int             `json:"fox_calm,omitempty"`
+	Ammo      *int            `json:"ammo,omitempty"` // acorns left; nil in saves from before acorns
+	Height    int             `json:"height"`         // rows above the running line
+	VelY      int             `json:"vel_y"`
+	VelFx     int             `json:"vel_fx,omitempty"` // momentum physics
+	SubY      int             `json:"sub_y,omitempty"`
+	Physics   string          `json:"physics,omitempty"`
+	Mods      []string        `json:"mods,omitempty"`
+	Perks     []string        `json:"perks,omitempty"`
+	Clinging  bool            `json:"clinging,omitempty"`
+	Class     string          `json:"class,omitempty"` // the run keeps its class when resumed
+	AirJumps  int             `json:"air_jumps,omitempty"`
+	Hits      int             `json:"hits,omitempty"` // absorbed by shields
+	Scoring   string          `json:"scoring,omitempty"`
+	Points    int             `json:"points,omitempty"`
+	Combo     int             `json:"combo,omitempty"`
+	Loop      int             `json:"loop,omitempty"` // prestige loops
+	Banked    int             `json:"banked,omitempty"`
+	FrameDur  time.Duration   `json:"frame_dur"`
+	Obstacles []savedObstacle `json:"obstacles"`
+	Next      int             `json:"next"`          // spawner cursor, world cells
+	Last      int             `json:"last"`          // spawner's most recent hazard
+	End       int             `json:"end,omitempty"` // and its last cell, if it's wide
+	Tight     int             `json:"tight"`
+	SavedAt   time.Time       `json:"saved_at"`
+}
+
+type savedObstacle struct {
+	X   int    `json:"x"` // world cell
+	Typ string `json:"typ"`
+}
+
+func autosavePath() string { return dataPath(autosaveFile) }
+
+// snapshot captures the live run
+func (m model) snapshot() snapshot {
+	s := snapshot{
+		Seed:     m.seed,
+		Dist:     m.dist,
+		Jumps:    m.jumps,
+		Bonus:    m.bonus,
+		Boost:    m.boostLeft,
+		Fox:      m.fox,
+		FoxCalm:  m.foxCalm,
+		Ammo:     &m.ammo,
+		Height:   m.gameRows - 2 - m.playerY,
+		VelY:     m.velY,
+		VelFx:    m.velFx,
+		SubY:     m.subY,
+		Physics:  m.cfg.Physics,
+		Mods:     m.mods,
+		Perks:    m.perks,
+		Clinging: m.clinging,
+		Class:    m.cfg.Class,
+		AirJumps: m.airJumps,
+		Hits:     m.hits,
+		Scoring:  m.cfg.Scoring,
+		Points:   m.points,
+		Combo:    m.combo,
+		Loop:     m.loop,
+		Banked:   m.banked,
+		FrameDur: m.frameDur,
+		Next:     m.spawn.next,
+		Last:     m.spawn.last,
+		End:      m.spawn.end,
+		Tight:    m.spawn.tight,
+		SavedAt:  m.now(),
+	}
+	for _, ob := range m.obstacles {
+		s.Obstacles = append(s.Obstacles, savedObstacle{ob.x, ob.kind.Name()})
+	}
+	return s
+}
+
+// restoreSnapshot loads s into the model; the grid must already be sized
+func (m *model) restoreSnapshot(s snapshot) {
+	// math/rand state can't be saved, so the stream carries on from a seed
+	// derived from the saved one; the run still belongs to its original seed
+	m.seed = s.Seed
+	m.tape = nil // the course carries on from another seed, so it can't be replayed
+	m.spawn = newSpawner(s.Seed^int64(s.Dist), s.Next, 0)
+	m.spawn.last, m.spawn.end, m.spawn.tight = s.Last, max(s.End, s.Last), s.Tight
+	m.mods = s.Mods
+	m.perks, m.perkOffer = s.Perks, nil
+	m.dist = s.Dist
+	m.jumps = s.Jumps
+	m.bonus, m.boostLeft = s.Bonus, s.Boost
+	m.fox, m.foxCalm = s.Fox, s.FoxCalm
+	if m.fox <= 0 {
+		m.fox = foxStart // saved before the fox existed
+	}
+	m.ammo, m.acorns = acornStart, nil
+	if s.Ammo != nil {
+		m.ammo = *s.Ammo
+	}
+	m.playerY = min(m.gameRows-2-s.Height, m.gameRows-2)
+	m.velY, m.velFx, m.subY = s.VelY, s.VelFx, s.SubY
+	if validPhysics(s.Physics) == nil {
+		m.cfg.Physics = s.Physics
+	}
+	m.clinging, m.slide = s.Clinging, 0
+	if _, ok := characterByName(s.Class); ok {
+		m.cfg.Class = s.Class
+	}
+	m.airJumps, m.hits = s.AirJumps, s.Hits
+	if validScoring(s.Scoring) == nil { // the run keeps the rules it scored under
+		m.cfg.Scoring, m.rules = s.Scoring, rulesByName(s.Scoring)
+	}
+	m.points, m.combo, m.cleared = s.Points, s.Combo, 0
+	m.loop, m.banked = s.Loop, s.Banked
+	m.bot = nil    // the race was lost with the crash
+	m.fitSpawner() // after the class and physics are back
+	m.startDirector()
+	m.yesterday = nil
+	m.frameDur = s.FrameDur
+	m.obstacles = nil
+	for _, ob := range s.Obstacles {
+		if k, ok := kindByName(ob.Typ); ok {
+			m.obstacles = append(m.obstacles, obstacle{ob.X, k})
+		}
+	}
+}
+
+// autosave writes the live run to disk at most every autosaveEvery; the file
+// is written under a temporary name and renamed so a crash mid-write never
+// leaves a truncated save behind
+func (m *model) autosave() {
+	if m.racing() || m.saver || m.playback != nil || m.cfg.Store == "memory" {
+		return // a memory store writes nothing to disk
+	}
+	if m.now().Sub(m.lastSave) < autosaveEvery {
+		return
+	}
+	m.lastSave = m.now()
+	data, err := json.Marshal(m.snapshot())
+	if err != nil {
+		return
+	}
+	tmp := autosavePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, autosavePath())
+}
+
+// loadAutosave returns the run left behind by a previous session, if any
+func loadAutosave() *snapshot {
+	data, err := os.ReadFile(autosavePath())
+	if err != nil {
+		return nil
+	}
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil || s.Dist == 0 {
+		return nil
+	}
+	return &s
+}
+
+// clearAutosave drops the save once its run has been recorded or declined
+func clearAutosave() { _ = os.Remove(autosavePath()) }
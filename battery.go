@@ -0,0 +1,40 @@
+package gopherdash
+
+import "time"
+
+// ----------------------------------------------------------------------------
+// BATTERY SAVER
+// ----------------------------------------------------------------------------
+
+// -battery-saver trades looks for CPU time: half as many redraws during a
+// run, no decorative layers (confetti, smooth in-between frames), and the
+// HUD bar is only rebuilt when its text changes.
+
+// decorative reports whether purely decorative effects are drawn
+func (m model) decorative() bool { return !m.cfg.ReducedMotion && !m.cfg.BatterySaver }
+
+// renderRate is the redraw cap during a run: -render-fps, halved by the
+// battery saver, which halves the game's own rate when there's no cap
+func (m model) renderRate() int {
+	fps := m.cfg.RenderFPS
+	if !m.cfg.BatterySaver {
+		return fps
+	}
+	if fps == 0 {
+		fps = int(time.Second / m.tickDur())
+	}
+	return max(fps/2, 1)
+}
+
+// hudBox draws the HUD bar around status
+func (m model) hudBox(status string) string {
+	p := m.perf
+	if m.cfg.BatterySaver && p != nil && p.hudStatus == status && p.hudWidth == m.w {
+		return p.hud
+	}
+	hud := m.renderHUD(status)
+	if p != nil {
+		p.hudStatus, p.hudWidth, p.hud = status, m.w, hud
+	}
+	return hud
+}
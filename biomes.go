@@ -0,0 +1,141 @@
+package gopherdash
+
+// ----------------------------------------------------------------------------
+// BIOMES (world chunks)
+// ----------------------------------------------------------------------------
+
+const (
+	chunkCells = 64       // world cells per chunk
+	biomeTurn  = 3        // about one chunk boundary in biomeTurn starts a new biome
+	chunkSalt  = 0xc4a2c5 // mixed into the seed for a chunk's random numbers
+	biomeSalt  = 0xb10e5  // and for where biomes turn and which they turn to
+)
+
+// The world is cut into chunkCells-cell chunks, and each chunk is generated
+// by its biome with random numbers of its own, drawn from the seed and the
+// chunk's index. Nothing about a chunk depends on how far the course has
+// been generated or on the random numbers any other chunk took, so a world
+// goes on for as long as anyone can run and a seed always makes the same
+// one. The stream's spacing – the gaps and the jump rhythm (see spawner.go)
+// – carries straight on over a boundary, which keeps the course fair where
+// one biome gives way to the next.
+//
+// Biomes come in stretches of a chunk or more: where one turns is a hash
+// of the seed and the boundary, and so is what it turns to. A run opens in
+// the first of biomes.
+
+// Biome is a generator for the chunks it's picked for: how thick its
+// hazards come and which kinds they are. Adding a biome means implementing
+// it and listing it in biomes.
+type Biome interface {
+	Name() string
+	Density() float64              // times the stream's spawn chance
+	Weight(k ObstacleKind) float64 // k's share of its spawns; 0 = never
+}
+
+// biomes is the registry. Chunks draw their biome evenly from it, so
+// reordering it changes every seeded course.
+var biomes = []Biome{meadow{}, quarry{}, marsh{}, woods{}}
+
+// chunkOf is the chunk world cell x is in
+func chunkOf(x int) int {
+	c, _ := floorDiv(x, chunkCells)
+	return c
+}
+
+// biomeOf is the biome of chunk c in the world of seed, from among list:
+// that of the stretch it's in, which started at the latest boundary that
+// turned
+func biomeOf(seed int64, c int, list []Biome) Biome {
+	start := max(c, 0)
+	for start > 0 && terrainHash(seed^biomeSalt, start)%biomeTurn != 0 {
+		start--
+	}
+	if start == 0 {
+		return list[0]
+	}
+	return list[terrainHash(seed^biomeSalt, start)>>32%uint64(len(list))]
+}
+
+// chunkSeed is the seed of chunk c's random numbers
+func chunkSeed(seed int64, c int) int64 {
+	return int64(terrainHash(seed^chunkSalt, c))
+}
+
+// pickKind chooses a kind for one of b's random spawns from u in [0, 1)
+func pickKind(b Biome, u float64) ObstacleKind {
+	total := 0.0
+	for _, k := range obstacleKinds {
+		total += b.Weight(k)
+	}
+	u *= total
+	for _, k := range obstacleKinds {
+		if u < b.Weight(k) {
+			return k
+		}
+		u -= b.Weight(k)
+	}
+	return obstacleKinds[len(obstacleKinds)-1]
+}
+
+// meadow is the classic mix, each kind at its own weight
+type meadow struct{}
+
+func (meadow) Name() string                  { return "meadow" }
+func (meadow) Density() float64              { return 1 }
+func (meadow) Weight(k ObstacleKind) float64 { return k.Weight() }
+
+// quarry is rocks, loose and piled up, a little thicker on the ground
+type quarry struct{}
+
+func (quarry) Name() string     { return "quarry" }
+func (quarry) Density() float64 { return 1.2 }
+func (quarry) Weight(k ObstacleKind) float64 {
+	switch k.(type) {
+	case rock:
+		return 0.7
+	case rockStack:
+		return 3 * k.Weight()
+	case hole:
+		return 0.1
+	case wideHole:
+		return 0
+	}
+	return k.Weight()
+}
+
+// marsh is mostly holes, wide ones among them, with nothing to pile rocks on
+type marsh struct{}
+
+func (marsh) Name() string     { return "marsh" }
+func (marsh) Density() float64 { return 1 }
+func (marsh) Weight(k ObstacleKind) float64 {
+	switch k.(type) {
+	case hole:
+		return 0.8
+	case wideHole:
+		return 0.4
+	case rock:
+		return 0.2
+	case rockStack:
+		return 0
+	}
+	return k.Weight()
+}
+
+// woods is fallen logs, with the trees spacing things out a little
+type woods struct{}
+
+func (woods) Name() string     { return "woods" }
+func (woods) Density() float64 { return 0.9 }
+func (woods) Weight(k ObstacleKind) float64 {
+	switch k.(type) {
+	case fallenLog:
+		return 0.5
+	case rock:
+		return 0.4
+	case hole:
+		return 0.2
+	}
+	return k.Weight()
+}
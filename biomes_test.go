@@ -0,0 +1,103 @@
+package gopherdash
+
+import "testing"
+
+// biomeCourse generates the first courseCells world cells for seed with
+// every chunk in biome b
+func biomeCourse(seed int64, b Biome) []obstacle {
+	s := newSpawner(seed, playerCol+1, defaultGraceCells)
+	s.biomes = []Biome{b}
+	s.enter(s.chunk)
+	return s.fill(playerCol + 1 + courseCells)
+}
+
+// Each generator on its own keeps to its own kinds, and its courses stay
+// spaced and clearable.
+func TestBiomeGenerators(t *testing.T) {
+	for _, b := range biomes {
+		counts := map[string]int{}
+		for seed := int64(1); seed <= 30; seed++ {
+			obs := biomeCourse(seed, b)
+			for i, ob := range obs {
+				counts[ob.kind.Name()]++
+				if b.Weight(ob.kind) <= 0 {
+					t.Errorf("%s, seed %d: a %s, which it never spawns", b.Name(), seed, ob.kind.Name())
+				}
+				if i > 0 && ob.x-obs[i-1].extent().hi < minGapCells {
+					t.Errorf("%s, seed %d: hazards at %d and %d", b.Name(), seed, obs[i-1].x, ob.x)
+				}
+			}
+			if d, ok := clearable(obs, courseCells); !ok {
+				t.Errorf("%s, seed %d: no way past distance %d", b.Name(), seed, d)
+			}
+		}
+		top := obstacleKinds[0]
+		for _, k := range obstacleKinds {
+			if b.Weight(k) > b.Weight(top) {
+				top = k
+			}
+		}
+		for _, k := range obstacleKinds {
+			if n := counts[k.Name()]; b.Weight(k) < b.Weight(top) && n > counts[top.Name()] {
+				t.Errorf("%s: more of %s (%d) than of its favourite, %s (%d)",
+					b.Name(), k.Name(), n, top.Name(), counts[top.Name()])
+			}
+		}
+	}
+}
+
+func TestBiomeStretches(t *testing.T) {
+	seen := map[string]bool{}
+	for seed := int64(1); seed <= 20; seed++ {
+		if b := biomeOf(seed, 0, biomes); b != biomes[0] {
+			t.Errorf("seed %d opens in the %s", seed, b.Name())
+		}
+		for c := 1; c < 100; c++ {
+			b := biomeOf(seed, c, biomes)
+			seen[b.Name()] = true
+			turns := terrainHash(seed^biomeSalt, c)%biomeTurn == 0
+			if !turns && b != biomeOf(seed, c-1, biomes) {
+				t.Errorf("seed %d: the biome changed at chunk %d, which doesn't turn", seed, c)
+			}
+		}
+	}
+	for _, b := range biomes {
+		if !seen[b.Name()] {
+			t.Errorf("no %s in 20 worlds", b.Name())
+		}
+	}
+}
+
+// A spawner rebuilt from what a state dump keeps carries on with the same
+// course, wherever in a chunk it was taken.
+func TestSpawnerRestore(t *testing.T) {
+	for _, cut := range []int{100, chunkCells * 3, chunkCells*5 + 17} {
+		s := newSpawner(7, playerCol+1, defaultGraceCells)
+		s.fill(cut)
+		back := newSpawner(s.seed, s.next, 0)
+		back.skipDraws(s.draws)
+		back.last, back.end, back.tight = s.last, s.end, s.tight
+		want, got := s.fill(cut+400), back.fill(cut+400)
+		if len(got) != len(want) {
+			t.Fatalf("cut at %d: %d hazards after, want %d", cut, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("cut at %d: hazard %d is %v, want %v", cut, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+// Far-off chunks are as easy to reach as near ones.
+func TestChunksEndless(t *testing.T) {
+	s := newSpawner(3, playerCol+1, defaultGraceCells)
+	far := 1000 * chunkCells
+	s.fill(far)
+	if s.chunk != chunkOf(s.next) || s.next < far {
+		t.Fatalf("stopped in chunk %d at %d", s.chunk, s.next)
+	}
+	if obs := s.fill(far + chunkCells); len(obs) == 0 {
+		t.Error("nothing in chunk 1000")
+	}
+}
@@ -0,0 +1,199 @@
+package gopherdash
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// RACE THE BOT (-bot)
+// ----------------------------------------------------------------------------
+
+// With -bot the screensaver's bot (see screensaver.go) runs the same course
+// in a second pane, a step for each of ours, and the first gopher to
+// -bot-target wins; one crashing first hands the race to the other. The
+// bot's tier sets how it plays: how many steps it takes to react, so it
+// decides that far ahead, and how often a jump slips, coming that many
+// steps early or late. The perfect bot never slips. The race is decided
+// once, with a banner, and the run itself carries on as usual.
+
+// botTier is how well the bot plays
+type botTier struct {
+	name     string
+	reaction int     // steps from deciding to jump to the press
+	slip     float64 // chance a press comes early or late
+}
+
+var botTiers = []botTier{
+	{"easy", 3, 0.15},
+	{"normal", 2, 0.06},
+	{"hard", 1, 0.02},
+	{"perfect", 0, 0},
+}
+
+// botTierByName looks a tier up by its name
+func botTierByName(name string) (botTier, bool) {
+	i := slices.IndexFunc(botTiers, func(t botTier) bool { return t.name == name })
+	if i < 0 {
+		return botTier{}, false
+	}
+	return botTiers[i], true
+}
+
+// validBot checks name against the tiers; "" is no bot
+func validBot(name string) error {
+	if _, ok := botTierByName(name); ok || name == "" {
+		return nil
+	}
+	names := make([]string, len(botTiers))
+	for i, t := range botTiers {
+		names[i] = t.name
+	}
+	return fmt.Errorf("unknown bot %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// race outcomes, from our side
+const (
+	botWon  = "won"
+	botLost = "lost"
+	botTied = "tied"
+)
+
+// botRace is a race against the bot
+type botRace struct {
+	tier    botTier
+	run     *model       // the bot's gopher, on our course
+	presses map[int]bool // its jumps by the step they're pressed on
+	busy    int          // the step its last jump lands on; no deciding till then
+	rng     *rand.Rand   // its slips, from the course's seed
+	result  string       // botWon, botLost or botTied once decided
+}
+
+// startBot puts a fresh bot on the run's course, if there's a race on
+func (m *model) startBot() {
+	tier, ok := botTierByName(m.cfg.Bot)
+	if !ok || m.racing() || m.ghost || m.saver || m.playback != nil {
+		m.bot = nil
+		return
+	}
+	cfg := m.cfg
+	cfg.Practice, cfg.Timer, cfg.Twitch, cfg.Bot = false, false, "", ""
+	cfg.Seed, cfg.Daily = 0, false // keeps the heatmap strip off its pane
+	cfg.Mods, cfg.Weekly = m.mods, false
+	run := &model{cfg: cfg, clock: m.clock, frameDur: startFrame, ghost: true, event: m.event,
+		fox: foxStart, ammo: acornStart}
+	run.reseed(m.seed)
+	m.bot = &botRace{tier: tier, run: run, presses: map[int]bool{},
+		rng: rand.New(rand.NewSource(m.seed))}
+	if m.gameRows > 0 {
+		m.sizeGhost(run)
+	}
+}
+
+// stepBot plays the bot's move and step alongside ours, and decides the
+// race once it can be
+func (m *model) stepBot() {
+	b := m.bot
+	if b == nil || b.result != "" || b.run.gameRows == 0 {
+		return
+	}
+	o := b.run
+	if !o.gameOver {
+		t := o.steps + 1
+		if b.presses[t] {
+			o.pressJump()
+		}
+		delete(b.presses, t)
+		if t >= b.busy && o.botJumpFrom(o.camera().toWorld(playerCol)+b.tier.reaction) {
+			at := t + b.tier.reaction
+			if b.rng.Float64() < b.tier.slip {
+				off := 1 + b.rng.Intn(max(b.tier.reaction, 1))
+				if b.rng.Intn(2) == 0 {
+					off = -off
+				}
+				at = max(at+off, t)
+			}
+			if at == t {
+				o.pressJump()
+			} else {
+				b.presses[at] = true
+			}
+			b.busy = at + len(o.spawn.arc) + 1
+		}
+		o.step(m.now())
+	}
+	target := m.cfg.BotTarget
+	switch {
+	case m.gameOver && o.gameOver:
+		b.result = botTied
+		if m.dist > o.dist {
+			b.result = botWon
+		} else if m.dist < o.dist {
+			b.result = botLost
+		}
+	case m.gameOver:
+		b.result = botLost
+	case o.gameOver:
+		b.result = botWon
+	case m.dist >= target && o.dist >= target:
+		b.result = botTied
+	case m.dist >= target:
+		b.result = botWon
+	case o.dist >= target:
+		b.result = botLost
+	default:
+		return
+	}
+	m.logInfo("bot race over", "result", b.result, "bot", b.tier.name, "dist", m.dist, "bot_dist", o.dist)
+	if !m.gameOver {
+		m.banner, m.bannerAt = m.botHeadline(), m.now()
+	}
+}
+
+// botHeadline is the race's result in a few words
+func (m model) botHeadline() string {
+	switch m.bot.result {
+	case botWon:
+		return "You beat the bot!"
+	case botLost:
+		return "The bot wins"
+	case botTied:
+		return "Dead heat with the bot"
+	}
+	return ""
+}
+
+// botBar is the HUD bar of both runs' way to the target
+func (m model) botBar() string {
+	target := max(m.cfg.BotTarget, 1)
+	bar := func(d int) string {
+		n := min(d, target) * raceBarWidth / target
+		return strings.Repeat("█", n) + strings.Repeat("░", raceBarWidth-n)
+	}
+	o := m.bot.run
+	bot := fmt.Sprintf("Bot (%s) %s %d", m.bot.tier.name, bar(o.dist), o.dist)
+	if o.gameOver {
+		bot += " ✗"
+	}
+	return fmt.Sprintf("You %s │ %s → %d", bar(m.dist), bot, target)
+}
+
+// botResult is the race's lines for the game-over screen
+func (m model) botResult() []string {
+	head := m.botHeadline()
+	if m.bot.result == botWon {
+		head = bannerStyle.Render("★ YOU BEAT THE BOT ★")
+	}
+	return []string{head, fmt.Sprintf("To %d: you %d, %s bot %d",
+		m.cfg.BotTarget, m.dist, m.bot.tier.name, m.bot.run.dist)}
+}
+
+// botPane renders the bot's playfield in its own box
+func (m model) botPane() string {
+	return m.pane(m.bot.run.renderGame())
+}
+
+// splitPanes reports whether a second playfield goes under ours
+func (m model) splitPanes() bool { return m.race.lockstep || m.bot != nil }
@@ -0,0 +1,69 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+)
+
+// raceBot plays our side with the screensaver's bot until the race with
+// the other one is decided
+func raceBot(t *testing.T, tier string, seed int64) model {
+	t.Helper()
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Seed = 0, seed
+	cfg.Bot, cfg.BotTarget = tier, 400
+	m, _ := clockedModel(t, cfg)
+	if m.bot == nil || m.bot.run.gameRows != m.gameRows {
+		t.Fatal("no bot sized to our playfield")
+	}
+	for i := 0; i < 2*cfg.BotTarget && m.bot.result == ""; i++ {
+		if m.botJump() {
+			m.pressJump()
+		}
+		m.step(m.now())
+		m.stepBot()
+	}
+	return m
+}
+
+func TestBotRace(t *testing.T) {
+	m := raceBot(t, "perfect", 11)
+	if m.bot.result != botTied || m.gameOver || m.bot.run.dist != m.dist {
+		t.Fatalf("two perfect runs: %q at %d against %d", m.bot.result, m.dist, m.bot.run.dist)
+	}
+	if !strings.Contains(m.bannerText(m.w), "Dead heat") {
+		t.Errorf("no banner for the result: %q", m.bannerText(m.w))
+	}
+
+	// an easy bot slips often enough to lose on most courses
+	won := 0
+	for seed := int64(1); seed <= 8; seed++ {
+		m := raceBot(t, "easy", seed)
+		if m.bot.result == botWon {
+			won++
+			if !m.bot.run.gameOver {
+				t.Errorf("seed %d: won without the bot crashing", seed)
+			}
+		}
+	}
+	if won == 0 {
+		t.Error("a perfect run never beat the easy bot")
+	}
+}
+
+func TestBotTiers(t *testing.T) {
+	if err := validBot("hard"); err != nil {
+		t.Error(err)
+	}
+	if err := validBot(""); err != nil {
+		t.Error(err)
+	}
+	if err := validBot("godlike"); err == nil {
+		t.Error("an unknown tier passed")
+	}
+	cfg := defaultConfig()
+	m, _ := clockedModel(t, cfg)
+	if m.bot != nil || m.splitPanes() {
+		t.Error("a bot without -bot")
+	}
+}
@@ -0,0 +1,39 @@
+package gopherdash
+
+// ----------------------------------------------------------------------------
+// RUN EVENTS (the in-run event bus)
+// ----------------------------------------------------------------------------
+
+// Moments in a run are announced on a bus rather than handled where the
+// game loop spots them, and each feature that cares subscribes from its
+// own corner: the HUD, the score, the music, the achievements and the
+// perks. Handlers run in the order listed, on the model, in the step that
+// raised the event.
+
+// runEvent is something that happened in a run
+type runEvent int
+
+const (
+	eventMilestone runEvent = iota // another milestoneEvery of distance; n is the distance
+)
+
+// runHandler reacts to an event; n is the event's detail
+type runHandler func(m *model, n int)
+
+// runHandlers are the subscribers to each event
+var runHandlers = map[runEvent][]runHandler{
+	eventMilestone: {
+		(*model).milestoneBanner,
+		(*model).milestoneBonus,
+		(*model).milestoneFanfare,
+		(*model).milestoneAchievement,
+		(*model).milestonePerks,
+	},
+}
+
+// emit announces ev to its subscribers
+func (m *model) emit(ev runEvent, n int) {
+	for _, h := range runHandlers[ev] {
+		h(m, n)
+	}
+}
@@ -0,0 +1,22 @@
+package gopherdash
+
+// ----------------------------------------------------------------------------
+// CAMERA
+// ----------------------------------------------------------------------------
+
+// camera is the sliding window onto the world. Obstacles keep absolute world
+// positions; only the window moves, one cell per tick, so its left edge is
+// always the distance run.
+type camera struct{ left int }
+
+func (m model) camera() camera { return camera{m.dist} }
+
+// viewCamera is the window the playfield is drawn through, which
+// -latency-comp moves ahead of the gopher (see latency.go)
+func (m model) viewCamera() camera { return camera{m.dist + m.lead()} }
+
+// toScreen maps a world cell to a playfield column (may be off-screen)
+func (c camera) toScreen(w int) int { return w - c.left }
+
+// toWorld maps a playfield column to the world cell currently under it
+func (c camera) toWorld(x int) int { return x + c.left }
@@ -0,0 +1,129 @@
+package gopherdash
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// CHAT CHAOS MODE
+// ----------------------------------------------------------------------------
+
+const (
+	chaosRound    = 30 * time.Second // votes are tallied this often
+	chaosDuration = 10 * time.Second // how long a winning event lasts
+	waveRocks     = 4                // rocks in a chat-summoned wave
+	waveGap       = 8                // a jump spends 6 ticks airborne; leave room to land and go again
+)
+
+// chaosEvents are the things chat can vote for, in tie-break order
+var chaosEvents = []struct{ name, desc string }{
+	{"invert", "controls inverted: S/↓ jumps"},
+	{"speed", "double speed!"},
+	{"wave", "rock wave incoming!"},
+	{"night", "lights out!"},
+}
+
+// chaosRoundMsg closes a voting round
+type chaosRoundMsg struct{}
+
+func (m model) chaosTick() tea.Cmd {
+	return m.after(chaosRound, func(time.Time) tea.Msg { return chaosRoundMsg{} })
+}
+
+// chaos is the vote tally and the currently applied event
+type chaos struct {
+	votes  map[string]int
+	active string    // winning event, if still running
+	until  time.Time // when the active event wears off
+	status string    // chat connection state
+	round  time.Time // when the current round closes
+}
+
+// on reports whether event is running at now
+func (c chaos) on(event string, now time.Time) bool {
+	return c.active == event && now.Before(c.until)
+}
+
+// vote counts one chat vote towards the next round
+func (m *model) vote(event string) {
+	if m.chaos.votes == nil {
+		m.chaos.votes = map[string]int{}
+	}
+	m.chaos.votes[event]++
+}
+
+// closeRound applies the most-voted event and starts a fresh round
+func (m *model) closeRound() tea.Cmd {
+	best, n := "", 0
+	for _, ev := range chaosEvents {
+		if v := m.chaos.votes[ev.name]; v > n {
+			best, n = ev.name, v
+		}
+	}
+	m.chaos.votes = nil
+	m.chaos.round = m.now().Add(chaosRound)
+	if best != "" && m.live() {
+		m.chaos.active = best
+		m.chaos.until = m.now().Add(chaosDuration)
+		m.logInfo("chaos event", "event", best, "votes", n)
+		if best == "wave" {
+			m.obstacles = append(m.obstacles, m.spawn.wave(waveRocks, waveGap)...)
+		}
+	}
+	return m.chaosTick()
+}
+
+// isJumpKey maps keys to the jump action, honouring inverted controls
+// (which one key can't swap)
+func (m model) isJumpKey(key string) bool {
+	if m.mirrored() && !m.keys().oneKey {
+		return slices.Contains(m.keys().dive, key)
+	}
+	return slices.Contains(m.keys().jump, key)
+}
+
+// isDiveKey maps keys to the dive action (see physics.go), which swaps
+// with jump under inverted controls
+func (m model) isDiveKey(key string) bool {
+	if m.mirrored() && !m.keys().oneKey {
+		return slices.Contains(m.keys().jump, key)
+	}
+	return slices.Contains(m.keys().dive, key)
+}
+
+// tickDur is the delay until the next gameplay step
+func (m model) tickDur() time.Duration {
+	d := m.frameDur
+	if m.chaos.on("speed", m.now()) {
+		d /= 2
+	}
+	if m.mod(modDoubleSpeed) {
+		d /= 2
+	}
+	if m.playback != nil {
+		d /= time.Duration(m.playback.speed)
+	}
+	return d
+}
+
+// chaosHUD shows the running tally and the active event
+func (m model) chaosHUD() string {
+	if m.chaos.active != "" && m.chaos.on(m.chaos.active, m.now()) {
+		for _, ev := range chaosEvents {
+			if ev.name == m.chaos.active {
+				return "Chat: " + ev.desc
+			}
+		}
+	}
+	parts := make([]string, 0, len(chaosEvents))
+	for _, ev := range chaosEvents {
+		parts = append(parts, fmt.Sprintf("!%s %d", ev.name, m.chaos.votes[ev.name]))
+	}
+	left := max(int(m.chaos.round.Sub(m.now()).Seconds()), 0)
+	return fmt.Sprintf("%s  %s (%ds)", m.chaos.status, strings.Join(parts, " "), left)
+}
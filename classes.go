@@ -0,0 +1,93 @@
+package gopherdash
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// CHARACTER CLASSES (skins with abilities)
+// ----------------------------------------------------------------------------
+
+// character is a selectable gopher: its look and the physics it runs with
+type character struct {
+	Name     string
+	Sprite   string
+	JumpVel  int     // take-off speed; negative is up
+	Gravity  int     // added to the vertical speed every tick
+	Accel    float64 // frame time multiplier per tick; nearer 1 speeds up slower
+	AirJumps int     // extra jumps before touching down again
+	Slim     bool    // smaller hitbox: coming down onto a rock's edge slips past it
+	Shields  int     // fatal hits shrugged off per run
+}
+
+// characters lists the classes; the first is the default. Every class
+// stays in the air for jumpCells steps so the spawner's fairness rules hold
+// for all of them: the heavy gopher's higher jump comes from a harder
+// take-off against double gravity.
+var characters = []character{
+	{Name: "gopher", Sprite: playerChar, JumpVel: jumpVel, Gravity: gravity, Accel: accelFactor},
+	{Name: "heavy", Sprite: "🦫", JumpVel: -8, Gravity: 2, Accel: 0.999},
+	{Name: "ninja", Sprite: "🥷", JumpVel: jumpVel, Gravity: gravity, Accel: accelFactor, AirJumps: 1, Slim: true},
+	{Name: "tank", Sprite: "🦔", JumpVel: jumpVel, Gravity: gravity, Accel: accelFactor, Shields: 1},
+}
+
+// characterByName looks a class up; "" is the default
+func characterByName(name string) (character, bool) {
+	if name == "" {
+		return characters[0], true
+	}
+	for _, c := range characters {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return character{}, false
+}
+
+// characterNames lists the classes for flag help and errors
+func characterNames() string {
+	names := make([]string, len(characters))
+	for i, c := range characters {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// character is the class this run is played with
+func (m model) character() character {
+	c, ok := characterByName(m.cfg.Class)
+	if !ok {
+		return characters[0] // a hand-edited state dump
+	}
+	return c
+}
+
+// airJump uses up one of the class's mid-air jumps, or the higher-jump
+// perks', if it has any left
+func (m *model) airJump() bool {
+	if m.airJumps >= m.character().AirJumps+m.perk(perkJump) {
+		return false
+	}
+	m.airJumps++
+	m.logDebug("air jump", "used", m.airJumps)
+	m.jump()
+	return true
+}
+
+// shrugOff spends a shield on a fatal hit, if one is left
+func (m *model) shrugOff(ob obstacle) bool {
+	if m.hits >= m.shields() {
+		return false
+	}
+	m.hits++
+	m.combo = 0
+	m.logInfo("collision", "kind", ob.kind.Name(), "x", ob.x, "hit", "shielded")
+	m.notify("Shield broken!")
+	return true
+}
+
+// shieldHUD shows the shields left
+func (m model) shieldHUD() string {
+	return fmt.Sprintf("🛡️×%d", m.shields()-m.hits)
+}
@@ -0,0 +1,106 @@
+package gopherdash
+
+import (
+	"math"
+	"testing"
+)
+
+func classModel(t *testing.T, class string) model {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Class = 0, class
+	m, _ := clockedModel(t, cfg)
+	m.obstacles = nil
+	return m
+}
+
+// Every class is in the air for jumpCells steps, the heavy gopher's harder
+// take-off against double gravity included, or the spawner's fairness
+// rules wouldn't hold for it.
+func TestClassAirTime(t *testing.T) {
+	for _, c := range characters {
+		if arc := jumpArc(c.JumpVel, c.Gravity, math.MaxInt, 1); len(arc) != jumpCells {
+			t.Errorf("%s: arc %v, want %d steps", c.Name, arc, jumpCells)
+		}
+		m := classModel(t, c.Name)
+		m.jump()
+		air := 0
+		for m.step(m.now()); !m.grounded() && air < 2*jumpCells; m.step(m.now()) {
+			air++
+		}
+		if air != jumpCells {
+			t.Errorf("%s: in the air %d steps, want %d", c.Name, air, jumpCells)
+		}
+	}
+}
+
+// The tank shrugs off one fatal hit a run, knocking the rock away.
+func TestClassTankShield(t *testing.T) {
+	m := classModel(t, "tank")
+	m.obstacles = []obstacle{{m.body().lo + 1, rock{}}, {m.body().lo + 3, rock{}}}
+	m.step(m.now())
+	near := 0 // the step may have spawned more at the far edge
+	for _, ob := range m.obstacles {
+		if ob.x < m.body().lo+10 {
+			near++
+		}
+	}
+	if m.gameOver || m.hits != 1 || near != 1 {
+		t.Fatalf("first rock: game over %v, %d hits, %d rocks left", m.gameOver, m.hits, near)
+	}
+	if m.shieldHUD() != "🛡️×0" {
+		t.Errorf("HUD shows %q with the shield spent", m.shieldHUD())
+	}
+	for range 2 {
+		m.step(m.now())
+	}
+	if !m.gameOver || m.cause != "rock" {
+		t.Errorf("second rock: game over %v, cause %q", m.gameOver, m.cause)
+	}
+}
+
+// The ninja gets one jump in mid-air, and until it lands no more.
+func TestClassNinjaAirJump(t *testing.T) {
+	for _, tc := range []struct {
+		class string
+		air   int // air jumps the class gets
+	}{
+		{"gopher", 0},
+		{"ninja", 1},
+	} {
+		m := classModel(t, tc.class)
+		m.pressJump()
+		m.step(m.now())
+		m.step(m.now())
+		for range 2 {
+			m.pressJump()
+		}
+		if m.airJumps != tc.air || m.jumps != 1+tc.air {
+			t.Errorf("%s: %d air jumps, %d jumps, want %d air jumps", tc.class, m.airJumps, m.jumps, tc.air)
+		}
+	}
+}
+
+// The ninja's slim hitbox slips past a rock it comes down on the edge of;
+// anyone else trips on it.
+func TestClassNinjaSlim(t *testing.T) {
+	if rock.Collides(rock{}, player{landing: true, slim: true}) != miss {
+		t.Error("a slim landing on a rock's edge hits it")
+	}
+	for _, tc := range []struct {
+		class string
+		dies  bool
+	}{
+		{"gopher", true},
+		{"ninja", false},
+	} {
+		m := classModel(t, tc.class)
+		m.obstacles = []obstacle{{m.body().lo + jumpCells + 1, rock{}}}
+		m.jump()
+		for range jumpCells + 3 {
+			m.step(m.now())
+		}
+		if m.gameOver != tc.dies {
+			t.Errorf("%s landing on a rock: game over %v, want %v", tc.class, m.gameOver, tc.dies)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package gopherdash
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// CLOCK (where the model gets the time from)
+// ----------------------------------------------------------------------------
+
+// Everything the model times – ticks, the countdown, the restart cooldown,
+// pauses, notices, chaos rounds – goes through its clock instead of
+// time.Now and tea.Tick. The game runs on the wall clock; tests swap in one
+// that only moves when a scheduled tick fires, so a whole run can play out
+// deterministically in no time at all. Calendar things (daily and weekly
+// seeds) and real measurements (frame times, network deadlines) stay on
+// the wall clock.
+
+// gameClock tells the model the time and schedules its ticks
+type gameClock interface {
+	now() time.Time
+	// after is tea.Tick on this clock: a command that fires fn d from now
+	after(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd
+}
+
+// wallClock is real time
+type wallClock struct{}
+
+func (wallClock) now() time.Time { return time.Now() }
+
+func (wallClock) after(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	return tea.Tick(d, fn)
+}
+
+// clocked is the model's clock; models built without one run on real time
+func (m model) clocked() gameClock {
+	if m.clock == nil {
+		return wallClock{}
+	}
+	return m.clock
+}
+
+// now is the current time on the model's clock
+func (m model) now() time.Time { return m.clocked().now() }
+
+// after schedules fn d from now on the model's clock
+func (m model) after(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	return m.clocked().after(d, fn)
+}
@@ -0,0 +1,96 @@
+package gopherdash
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// manualClock stands still until a command it scheduled runs, which jumps
+// it straight to the time that command was due
+type manualClock struct{ t time.Time }
+
+func (c *manualClock) now() time.Time { return c.t }
+
+func (c *manualClock) after(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		c.t = c.t.Add(d)
+		return fn(c.t)
+	}
+}
+
+// clockedModel is a sized game on a manual clock, saving nothing to disk
+func clockedModel(t *testing.T, cfg config) (model, *manualClock) {
+	saves := isolateSaves(t)
+	c := &manualClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.Store = "memory"
+	m := initialModel(cfg, saves)
+	m.clock, m.offer, m.crashNote = c, nil, nil
+	m.started = c.t
+	m.startIntro()
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return next.(model), c
+}
+
+func TestClockCountdown(t *testing.T) {
+	m, c := clockedModel(t, defaultConfig())
+	for _, want := range []string{"3", "2", "1", "GO!"} {
+		if got := m.introLabel(); got != want {
+			t.Fatalf("at %v: countdown shows %q, want %q", c.t.Format("15:04:05"), got, want)
+		}
+		c.t = c.t.Add(time.Second)
+	}
+	c.t = c.t.Add(goFlash - time.Second)
+	if m.inIntro() || m.introLabel() != "" {
+		t.Errorf("countdown still up after it ran out: %q", m.introLabel())
+	}
+}
+
+func TestClockCooldown(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.RestartHold = 0, 0
+	m, c := clockedModel(t, cfg)
+	m.dist = 10
+	m.setGameOver("rock")
+	space := tea.KeyMsg{Type: tea.KeySpace}
+	next, _ := m.Update(space)
+	if m = next.(model); !m.gameOver {
+		t.Fatal("restarted during the cooldown")
+	}
+	c.t = c.t.Add(cooldownSeconds*time.Second + time.Millisecond)
+	next, _ = m.Update(space)
+	if m = next.(model); m.gameOver {
+		t.Fatal("no restart once the cooldown was over")
+	}
+}
+
+// TestClockAcceleration plays ticks as fast as they can be run: the clock
+// should have moved by exactly the frames the run asked for, each one
+// accelFactor shorter than the last
+func TestClockAcceleration(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.GraceCells = 0, 1000
+	m, c := clockedModel(t, cfg)
+	start := c.t
+	var elapsed time.Duration
+	cmd := m.tickAfter(m.frameDur, m.tickGen)
+	const ticks = 200
+	for i := 0; i < ticks; i++ {
+		elapsed += m.frameDur
+		var next tea.Model
+		next, cmd = m.Update(cmd())
+		m = next.(model)
+	}
+	if m.gameOver || m.dist != ticks {
+		t.Fatalf("ran %d cells (game over: %v), want %d", m.dist, m.gameOver, ticks)
+	}
+	if got := c.t.Sub(start); got != elapsed {
+		t.Errorf("clock moved %v, want %v", got, elapsed)
+	}
+	want := float64(startFrame) * math.Pow(accelFactor, ticks)
+	if got := float64(m.frameDur); math.Abs(got-want) > float64(ticks) {
+		t.Errorf("frame time %v after %d ticks, want about %v", m.frameDur, ticks, time.Duration(want))
+	}
+}
@@ -0,0 +1,236 @@
+package gopherdash
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// CLOUD SYNC (profile archive on a WebDAV or S3-compatible endpoint)
+// ----------------------------------------------------------------------------
+
+// With sync_url set, the profile archive (see archive.go) is kept at that
+// URL: on startup it's downloaded and imported, scores merging and
+// everything else going to whichever side wrote last, and on exit the saves
+// are exported and uploaded over it. sync_kind picks the protocol: "webdav"
+// (HTTP basic auth with sync_user and sync_secret; the folder must exist)
+// or "s3", a path-style object URL signed with AWS signature v4 using
+// sync_user and sync_secret as the access key and secret key. The secret is
+// left out of exported archives, doctor bundles, state dumps and crash
+// reports (see config.redacted). The stats screen shows how the last sync
+// went.
+
+const syncTimeout = 10 * time.Second
+
+// syncer is a remote copy of the saves
+type syncer interface {
+	// pull imports the remote copy, if there is one, into saves and says
+	// how it went
+	pull(saves Store, sign bool) string
+	// push uploads the saves as they are now
+	push(saves Store) error
+	// host names the remote in status lines
+	host() string
+}
+
+// openSyncs are the syncs cfg turns on: cloud sync and the gist (see
+// gist.go), either or both
+func openSyncs(cfg config) ([]syncer, error) {
+	var syncs []syncer
+	cloud, err := newCloudSync(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cloud != nil {
+		syncs = append(syncs, cloud)
+	}
+	gist, err := newGistSync(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if gist != nil {
+		syncs = append(syncs, gist)
+	}
+	return syncs, nil
+}
+
+// cloudSync is the configured remote copy of the saves
+type cloudSync struct {
+	url, kind    string
+	user, secret string
+	region       string
+	client       *http.Client
+}
+
+// newCloudSync is the sync cfg asks for, or nil if it's off
+func newCloudSync(cfg config) (*cloudSync, error) {
+	if cfg.SyncURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(cfg.SyncURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("sync url %q: want an http(s) URL of the archive", cfg.SyncURL)
+	}
+	if cfg.SyncKind != "webdav" && cfg.SyncKind != "s3" {
+		return nil, fmt.Errorf("sync kind %q: want webdav or s3", cfg.SyncKind)
+	}
+	return &cloudSync{
+		url: cfg.SyncURL, kind: cfg.SyncKind,
+		user: cfg.SyncUser, secret: cfg.SyncSecret, region: cfg.SyncRegion,
+		client: &http.Client{Timeout: syncTimeout},
+	}, nil
+}
+
+func (c *cloudSync) host() string {
+	u, _ := url.Parse(c.url)
+	return u.Host
+}
+
+func (c *cloudSync) pull(saves Store, sign bool) string {
+	data, found, err := c.get()
+	switch {
+	case err != nil:
+		return fmt.Sprintf("Sync: couldn't reach %s: %v", c.host(), err)
+	case !found:
+		return fmt.Sprintf("Sync: nothing on %s yet", c.host())
+	}
+	tmp, err := os.CreateTemp("", "gopherdash-sync-*.tar.gz")
+	if err != nil {
+		return "Sync: " + err.Error()
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "Sync: " + err.Error()
+	}
+	entries, err := readArchive(tmp.Name())
+	if err != nil {
+		return fmt.Sprintf("Sync: the archive on %s is unusable: %v", c.host(), err)
+	}
+	for _, line := range importArchive(saves, entries, "newer", sign) {
+		logger.Info("sync pull", "result", line)
+	}
+	return fmt.Sprintf("Sync: pulled from %s at %s", c.host(), time.Now().Format("15:04"))
+}
+
+func (c *cloudSync) push(saves Store) error {
+	tmp, err := os.CreateTemp("", "gopherdash-sync-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := writeArchive(saves, tmp.Name()); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	return c.put(data)
+}
+
+func (c *cloudSync) get() (data []byte, found bool, err error) {
+	resp, err := c.do(http.MethodGet, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("GET: %s", resp.Status)
+	}
+	data, err = io.ReadAll(io.LimitReader(resp.Body, archiveMaxFile))
+	return data, err == nil, err
+}
+
+func (c *cloudSync) put(data []byte) error {
+	resp, err := c.do(http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("PUT: %s", resp.Status)
+	}
+	return nil
+}
+
+// do sends one request for the archive, authenticated for the endpoint
+func (c *cloudSync) do(method string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	switch {
+	case c.kind == "s3":
+		signV4(req, body, c.user, c.secret, c.region, time.Now())
+	case c.user != "":
+		req.SetBasicAuth(c.user, c.secret)
+	}
+	return c.client.Do(req)
+}
+
+// signV4 signs req for S3 with AWS signature version 4
+func signV4(req *http.Request, body []byte, key, secret, region string, at time.Time) {
+	if region == "" {
+		region = "us-east-1"
+	}
+	at = at.UTC()
+	stamp, day := at.Format("20060102T150405Z"), at.Format("20060102")
+	payload := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payload[:]))
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, name := range names {
+		canonHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+	canonical := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery,
+		canonHeaders.String(), signed, hex.EncodeToString(payload[:]),
+	}, "\n")
+	scope := day + "/" + region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	mac := func(k []byte, s string) []byte {
+		h := hmac.New(sha256.New, k)
+		h.Write([]byte(s))
+		return h.Sum(nil)
+	}
+	k := mac([]byte("AWS4"+secret), day)
+	k = mac(k, region)
+	k = mac(k, "s3")
+	k = mac(k, "aws4_request")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		key, scope, signed, hex.EncodeToString(mac(k, toSign))))
+}
@@ -0,0 +1,13 @@
+// Command gopherdash is the terminal game; the game itself, which other
+// Bubble Tea programs can embed, is the gopherdash package at the module root.
+package main
+
+import (
+	"os"
+
+	"github.com/krisfur/gopherdash"
+)
+
+func main() {
+	os.Exit(gopherdash.Main(os.Args[1:]))
+}
@@ -0,0 +1,117 @@
+package gopherdash
+
+// ----------------------------------------------------------------------------
+// COLLISION GEOMETRY
+// ----------------------------------------------------------------------------
+
+// Collisions compare extents rather than single columns: the world cells the
+// gopher and each hazard cover before and after a step. A hazard meets the
+// gopher if the two overlap when the step ends, or anywhere on the hazard's
+// path relative to the gopher during the step, which a hazard that moves
+// more than a cell a step relative to the gopher would otherwise skip.
+// Along the way the gopher is where its trajectory had it at that point of
+// the step, so a hazard can pass under a jump it meets at the top and still
+// catch the gopher on the way down.
+
+// span is a run of world cells, lo to hi inclusive
+type span struct{ lo, hi int }
+
+func (s span) overlaps(o span) bool { return s.lo <= o.hi && o.lo <= s.hi }
+
+func (s span) shift(d int) span { return span{s.lo + d, s.hi + d} }
+
+// extent is the cells ob covers
+func (ob obstacle) extent() span { return span{ob.x, ob.x + ob.kind.Width() - 1} }
+
+// body is the cells the gopher covers
+func (m model) body() span {
+	at := m.camera().toWorld(playerCol)
+	return span{at, at}
+}
+
+// sweep walks a hazard's path relative to the gopher during a step, a cell
+// at a time, from was (against the gopher on pwas) to now (against pnow).
+// At each cell of the path where the two overlap, see tells what the
+// hazard does to the gopher k of n of the way through the step, and the
+// first hit that isn't a miss ends the walk. met is whether they touched
+// at all.
+func sweep(was, now, pwas, pnow span, see func(k, n int) hit) (h hit, met bool) {
+	body := pnow.shift(-pnow.lo)
+	from, to := was.shift(-pwas.lo), now.shift(-pnow.lo)
+	n, dir := to.lo-from.lo, 1
+	if n < 0 {
+		n, dir = -n, -1
+	}
+	if n == 0 { // kept pace with the gopher
+		if to.overlaps(body) {
+			return see(1, 1), true
+		}
+		return miss, false
+	}
+	// k = 0 is where the last step left them, and was checked then
+	for k := 1; k <= n; k++ {
+		if from.shift(dir * k).overlaps(body) {
+			met = true
+			if h = see(k, n); h != miss {
+				return h, true
+			}
+		}
+	}
+	return miss, met
+}
+
+// contact is a hazard meeting the gopher during a step
+type contact struct {
+	ob  obstacle
+	p   player // the gopher when they met
+	hit hit
+}
+
+// advanceObstacles moves the hazards that move and forgets those the camera
+// has left behind. It returns where the ones that moved were before.
+func (m *model) advanceObstacles() (moved map[obstacle]span) {
+	cam := m.camera()
+	kept := m.obstacles[:0]
+	for _, ob := range m.obstacles {
+		before := ob.extent()
+		if ob.x = ob.kind.Advance(ob.x); ob.extent() != before {
+			if moved == nil {
+				moved = map[obstacle]span{}
+			}
+			moved[ob] = before
+		}
+		if cam.toScreen(ob.x) >= -1 {
+			kept = append(kept, ob)
+		}
+	}
+	m.obstacles = kept
+	return moved
+}
+
+// reached lists the hazards that met the gopher during the step just
+// taken, given where those that moved were before it and the gopher before
+// (p0) and after (p) it
+func (m model) reached(moved map[obstacle]span, p0, p player) []contact {
+	pnow := m.body()
+	pwas := pnow.shift(-1) // one cell a step
+	var met []contact
+	for _, ob := range m.obstacles {
+		before, ok := moved[ob]
+		if !ok {
+			before = ob.extent()
+		}
+		c := contact{ob: ob}
+		see := func(k, n int) hit {
+			c.p = p
+			if k < n { // partway: no landing yet, at a height between
+				c.p = player{height: p0.height + (p.height-p0.height)*k/n, slim: p.slim}
+			}
+			return ob.kind.Collides(c.p)
+		}
+		var touched bool
+		if c.hit, touched = sweep(before, ob.extent(), pwas, pnow, see); touched {
+			met = append(met, c)
+		}
+	}
+	return met
+}
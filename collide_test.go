@@ -0,0 +1,79 @@
+package gopherdash
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSweep(t *testing.T) {
+	gopher := span{10, 10} // after the step; it was on 9
+	for _, tc := range []struct {
+		name     string
+		was, now span
+		at       []int // the points of the step (k of n) where they touch
+		n        int
+	}{
+		{"static, on the gopher", span{10, 10}, span{10, 10}, []int{1}, 1},
+		{"static, ahead", span{11, 11}, span{11, 11}, nil, 1},
+		{"static, passed last step", span{9, 9}, span{9, 9}, nil, 1},
+		{"moved onto the gopher", span{13, 13}, span{10, 10}, []int{4}, 4},
+		{"moved through the gopher", span{12, 12}, span{7, 7}, []int{3}, 6},
+		{"moved up to the gopher", span{14, 14}, span{11, 11}, nil, 4},
+		{"caught up from behind", span{6, 6}, span{12, 12}, []int{3}, 5},
+		{"kept pace, on the gopher", span{9, 9}, span{10, 10}, []int{1}, 1},
+		{"wide, trailing end on the gopher", span{8, 10}, span{8, 10}, []int{1}, 1},
+		{"wide, moved through", span{12, 14}, span{6, 8}, []int{3, 4, 5}, 7},
+	} {
+		var at []int
+		_, met := sweep(tc.was, tc.now, gopher.shift(-1), gopher, func(k, n int) hit {
+			if n != tc.n {
+				t.Errorf("%s: %d points in the step, want %d", tc.name, n, tc.n)
+			}
+			at = append(at, k)
+			return miss // walk the whole path
+		})
+		if met != (len(tc.at) > 0) || !slices.Equal(at, tc.at) {
+			t.Errorf("%s: touched at %v, want %v", tc.name, at, tc.at)
+		}
+	}
+}
+
+// roller is a hazard rolling towards the gopher faster than it runs
+type roller struct{ rock }
+
+func (roller) Name() string          { return "roller" }
+func (roller) Advance(x int) int     { return x - 3 }
+func (roller) Death(dist int) string { return fmt.Sprintf("Rolled over at %d", dist) }
+
+func TestFastHazardsDontTunnel(t *testing.T) {
+	for _, tc := range []struct {
+		ahead int // cells between the gopher and the roller
+		air   int // rows the gopher is off the ground; -1 is a row and falling
+		dies  bool
+	}{
+		{2, 0, true}, // ends the step two cells behind the gopher
+		{3, 0, true},
+		{4, 0, true}, // ends it on the gopher's cell
+		{5, 0, false},
+		{2, 3, false},
+		{2, -1, false}, // passed under the gopher while it was coming down
+		{4, -1, true},  // reached it as it landed
+	} {
+		cfg := defaultConfig()
+		cfg.Countdown = 0
+		m, _ := clockedModel(t, cfg)
+		clearHazards(&m)
+		at := m.camera().toWorld(playerCol)
+		m.obstacles = append(m.obstacles, obstacle{at + tc.ahead, roller{}})
+		m.playerY = m.groundRow() - tc.air
+		if tc.air < 0 {
+			m.playerY, m.velY = m.groundRow()-1, 1
+		}
+		m.step(m.now())
+		if m.gameOver != tc.dies {
+			t.Errorf("roller %d cells ahead, gopher %d up: game over = %v, want %v",
+				tc.ahead, tc.air, m.gameOver, tc.dies)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package gopherdash
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// COMPACT LAYOUT (narrow terminals)
+// ----------------------------------------------------------------------------
+
+// Below compactWidth columns, as in a phone's SSH client held upright, the
+// three boxed panes don't fit. The compact layout stacks the HUD over
+// compactHUDRows plain lines, draws the playfield between two rules with no
+// side borders and puts the controls on one bare line. When even that can't
+// fit compactCells emoji-wide cells, every cell is drawn one column wide
+// with the narrow glyphs below, so the playfield never drops under
+// compactCells cells.
+//
+// The minimal layout (-minimal), for tiling window managers and small
+// panes, drops the boxes and the controls altogether: one status line over
+// the bare playfield, which goes narrow the same way below 40 columns.
+
+const (
+	compactWidth   = 48
+	compactCells   = 20
+	compactHUDRows = 2
+)
+
+// narrowGlyphs stand in for the two-column sprites and tiles in one column;
+// anything not listed is drawn as narrowUnknown
+var narrowGlyphs = map[string]string{
+	"  ":            " ",
+	playerChar:      "@",
+	"🦫":             "B",
+	"🥷":             "N",
+	"🦔":             "H",
+	rockChar:        "▲",
+	logChar:         "▬",
+	"🎃":             "●",
+	groundChar:      "▀",
+	"⬜":             "▔",
+	wallChar:        "█",
+	acornChar:       "•",
+	foxChar:         "F",
+	"🟨":             "^",
+	"🟦":             "»",
+	fogChar:         "░",
+	nightGroundChar: "▁",
+}
+
+const narrowUnknown = "*"
+
+// compact reports whether the window is too narrow for the boxed layout
+func (m model) compact() bool { return m.w > 0 && m.w < compactWidth }
+
+// narrow reports whether playfield cells are drawn one column wide
+func (m model) narrow() bool {
+	return (m.compact() || m.cfg.Minimal) && m.w < 2*compactCells
+}
+
+// pane frames a centre pane: a full box, in the compact layout just a rule
+// above and below, and in the minimal layout not at all
+func (m model) pane(s string) string {
+	if m.cfg.Minimal {
+		return s
+	}
+	style := lipgloss.NewStyle().Border(lipgloss.NormalBorder())
+	if m.compact() {
+		style = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), true, false)
+	}
+	return style.Width(m.w).Render(s)
+}
+
+// compactControls squeezes the spacing out of a controls line
+var compactControls = strings.NewReplacer(" = ", "=", "   ", "  ")
+
+// bar is the controls pane, "" in the minimal layout
+func (m model) bar(text string) string {
+	switch {
+	case m.cfg.Minimal:
+		return ""
+	case m.compact():
+		return truncate(compactControls.Replace(text), m.w)
+	}
+	return lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Width(m.w).
+		Align(lipgloss.Left).Render(pad(text, m.w-2))
+}
+
+// renderHUD is the HUD pane for status; in the compact layout its parts,
+// separated by three spaces, are packed onto compactHUDRows lines, and in
+// the minimal layout it's one bare line
+func (m model) renderHUD(status string) string {
+	if m.cfg.Minimal {
+		return truncate(status, m.w)
+	}
+	if !m.compact() {
+		return lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Width(m.w).
+			Align(lipgloss.Left).Render(pad(status, m.w-2))
+	}
+	lines := make([]string, 1, compactHUDRows)
+	for _, part := range strings.Split(status, "   ") {
+		last := &lines[len(lines)-1]
+		switch {
+		case *last == "":
+			*last = part
+		case lipgloss.Width(*last)+2+lipgloss.Width(part) <= m.w:
+			*last += "  " + part
+		case len(lines) < compactHUDRows:
+			lines = append(lines, part)
+		default:
+			*last += "  " + part // cut off below
+		}
+	}
+	for len(lines) < compactHUDRows {
+		lines = append(lines, "")
+	}
+	for i, l := range lines {
+		lines[i] = truncate(l, m.w)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate cuts s to at most w columns
+func truncate(s string, w int) string {
+	return lipgloss.NewStyle().MaxWidth(max(w, 1)).Render(s)
+}
+
+// narrowRow joins a row of two-column cells drawn one column each. Text
+// stamped over the row keeps both its characters and takes its extra
+// columns from the blank cells after it, so it stays readable.
+func narrowRow(cells []string) string {
+	var b strings.Builder
+	debt := 0
+	for _, c := range cells {
+		n := narrowCell(c)
+		if n == "" {
+			b.WriteString(c) // two characters of stamped text
+			debt++
+			continue
+		}
+		if n == " " && debt > 0 {
+			debt--
+			continue
+		}
+		b.WriteString(n)
+	}
+	return b.String()
+}
+
+// narrowCell is the one-column form of a cell, or "" for a cell of stamped
+// text, which has no such form. Styled cells keep their styling.
+func narrowCell(c string) string {
+	if n, ok := narrowGlyphs[c]; ok {
+		return n
+	}
+	if strings.Contains(c, "\x1b") {
+		text := visible(c)
+		n := narrowCell(text)
+		if n == "" {
+			n = narrowUnknown
+		}
+		return strings.Replace(c, text, n, 1)
+	}
+	r := []rune(c)
+	if len(r) != 2 || lipgloss.Width(c) != 2 {
+		return narrowUnknown
+	}
+	word := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	switch {
+	case word(r[0]) || word(r[1]):
+		return ""
+	case r[0] == r[1] || r[1] == ' ':
+		return string(r[0]) // a doubled block, or a radar mark
+	case r[0] == ' ':
+		return string(r[1])
+	}
+	return ""
+}
+
+// visible strips the escape sequences from a styled cell
+func visible(c string) string {
+	var b strings.Builder
+	for i := 0; i < len(c); {
+		if c[i] == '\x1b' {
+			j := i + 1
+			if j < len(c) && c[j] == '[' {
+				for j++; j < len(c) && (c[j] < 0x40 || c[j] > 0x7e); j++ {
+				}
+			}
+			i = j + 1
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(c[i:])
+		b.WriteString(c[i : i+size])
+		i += size
+	}
+	return b.String()
+}
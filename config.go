@@ -0,0 +1,397 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// CONFIGURATION
+// ----------------------------------------------------------------------------
+
+const configFile = ".gopherdash_config"
+
+// config holds user settings; loaded from ./.gopherdash_config (JSON) next to
+// the binary, then overridden by command-line flags
+type config struct {
+	ReducedMotion bool `json:"reduced_motion"` // skip purely decorative animation
+	BatterySaver  bool `json:"battery_saver"`  // fewer redraws and no decorative layers
+	Countdown     int  `json:"countdown"`      // seconds of 3‑2‑1 before each run; 0 disables
+	IdlePause     int  `json:"idle_pause"`     // pause after this many seconds without input; 0 disables
+
+	Seed   int64 `json:"seed"`   // replay the same course every run; 0 = random
+	Daily  bool  `json:"daily"`  // use today's shared seed (overrides Seed)
+	Weekly bool  `json:"weekly"` // this week's seed and modifiers (overrides Daily and Seed)
+
+	Mods []string `json:"mods"` // run modifiers on top of any weekly ones (see modifiers.go)
+
+	Practice bool `json:"practice"`  // radar of upcoming obstacles; runs don't set high scores
+	Streak   bool `json:"streak"`    // hardcore: each run must beat the streak's target (see streak.go)
+	Timer    bool `json:"timer"`     // speed-run clock with splits every 100 distance
+	Night    bool `json:"night"`     // flashlight cone only, with the odd lightning flash
+	Sky      bool `json:"sky"`       // truecolor sky gradient that follows the time of day (see sky.go)
+	Terrain  bool `json:"terrain"`   // hills and raised platforms instead of flat ground
+	Fox      bool `json:"fox"`       // a chaser that closes in on every near-miss
+	Events   bool `json:"events"`    // seasonal themes and achievements from the event calendar
+	Music    bool `json:"music"`     // chiptune loop through the system's audio player (see music.go)
+	Notify   bool `json:"notify"`    // desktop notifications for new records and achievements (see desktop.go)
+	PhotoSVG bool `json:"photo_svg"` // photo mode also saves an SVG of the frame
+	Hitboxes bool `json:"hitboxes"`  // colour the cells collisions are checked on (see hitbox.go)
+	Coop     bool `json:"coop"`      // two players on one keyboard: P1 jumps, P2 dives and throws (see coop.go)
+	Director bool `json:"director"`  // pace the stream by the player's stress (see director.go)
+	Zones    bool `json:"zones"`     // stretches of track that score double, with more hazards (see zones.go)
+	Perks    bool `json:"perks"`     // a choice of two perks every 200 distance (see perks.go)
+
+	Keys string `json:"keys"` // control scheme: default, ijkl, numpad or one (see keymaps.go)
+
+	Bot       string `json:"bot"`        // race the bot at easy, normal, hard or perfect; "" = off (see botrace.go)
+	BotTarget int    `json:"bot_target"` // distance the race with the bot is to
+
+	Class   string `json:"class"`   // character class: gopher, heavy, ninja or tank
+	Physics string `json:"physics"` // classic (whole rows) or momentum (fixed point)
+	Smooth  bool   `json:"smooth"`  // draw a half-cell frame between ticks
+	Minimal bool   `json:"minimal"` // no boxes or controls: a status line over the playfield
+	Scoring string `json:"scoring"` // rules from .gopherdash_scoring; "" = classic (see scoring.go)
+
+	Renderer string `json:"renderer"` // text, auto, kitty, iterm2 or sixel (see graphics.go)
+
+	MaxCols int `json:"max_cols"` // widest playfield in cells; wider windows are letterboxed; 0 = no cap
+	MaxRows int `json:"max_rows"` // tallest playfield in rows; 0 = no cap
+
+	TickRate  int `json:"tick_rate"`  // most simulation wakeups per second; 0 = uncapped
+	RenderFPS int `json:"render_fps"` // most redraws per second during a run; 0 = uncapped
+
+	Twitch string `json:"twitch"` // channel whose chat votes on chaos events; "" = off
+
+	MetricsAddr string `json:"metrics_addr"` // serve Prometheus /metrics here; "" = off
+
+	// limits on the sessions a server hosts (see limits.go)
+	MaxSessions int `json:"max_sessions"` // sessions Join lets in at once; 0 = no cap
+	MaxPerIP    int `json:"max_per_ip"`   // of those, from one address; 0 = no cap
+	IdleKick    int `json:"idle_kick"`    // end a hosted session after this many seconds without input; 0 = never
+
+	// cloud sync of the saves (see cloud.go)
+	SyncURL    string `json:"sync_url"`    // URL of the archive on the endpoint; "" = off
+	SyncKind   string `json:"sync_kind"`   // webdav or s3
+	SyncUser   string `json:"sync_user"`   // WebDAV user, or S3 access key
+	SyncSecret string `json:"sync_secret"` // WebDAV password, or S3 secret key (config file only)
+	SyncRegion string `json:"sync_region"` // S3 region; "" = us-east-1
+
+	// score sync through a private GitHub Gist (see gist.go)
+	GistToken string `json:"gist_token"` // personal access token with the gist scope; "" = off (config file only)
+	GistID    string `json:"gist_id"`    // gist to sync with; "" = the one made on the first upload
+
+	// difficulty
+	JumpBuffer  int  `json:"jump_buffer"`  // ticks an early jump press is remembered for
+	LatencyComp bool `json:"latency_comp"` // draw the course ahead by the profile's measured latency (see latency.go)
+	Coyote      int  `json:"coyote"`       // ticks a jump still counts after running onto a hole
+	GraceCells  int  `json:"grace_cells"`  // obstacle-free cells ahead of the gopher at the start of a run
+
+	RestartHold int `json:"restart_hold_ms"` // hold Space this long to restart; 0 = instant
+	Debounce    int `json:"debounce_ms"`     // a jump key this soon after the last is a key repeat; 0 = off
+	MinTick     int `json:"min_tick_ms"`     // the speed ramp stops at this step length; 0 = no ceiling (see speedcap.go)
+	MaxTick     int `json:"max_tick_ms"`     // no step is longer than this; 0 = no floor
+
+	SignSaves bool   `json:"sign_saves"` // HMAC-sign the profile so edits show up as unverified
+	Store     string `json:"store"`      // where the profile, stats and history live: file, sqlite or memory
+	DB        string `json:"db"`         // database for the sqlite store; "" = .gopherdash.db next to the binary
+
+	LoadState    string `json:"-"`             // start from a state dump (flag only)
+	CheckNow     bool   `json:"-"`             // look for a newer release and exit (flag only)
+	CheckUpdates bool   `json:"check_updates"` // look for a newer release on startup (see update.go)
+
+	// anonymous totals, kept locally until sent by hand (see telemetry.go)
+	Telemetry    bool   `json:"telemetry"`     // opt in to counting them
+	TelemetryURL string `json:"telemetry_url"` // where `gopherdash telemetry send` posts them
+
+	Log      string `json:"log"`       // append a debug log to this file; "" = off
+	LogLevel string `json:"log_level"` // debug, info, warn or error
+}
+
+func defaultConfig() config {
+	return config{
+		Countdown:  3,
+		JumpBuffer: 3,
+		Coyote:     2,
+		GraceCells: defaultGraceCells,
+		Events:     true,
+		Sky:        true,
+		BotTarget:  1000,
+		MaxCols:    80,
+		MaxRows:    30,
+
+		RestartHold: 500,
+		Debounce:    90,
+
+		SyncKind: "webdav",
+
+		LogLevel: "info",
+	}
+}
+
+func configPath() string { return dataPath(configFile) }
+
+// secretKeys are the config fields kept out of archives and bundles
+var secretKeys = []string{"sync_secret", "gist_token"}
+
+// redacted is the config without its secrets, for anything written out
+// that isn't the config file itself: state dumps, crash reports, replays
+// and doctor reports
+func (c config) redacted() config {
+	c.SyncSecret, c.GistToken = "", ""
+	return c
+}
+
+// redactConfig drops the secrets from a config file's contents
+func redactConfig(data []byte) []byte {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil // not a config we can vouch for
+	}
+	for _, k := range secretKeys {
+		delete(fields, k)
+	}
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// keepSecrets is an incoming config file's contents with the secrets of
+// the local one put back, so a redacted copy doesn't wipe them
+func keepSecrets(data, local []byte) []byte {
+	var fields, mine map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil || fields == nil {
+		return nil
+	}
+	_ = json.Unmarshal(local, &mine)
+	for _, k := range secretKeys {
+		if v, ok := mine[k]; ok {
+			fields[k] = v
+		} else {
+			delete(fields, k)
+		}
+	}
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// loadConfig reads the config file if present; a missing or broken file
+// leaves the defaults in place
+func loadConfig() config {
+	cfg := defaultConfig()
+	data, err := os.ReadFile(configPath())
+	if err != nil {
+		return cfg
+	}
+	_ = json.Unmarshal(data, &cfg)
+	return cfg
+}
+
+// parseFlags applies command-line overrides on top of cfg
+func parseFlags(cfg config, args []string) (config, error) {
+	fs := flag.NewFlagSet("gopherdash", flag.ContinueOnError)
+	cfg.bindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if _, ok := characterByName(cfg.Class); !ok {
+		err := fmt.Errorf("unknown class %q (want %s)", cfg.Class, characterNames())
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	if err := validPhysics(cfg.Physics); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	if err := validScoring(cfg.Scoring); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	if err := validRenderer(cfg.Renderer); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	if err := validBot(cfg.Bot); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	if err := validKeys(cfg.Keys); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	if err := validTicks(cfg); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	if err := validMods(cfg.Mods); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+	cfg.clamp()
+	return cfg, nil
+}
+
+// bindFlags registers every game option on fs, writing into cfg
+func (cfg *config) bindFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&cfg.ReducedMotion, "reduced-motion", cfg.ReducedMotion,
+		"disable decorative animations such as confetti")
+	fs.BoolVar(&cfg.BatterySaver, "battery-saver", cfg.BatterySaver,
+		"low-power mode: half the redraws, no decorative layers, HUD redrawn only on change")
+	fs.IntVar(&cfg.Countdown, "countdown", cfg.Countdown,
+		"seconds of countdown before each run (0 = start immediately)")
+	fs.IntVar(&cfg.IdlePause, "idle-pause", cfg.IdlePause,
+		"pause after N seconds without input (0 = never)")
+	fs.Int64Var(&cfg.Seed, "seed", cfg.Seed,
+		"play the same course every run (0 = random)")
+	fs.BoolVar(&cfg.Daily, "daily", cfg.Daily,
+		"play today's daily-challenge seed")
+	fs.BoolVar(&cfg.Minimal, "minimal", cfg.Minimal,
+		"borderless layout: one status line over the playfield, for tiling window managers")
+	fs.IntVar(&cfg.MaxCols, "max-cols", cfg.MaxCols,
+		"widest playfield in cells; wider terminals are letterboxed (0 = no cap)")
+	fs.IntVar(&cfg.MaxRows, "max-rows", cfg.MaxRows,
+		"tallest playfield in rows; taller terminals are letterboxed (0 = no cap)")
+	fs.BoolVar(&cfg.PhotoSVG, "photo-svg", cfg.PhotoSVG,
+		"photo mode (P) also saves an SVG next to the text capture")
+	fs.BoolVar(&cfg.Streak, "streak", cfg.Streak,
+		"hardcore streak: every run must beat a rising target distance or the streak resets")
+	fs.BoolVar(&cfg.Weekly, "weekly", cfg.Weekly,
+		"play this week's challenge: a shared seed with the week's modifiers")
+	fs.Func("mods", "comma-separated run modifiers: "+modNames(), func(s string) error {
+		cfg.Mods = nil
+		for _, name := range strings.Split(s, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Mods = append(cfg.Mods, name)
+			}
+		}
+		return nil
+	})
+	fs.BoolVar(&cfg.Practice, "practice", cfg.Practice,
+		"practice mode: obstacle radar, no high scores")
+	fs.BoolVar(&cfg.Timer, "timer", cfg.Timer,
+		"speed-run timer with splits against your personal best")
+	fs.BoolVar(&cfg.Terrain, "terrain", cfg.Terrain,
+		"generate slopes and raised platforms to run over")
+	fs.BoolVar(&cfg.Fox, "fox", cfg.Fox,
+		"a fox chases the gopher, creeping closer on every near-miss")
+	fs.BoolVar(&cfg.Night, "night", cfg.Night,
+		"night runs: only a flashlight cone ahead of the gopher is lit")
+	fs.BoolVar(&cfg.Sky, "sky", cfg.Sky,
+		"a sky gradient behind the playfield on truecolor terminals, from morning to night")
+	fs.BoolVar(&cfg.Director, "director", cfg.Director,
+		"pace the course: clusters of hazards and breathers, shaped by how hard you're finding it")
+	fs.BoolVar(&cfg.Zones, "zones", cfg.Zones,
+		"multiplier zones: highlighted stretches of track where everything scores double, with more hazards")
+	fs.BoolVar(&cfg.Perks, "perks", cfg.Perks,
+		"every 200 distance, pick one of two perks for the rest of the run; runs with perks set no bests")
+	fs.BoolVar(&cfg.Coop, "coop", cfg.Coop,
+		"local co-op: one player jumps (W/Space), the other throws acorns (→) and dives (↓)")
+	fs.StringVar(&cfg.Keys, "keys", cfg.Keys,
+		"control scheme: default (W/Space, S, D), ijkl (right hand), numpad, or one (Space jumps or dives)")
+	fs.StringVar(&cfg.Bot, "bot", cfg.Bot,
+		"race the bot on a second track: easy, normal, hard or perfect")
+	fs.IntVar(&cfg.BotTarget, "bot-target", cfg.BotTarget,
+		"distance the race with the bot is to")
+	fs.BoolVar(&cfg.Hitboxes, "hitboxes", cfg.Hitboxes,
+		"overlay the collision cells: the gopher, obstacles and the checked column (X toggles)")
+	fs.StringVar(&cfg.Class, "class", cfg.Class,
+		"character class: "+characterNames())
+	fs.StringVar(&cfg.Physics, "physics", cfg.Physics,
+		"physics profile: classic, or momentum for smooth arcs, a capped fall and dives")
+	fs.StringVar(&cfg.Scoring, "scoring", cfg.Scoring,
+		"scoring rules from .gopherdash_scoring, e.g. arcade; only classic sets high scores")
+	fs.StringVar(&cfg.Renderer, "renderer", cfg.Renderer,
+		"draw the sprites as pixel art: auto, kitty, iterm2 or sixel (default text)")
+	fs.BoolVar(&cfg.Smooth, "smooth", cfg.Smooth,
+		"smoother scrolling: draw a frame half a cell on between ticks (colour terminals)")
+	fs.IntVar(&cfg.TickRate, "tick-rate", cfg.TickRate,
+		"wake the simulation at most N times a second, stepping more per wakeup (0 = uncapped)")
+	fs.IntVar(&cfg.RenderFPS, "render-fps", cfg.RenderFPS,
+		"redraw at most N times a second during a run, e.g. 15 on slow links (0 = uncapped)")
+	fs.BoolVar(&cfg.Music, "music", cfg.Music,
+		"play a chiptune loop that speeds up with the game (needs paplay or aplay); N mutes it")
+	fs.BoolVar(&cfg.Notify, "notify", cfg.Notify,
+		"desktop notifications (OSC 9/777) for new high scores and achievements")
+	fs.BoolVar(&cfg.Events, "events", cfg.Events,
+		"seasonal themes and achievements from the calendar in .gopherdash_events")
+	fs.StringVar(&cfg.Twitch, "twitch", cfg.Twitch,
+		"let this Twitch channel's chat vote on chaos events")
+	fs.StringVar(&cfg.MetricsAddr, "metrics-addr", cfg.MetricsAddr,
+		"serve Prometheus metrics on this address, e.g. :9100")
+	fs.IntVar(&cfg.MaxSessions, "max-sessions", cfg.MaxSessions,
+		"hosted games: most sessions at once (0 = no cap)")
+	fs.IntVar(&cfg.MaxPerIP, "max-per-ip", cfg.MaxPerIP,
+		"hosted games: most sessions at once from one address (0 = no cap)")
+	fs.IntVar(&cfg.IdleKick, "idle-kick", cfg.IdleKick,
+		"hosted games: end a session after N seconds without input (0 = never)")
+	fs.StringVar(&cfg.SyncURL, "sync-url", cfg.SyncURL,
+		"keep the saves in sync with the archive at this WebDAV or S3 URL")
+	fs.StringVar(&cfg.SyncKind, "sync-kind", cfg.SyncKind,
+		"sync protocol: webdav or s3")
+	fs.StringVar(&cfg.SyncUser, "sync-user", cfg.SyncUser,
+		"WebDAV user or S3 access key; the secret goes in the config file as sync_secret")
+	fs.StringVar(&cfg.SyncRegion, "sync-region", cfg.SyncRegion,
+		"S3 region (default us-east-1)")
+	fs.StringVar(&cfg.GistID, "gist-id", cfg.GistID,
+		"sync scores with this GitHub Gist; the token goes in the config file as gist_token")
+	fs.IntVar(&cfg.JumpBuffer, "jump-buffer", cfg.JumpBuffer,
+		"ticks an early jump press is buffered for (0 = off)")
+	fs.IntVar(&cfg.Coyote, "coyote", cfg.Coyote,
+		"ticks a late jump is still accepted over a hole (0 = off)")
+	fs.BoolVar(&cfg.LatencyComp, "latency-comp", cfg.LatencyComp,
+		"draw the course ahead of the gopher by the latency `gopherdash latency` measured")
+	fs.IntVar(&cfg.GraceCells, "grace", cfg.GraceCells,
+		"obstacle-free cells at the start of every run")
+	fs.IntVar(&cfg.Debounce, "debounce", cfg.Debounce,
+		"milliseconds after a jump key in which another is taken for a key repeat (0 = off)")
+	fs.IntVar(&cfg.RestartHold, "restart-hold", cfg.RestartHold,
+		"milliseconds Space must be held to restart (0 = instant)")
+	fs.IntVar(&cfg.MinTick, "min-tick", cfg.MinTick,
+		"shortest step in milliseconds, capping how fast the run gets (0 = off; capped runs are ranked apart)")
+	fs.IntVar(&cfg.MaxTick, "max-tick", cfg.MaxTick,
+		"longest step in milliseconds, so the run is never slower than this (0 = off; capped runs are ranked apart)")
+	fs.BoolVar(&cfg.Telemetry, "telemetry", cfg.Telemetry,
+		"count anonymous totals (runs, crashes, terminal) locally, sent only with `gopherdash telemetry send`")
+	fs.BoolVar(&cfg.SignSaves, "sign-saves", cfg.SignSaves,
+		"sign the high score with a per-install key; intact saves show as verified")
+	fs.StringVar(&cfg.Store, "store", cfg.Store,
+		"where the profile, stats and run history are kept: file, sqlite, or memory to save nothing")
+	fs.StringVar(&cfg.DB, "db", cfg.DB,
+		"SQLite database for -store sqlite (default .gopherdash.db next to the binary)")
+	fs.StringVar(&cfg.LoadState, "load-state", cfg.LoadState,
+		"start from a state dump written with Ctrl+D")
+	fs.BoolVar(&cfg.CheckNow, "check-update", cfg.CheckNow,
+		"check GitHub for a newer release and exit (nothing is installed)")
+	fs.StringVar(&cfg.Log, "log", cfg.Log,
+		"append a log of spawns, collisions and state changes to this file")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel,
+		"log verbosity: debug (every spawn and jump), info, warn or error")
+}
+
+// clamp replaces negative values, which would break timers and the spawner
+func (cfg *config) clamp() {
+	cfg.Countdown = max(cfg.Countdown, 0)
+	cfg.JumpBuffer = max(cfg.JumpBuffer, 0)
+	cfg.Coyote = max(cfg.Coyote, 0)
+	cfg.Debounce = max(cfg.Debounce, 0)
+	cfg.GraceCells = max(cfg.GraceCells, 0)
+	cfg.TickRate = max(cfg.TickRate, 0)
+	cfg.RenderFPS = max(cfg.RenderFPS, 0)
+	if cfg.BotTarget <= 0 {
+		cfg.BotTarget = defaultConfig().BotTarget
+	}
+	if cfg.MaxCols = max(cfg.MaxCols, 0); cfg.MaxCols > 0 {
+		cfg.MaxCols = max(cfg.MaxCols, minMaxCols)
+	}
+	if cfg.MaxRows = max(cfg.MaxRows, 0); cfg.MaxRows > 0 {
+		cfg.MaxRows = max(cfg.MaxRows, minMaxRows)
+	}
+}
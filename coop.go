@@ -0,0 +1,85 @@
+package gopherdash
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ----------------------------------------------------------------------------
+// LOCAL CO-OP (-coop)
+// ----------------------------------------------------------------------------
+
+// With -coop two players share the gopher from one keyboard: player one
+// has the left hand's jump (Space or W) and player two the arrows, ↓ to
+// dive and → to throw an acorn. S and D do nothing, so neither player can
+// take over the other's job. The HUD keeps score for each seat, and the
+// game-over screen says whose job the hazard was. Races have a player on
+// each end of the link already, so they play solo keys.
+
+// keymap is the keys for each of the gopher's actions, and how the
+// controls bar puts them
+type keymap struct {
+	jump, dive, throw []string
+	bar, diveBar      string
+	oneKey            bool // jump dives when it can't jump (see keymaps.go)
+}
+
+var (
+	soloKeys = keymap{jump: []string{" ", "w"}, dive: []string{"s", "down"}, throw: []string{"d"},
+		bar: controlsRunning, diveBar: "S = dive"}
+	coopKeys = keymap{jump: []string{" ", "w"}, dive: []string{"down"}, throw: []string{"right"},
+		bar: controlsCoop, diveBar: "P2: ↓ = dive"}
+)
+
+// coopSeats is what player two did this run; player one's jumps are the
+// run's jumps
+type coopSeats struct {
+	dives, throws, hits int
+}
+
+// coopOn reports whether two players are sharing the gopher
+func (m model) coopOn() bool { return m.cfg.Coop && !m.racing() }
+
+// keys is the keymap in play
+func (m model) keys() keymap {
+	if m.coopOn() {
+		return coopKeys
+	}
+	if k, ok := keymaps[m.cfg.Keys]; ok {
+		return k
+	}
+	return soloKeys
+}
+
+// isThrowKey maps keys to throwing an acorn
+func (m model) isThrowKey(key string) bool { return slices.Contains(m.keys().throw, key) }
+
+// coopHUD is each seat's tally
+func (m model) coopHUD() string {
+	p2 := fmt.Sprintf("P2 %s %d/%d", acornChar, m.seats.hits, m.seats.throws)
+	if m.momentum() {
+		p2 += fmt.Sprintf(" ⬇ %d", m.seats.dives)
+	}
+	return fmt.Sprintf("P1 ⬆ %d   %s", m.jumps, p2)
+}
+
+// coopTally is the team's tally for the game-over screen
+func (m model) coopTally() string {
+	return fmt.Sprintf("P1: %d jumps   P2: %d acorns, %d rocks knocked out",
+		m.jumps, m.seats.throws, m.seats.hits)
+}
+
+// coopBlame says whose job the thing that ended the run was
+func (m model) coopBlame() string {
+	switch {
+	case m.cause == "quit":
+		return "Both of you walked off"
+	case m.cause == foxCause:
+		return "Too many near-misses: the fox was P1's to shake off"
+	case m.cause == (rock{}).Name() && m.ammo > 0:
+		return fmt.Sprintf("Team effort: P1 didn't jump it, P2 still had %d %s", m.ammo, acornChar)
+	case m.cause == (rock{}).Name():
+		return "P1's jump to make: P2 was out of acorns"
+	}
+	return "P1's jump to make"
+}
@@ -0,0 +1,69 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCoopKeys(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	cfg.Coop = true
+	m, _ := clockedModel(t, cfg)
+	clearHazards(&m)
+	press := func(k tea.KeyMsg) {
+		next, _ := m.Update(k)
+		m = next.(model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if m.ammo != acornStart {
+		t.Fatal("D threw an acorn in co-op")
+	}
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if m.ammo != acornStart-1 || m.seats.throws != 1 {
+		t.Fatalf("→ left %d acorns, %d throws", m.ammo, m.seats.throws)
+	}
+	press(tea.KeyMsg{Type: tea.KeySpace})
+	if m.jumps != 1 {
+		t.Fatalf("Space made %d jumps", m.jumps)
+	}
+	if hud := m.coopHUD(); !strings.Contains(hud, "P1 ⬆ 1") || !strings.Contains(hud, "0/1") {
+		t.Errorf("HUD reads %q", hud)
+	}
+
+	m.mods = []string{modMirror}
+	if !m.isJumpKey("down") || m.isJumpKey("s") || !m.isDiveKey("w") {
+		t.Error("mirrored co-op keys don't swap the seats' keys")
+	}
+
+	solo := defaultConfig()
+	solo.Countdown = 0
+	m, _ = clockedModel(t, solo)
+	if !m.isThrowKey("d") || m.isThrowKey("right") {
+		t.Error("solo play lost D for acorns")
+	}
+}
+
+func TestCoopBlame(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Coop = true
+	m, _ := clockedModel(t, cfg)
+	for _, c := range []struct {
+		cause string
+		ammo  int
+		want  string
+	}{
+		{"rock", 2, "P2 still had 2"},
+		{"rock", 0, "P2 was out of acorns"},
+		{"hole", 3, "P1's jump"},
+		{foxCause, 3, "fox was P1's"},
+	} {
+		m.cause, m.ammo = c.cause, c.ammo
+		if got := m.coopBlame(); !strings.Contains(got, c.want) {
+			t.Errorf("%s with %d acorns: %q, want %q in it", c.cause, c.ammo, got, c.want)
+		}
+	}
+}
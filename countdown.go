@@ -0,0 +1,57 @@
+package gopherdash
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// PRE-RUN COUNTDOWN
+// ----------------------------------------------------------------------------
+
+const goFlash = 400 * time.Millisecond // how long "GO!" stays up once moving
+
+// startIntro freezes the world for the configured countdown
+func (m *model) startIntro() {
+	m.introUntil = m.now().Add(time.Duration(m.cfg.Countdown) * time.Second)
+}
+
+// inIntro reports whether the world is frozen behind the countdown
+func (m model) inIntro() bool { return m.now().Before(m.introUntil) }
+
+// skipIntro ends the countdown immediately
+func (m *model) skipIntro() {
+	m.logDebug("countdown skipped")
+	m.introUntil = m.now()
+}
+
+// introLabel is the text drawn over the playfield: "3", "2", "1", then
+// "GO!" briefly after the world starts moving
+func (m model) introLabel() string {
+	left := m.introUntil.Sub(m.now())
+	switch {
+	case left > 0:
+		return strconv.Itoa(int(math.Ceil(left.Seconds())))
+	case left > -goFlash && m.cfg.Countdown > 0:
+		return "GO!"
+	}
+	return ""
+}
+
+// stampText writes text centred on row y of a grid of width‑2 cells
+func stampText(rows [][]string, y int, text string) {
+	if y < 0 || y >= len(rows) {
+		return
+	}
+	if len(text)%2 == 1 {
+		text += " "
+	}
+	cells := len(text) / 2
+	x0 := (len(rows[y]) - cells) / 2
+	for i := 0; i < cells; i++ {
+		if x := x0 + i; x >= 0 && x < len(rows[y]) {
+			rows[y][x] = text[2*i : 2*i+2]
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// CRASH REPORTS & EXIT CODES
+// ----------------------------------------------------------------------------
+
+const crashFile = ".gopherdash_crash.json"
+
+// process exit codes
+const (
+	exitError = 1 // the game couldn't start or stopped with an error
+	exitUsage = 2 // bad command line; the flag package has said why
+	exitCrash = 3 // a panic was recovered and a crash report written
+)
+
+// crashReport is written when Update or View panics. Bubble Tea restores the
+// terminal and prints the stack itself; the report adds the game state as it
+// was before the message that crashed, so loading it with -load-state and
+// repeating that input reproduces the crash.
+type crashReport struct {
+	At      time.Time       `json:"at"`
+	Panic   string          `json:"panic"`
+	During  string          `json:"during"` // the message being handled, or "View"
+	Stack   string          `json:"stack"`
+	Go      string          `json:"go"`
+	OS      string          `json:"os"`
+	Version string          `json:"version,omitempty"`
+	State   json.RawMessage `json:"state,omitempty"`
+	Seen    bool            `json:"seen,omitempty"` // the next session has offered a bundle
+}
+
+// lastCrash is the report written by the most recent recovered panic; the
+// panic unwinds through Bubble Tea, so it can't travel back in the model
+var lastCrash string
+
+func crashPath() string { return dataPath(crashFile) }
+
+// Update is the tea.Model entry point; see update for the game itself
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer m.reportPanic(fmt.Sprintf("%T", msg))
+	next, cmd := m.update(msg)
+	if m, ok := next.(model); ok {
+		return m.flushSaves(cmd)
+	}
+	return next, cmd
+}
+
+// View is the tea.Model entry point; see view for the layout
+func (m model) View() string {
+	defer m.reportPanic("View")
+	return m.perf.draw(m.renderRate(), m.live() && !m.inIntro(), m.view)
+}
+
+// reportPanic writes a crash report for a panic in progress and lets it carry
+// on to Bubble Tea, which restores the terminal
+func (m model) reportPanic(during string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	rep := crashReport{
+		At:     time.Now(),
+		Panic:  fmt.Sprint(r),
+		During: during,
+		Stack:  string(debug.Stack()),
+		Go:     runtime.Version(),
+		OS:     runtime.GOOS + "/" + runtime.GOARCH,
+		State:  m.crashState(),
+	}
+	rep.Version = buildVersion()
+	if data, err := json.MarshalIndent(rep, "", "  "); err == nil &&
+		os.WriteFile(crashPath(), data, 0o644) == nil {
+		lastCrash = crashPath()
+	}
+	m.tallyCrash()
+	panic(r)
+}
+
+// crashState serialises m, giving up quietly if the state is too broken to
+func (m model) crashState() (data json.RawMessage) {
+	defer func() {
+		if recover() != nil {
+			data = nil
+		}
+	}()
+	data, _ = json.Marshal(m)
+	return data
+}
+
+// loadCrashNote returns the crash report of a previous session that hasn't
+// been shown to the player yet
+func loadCrashNote() *crashReport {
+	data, err := os.ReadFile(crashPath())
+	if err != nil {
+		return nil
+	}
+	var rep crashReport
+	if json.Unmarshal(data, &rep) != nil || rep.Seen {
+		return nil
+	}
+	return &rep
+}
+
+// answerCrash handles the prompt shown after a crashed session: B writes a
+// diagnostics bundle, any other key carries on; either way the report is
+// marked as seen so the prompt doesn't come back
+func (m *model) answerCrash(key string) {
+	if key == "b" {
+		if path, err := writeBundle(); err != nil {
+			m.notify(fmt.Sprintf("Bundle failed: %v", err))
+		} else {
+			m.notify("Diagnostics saved to " + path)
+		}
+	}
+	m.crashNote.Seen = true
+	if data, err := json.MarshalIndent(m.crashNote, "", "  "); err == nil {
+		_ = os.WriteFile(crashPath(), data, 0o644)
+	}
+	m.crashNote = nil
+	m.lastInput = m.now()
+	m.startIntro()
+}
+
+// exitCode explains err to the user and picks the process exit code
+func exitCode(err error) int {
+	if errors.Is(err, tea.ErrProgramPanic) {
+		fmt.Println("Sorry – Gopher-Dash crashed. Your terminal has been restored.")
+		if lastCrash != "" {
+			fmt.Println("A crash report with the game state was saved to", lastCrash)
+			fmt.Println("`gopherdash doctor -bundle` zips it up with everything else a bug report needs.")
+		}
+		return exitCrash
+	}
+	fmt.Println("error:", err)
+	return exitError
+}
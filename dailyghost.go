@@ -0,0 +1,133 @@
+package gopherdash
+
+import (
+	"fmt"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// YESTERDAY'S GHOST (-daily)
+// ----------------------------------------------------------------------------
+
+// The best daily run of each day is taped to the store as well as the last
+// run. When the seed changes at midnight the old day's tape is kept aside,
+// and every daily run the next day runs it alongside as a ghost: its own
+// course, played back a step for each of ours, so at any distance the HUD
+// shows how far ahead or behind yesterday's best you are on score. Once the
+// ghost's run is over the HUD compares with its final score, and the
+// game-over screen has the verdict. A run restored from an autosave goes
+// without, as the ghost would be starting from scratch.
+
+const (
+	replayDaily         = "daily"     // the best daily run of its day
+	replayYesterday     = "yesterday" // the day before's, once a new day's run has been taped
+	dailyReplayFile     = "gopherdash-daily.replay"
+	yesterdayReplayFile = "gopherdash-yesterday.replay"
+)
+
+// yesterday is the day before's best daily run, being played alongside
+type yesterday struct {
+	tape replay
+	run  *model
+}
+
+// dailyRun reports whether the run is on the daily seed
+func (m model) dailyRun() bool { return m.cfg.Daily && !m.cfg.Weekly }
+
+// saveDaily keeps the run's tape as its day's best daily run, if it is,
+// moving the day before's aside first
+func (m *model) saveDaily(data []byte) {
+	if !m.dailyRun() || m.cfg.Practice {
+		return
+	}
+	if old, ok := m.saves.readReplay(replayDaily); ok {
+		if prev, err := parseReplay(old, "daily replay"); err == nil {
+			if prev.Seed == m.tape.Seed && prev.Score >= m.tape.Score {
+				return // the day's best stands
+			}
+			if prev.Seed != m.tape.Seed {
+				_ = m.saves.writeReplay(replayYesterday, old)
+			}
+		}
+	}
+	if err := m.saves.writeReplay(replayDaily, data); err != nil {
+		logger.Warn("saving daily replay", "err", err)
+	}
+}
+
+// yesterdaysTape finds the best daily run in saves of the day before now
+func yesterdaysTape(saves Store, now time.Time) (replay, bool) {
+	want := dailySeed(now.AddDate(0, 0, -1))
+	for _, name := range []string{replayDaily, replayYesterday} {
+		data, ok := saves.readReplay(name)
+		if !ok {
+			continue
+		}
+		if tape, err := parseReplay(data, name+" replay"); err == nil && tape.Seed == want {
+			return tape, true
+		}
+	}
+	return replay{}, false
+}
+
+// startYesterday puts yesterday's best on its course, if it's a daily run
+// and there's one taped
+func (m *model) startYesterday() {
+	m.yesterday = nil
+	if !m.dailyRun() || m.racing() || m.ghost || m.saver || m.playback != nil {
+		return
+	}
+	tape, ok := yesterdaysTape(m.saves, m.now())
+	if !ok {
+		return
+	}
+	cfg := tape.Config
+	cfg.Seed, cfg.Daily, cfg.Weekly, cfg.Mods = tape.Seed, false, false, tape.Mods
+	cfg.Twitch, cfg.Bot, cfg.Timer = "", "", false
+	run := &model{cfg: cfg, clock: m.clock, frameDur: startFrame, ghost: true,
+		fox: foxStart, ammo: acornStart, rules: rulesByName(cfg.Scoring)}
+	run.reseed(tape.Seed)
+	run.playback = &playback{tape: tape, speed: 1}
+	m.yesterday = &yesterday{tape: tape, run: run}
+	if m.gameRows > 0 {
+		m.sizeGhost(run)
+	}
+}
+
+// stepYesterday plays yesterday's next step alongside ours
+func (m *model) stepYesterday() {
+	y := m.yesterday
+	if y == nil || y.run.gameOver || y.run.gameRows == 0 {
+		return
+	}
+	y.run.playInputs()
+	y.run.step(m.now())
+}
+
+// yesterdayHUD is how the run stands against yesterday's best
+func (m model) yesterdayHUD() string {
+	o := m.yesterday.run
+	label := "👻 Yesterday"
+	if o.gameOver {
+		label += " ✗" // it's a fixed mark from here
+	}
+	switch d := m.score() - o.score(); {
+	case d > 0:
+		return fmt.Sprintf("%s ▲ +%d", label, d)
+	case d < 0:
+		return fmt.Sprintf("%s ▼ %d", label, d)
+	}
+	return label + " = level"
+}
+
+// yesterdayLine is the verdict for the game-over screen
+func (m model) yesterdayLine() string {
+	best := m.yesterday.tape.Score
+	switch {
+	case m.score() > best:
+		return fmt.Sprintf("Beat yesterday's best of %d by %d", best, m.score()-best)
+	case m.score() == best:
+		return fmt.Sprintf("Matched yesterday's best of %d", best)
+	}
+	return fmt.Sprintf("Yesterday's best: %d (%d short)", best, best-m.score())
+}
@@ -0,0 +1,81 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// A day's best stays till it's beaten, and moves aside for the day after's
+// first run.
+func TestSaveDaily(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Daily = 0, true
+	m, _ := clockedModel(t, cfg)
+	taped := func(name string) replay {
+		data, ok := m.saves.readReplay(name)
+		if !ok {
+			return replay{}
+		}
+		tape, _ := parseReplay(data, name)
+		return tape
+	}
+	save := func(seed int64, score int) {
+		m.tape = &replay{Format: replayFormat, Seed: seed, Score: score}
+		data, _ := json.Marshal(m.tape)
+		m.saveDaily(data)
+	}
+
+	save(20251231, 100)
+	save(20251231, 50)
+	if got := taped(replayDaily); got.Score != 100 {
+		t.Errorf("the day's best is %d, want 100", got.Score)
+	}
+	save(20260101, 20)
+	if got := taped(replayYesterday); got.Seed != 20251231 || got.Score != 100 {
+		t.Errorf("yesterday's is seed %d, score %d", got.Seed, got.Score)
+	}
+	if got := taped(replayDaily); got.Seed != 20260101 {
+		t.Errorf("today's is seed %d", got.Seed)
+	}
+	if tape, ok := yesterdaysTape(m.saves, m.now()); !ok || tape.Score != 100 {
+		t.Errorf("found %v, score %d", ok, tape.Score)
+	}
+}
+
+// Yesterday's ghost steps with the run and the HUD says who's ahead.
+func TestYesterdayGhost(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Daily = 0, true
+	m, _ := clockedModel(t, cfg)
+	tape := replay{Format: replayFormat, Seed: 20251231, Config: cfg, Score: 30}
+	data, _ := json.Marshal(tape)
+	if err := m.saves.writeReplay(replayDaily, data); err != nil {
+		t.Fatal(err)
+	}
+	m.startYesterday()
+	if m.yesterday == nil {
+		t.Fatal("no ghost on a daily run with yesterday's taped")
+	}
+	clearHazards(&m)
+	for range 10 {
+		m.step(m.now())
+		m.stepYesterday()
+		clearHazards(&m)
+	}
+	if o := m.yesterday.run; o.steps != m.steps {
+		t.Errorf("the ghost's at step %d, the run at %d", o.steps, m.steps)
+	}
+	if hud := m.View(); !strings.Contains(hud, "👻 Yesterday") {
+		t.Errorf("no ghost in the HUD:\n%s", hud)
+	}
+	if line := m.yesterdayLine(); !strings.Contains(line, "short") {
+		t.Errorf("10 steps in, against 30: %q", line)
+	}
+
+	cfg.Daily = false
+	m, _ = clockedModel(t, cfg)
+	if m.startYesterday(); m.yesterday != nil {
+		t.Error("a ghost on a run that isn't the daily")
+	}
+}
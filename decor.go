@@ -0,0 +1,83 @@
+package gopherdash
+
+// ----------------------------------------------------------------------------
+// DECORATIONS
+// ----------------------------------------------------------------------------
+
+// The ground line has scenery on it: flowers, sprouts, mushrooms,
+// footprints and the odd signpost, scrolling with the world. Collisions
+// never look at it. Each world cell's decoration, mostly none, comes from
+// the course's seed and the cell alone, without drawing on the obstacle
+// stream, so a replay or a daily run looks the same every time it's played.
+// Like the other decorative layers it's left out by the battery saver and
+// reduced motion.
+
+const decorEvery = 9 // one world cell in this many has a decoration, on average
+
+// decorations are the scenery and how often each comes up
+var decorations = []struct {
+	glyph  string
+	weight int
+}{
+	{"🌼", 4},
+	{"🌱", 4},
+	{"🍄", 2},
+	{"👣", 2},
+	{"🪧", 1},
+}
+
+// decorHash mixes the seed and a world cell into well-spread bits
+// (splitmix64's finaliser)
+func decorHash(seed int64, w int) uint64 {
+	z := uint64(seed) + uint64(w)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// decorAt is the decoration on world cell w of the course seed makes, or ""
+func decorAt(seed int64, w int) string {
+	h := decorHash(seed, w)
+	if h%decorEvery != 0 {
+		return ""
+	}
+	total := 0
+	for _, d := range decorations {
+		total += d.weight
+	}
+	pick := int(h / decorEvery % uint64(total))
+	for _, d := range decorations {
+		if pick < d.weight {
+			return d.glyph
+		}
+		pick -= d.weight
+	}
+	return ""
+}
+
+// decorate puts the decorations in view on the ground line of rows, on
+// top of the terrain and clear of anything in the world there, holes
+// included
+func (m model) decorate(rows [][]string) {
+	if !m.decorative() || len(rows) == 0 {
+		return
+	}
+	taken := map[int]bool{}
+	for _, ob := range m.obstacles {
+		for w := ob.x; w <= ob.extent().hi; w++ {
+			taken[w] = true
+		}
+	}
+	groundY := len(rows) - 1
+	cam := m.viewCamera()
+	for x := range rows[0] {
+		w := cam.toWorld(x)
+		d := decorAt(m.seed, w)
+		if d == "" || taken[w] {
+			continue
+		}
+		if y := groundY - m.terrainAt(w) - 1; y >= 0 {
+			rows[y][x] = d
+		}
+	}
+}
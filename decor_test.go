@@ -0,0 +1,77 @@
+package gopherdash
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecorSeeded(t *testing.T) {
+	const cells = 9000
+	var a, b []string
+	count := 0
+	for w := range cells {
+		a, b = append(a, decorAt(7, w)), append(b, decorAt(8, w))
+		if decorAt(7, w) != a[w] {
+			t.Fatalf("cell %d changed between calls", w)
+		}
+		if a[w] != "" {
+			count++
+		}
+	}
+	if slices.Equal(a, b) {
+		t.Error("two seeds, one scenery")
+	}
+	if want := cells / decorEvery; count < want*3/4 || count > want*5/4 {
+		t.Errorf("%d decorations in %d cells, want about %d", count, cells, want)
+	}
+}
+
+// TestDecorClear keeps the scenery off hazards and holes, and out with the
+// battery saver
+func TestDecorClear(t *testing.T) {
+	isolateSaves(t)
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	clearHazards(&m)
+	cam := m.camera()
+	for x := range m.gameCols {
+		if x%2 == 0 {
+			m.obstacles = append(m.obstacles, obstacle{cam.toWorld(x), hole{}})
+		}
+	}
+	// a seed with scenery both over the holes and between them
+	for m.seed = 1; ; m.seed++ {
+		var odd, even bool
+		for x := range m.gameCols {
+			if x != playerCol && decorAt(m.seed, cam.toWorld(x)) != "" {
+				odd, even = odd || x%2 == 1, even || x%2 == 0
+			}
+		}
+		if odd && even {
+			break
+		}
+	}
+	found := 0
+	for x, cell := range m.gameCells(false)[m.gameRows-2] {
+		if decorAt(m.seed, cam.toWorld(x)) == "" || x == playerCol {
+			continue
+		}
+		if x%2 == 0 && cell != "  " {
+			t.Errorf("%s over a hole at %d", cell, x)
+		}
+		if x%2 == 1 && cell == decorAt(m.seed, cam.toWorld(x)) {
+			found++
+		}
+	}
+	if found == 0 {
+		t.Error("no scenery between the holes")
+	}
+
+	m.cfg.BatterySaver = true
+	for x, cell := range m.gameCells(false)[m.gameRows-2] {
+		if x != playerCol && cell != "  " {
+			t.Errorf("%s drawn with the battery saver", cell)
+		}
+	}
+}
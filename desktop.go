@@ -0,0 +1,65 @@
+package gopherdash
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// DESKTOP NOTIFICATIONS (-notify)
+// ----------------------------------------------------------------------------
+
+// With -notify a new high score or an achievement also pops up a desktop
+// notification, so it's seen with the terminal in the background. The
+// terminal raises it from an escape sequence: OSC 9 for iTerm2, Windows
+// Terminal and ConEmu, OSC 777 for the rest that can (foot, Ghostty,
+// WezTerm, urxvt with its notify extension). Terminals that can't ignore
+// both. Inside tmux the sequence is passed through to the outer terminal.
+
+// desktopOut is where the sequences are written, these, the clipboard's
+// (see share.go) and the sprites' (see graphics.go): the terminal
+var desktopOut io.Writer = os.Stdout
+
+// osc9Terminals are the TERM_PROGRAMs that take OSC 9 rather than 777
+var osc9Terminals = []string{"iTerm.app"}
+
+// desktopSequence is the escape sequence raising a notification
+func desktopSequence(title, body string, getenv func(string) string) string {
+	clean := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r < ' ' || r == 0x7f || r == ';' {
+				return ' ' // would end the sequence or split its fields
+			}
+			return r
+		}, s)
+	}
+	title, body = clean(title), clean(body)
+	seq := fmt.Sprintf("\x1b]777;notify;%s;%s\x1b\\", title, body)
+	osc9 := getenv("WT_SESSION") != "" || getenv("ConEmuPID") != ""
+	for _, t := range osc9Terminals {
+		osc9 = osc9 || getenv("TERM_PROGRAM") == t
+	}
+	if osc9 {
+		seq = fmt.Sprintf("\x1b]9;%s: %s\x1b\\", title, body)
+	}
+	return passThrough(seq, getenv)
+}
+
+// passThrough wraps seq for tmux to hand on to the terminal it runs in
+func passThrough(seq string, getenv func(string) string) string {
+	if getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// desktop raises a notification, if -notify asked for them
+func (m model) desktop(title, body string) {
+	if !m.cfg.Notify || m.embedded {
+		return // an embedding program owns the terminal
+	}
+	_, _ = io.WriteString(desktopOut, desktopSequence(title, body, os.Getenv))
+	m.logDebug("desktop notification", "title", title)
+}
@@ -0,0 +1,54 @@
+package gopherdash
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDesktopSequence(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"foot", map[string]string{"TERM": "foot"},
+			"\x1b]777;notify;New best;412 m\x1b\\"},
+		{"iTerm2", map[string]string{"TERM_PROGRAM": "iTerm.app"},
+			"\x1b]9;New best: 412 m\x1b\\"},
+		{"Windows Terminal", map[string]string{"WT_SESSION": "1"},
+			"\x1b]9;New best: 412 m\x1b\\"},
+		{"tmux", map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"},
+			"\x1bPtmux;\x1b\x1b]777;notify;New best;412 m\x1b\x1b\\\x1b\\"},
+	} {
+		got := desktopSequence("New best", "412 m", func(k string) string { return tc.env[k] })
+		if got != tc.want {
+			t.Errorf("%s: %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	got := desktopSequence("a;b", "c\x07d\x1b]0;title", func(string) string { return "" })
+	if want := "\x1b]777;notify;a b;c d ]0 title\x1b\\"; got != want {
+		t.Errorf("unsanitised: %q, want %q", got, want)
+	}
+}
+
+// TestDesktopOnRecord raises a notification for a new high score, and only
+// with -notify
+func TestDesktopOnRecord(t *testing.T) {
+	t.Cleanup(func() { desktopOut = os.Stdout })
+	for _, on := range []bool{false, true} {
+		var out strings.Builder
+		desktopOut = &out
+		cfg := defaultConfig()
+		cfg.Notify = on
+		m, _ := clockedModel(t, cfg)
+		m.dist = 50
+		m.setGameOver("rock")
+		if !m.newRecord {
+			t.Fatal("no new record to notify")
+		}
+		if got := strings.Contains(out.String(), "new high score!"); got != on {
+			t.Errorf("-notify=%v: notified = %v (%q)", on, got, out.String())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package gopherdash
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// GOPHERDEX (G on game over)
+// ----------------------------------------------------------------------------
+
+// Every hazard and ground tile the gopher meets is counted in the lifetime
+// stats. `G` on the game-over screen opens the Gopherdex: each kind in the
+// registry with how often it's been met and how many runs it's ended, ↑↓
+// choosing one to read about. A kind stays a "???" until it's been met, so
+// the dex fills in as the player gets further.
+
+const controlsDex = "↑↓ = select   G/Esc = back   Q = quit"
+
+// dexAbout is each kind's entry, by its saved name
+var dexAbout = map[string]string{
+	"rock":        "Sits on the running line. Jump it, or knock it out with an acorn.",
+	"hole":        "A missing tile. Run onto it and a jump still saves you for a moment (coyote time).",
+	"log":         "Three cells long: the whole jump has to clear it, landing included.",
+	"wide hole":   "Two missing tiles; coyote time still saves a late jump at its near edge.",
+	"stack":       "Two rocks high. Only a jump that gets high enough clears it.",
+	"tall stack":  "Three rocks high, cleared only at the top of a jump; left out when the run's jump can't.",
+	"springboard": "Land on it or run over it for a higher, longer jump.",
+	"speed pad":   "Run over it and the score counts double for a while.",
+	"acorn":       "Run through it for another acorn to throw, up to five in your pockets.",
+}
+
+// dexKinds is everything in the dex, in registry order
+func dexKinds() []ObstacleKind { return slices.Concat(obstacleKinds, tileKinds) }
+
+// meet counts ob the step its first cell reaches the gopher
+func (m *model) meet(ob obstacle) {
+	if ob.x != m.body().lo {
+		return
+	}
+	if m.spawn.big && slices.Contains(m.obstacles, obstacle{ob.x - 1, ob.kind}) {
+		return // the rest of a big rock
+	}
+	if m.met == nil {
+		m.met = map[string]int{}
+	}
+	m.met[ob.kind.Name()]++
+}
+
+// addMet adds a run's meetings to the lifetime counts
+func (st *stats) addMet(met map[string]int) {
+	for name, n := range met {
+		if st.Met == nil {
+			st.Met = map[string]int{}
+		}
+		st.Met[name] += n
+	}
+}
+
+// dexLines is the Gopherdex screen
+func (m model) dexLines() []string {
+	kinds := dexKinds()
+	known := 0
+	for _, k := range kinds {
+		if m.stats.Met[k.Name()] > 0 {
+			known++
+		}
+	}
+	lines := []string{fmt.Sprintf("Gopherdex: %d of %d met", known, len(kinds)), ""}
+	for i, k := range kinds {
+		mark := "  "
+		if i == m.dexRow {
+			mark = "▸ "
+		}
+		name := k.Name()
+		met := m.stats.Met[name]
+		if met == 0 {
+			lines = append(lines, mark+"❔ ???")
+			continue
+		}
+		glyph, _ := k.Sprite()
+		if strings.TrimSpace(glyph) == "" {
+			glyph = k.Radar() // a hole is a gap in the ground
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %-12s met %5d   deaths %4d", mark, glyph, name, met, m.stats.ByCause[name]))
+	}
+	lines = append(lines, "")
+	if k := kinds[m.dexRow]; m.stats.Met[k.Name()] > 0 {
+		return append(lines, dexAbout[k.Name()])
+	}
+	return append(lines, "Not met yet: keep running")
+}
+
+// dexKey handles keys in the Gopherdex
+func (m *model) dexKey(key string) {
+	n := len(dexKinds())
+	switch key {
+	case "up":
+		m.dexRow = (m.dexRow + n - 1) % n
+	case "down":
+		m.dexRow = (m.dexRow + 1) % n
+	case "g", "esc":
+		m.showDex = false
+	}
+}
@@ -0,0 +1,49 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDexAbout(t *testing.T) {
+	for _, k := range dexKinds() {
+		if dexAbout[k.Name()] == "" {
+			t.Errorf("%s has no Gopherdex entry", k.Name())
+		}
+	}
+}
+
+// A rock jumped is met once, not once a step it's under the gopher, and
+// the dex shows it once the run's in the stats.
+func TestDex(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	m.obstacles = []obstacle{{m.body().lo + 2, rock{}}}
+	m.jump()
+	for range 4 {
+		m.step(m.now())
+	}
+	if m.gameOver || m.met["rock"] != 1 {
+		t.Fatalf("met %v, game over %v", m.met, m.gameOver)
+	}
+	if lines := strings.Join(m.dexLines(), "\n"); strings.Contains(lines, "rock") {
+		t.Errorf("the rock's in the dex before the run's counted:\n%s", lines)
+	}
+
+	m.setGameOver("rock")
+	if m.stats.Met["rock"] != 1 || m.met != nil {
+		t.Fatalf("stats met %v, run met %v", m.stats.Met, m.met)
+	}
+	lines := strings.Join(m.dexLines(), "\n")
+	if !strings.Contains(lines, "1 of 9 met") || !strings.Contains(lines, "deaths    1") || !strings.Contains(lines, "???") {
+		t.Errorf("dex:\n%s", lines)
+	}
+	if !strings.Contains(lines, dexAbout["rock"]) {
+		t.Error("the selected rock's entry isn't shown")
+	}
+	m.dexKey("down")
+	if lines := m.dexLines(); lines[len(lines)-1] != "Not met yet: keep running" {
+		t.Errorf("a hole not met reads %q", lines[len(lines)-1])
+	}
+}
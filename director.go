@@ -0,0 +1,124 @@
+package gopherdash
+
+import "math"
+
+// ----------------------------------------------------------------------------
+// DIFFICULTY DIRECTOR (-director)
+// ----------------------------------------------------------------------------
+
+// Left to itself the stream is evenly random: hazards come at the same
+// rate whatever the player's going through. With -director a director
+// paces it instead, chunk by chunk (see biomes.go): a chunk opens with a
+// cluster of hazards at twice the usual rate, then gives a breather with
+// none, then the usual stream. How it splits the chunk comes from how
+// stressed the player has been – how much of the time they've been
+// jumping, and how many hazards they've only just cleared – so a player
+// who's coasting gets long clusters and short breathers, and one who's
+// scraping through gets the other way round.
+//
+// Stress is measured at each chunk boundary the gopher crosses and shapes
+// the chunk directorAhead further on, which is decided only then: the
+// course gets no further ahead of the gopher than that, however wide the
+// terminal. That keeps it the same however it's looked at, so replays and
+// state dumps play it back exactly. Races and races with the bot are run
+// on one course for both sides, so they go without a director.
+
+const (
+	directorAhead  = 2    // chunks decided ahead of the one the gopher's in
+	directorMemory = 0.5  // share of the stress so far a checkpoint keeps
+	surgeChance    = 2    // times the usual spawn chance in a cluster
+	surgeMost      = 32   // cells of cluster for a player who's coasting
+	restLeast      = 8    // cells of breather for them
+	restMost       = 32   // and for a player who's scraping through
+	jumpStress     = 0.6  // weight of the share of steps spent jumping
+	closeStress    = 0.8  // and of the share of clears that were near-misses
+	calmStress     = 0.25 // stress at the start of a run
+)
+
+// pace is how the director shapes a chunk: a cluster from its start, then
+// a breather, then the usual stream. The zero pace is the usual stream.
+type pace struct {
+	Surge int `json:"surge"` // cells of cluster
+	Rest  int `json:"rest"`  // cells of breather after it
+}
+
+// at is the spawn chance's multiplier at offset off into the chunk
+func (p pace) at(off int) float64 {
+	switch {
+	case off < p.Surge:
+		return surgeChance
+	case off < p.Surge+p.Rest:
+		return 0
+	}
+	return 1
+}
+
+// paceFor is the pace for a player under stress s, from 0 to 1
+func paceFor(s float64) pace {
+	return pace{
+		Surge: int(math.Round(surgeMost * (1 - s))),
+		Rest:  restLeast + int(math.Round((restMost-restLeast)*s)),
+	}
+}
+
+// director is the pacing's state: the stress so far, and the run's
+// telemetry as of the last checkpoint, to measure the next one against
+type director struct {
+	Stress float64 `json:"stress"`
+	Chunk  int     `json:"chunk"` // the chunk the gopher was in at the last checkpoint
+	Dist   int     `json:"dist"`
+	Jumps  int     `json:"jumps"`
+	Clears int     `json:"clears"`
+	Close  int     `json:"close"`
+}
+
+// directed reports whether the run's stream has a director
+func (m model) directed() bool {
+	return m.cfg.Director && m.cfg.Bot == "" && !m.racing() && !m.ghost && !m.saver
+}
+
+// startDirector starts pacing a freshly built stream from the gopher's
+// chunk on, carrying the stress over from the run so far
+func (m *model) startDirector() {
+	if !m.directed() {
+		m.dir = nil
+		return
+	}
+	stress := calmStress
+	if m.dir != nil {
+		stress = m.dir.Stress
+	}
+	m.dir = &director{Stress: stress}
+	m.dir.mark(*m)
+}
+
+// mark takes the checkpoint's readings from m
+func (d *director) mark(m model) {
+	d.Chunk = chunkOf(m.camera().toWorld(playerCol))
+	d.Dist, d.Jumps, d.Clears, d.Close = m.dist, m.tele.jumps, m.tele.clears, m.tele.close
+}
+
+// direct checks the gopher in at a chunk boundary it's crossed, shaping
+// the chunk directorAhead on, and reports how far the stream may be
+// decided
+func (m *model) direct(upTo int) int {
+	d := m.dir
+	if d == nil {
+		return upTo
+	}
+	if c := chunkOf(m.camera().toWorld(playerCol)); d.Chunk < c {
+		cells := max(m.dist-d.Dist, 1)
+		jumping := float64((m.tele.jumps-d.Jumps)*(jumpCells+1)) / float64(cells)
+		near := 0.0
+		if clears := m.tele.clears - d.Clears; clears > 0 {
+			near = float64(m.tele.close-d.Close) / float64(clears)
+		}
+		felt := min(jumpStress*min(jumping, 1)+closeStress*near, 1)
+		d.Stress = directorMemory*d.Stress + (1-directorMemory)*felt
+		p := paceFor(d.Stress)
+		m.spawn.shape(c+directorAhead, p)
+		d.mark(*m)
+		m.logInfo("director", "chunk", c+directorAhead, "stress", d.Stress, "surge", p.Surge, "rest", p.Rest)
+	}
+	return min(upTo, (d.Chunk+directorAhead+1)*chunkCells)
+}
@@ -0,0 +1,137 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPace(t *testing.T) {
+	if p := paceFor(0); p != (pace{surgeMost, restLeast}) {
+		t.Errorf("coasting: %+v", p)
+	}
+	if p := paceFor(1); p != (pace{0, restMost}) {
+		t.Errorf("scraping through: %+v", p)
+	}
+	p := pace{Surge: 10, Rest: 5}
+	for off, want := range map[int]float64{0: surgeChance, 9: surgeChance, 10: 0, 14: 0, 15: 1, 63: 1} {
+		if got := p.at(off); got != want {
+			t.Errorf("%+v at %d: %v, want %v", p, off, got, want)
+		}
+	}
+	if (pace{}).at(0) != 1 {
+		t.Error("the zero pace isn't the usual stream")
+	}
+}
+
+// A shaped chunk's breather has nothing start in it, and its clusters come
+// thicker than the usual stream.
+func TestSpawnerShape(t *testing.T) {
+	const c = 3
+	p := pace{Surge: 24, Rest: 24}
+	start := c * chunkCells
+	surged, usual := 0, 0
+	for seed := int64(1); seed <= 50; seed++ {
+		s := newSpawner(seed, playerCol+1, defaultGraceCells)
+		s.shape(c, p)
+		plain := newSpawner(seed, playerCol+1, defaultGraceCells)
+		for _, ob := range s.fill(start + chunkCells) {
+			switch off := ob.x - start; {
+			case off >= p.Surge && off < p.Surge+p.Rest:
+				t.Errorf("seed %d: a %s at %d, in the breather", seed, ob.kind.Name(), ob.x)
+			case off >= 0 && off < p.Surge:
+				surged++
+			}
+		}
+		for _, ob := range plain.fill(start + chunkCells) {
+			if off := ob.x - start; off >= 0 && off < p.Surge {
+				usual++
+			}
+		}
+	}
+	if surged <= usual {
+		t.Errorf("%d hazards in clusters against %d in the same cells unshaped", surged, usual)
+	}
+}
+
+func TestDirectorStress(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Director = 0, true
+	m, _ := clockedModel(t, cfg)
+	if m.dir == nil || m.dir.Stress != calmStress {
+		t.Fatal("no director, or it didn't start calm")
+	}
+	cap0 := m.direct(1 << 20)
+	if cap0 != (m.dir.Chunk+directorAhead+1)*chunkCells {
+		t.Errorf("the stream may go to %d from chunk %d", cap0, m.dir.Chunk)
+	}
+
+	// a chunk of jumping flat out, every clear a near-miss
+	m.dist += chunkCells
+	m.tele.jumps += chunkCells / (jumpCells + 1)
+	m.tele.clears += 8
+	m.tele.close += 8
+	c := chunkOf(m.camera().toWorld(playerCol))
+	m.direct(1 << 20)
+	frantic := m.dir.Stress
+	if frantic <= calmStress || m.dir.Chunk != c {
+		t.Fatalf("stress %v at chunk %d after a frantic chunk", frantic, m.dir.Chunk)
+	}
+	if got := m.spawn.paces[c+directorAhead]; got != paceFor(frantic) {
+		t.Errorf("chunk %d paced %+v, want %+v", c+directorAhead, got, paceFor(frantic))
+	}
+
+	// then one coasting: no jumps, nothing close
+	m.dist += chunkCells
+	m.direct(1 << 20)
+	if m.dir.Stress >= frantic {
+		t.Errorf("stress %v after a quiet chunk, from %v", m.dir.Stress, frantic)
+	}
+	if p := m.spawn.paces[c+1+directorAhead]; p.Surge <= paceFor(frantic).Surge {
+		t.Errorf("a calmer player got a shorter cluster: %+v", p)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, _ := clockedModel(t, cfg)
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if *back.dir != *m.dir || len(back.spawn.paces) != len(m.spawn.paces) {
+		t.Errorf("dump restored director %+v, paces %v; want %+v, %v", back.dir, back.spawn.paces, m.dir, m.spawn.paces)
+	}
+}
+
+// How wide the window is decides nothing about a directed course.
+func TestDirectorWidthIndependent(t *testing.T) {
+	run := func(width int) map[int]string {
+		cfg := defaultConfig()
+		cfg.Countdown, cfg.Director, cfg.Seed, cfg.MaxCols = 0, true, 5, 0
+		m, _ := clockedModel(t, cfg)
+		next, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 24})
+		m = next.(model)
+		seen := map[int]string{}
+		for i := 0; i < 8*chunkCells && !m.gameOver; i++ {
+			if m.botJump() {
+				m.pressJump()
+			}
+			m.step(m.now())
+			for _, ob := range m.obstacles {
+				seen[ob.x] = ob.kind.Name()
+			}
+		}
+		if m.gameOver {
+			t.Fatalf("%d wide: the bot crashed at %d", width, m.dist)
+		}
+		return seen
+	}
+	narrow, wide := run(60), run(300)
+	for x, name := range narrow {
+		if wide[x] != name {
+			t.Errorf("at %d: %q 60 wide, %q 300 wide", x, name, wide[x])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package gopherdash
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+)
+
+// ----------------------------------------------------------------------------
+// DOCTOR (diagnostics for bug reports)
+// ----------------------------------------------------------------------------
+
+// bundleFile is a file copied into a diagnostics bundle under name
+type bundleFile struct{ name, path string }
+
+// bundleFiles lists the save files and, if one is configured, the log
+func bundleFiles() []bundleFile {
+	files := []bundleFile{
+		{"config.json", configPath()},
+		{"crash.json", crashPath()},
+		{"events.json", eventsPath()},
+		{"history.json", historyPath()},
+		{"profile.json", profilePath()},
+		{"stats.json", statsPath()},
+		{"weekly.json", weeklyPath()},
+		{"streak.json", streakPath()},
+	}
+	cfg := loadConfig()
+	if cfg.Store == "sqlite" {
+		files = append(files, bundleFile{"gopherdash.db", dbPath(cfg)})
+	}
+	if cfg.Log != "" {
+		files = append(files, bundleFile{"debug.log", cfg.Log})
+	}
+	return files
+}
+
+// doctorMain runs `gopherdash doctor [-bundle]`
+func doctorMain(args []string) int {
+	fs := flag.NewFlagSet("gopherdash doctor", flag.ContinueOnError)
+	bundle := fs.Bool("bundle", false, "write a zip of diagnostics to attach to a bug report")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	fmt.Print(doctorReport())
+	if !*bundle {
+		return 0
+	}
+	path, err := writeBundle()
+	if err != nil {
+		return exitCode(err)
+	}
+	fmt.Println("\nDiagnostics bundle written to", path)
+	return 0
+}
+
+// doctorReport describes the build, the terminal and the save files
+func doctorReport() string {
+	var b strings.Builder
+	line := func(k, v string) { fmt.Fprintf(&b, "%-26s %s\n", k+":", v) }
+	build := currentBuild()
+	line("gopherdash", build.Version)
+	line("commit", build.Commit)
+	line("built", build.Date)
+	line("go", runtime.Version()+" "+runtime.GOOS+"/"+runtime.GOARCH)
+	line("generated", time.Now().Format(time.RFC3339))
+
+	b.WriteString("\nterminal\n")
+	size := "not a terminal"
+	if w, h, err := term.GetSize(os.Stdout.Fd()); err == nil {
+		size = fmt.Sprintf("%dx%d", w, h)
+	}
+	line("  size", size)
+	line("  colours", lipgloss.ColorProfile().Name())
+	line("  dark bg", fmt.Sprint(lipgloss.HasDarkBackground()))
+	for _, env := range []string{"TERM", "COLORTERM", "TERM_PROGRAM", "LANG", "LC_ALL", "TMUX", "SSH_TTY"} {
+		if v := os.Getenv(env); v != "" {
+			line("  "+env, v)
+		}
+	}
+
+	b.WriteString("\nsave files\n")
+	for _, f := range bundleFiles() {
+		state := "missing"
+		if st, err := os.Stat(f.path); err == nil {
+			state = fmt.Sprintf("%d bytes, %s", st.Size(), st.ModTime().Format("2006-01-02 15:04"))
+		}
+		line("  "+filepath.Base(f.path), state)
+	}
+
+	// the effective settings, after the config file, minus the secrets
+	if data, err := json.MarshalIndent(loadConfig().redacted(), "  ", "  "); err == nil {
+		fmt.Fprintf(&b, "\nconfig\n  %s\n", data)
+	}
+	return b.String()
+}
+
+// writeBundle zips the report and the save files next to the binary
+func writeBundle() (string, error) {
+	path := dataPath(fmt.Sprintf("gopherdash-doctor-%s.zip", time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("doctor.txt")
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write([]byte(doctorReport())); err != nil {
+		return "", err
+	}
+	for _, bf := range bundleFiles() {
+		data, err := os.ReadFile(bf.path)
+		if err != nil {
+			continue // not every save file exists
+		}
+		if bf.name == "config.json" {
+			data = redactConfig(data)
+		}
+		w, err := zw.Create(bf.name)
+		if err != nil {
+			return "", err
+		}
+		if _, err := w.Write(data); err != nil {
+			return "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,207 @@
+package gopherdash
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// LIBRARY API (embedding the game in another Bubble Tea program)
+// ----------------------------------------------------------------------------
+
+// Model is the game as a tea.Model another program can embed as a widget:
+//
+//	game, err := gopherdash.New(gopherdash.WithSize(60, 20))
+//
+// and from the host's Update, route messages through game.Update. Quitting
+// the game (Q or Ctrl+C) sends a DoneMsg instead of ending the host.
+type Model struct {
+	m      model
+	sized  bool   // the host sets the size; WindowSizeMsg is the host's own
+	remote string // where the player connects from (see WithRemote)
+}
+
+// DoneMsg is sent when the player quits an embedded game, or when
+// -idle-kick ends it
+type DoneMsg struct {
+	Best int  // the player's high score
+	Idle bool // ended for want of input, not by the player
+}
+
+// Option configures a Model
+type Option func(*options)
+
+type options struct {
+	w, h   int
+	theme  string
+	dir    string
+	args   []string
+	saves  Store
+	player string
+	remote string
+}
+
+// WithSize fixes the game's size in cells; without it the game fills the
+// window, following WindowSizeMsg
+func WithSize(w, h int) Option {
+	return func(o *options) { o.w, o.h = w, h }
+}
+
+// WithTheme dresses every run in a theme: a seasonal event from the
+// calendar by name (e.g. "Halloween"), or "night" for flashlight runs
+func WithTheme(name string) Option {
+	return func(o *options) { o.theme = name }
+}
+
+// WithDataDir keeps save files in dir instead of next to the running
+// binary. It applies to every game in the process.
+func WithDataDir(dir string) Option {
+	return func(o *options) { o.dir = dir }
+}
+
+// WithStore keeps the game's profile, stats, history and replays in s
+// instead of the store -store picks. A server can hand each player their
+// own, or several games the same one.
+func WithStore(s Store) Option {
+	return func(o *options) { o.saves = s }
+}
+
+// WithPlayer keeps the game's saves apart for the player known by key,
+// usually their SSH public key as ssh.MarshalAuthorizedKey writes it, so
+// they find their best and stats again when they come back (see
+// players.go). WithStore overrides it.
+func WithPlayer(key string) Option {
+	return func(o *options) { o.player = key }
+}
+
+// WithRemote gives the address the player connects from, such as
+// ssh.Session's RemoteAddr().String(), for -max-per-ip to count by
+func WithRemote(addr string) Option {
+	return func(o *options) { o.remote = remoteHost(addr) }
+}
+
+// WithArgs applies command-line options, as the gopherdash command takes
+// them, e.g. WithArgs("-class", "ninja", "-minimal")
+func WithArgs(args ...string) Option {
+	return func(o *options) { o.args = append(o.args, args...) }
+}
+
+// New builds a game from the default config and opts. The player's config
+// file is not read.
+func New(opts ...Option) (Model, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.dir != "" {
+		dataDir = o.dir
+	}
+	cfg, err := parseFlags(defaultConfig(), o.args)
+	if err != nil {
+		return Model{}, err
+	}
+	saves := o.saves
+	switch {
+	case saves != nil:
+	case o.player != "":
+		saves, err = playerStore(o.player)
+	default:
+		saves, err = openStore(cfg)
+	}
+	if err != nil {
+		return Model{}, err
+	}
+	var ev *event
+	switch {
+	case o.theme == "":
+	case strings.EqualFold(o.theme, "night"):
+		cfg.Night = true
+	default:
+		ev = findEvent(o.theme)
+		if ev == nil {
+			return Model{}, fmt.Errorf("theme %q: no such event in the calendar", o.theme)
+		}
+	}
+	if cfg.MetricsAddr != "" {
+		if err := serveMetrics(cfg.MetricsAddr); err != nil {
+			return Model{}, err
+		}
+	}
+	g := Model{m: initialModel(cfg, saves), remote: o.remote}
+	g.m.embedded = true
+	if ev != nil {
+		g.m.event = ev
+	}
+	if o.w > 0 && o.h > 0 {
+		g = g.SetSize(o.w, o.h)
+	}
+	return g, nil
+}
+
+// findEvent looks an event up in the calendar by name, ignoring case
+func findEvent(name string) *event {
+	for _, ev := range loadEvents() {
+		if strings.EqualFold(ev.Name, name) {
+			return &ev
+		}
+	}
+	return nil
+}
+
+// SetSize resizes the game to w×h cells; from then on the host's
+// WindowSizeMsg no longer reaches it
+func (g Model) SetSize(w, h int) Model {
+	g.sized = true
+	next, _ := g.m.Update(tea.WindowSizeMsg{Width: w, Height: h})
+	g.m = next.(model)
+	return g
+}
+
+// Join connects the game to the others in this process, as a server that
+// hosts one per connection (over SSH, say) runs them: it counts as an
+// active session in /metrics, and when any of the games sets a new server
+// record the others show a toast. send delivers messages to this game's
+// program, usually tea.Program.Send. Call leave when the session ends.
+// With -max-sessions or -max-per-ip reached it lets the session in no
+// further, returning ErrServerFull or ErrTooManyFromAddr (see limits.go).
+func (g Model) Join(send func(tea.Msg)) (joined Model, leave func(), err error) {
+	if err := gate.enter(g.remote, g.m.cfg); err != nil {
+		return g, func() {}, err
+	}
+	metrics.sessionStarted()
+	g.m.session = records.join(g.m.profile.HighScore)
+	records.listen(g.m.session, send)
+	id, remote := g.m.session, g.remote
+	return g, func() {
+		records.leave(id)
+		metrics.sessionEnded()
+		gate.exit(remote)
+	}, nil
+}
+
+// Init starts the game's clock
+func (g Model) Init() tea.Cmd { return g.m.Init() }
+
+// Update plays msg
+func (g Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.WindowSizeMsg); ok && g.sized {
+		return g, nil
+	}
+	next, cmd := g.m.Update(msg)
+	g.m = next.(model)
+	return g, cmd
+}
+
+// View draws the game at its size
+func (g Model) View() string { return g.m.View() }
+
+// quit ends the program, or when embedded hands control back to the host
+func (m model) quit() tea.Cmd {
+	if !m.embedded {
+		return tea.Quit
+	}
+	done := DoneMsg{m.profile.HighScore, m.idleKicked()}
+	return func() tea.Msg { return done }
+}
@@ -0,0 +1,73 @@
+package gopherdash
+
+import (
+	"slices"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// RACE EMOTES
+// ----------------------------------------------------------------------------
+
+// During a race 1, 2 and 3 send a quick emote down the race connection,
+// which shows next to the opponent's bar in the other player's HUD for a
+// few seconds. M mutes the ones coming in. Only the emotes listed here are
+// shown, whatever the other end sends.
+
+const (
+	emoteShow = 3 * time.Second        // an emote stays in the HUD this long
+	emoteGap  = 700 * time.Millisecond // shortest time between two we send
+)
+
+// emotes are sent with keys 1, 2, 3…
+var emotes = []string{"👍", "😱", "🏁"}
+
+// raceEmoteMsg is an emote from the opponent
+type raceEmoteMsg struct{ emote string }
+
+// emoteKey is the emote a key sends, or ""
+func emoteKey(key string) string {
+	if len(key) == 1 && key[0] >= '1' && int(key[0]-'1') < len(emotes) {
+		return emotes[key[0]-'1']
+	}
+	return ""
+}
+
+// sendEmote sends e to the opponent, unless the last one just went
+func (m *model) sendEmote(e string) {
+	r := &m.race
+	if r.oppGone || m.now().Sub(r.emoteSent) < emoteGap {
+		return
+	}
+	r.emoteSent = m.now()
+	r.link.send(raceWire{Type: "emote", Emote: e})
+	m.notify("You sent " + e)
+}
+
+// gotEmote shows the opponent's emote, if it's one we know and not muted
+func (m *model) gotEmote(e string) {
+	if m.race.muted || !slices.Contains(emotes, e) {
+		return
+	}
+	m.race.emote, m.race.emoteAt = e, m.now()
+}
+
+// toggleMute turns the opponent's emotes off or back on
+func (m *model) toggleMute() {
+	r := &m.race
+	r.muted, r.emote = !r.muted, ""
+	if r.muted {
+		m.notify("Opponent's emotes muted")
+	} else {
+		m.notify("Opponent's emotes on")
+	}
+}
+
+// oppEmote is the opponent's emote to show in the HUD, or ""
+func (m model) oppEmote() string {
+	r := m.race
+	if r.emote == "" || r.muted || m.now().Sub(r.emoteAt) > emoteShow {
+		return ""
+	}
+	return r.emote
+}
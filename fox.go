@@ -0,0 +1,60 @@
+package gopherdash
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// FOX (a chaser that closes in on near-misses)
+// ----------------------------------------------------------------------------
+
+const (
+	foxChar        = "🦊"
+	foxCause       = "fox"
+	foxStart       = 8   // cells behind the gopher at the start of a run
+	foxRecede      = 100 // clean distance for the fox to drop back a cell
+	nearMissHeight = 3   // clearing a hazard this low counts as a near-miss
+)
+
+// hazardous reports whether k hurts a gopher on the ground, as opposed to
+// a tile it can run over
+func hazardous(k ObstacleKind) bool {
+	h := k.Collides(player{})
+	return h == fatal || h == ledge
+}
+
+// foxCloser moves the fox a cell nearer after a near-miss: a hazard cleared
+// by a whisker or a hole saved with coyote time. Reaching the gopher's
+// column ends the run.
+func (m *model) foxCloser(why string) {
+	if !m.cfg.Fox || m.gameOver {
+		return
+	}
+	m.fox--
+	m.foxCalm = 0
+	m.logInfo("fox closer", "why", why, "behind", m.fox)
+	if m.fox <= 0 {
+		m.setGameOver(foxCause)
+	}
+}
+
+// stepFox lets the fox fall back while the gopher runs cleanly
+func (m *model) stepFox() {
+	if !m.cfg.Fox {
+		return
+	}
+	if m.foxCalm++; m.foxCalm >= foxRecede && m.fox < foxStart {
+		m.fox++
+		m.foxCalm = 0
+	}
+}
+
+// foxColumn is the playfield column the fox is in; negative is off-screen
+func (m model) foxColumn() int { return playerCol - m.fox }
+
+// foxHUD is the proximity meter
+func (m model) foxHUD() string {
+	near := foxStart - m.fox
+	return fmt.Sprintf("%s %s%s", foxChar, strings.Repeat("▮", near), strings.Repeat("▯", m.fox))
+}
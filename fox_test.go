@@ -0,0 +1,72 @@
+package gopherdash
+
+import "testing"
+
+func foxModel(t *testing.T) model {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Fox = 0, true
+	m, _ := clockedModel(t, cfg)
+	return m
+}
+
+// Clearing a rock low down brings the fox a cell closer; clearing it high
+// doesn't.
+func TestFoxNearMiss(t *testing.T) {
+	for _, tc := range []struct {
+		ahead int // cells between the gopher and the rock when it jumps
+		fox   int
+	}{
+		{1, foxStart - 1}, // still rising, 3 rows up
+		{3, foxStart},     // at the top of the jump
+	} {
+		m := foxModel(t)
+		m.obstacles = []obstacle{{m.body().lo + tc.ahead, rock{}}}
+		m.jump()
+		for range jumpCells + 1 {
+			m.step(m.now())
+		}
+		if m.gameOver || m.fox != tc.fox {
+			t.Errorf("rock %d ahead: fox %d behind (game over %v), want %d", tc.ahead, m.fox, m.gameOver, tc.fox)
+		}
+	}
+}
+
+// Jumping out of a hole in the coyote window is a near-miss too.
+func TestFoxCoyote(t *testing.T) {
+	m := foxModel(t)
+	m.obstacles = []obstacle{{m.body().lo + 1, hole{}}}
+	m.step(m.now())
+	if m.coyote == 0 || m.gameOver {
+		t.Fatalf("running onto the hole: coyote %d, game over %v", m.coyote, m.gameOver)
+	}
+	m.pressJump()
+	m.step(m.now())
+	if m.gameOver || m.fox != foxStart-1 {
+		t.Errorf("coyote save: fox %d behind, game over %v", m.fox, m.gameOver)
+	}
+}
+
+// The fox drops back a cell per foxRecede clean cells, no further than
+// where it started.
+func TestFoxRecedes(t *testing.T) {
+	m := foxModel(t)
+	m.fox = foxStart - 2
+	for i := 1; i <= 3*foxRecede; i++ {
+		m.step(m.now())
+		clearHazards(&m)
+		want := min(foxStart-2+i/foxRecede, foxStart)
+		if m.gameOver || m.fox != want {
+			t.Fatalf("after %d clean cells the fox is %d behind, want %d", i, m.fox, want)
+		}
+	}
+}
+
+// The fox reaching the gopher's column ends the run.
+func TestFoxCatches(t *testing.T) {
+	m := foxModel(t)
+	m.dist, m.fox = 40, 1
+	m.foxCloser("rock")
+	if !m.gameOver || m.cause != foxCause || m.foxColumn() != playerCol {
+		t.Errorf("fox in column %d: game over %v, cause %q", m.foxColumn(), m.gameOver, m.cause)
+	}
+}
@@ -0,0 +1,424 @@
+package gopherdash
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// SAVE REPAIR (`gopherdash fsck [-n]`)
+// ----------------------------------------------------------------------------
+
+// A game killed half-way through writing a save can leave it truncated, and
+// the game reads a save it can't decode as empty, so its next write throws
+// away whatever was left. `gopherdash fsck` goes over every save the game
+// writes, decoding each against its format and checking what it holds.
+// Entries that make no sense, such as a negative score, are dropped or
+// zeroed, and a truncated list or map keeps the entries before the cut; the save is then
+// written back tidied, with the original kept as NAME.corrupt-TIME. A save
+// with nothing to salvage is moved there instead, out of the game's way.
+// With -n it only reports, and exits 1 if anything needs fixing.
+//
+// The sqlite store keeps the profile, stats, history and replays in tables
+// with a schema of their own, so fsck runs the database's integrity check
+// on it instead; the smaller saves are files whatever the store.
+
+// fsckSave is a save fsck knows the format of
+type fsckSave struct {
+	path func() string
+	// check decodes data and returns it tidied, with what was wrong with
+	// it, or an error if none of it can be read
+	check func(data []byte) (tidied any, wrong []string, err error)
+}
+
+// fsckSaves are the files saved whatever the store
+var fsckSaves = []fsckSave{
+	{autosavePath, checkAutosave},
+	{splitsPath, tidyMap("books", func(b splitBook) bool {
+		return b.PBDist >= 0 && !slices.ContainsFunc(slices.Concat(b.PB, b.Best), func(d time.Duration) bool { return d < 0 })
+	})},
+	{deathsPath, tidyMap("seeds", func(dists []int) bool {
+		return !slices.ContainsFunc(dists, func(d int) bool { return d < 0 })
+	})},
+	{weeklyPath, tidyMap("boards", func(b weeklyBoard) bool { return b.Seed != 0 && validScores(b.Scores) })},
+	{streakPath, checkStreak},
+	{telemetryPath, checkTelemetry},
+	{scoresPath, tidyMap("rule sets", func(t map[string][]weeklyScore) bool {
+		for _, s := range t {
+			if !validScores(s) {
+				return false
+			}
+		}
+		return true
+	})},
+}
+
+// fsckFiles are the file store's own saves
+var fsckFiles = []fsckSave{
+	{profilePath, checkProfile},
+	{statsPath, checkStats},
+	{historyPath, tidyList("runs", func(r runRecord) bool { return r.Distance >= 0 && r.Jumps >= 0 && r.Bonus >= 0 })},
+	{func() string { return replayPath(replayLast) }, checkReplay},
+	{func() string { return replayPath(replayBest) }, checkReplay},
+	{func() string { return replayPath(replayDaily) }, checkReplay},
+	{func() string { return replayPath(replayYesterday) }, checkReplay},
+}
+
+// fsckMain runs `gopherdash fsck [-n]`
+func fsckMain(args []string) int {
+	cfg := loadConfig()
+	fs := flag.NewFlagSet("gopherdash fsck", flag.ContinueOnError)
+	dry := fs.Bool("n", false, "only report what's wrong; change nothing")
+	fs.StringVar(&cfg.Store, "store", cfg.Store, "store the saves are in: file, sqlite or memory")
+	fs.StringVar(&cfg.DB, "db", cfg.DB, "database file for -store sqlite")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	var (
+		report string
+		bad    int
+	)
+	withSaveLock(func() { report, bad = fsck(cfg, *dry, time.Now()) })
+	fmt.Print(report)
+	switch {
+	case bad == 0:
+		fmt.Println("\nEverything's in order.")
+	case *dry:
+		fmt.Printf("\n%d saves need fixing; run without -n to fix them.\n", bad)
+		return exitError
+	default:
+		fmt.Printf("\n%d saves fixed.\n", bad)
+	}
+	return 0
+}
+
+// fsck checks the saves cfg's store uses, fixing them unless dry, and
+// reports on each; bad is how many weren't in order
+func fsck(cfg config, dry bool, now time.Time) (report string, bad int) {
+	var b strings.Builder
+	line := func(k, v string) { fmt.Fprintf(&b, "  %-26s %s\n", k, v) }
+	fmt.Fprintf(&b, "saves in %s\n", filepath.Dir(dataPath(configFile)))
+	checks := fsckSaves
+	switch cfg.Store {
+	case "", "file":
+		checks = append(slices.Clone(fsckFiles), fsckSaves...)
+	case "sqlite":
+		state, ok := fsckDB(dbPath(cfg))
+		line(filepath.Base(dbPath(cfg)), state)
+		if !ok {
+			bad++
+		}
+	}
+	for _, s := range checks {
+		state, ok := fsckFile(s, dry, now)
+		line(filepath.Base(s.path()), state)
+		if !ok {
+			bad++
+		}
+	}
+	return b.String(), bad
+}
+
+// fsckFile checks one save, fixing it unless dry, and says how it was
+func fsckFile(s fsckSave, dry bool, now time.Time) (state string, ok bool) {
+	path := s.path()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "missing", true
+	}
+	if err != nil {
+		return "unreadable: " + err.Error(), false
+	}
+	tidied, wrong, err := s.check(data)
+	if err == nil && len(wrong) == 0 {
+		return "ok", true
+	}
+	what := strings.Join(wrong, ", ")
+	if err != nil {
+		what = err.Error()
+	}
+	aside := fmt.Sprintf("%s.corrupt-%s", path, now.Format("20060102-150405"))
+	switch {
+	case dry && err != nil:
+		return "corrupt, nothing to salvage: " + what, false
+	case dry:
+		return "needs repair: " + what, false
+	case err != nil:
+		if err := os.Rename(path, aside); err != nil {
+			return "corrupt, and couldn't be moved aside: " + err.Error(), false
+		}
+		return fmt.Sprintf("quarantined as %s: %s", filepath.Base(aside), what), false
+	}
+	fixed, err := json.Marshal(tidied)
+	if err == nil {
+		err = os.WriteFile(aside, data, 0o644)
+	}
+	if err == nil {
+		err = os.WriteFile(path+".tmp", fixed, 0o644)
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		return fmt.Sprintf("needs repair (%s), but couldn't be rewritten: %v", what, err), false
+	}
+	return fmt.Sprintf("repaired, original kept as %s: %s", filepath.Base(aside), what), false
+}
+
+// fsckDB runs the sqlite database's own integrity check
+func fsckDB(path string) (state string, ok bool) {
+	if _, err := os.Stat(path); err != nil {
+		return "missing", errors.Is(err, os.ErrNotExist)
+	}
+	s, err := openSQLite(path)
+	if err != nil {
+		return "can't be opened: " + err.Error(), false
+	}
+	defer s.db.Close()
+	if res := s.integrity(); res != "ok" {
+		return "damaged: " + res, false
+	}
+	return "ok", true
+}
+
+// ----------------------------------------------------------------------------
+// Salvaging lists and maps
+// ----------------------------------------------------------------------------
+
+// fsckEntry is one element of a list, or one key and its value in a map
+type fsckEntry struct {
+	key string // "" in a list
+	raw json.RawMessage
+}
+
+// salvage splits a JSON list or object into its entries, as many as can be
+// read: a truncated one gives those before the cut, and sets cut
+func salvage(data []byte) (entries []fsckEntry, cut bool, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	open, err := dec.Token()
+	switch {
+	case err != nil:
+		return nil, false, errors.New("empty or unreadable")
+	case open == nil:
+		return nil, false, nil // null: saved empty
+	case open != json.Delim('[') && open != json.Delim('{'):
+		return nil, false, errors.New("not a list or a map")
+	}
+	for dec.More() {
+		var e fsckEntry
+		if open == json.Delim('{') {
+			k, err := dec.Token()
+			if err != nil {
+				return entries, true, nil
+			}
+			e.key, _ = k.(string)
+		}
+		if err := dec.Decode(&e.raw); err != nil {
+			return entries, true, nil
+		}
+		entries = append(entries, e)
+	}
+	if _, err := dec.Token(); err != nil {
+		return entries, true, nil
+	}
+	return entries, false, nil
+}
+
+// tidy decodes the entries of a list or map save as T, dropping those that
+// don't decode or that valid rejects; noun names the entries in the report
+func tidy[T any](data []byte, noun string, valid func(T) bool) (keys []string, vals []T, wrong []string, err error) {
+	entries, cut, err := salvage(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cut && len(entries) == 0 {
+		return nil, nil, nil, fmt.Errorf("cut short before any %s", noun)
+	}
+	dropped := 0
+	for _, e := range entries {
+		var v T
+		if json.Unmarshal(e.raw, &v) != nil || !valid(v) {
+			dropped++
+			continue
+		}
+		keys, vals = append(keys, e.key), append(vals, v)
+	}
+	if cut {
+		wrong = append(wrong, fmt.Sprintf("cut short after %d %s", len(entries), noun))
+	}
+	if dropped > 0 {
+		wrong = append(wrong, fmt.Sprintf("%d %s that made no sense dropped", dropped, noun))
+	}
+	return keys, vals, wrong, nil
+}
+
+// tidyList checks a save that's a list of T
+func tidyList[T any](noun string, valid func(T) bool) func([]byte) (any, []string, error) {
+	return func(data []byte) (any, []string, error) {
+		_, vals, wrong, err := tidy(data, noun, valid)
+		return vals, wrong, err
+	}
+}
+
+// tidyMap checks a save that's a map of T
+func tidyMap[T any](noun string, valid func(T) bool) func([]byte) (any, []string, error) {
+	return func(data []byte) (any, []string, error) {
+		keys, vals, wrong, err := tidy(data, noun, valid)
+		m := make(map[string]T, len(keys))
+		for i, k := range keys {
+			m[k] = vals[i]
+		}
+		return m, wrong, err
+	}
+}
+
+// validScores reports whether a board's scores could have been played
+func validScores(scores []weeklyScore) bool {
+	return !slices.ContainsFunc(scores, func(s weeklyScore) bool { return s.Score < 0 })
+}
+
+// ----------------------------------------------------------------------------
+// Single-record saves
+// ----------------------------------------------------------------------------
+
+// zeroNegative sets any of counts below zero to zero, reporting whether
+// there were any
+func zeroNegative(counts ...*int) bool {
+	found := false
+	for _, n := range counts {
+		if *n < 0 {
+			*n, found = 0, true
+		}
+	}
+	return found
+}
+
+// dropNegative deletes counts' entries below zero, reporting how many
+func dropNegative(counts map[string]int) int {
+	n := 0
+	for k, v := range counts {
+		if v < 0 {
+			delete(counts, k)
+			n++
+		}
+	}
+	return n
+}
+
+func checkProfile(data []byte) (any, []string, error) {
+	var p profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, nil, err
+	}
+	if p.newer() {
+		return nil, nil, nil // not this build's to judge
+	}
+	var wrong []string
+	if zeroNegative(&p.HighScore, &p.LatencyMS) {
+		wrong = append(wrong, "negative high score or latency zeroed")
+	}
+	if n := dropNegative(p.ModBests); n > 0 {
+		wrong = append(wrong, fmt.Sprintf("%d negative modified-run bests dropped", n))
+	}
+	return p, wrong, nil
+}
+
+func checkStats(data []byte) (any, []string, error) {
+	var st stats
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, nil, err
+	}
+	var wrong []string
+	negative := zeroNegative(&st.Runs, &st.Deaths, &st.Sessions)
+	if st.PlayTime < 0 {
+		st.PlayTime, negative = 0, true
+	}
+	for _, counts := range []map[string]int{st.ByCause, st.BySpeed, st.ByDistance, st.Met} {
+		negative = dropNegative(counts) > 0 || negative
+	}
+	if negative {
+		wrong = append(wrong, "negative counts dropped")
+	}
+	return st, wrong, nil
+}
+
+func checkStreak(data []byte) (any, []string, error) {
+	var s streak
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, nil, err
+	}
+	var wrong []string
+	if zeroNegative(&s.Length, &s.Best, &s.Target) {
+		wrong = append(wrong, "negative streak or target zeroed")
+	}
+	return s, wrong, nil
+}
+
+func checkTelemetry(data []byte) (any, []string, error) {
+	var b telemetryBatch
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, nil, err
+	}
+	var wrong []string
+	negative := zeroNegative(&b.Sessions, &b.Runs, &b.Distance, &b.Longest, &b.Crashes)
+	for _, counts := range []map[string]int{b.ByCause, b.Terminals} {
+		negative = dropNegative(counts) > 0 || negative
+	}
+	if negative {
+		wrong = append(wrong, "negative counts dropped")
+	}
+	return b, wrong, nil
+}
+
+func checkAutosave(data []byte) (any, []string, error) {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, nil, err
+	}
+	if s.Dist <= 0 || s.FrameDur <= 0 {
+		return nil, nil, errors.New("no run in it")
+	}
+	var wrong []string
+	n := len(s.Obstacles)
+	s.Obstacles = slices.DeleteFunc(s.Obstacles, func(ob savedObstacle) bool {
+		_, ok := kindByName(ob.Typ)
+		return !ok
+	})
+	if n > len(s.Obstacles) {
+		wrong = append(wrong, fmt.Sprintf("%d unknown obstacles dropped", n-len(s.Obstacles)))
+	}
+	return s, wrong, nil
+}
+
+func checkReplay(data []byte) (any, []string, error) {
+	var r replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, nil, err
+	}
+	if r.Format == 0 || r.Seed == 0 {
+		return nil, nil, errors.New("not a replay")
+	}
+	if r.Format > replayFormat {
+		return nil, nil, nil // a newer build's tape
+	}
+	var wrong []string
+	n := len(r.Inputs)
+	r.Inputs = slices.DeleteFunc(r.Inputs, func(in replayInput) bool { return in.Step < 0 || in.Step > r.Steps })
+	if n > len(r.Inputs) {
+		wrong = append(wrong, fmt.Sprintf("%d inputs outside the run dropped", n-len(r.Inputs)))
+	}
+	byStep := func(a, b replayInput) int { return a.Step - b.Step }
+	if !slices.IsSortedFunc(r.Inputs, byStep) {
+		slices.SortStableFunc(r.Inputs, byStep)
+		wrong = append(wrong, "inputs put back in order")
+	}
+	return r, wrong, nil
+}
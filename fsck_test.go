@@ -0,0 +1,83 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSalvage(t *testing.T) {
+	for _, tc := range []struct {
+		data    string
+		entries int
+		cut     bool
+		err     bool
+	}{
+		{`[1, 2, 3]`, 3, false, false},
+		{`[1, 2, 3`, 3, true, false},
+		{`[{"a": 1}, {"a": 2}, {"a"`, 2, true, false},
+		{`{"x": [1], "y": [2`, 1, true, false},
+		{`null`, 0, false, false},
+		{``, 0, false, true},
+		{`"text"`, 0, false, true},
+	} {
+		entries, cut, err := salvage([]byte(tc.data))
+		if len(entries) != tc.entries || cut != tc.cut || (err != nil) != tc.err {
+			t.Errorf("%q: %d entries, cut %v, err %v", tc.data, len(entries), cut, err)
+		}
+	}
+}
+
+// A truncated history keeps its readable runs, a profile with nothing to
+// salvage is moved aside, and -n touches neither.
+func TestFsck(t *testing.T) {
+	isolateSaves(t)
+	runs, _ := json.Marshal([]runRecord{{Distance: 120, Cause: "rock"}, {Distance: -5, Cause: "rock"}, {Distance: 340, Cause: "log"}})
+	history := string(runs[:len(runs)-20]) // killed mid-write
+	profileData := `{"version": 4, "high_sc`
+	stats, _ := json.Marshal(stats{Runs: 3, Deaths: 3})
+	for path, data := range map[string]string{historyPath(): history, profilePath(): profileData, statsPath(): string(stats)} {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := defaultConfig()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	report, bad := fsck(cfg, true, now)
+	if bad != 2 {
+		t.Errorf("-n found %d bad saves:\n%s", bad, report)
+	}
+	if data, _ := os.ReadFile(historyPath()); string(data) != history {
+		t.Error("-n changed the history")
+	}
+
+	report, bad = fsck(cfg, false, now)
+	if bad != 2 || !strings.Contains(report, "repaired") || !strings.Contains(report, "quarantined") {
+		t.Errorf("fixed %d saves:\n%s", bad, report)
+	}
+	got := fileStore{}.readRuns()
+	if len(got) != 1 || got[0].Distance != 120 {
+		t.Errorf("history after repair: %+v", got)
+	}
+	aside := ".corrupt-" + now.Format("20060102-150405")
+	if data, _ := os.ReadFile(historyPath() + aside); string(data) != history {
+		t.Error("the original history wasn't kept")
+	}
+	if _, err := os.Stat(profilePath()); !os.IsNotExist(err) {
+		t.Error("the corrupt profile is still in the game's way")
+	}
+	if data, _ := os.ReadFile(profilePath() + aside); string(data) != profileData {
+		t.Error("the corrupt profile wasn't kept")
+	}
+
+	if report, bad := fsck(cfg, true, now); bad != 0 {
+		t.Errorf("%d saves still bad after fsck:\n%s", bad, report)
+	}
+	if matches, _ := filepath.Glob(statsPath() + ".corrupt-*"); len(matches) > 0 {
+		t.Error("a sound save was moved aside")
+	}
+}
@@ -0,0 +1,151 @@
+package gopherdash
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// REPLAY GIFS (`gopherdash replay FILE -gif OUT`)
+// ----------------------------------------------------------------------------
+
+// A replay can be rendered to an animated GIF for sharing where there's no
+// terminal. The tape is played through off screen on a playfield of -cols
+// by -rows cells, and each step becomes a frame in which every cell is a
+// block of colour: the tile's, the sprite's, or the text's for anything
+// stamped over the playfield. Frames keep the run's own timing, speed-ups
+// included.
+
+const (
+	gifCell    = 8               // default pixels per cell
+	gifMinStep = 2               // shortest frame delay most viewers honour, in 1/100 s
+	gifHold    = 2 * time.Second // the last frame stays up this long
+)
+
+var (
+	gifSky  = color.RGBA{0x1c, 0x1c, 0x1c, 0xff} // empty cells
+	gifText = color.RGBA{0xd0, 0xd0, 0xd0, 0xff} // stamped text
+	gifMisc = color.RGBA{0xff, 0x00, 0xff, 0xff} // a glyph not in gifColours
+)
+
+// gifColours stand in for the sprites and tiles as one colour each
+var gifColours = map[string]color.RGBA{
+	playerChar:      {0xd2, 0x9b, 0x5a, 0xff},
+	"🦫":             {0x8b, 0x5a, 0x2b, 0xff},
+	"🥷":             {0x40, 0x40, 0x60, 0xff},
+	"🦔":             {0xa0, 0x78, 0x50, 0xff},
+	rockChar:        {0x8a, 0x8a, 0x8a, 0xff},
+	logChar:         {0x6b, 0x42, 0x26, 0xff},
+	"🎃":             {0xff, 0x8c, 0x00, 0xff},
+	groundChar:      {0x8b, 0x4a, 0x1b, 0xff},
+	"⬜":             {0xf0, 0xf0, 0xf0, 0xff},
+	wallChar:        {0xb2, 0x22, 0x22, 0xff},
+	acornChar:       {0xa0, 0x52, 0x2d, 0xff},
+	foxChar:         {0xff, 0x66, 0x00, 0xff},
+	"🟨":             {0xff, 0xd7, 0x00, 0xff},
+	"🟦":             {0x1e, 0x90, 0xff, 0xff},
+	"🌼":             {0xff, 0xe0, 0x60, 0xff},
+	"🌱":             {0x5c, 0xb8, 0x3c, 0xff},
+	"🍄":             {0xd0, 0x30, 0x30, 0xff},
+	"👣":             {0x60, 0x50, 0x48, 0xff},
+	"🪧":             {0xb0, 0x80, 0x50, 0xff},
+	fogChar:         {0x55, 0x55, 0x55, 0xff},
+	nightGroundChar: {0x30, 0x30, 0x30, 0xff},
+}
+
+// gifPalette has every colour a frame can use, the sky first
+func gifPalette() color.Palette {
+	p := color.Palette{gifSky, gifText, gifMisc}
+	for _, c := range gifColours {
+		p = append(p, c)
+	}
+	return p
+}
+
+// cellColour is the colour a playfield cell is drawn in
+func cellColour(cell string) color.Color {
+	if strings.Contains(cell, "\x1b") { // a styled tile, e.g. unlit ground
+		var b strings.Builder
+		for _, c := range parseANSI(cell) {
+			b.WriteString(c.text)
+		}
+		cell = b.String()
+	}
+	if c, ok := gifColours[cell]; ok {
+		return c
+	}
+	if strings.TrimSpace(cell) == "" {
+		return gifSky
+	}
+	if len(cell) <= 2 {
+		return gifText // plain ASCII from stampText
+	}
+	return gifMisc
+}
+
+// gifFrame draws the playfield as it is now
+func (m model) gifFrame(palette color.Palette, px int) *image.Paletted {
+	rows := m.gameCells(false)
+	img := image.NewPaletted(image.Rect(0, 0, m.gameCols*px, m.gameRows*px), palette)
+	for y, cells := range rows {
+		for x, cell := range cells {
+			c := uint8(palette.Index(cellColour(cell)))
+			for dy := 0; dy < px; dy++ {
+				for dx := 0; dx < px; dx++ {
+					img.SetColorIndex(x*px+dx, y*px+dy, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// writeGIF plays tape through on a cols×rows playfield and saves every step
+// as a frame of an animated GIF at path
+func writeGIF(tape replay, path string, cols, rows, px int) error {
+	m := newPlayback(tape)
+	// size the window, then grow it by whatever the HUD and strips took
+	size := tea.WindowSizeMsg{Width: 2*cols + 2, Height: rows + 2 + 3*2}
+	for range 2 {
+		next, _ := m.Update(size)
+		m = next.(model)
+		size.Width += 2 * (cols - m.gameCols)
+		size.Height += rows - m.gameRows
+	}
+	if m.gameRows <= 0 || m.gameCols <= 0 {
+		return fmt.Errorf("a %dx%d playfield is too small", cols, rows)
+	}
+	palette := gifPalette()
+	anim := &gif.GIF{}
+	owed := time.Duration(0) // frame time not yet given to a frame
+	add := func(d time.Duration) {
+		owed += d
+		delay := max(int(owed/(10*time.Millisecond)), gifMinStep)
+		owed -= time.Duration(delay) * 10 * time.Millisecond
+		anim.Image = append(anim.Image, m.gifFrame(palette, px))
+		anim.Delay = append(anim.Delay, delay)
+	}
+	for !m.gameOver && m.steps < tape.Steps {
+		d := m.tickDur()
+		add(d)
+		m.replayStep()
+	}
+	add(gifHold)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := gif.EncodeAll(f, anim); err != nil {
+		return err
+	}
+	return f.Close()
+}
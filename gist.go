@@ -0,0 +1,188 @@
+package gopherdash
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// GIST SYNC (scores in a private GitHub Gist)
+// ----------------------------------------------------------------------------
+
+// With gist_token set to a GitHub personal access token (the "gist" scope
+// is all it needs) the profile, stats, history and weekly boards are kept
+// as the JSON files of a private gist, so scores follow the player between
+// machines with no server of their own. The first upload creates the gist
+// and remembers its ID in .gopherdash_gist; on another machine set gist_id
+// (or -gist-id) to that ID to share it. Pulls and pushes work as cloud sync
+// does: on startup the gist is imported with scores merging and the rest
+// going to whichever side wrote last, and on exit it's overwritten.
+
+const (
+	gistFile        = ".gopherdash_gist"
+	gistDescription = "gopherdash saves"
+)
+
+// gistAPI is the GitHub API the gist lives on
+var gistAPI = "https://api.github.com"
+
+// gistSkip are the archive entries left out of the gist: the config is the
+// machine's own, and holds the token
+var gistSkip = []string{"config.json"}
+
+func gistPath() string { return dataPath(gistFile) }
+
+// gistSync is the gist the saves are kept in
+type gistSync struct {
+	id, token string
+	client    *http.Client
+}
+
+// gistFiles is the files object of the gist API
+type gistFiles map[string]struct {
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+	RawURL    string `json:"raw_url,omitempty"`
+}
+
+// newGistSync is the gist sync cfg asks for, or nil if it's off
+func newGistSync(cfg config) (*gistSync, error) {
+	if cfg.GistToken == "" {
+		if cfg.GistID != "" {
+			return nil, errors.New("gist sync needs a personal access token in the config file as gist_token")
+		}
+		return nil, nil
+	}
+	id := cfg.GistID
+	if id == "" {
+		data, _ := os.ReadFile(gistPath())
+		id = strings.TrimSpace(string(data))
+	}
+	return &gistSync{id: id, token: cfg.GistToken, client: &http.Client{Timeout: syncTimeout}}, nil
+}
+
+func (g *gistSync) host() string {
+	if g.id == "" {
+		return "a new gist"
+	}
+	return "gist " + g.id
+}
+
+func (g *gistSync) pull(saves Store, sign bool) string {
+	if g.id == "" {
+		return "Sync: no gist yet; one is made when you quit"
+	}
+	entries, found, err := g.get()
+	switch {
+	case err != nil:
+		return fmt.Sprintf("Sync: couldn't reach %s: %v", g.host(), err)
+	case !found:
+		return fmt.Sprintf("Sync: %s is gone; check gist_id", g.host())
+	}
+	if err := checkManifest(entries); err != nil {
+		return fmt.Sprintf("Sync: %s is unusable: %v", g.host(), err)
+	}
+	for _, line := range importArchive(saves, entries, "newer", sign) {
+		logger.Info("gist pull", "result", line)
+	}
+	return fmt.Sprintf("Sync: pulled from %s at %s", g.host(), time.Now().Format("15:04"))
+}
+
+func (g *gistSync) push(saves Store) error {
+	entries, err := archiveEntries(saves)
+	if err != nil {
+		return err
+	}
+	files := map[string]map[string]string{}
+	for _, e := range entries {
+		if !slices.Contains(gistSkip, e.name) {
+			files[e.name] = map[string]string{"content": string(e.data)}
+		}
+	}
+	body, err := json.Marshal(map[string]any{
+		"description": gistDescription, "public": false, "files": files,
+	})
+	if err != nil {
+		return err
+	}
+	if g.id != "" {
+		_, err := g.do(http.MethodPatch, gistAPI+"/gists/"+g.id, body)
+		return err
+	}
+	data, err := g.do(http.MethodPost, gistAPI+"/gists", body)
+	if err != nil {
+		return err
+	}
+	var made struct{ ID string }
+	if json.Unmarshal(data, &made) != nil || made.ID == "" {
+		return errors.New("GitHub didn't say which gist it made")
+	}
+	g.id = made.ID
+	return os.WriteFile(gistPath(), []byte(made.ID+"\n"), 0o644)
+}
+
+// get reads the gist's files, fetching any too big to come inline
+func (g *gistSync) get() (entries map[string][]byte, found bool, err error) {
+	data, err := g.do(http.MethodGet, gistAPI+"/gists/"+g.id, nil)
+	if errors.Is(err, errGistMissing) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var gist struct{ Files gistFiles }
+	if err := json.Unmarshal(data, &gist); err != nil {
+		return nil, false, err
+	}
+	entries = map[string][]byte{}
+	for name, f := range gist.Files {
+		if !f.Truncated {
+			entries[name] = []byte(f.Content)
+			continue
+		}
+		raw, err := g.do(http.MethodGet, f.RawURL, nil)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", name, err)
+		}
+		entries[name] = raw
+	}
+	return entries, true, nil
+}
+
+var errGistMissing = errors.New("no such gist")
+
+// do sends one authenticated API request and reads the reply
+func (g *gistSync) do(method, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errGistMissing
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, errors.New("GitHub refused gist_token")
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("%s: %s", method, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, archiveMaxFile))
+}
@@ -0,0 +1,133 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeGitHub serves the gist API for one token, keeping the gists in memory.
+// profile.json always comes back truncated, so it has to be fetched from its
+// raw_url
+type fakeGitHub struct {
+	*httptest.Server
+	gists map[string]map[string]string
+	calls []string // method and path of each request
+}
+
+func newFakeGitHub(t *testing.T, token string) *fakeGitHub {
+	f := &fakeGitHub{gists: map[string]map[string]string{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if name, ok := strings.CutPrefix(r.URL.Path, "/raw/"); ok {
+			id, file, _ := strings.Cut(name, "/")
+			_, _ = w.Write([]byte(f.gists[id][file]))
+			return
+		}
+		var sent struct {
+			Files map[string]struct{ Content string }
+		}
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		id := strings.TrimPrefix(r.URL.Path, "/gists/")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/gists":
+			id = "g1"
+			f.gists[id] = map[string]string{}
+			w.WriteHeader(http.StatusCreated)
+		case f.gists[id] == nil:
+			http.NotFound(w, r)
+			return
+		}
+		for name, file := range sent.Files {
+			f.gists[id][name] = file.Content
+		}
+		files := map[string]map[string]any{}
+		for name, content := range f.gists[id] {
+			files[name] = map[string]any{"content": content}
+			if name == "profile.json" {
+				files[name] = map[string]any{"content": content[:len(content)/2], "truncated": true,
+					"raw_url": f.URL + "/raw/" + id + "/" + name}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "files": files})
+	}))
+	t.Cleanup(f.Close)
+	old := gistAPI
+	gistAPI = f.URL
+	t.Cleanup(func() { gistAPI = old })
+	return f
+}
+
+func TestGistSync(t *testing.T) {
+	saves := isolateSaves(t)
+	gh := newFakeGitHub(t, "ghp_x")
+	cfg := defaultConfig()
+	cfg.GistToken = "ghp_x"
+	g, err := newGistSync(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.pull(saves, false); !strings.Contains(got, "no gist yet") {
+		t.Errorf("pull before there's a gist: %q", got)
+	}
+
+	_ = saveProfile(saves, profile{Version: profileVersion, HighScore: 300})
+	if err := g.push(saves); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := os.ReadFile(gistPath()); string(id) != "g1\n" {
+		t.Errorf("%s holds %q", gistFile, id)
+	}
+	if _, ok := gh.gists["g1"]["config.json"]; ok {
+		t.Error("the config, token and all, went into the gist")
+	}
+	_ = saveProfile(saves, profile{Version: profileVersion, HighScore: 400})
+	if err := g.push(saves); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"POST /gists", "PATCH /gists/g1"}; strings.Join(gh.calls, ", ") != strings.Join(want, ", ") {
+		t.Errorf("pushes sent %q, want %q", gh.calls, want)
+	}
+
+	gh.calls = nil
+	again, _ := newGistSync(cfg) // finds the ID in the gist file
+	fresh := MemoryStore()
+	if got := again.pull(fresh, false); !strings.Contains(got, "pulled from gist g1") {
+		t.Fatalf("pull: %q", got)
+	}
+	if p := loadProfile(fresh); p.HighScore != 400 {
+		t.Errorf("pulled a best of %d", p.HighScore)
+	}
+	if len(gh.calls) != 2 || gh.calls[1] != "GET /raw/g1/profile.json" {
+		t.Errorf("pull sent %q, want the truncated profile fetched raw", gh.calls)
+	}
+}
+
+func TestGistErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, id, token string
+		want            string
+	}{
+		{"deleted gist", "gone", "ghp_x", "gist gone is gone; check gist_id"},
+		{"revoked token", "g1", "ghp_old", "GitHub refused gist_token"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			saves := isolateSaves(t)
+			gh := newFakeGitHub(t, "ghp_x")
+			gh.gists["g1"] = map[string]string{}
+			cfg := defaultConfig()
+			cfg.GistID, cfg.GistToken = tc.id, tc.token
+			g, _ := newGistSync(cfg)
+			if got := g.pull(saves, false); !strings.Contains(got, tc.want) {
+				t.Errorf("pull: %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package gopherdash
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ----------------------------------------------------------------------------
+// RUN GRADE
+// ----------------------------------------------------------------------------
+
+// Every run is graded S, A, B or C on the game-over screen, from how far it
+// got for how hard it was, how cleanly it cleared hazards and how few jumps
+// it wasted, with a line on what to work on. The line comes from the
+// run's telemetry: each near-miss and the crash are put down to a jump
+// that came too early (the gopher was on its way down) or too late (on its
+// way up, or still on the ground).
+
+const (
+	gradePar      = 250 // distance that makes full marks for survival at difficulty 1
+	gradeTendency = 2   // mistimed jumps of one kind it takes to call it a habit
+)
+
+// gradeDifficulty is how much harder than a plain run each option and
+// modifier makes one; they multiply
+var gradeDifficulty = map[string]float64{
+	"terrain":      1.3,
+	"night":        1.2,
+	"fox":          1.2,
+	modFog:         1.3,
+	modDoubleSpeed: 1.5,
+	modBig:         1.3,
+	modMirror:      1.2,
+	modLowGravity:  0.9,
+	modTiny:        0.8,
+	modShield:      0.9,
+}
+
+// timing is how many of a kind's close calls came from early or late jumps
+type timing struct{ early, late int }
+
+// runTelemetry is what a run did, as far as grading it goes
+type runTelemetry struct {
+	jumps  int               // jumps made since it started or was resumed
+	clears int               // hazards jumped clear
+	close  int               // of those, by a whisker
+	useful int               // jumps that cleared something
+	timing map[string]timing // near-misses and the crash, by kind
+}
+
+// note puts a close call with a kind down to an early or a late jump
+func (t *runTelemetry) note(kind string, early bool) {
+	if t.timing == nil {
+		t.timing = map[string]timing{}
+	}
+	tm := t.timing[kind]
+	if early {
+		tm.early++
+	} else {
+		tm.late++
+	}
+	t.timing[kind] = tm
+}
+
+// difficulty multiplies up the run's options and modifiers
+func (m model) difficulty() float64 {
+	d := 1.0
+	for _, o := range []struct {
+		on   bool
+		name string
+	}{{m.cfg.Terrain, "terrain"}, {m.cfg.Night, "night"}, {m.cfg.Fox, "fox"}} {
+		if o.on {
+			d *= gradeDifficulty[o.name]
+		}
+	}
+	for _, md := range m.mods {
+		if f, ok := gradeDifficulty[md]; ok {
+			d *= f
+		}
+	}
+	return d
+}
+
+// grade scores the run out of 100: half for distance against par, a
+// quarter each for clean clears and for jumps that cleared something
+func (m model) grade() (letter string, marks int) {
+	par := gradePar / m.difficulty()
+	survival := min(float64(m.dist)/par, 1)
+	clean, useful := 1.0, 1.0
+	if m.tele.clears > 0 {
+		clean = 1 - float64(m.tele.close)/float64(m.tele.clears)
+	}
+	if m.tele.jumps > 0 {
+		useful = min(float64(m.tele.useful)/float64(m.tele.jumps), 1)
+	}
+	marks = int(50*survival + 25*clean + 25*useful + 0.5)
+	switch {
+	case marks >= 90:
+		return "S", marks
+	case marks >= 75:
+		return "A", marks
+	case marks >= 55:
+		return "B", marks
+	}
+	return "C", marks
+}
+
+// feedback is one thing the run could have done better
+func (m model) feedback() string {
+	kinds := make([]string, 0, len(m.tele.timing))
+	for k := range m.tele.timing {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	worst, worstBy, early := "", 0, false
+	for _, k := range kinds {
+		tm := m.tele.timing[k]
+		if by := max(tm.early, tm.late); by > worstBy {
+			worst, worstBy, early = k, by, tm.early > tm.late
+		}
+	}
+	switch {
+	case worstBy >= gradeTendency && early:
+		return fmt.Sprintf("You jump too early on %ss", worst)
+	case worstBy >= gradeTendency:
+		return fmt.Sprintf("You jump too late on %ss", worst)
+	case m.tele.jumps >= 4 && m.tele.useful*2 < m.tele.jumps:
+		return fmt.Sprintf("%d of %d jumps cleared nothing: wait for the hazard", m.tele.jumps-m.tele.useful, m.tele.jumps)
+	case m.tele.clears == 0:
+		return "Jump when a hazard is a cell or two ahead"
+	case m.tele.close == 0:
+		return "Clean clears all the way: try a modifier (M)"
+	}
+	return "Good timing: now push for distance"
+}
+
+// gradeLine is the game-over line with the grade and the feedback
+func (m model) gradeLine() string {
+	letter, _ := m.grade()
+	return fmt.Sprintf("Grade %s · %s", letter, m.feedback())
+}
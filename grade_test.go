@@ -0,0 +1,68 @@
+package gopherdash
+
+import "testing"
+
+// TestCrashTiming puts crashes down to the jump that caused them: running
+// into a rock is a late jump, coming down on one an early jump
+func TestCrashTiming(t *testing.T) {
+	for _, tc := range []struct {
+		falling bool
+		want    timing
+	}{
+		{false, timing{late: 1}},
+		{true, timing{early: 1}},
+	} {
+		cfg := defaultConfig()
+		cfg.Countdown = 0
+		m, _ := clockedModel(t, cfg)
+		clearHazards(&m)
+		at := m.camera().toWorld(playerCol)
+		m.obstacles = append(m.obstacles, obstacle{at + 1, rock{}})
+		if tc.falling {
+			m.playerY, m.velY = m.groundRow()-1, 1
+		}
+		m.step(m.now())
+		if !m.gameOver {
+			t.Fatalf("falling %v: the rock missed", tc.falling)
+		}
+		if got := m.tele.timing["rock"]; got != tc.want {
+			t.Errorf("falling %v: timing %+v, want %+v", tc.falling, got, tc.want)
+		}
+	}
+}
+
+func TestGrade(t *testing.T) {
+	habit := map[string]timing{"log": {early: 3, late: 1}}
+	for _, tc := range []struct {
+		name     string
+		dist     int
+		mods     []string
+		tele     runTelemetry
+		letter   string
+		feedback string
+	}{
+		{"clean and far", 400, nil, runTelemetry{jumps: 20, clears: 20, useful: 20},
+			"S", "Clean clears all the way: try a modifier (M)"},
+		{"short of par", 100, nil, runTelemetry{jumps: 5, clears: 5, useful: 5},
+			"B", "Clean clears all the way: try a modifier (M)"},
+		{"par is lower when it's harder", 130, []string{modDoubleSpeed, modFog}, runTelemetry{jumps: 5, clears: 5, useful: 5},
+			"S", "Clean clears all the way: try a modifier (M)"},
+		{"early on logs", 300, nil, runTelemetry{jumps: 10, clears: 10, close: 6, useful: 10, timing: habit},
+			"A", "You jump too early on logs"},
+		{"wasted jumps", 60, nil, runTelemetry{jumps: 12, clears: 3, close: 1, useful: 3},
+			"C", "9 of 12 jumps cleared nothing: wait for the hazard"},
+		{"nothing to go on", 10, nil, runTelemetry{},
+			"C", "Jump when a hazard is a cell or two ahead"},
+		{"a few close calls", 250, nil, runTelemetry{jumps: 8, clears: 8, close: 1, useful: 8},
+			"S", "Good timing: now push for distance"},
+	} {
+		m := model{dist: tc.dist, mods: tc.mods, tele: tc.tele}
+		letter, marks := m.grade()
+		if letter != tc.letter {
+			t.Errorf("%s: grade %s (%d), want %s", tc.name, letter, marks, tc.letter)
+		}
+		if got := m.feedback(); got != tc.feedback {
+			t.Errorf("%s: feedback %q, want %q", tc.name, got, tc.feedback)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package gopherdash
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// PIXEL SPRITES (-renderer kitty|iterm2|sixel)
+// ----------------------------------------------------------------------------
+
+// Terminals with an inline-image protocol can draw the sprites as real
+// pixel art in place of the emoji. Each sprite covers the two columns of its
+// cell, so the layout, and everything laid out by width, stays as it is:
+//
+//   - kitty (and Ghostty) get every sprite once at startup, as an image
+//     with a virtual placement; a cell then draws it with two Unicode
+//     placeholder characters whose colour names the image. That's text as
+//     far as the screen is concerned, so it redraws, diffs and passes
+//     through tmux like any other.
+//   - iTerm2 (and WezTerm) take the image inline in every cell that shows
+//     it. The image moves the cursor on by the two columns but has no width
+//     as text, so it's drawn over two spaces it steps back over first.
+//
+// Glyphs without pixel art, the narrow and half-step views and the styled
+// dark of night and fog keep their text. Sixel terminals get the whole
+// playfield as one image instead (see sixel.go). -renderer auto picks from
+// the environment; the default is text.
+
+const (
+	rendererText  = "text"
+	rendererAuto  = "auto"
+	rendererKitty = "kitty"
+	rendererITerm = "iterm2"
+	rendererSixel = "sixel"
+
+	spriteScale = 2    // pixels per art pixel in the images sent
+	kittyChunk  = 4096 // most base64 a kitty graphics command carries
+)
+
+var rendererNames = []string{rendererText, rendererAuto, rendererKitty, rendererITerm, rendererSixel}
+
+// validRenderer checks name against the renderers
+func validRenderer(name string) error {
+	if name == "" || slices.Contains(rendererNames, name) {
+		return nil
+	}
+	return fmt.Errorf("unknown renderer %q (want %s)", name, strings.Join(rendererNames, ", "))
+}
+
+// detectRenderer picks the renderer the terminal can take, from what its
+// environment says it is; for anything else it's sixel, if the terminal
+// says it can when asked
+func detectRenderer(getenv func(string) string) string {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case term == "xterm-kitty" || term == "xterm-ghostty" || program == "ghostty":
+		return rendererKitty
+	case program == "iTerm.app" || program == "WezTerm" || getenv("LC_TERMINAL") == "iTerm2":
+		return rendererITerm
+	}
+	return rendererSixel
+}
+
+// spritePalette colours the art; '.' is transparent
+var spritePalette = map[byte]color.RGBA{
+	'c': {0x6a, 0xd7, 0xe5, 0xff}, // gopher blue
+	'w': {0xff, 0xff, 0xff, 0xff},
+	'k': {0x10, 0x10, 0x10, 0xff},
+	't': {0xf2, 0xd0, 0xa4, 0xff}, // muzzle and paws
+	'g': {0x8a, 0x8a, 0x8a, 0xff}, // stone
+	'l': {0xb8, 0xb8, 0xb8, 0xff},
+	'd': {0x5a, 0x5a, 0x5a, 0xff},
+	'b': {0x8b, 0x5a, 0x2b, 0xff}, // bark and earth
+	'B': {0x5e, 0x3a, 0x1a, 0xff},
+	'n': {0x6b, 0x42, 0x26, 0xff}, // acorn cap
+	'a': {0xc8, 0x8a, 0x3c, 0xff},
+	'o': {0xff, 0x66, 0x00, 0xff}, // fox
+	'y': {0xff, 0xd7, 0x00, 0xff},
+	'Y': {0xb8, 0x96, 0x00, 0xff},
+	'u': {0x1e, 0x90, 0xff, 0xff},
+	'U': {0xa0, 0xd0, 0xff, 0xff},
+	'r': {0xb2, 0x22, 0x22, 0xff}, // brick
+	'm': {0xd8, 0xc8, 0xb0, 0xff}, // mortar
+}
+
+// spriteArt is the pixel art for each glyph that has some, 8×8 art pixels
+// to a two-column cell
+var spriteArt = map[string][]string{
+	playerChar: {
+		".cc..cc.",
+		"cccccccc",
+		"cwkccwkc",
+		"ccctttcc",
+		"ccctwtcc",
+		"cccccccc",
+		".cccccc.",
+		".tt..tt.",
+	},
+	rockChar: {
+		"........",
+		"...ll...",
+		"..lggg..",
+		".lgggdg.",
+		".ggdgggd",
+		"gggggdgd",
+		"gdgggggd",
+		"dddddddd",
+	},
+	logChar: {
+		"........",
+		"........",
+		"bbbbbbbb",
+		"BbbBbbbB",
+		"bbbbBbbb",
+		"bbBbbbbb",
+		"BBBBBBBB",
+		"........",
+	},
+	acornChar: {
+		"...n....",
+		"..nnnn..",
+		".nnnnnn.",
+		".aaaaaa.",
+		".aaaaaa.",
+		"..aaaa..",
+		"...aa...",
+		"........",
+	},
+	foxChar: {
+		"o.....o.",
+		"oo...oo.",
+		"ooooooo.",
+		"okooook.",
+		"oooooooo",
+		".owwwwk.",
+		"..wwww..",
+		"...ww...",
+	},
+	groundChar: {
+		"bbbbbbbb",
+		"bBbbbbBb",
+		"bbbbbbbb",
+		"bbbBbbbb",
+		"bbbbbbbb",
+		"Bbbbbbbb",
+		"bbbbbBbb",
+		"bbbbbbbb",
+	},
+	wallChar: {
+		"rrrmrrrr",
+		"rrrmrrrr",
+		"mmmmmmmm",
+		"rmrrrrmr",
+		"rmrrrrmr",
+		"mmmmmmmm",
+		"rrrmrrrr",
+		"rrrmrrrr",
+	},
+	"🟨": {
+		"........",
+		"........",
+		"........",
+		"..yyyy..",
+		".Y....Y.",
+		"..yyyy..",
+		".Y....Y.",
+		"yyyyyyyy",
+	},
+	"🟦": {
+		"........",
+		"........",
+		"........",
+		"........",
+		".U..U...",
+		"..U..U..",
+		".U..U...",
+		"uuuuuuuu",
+	},
+}
+
+// spritePNG draws art as a PNG, spriteScale pixels to an art pixel
+func spritePNG(art []string) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, len(art[0])*spriteScale, len(art)*spriteScale))
+	for y, row := range art {
+		for x := range len(row) {
+			c, ok := spritePalette[row[x]]
+			if !ok {
+				continue // transparent
+			}
+			for dy := range spriteScale {
+				for dx := range spriteScale {
+					img.SetRGBA(x*spriteScale+dx, y*spriteScale+dy, c)
+				}
+			}
+		}
+	}
+	var b bytes.Buffer
+	_ = png.Encode(&b, img)
+	return b.Bytes()
+}
+
+// graphics draws the sprites with an image protocol
+type graphics struct {
+	setup string            // sent once before the first frame
+	cells map[string]string // glyph → what draws it instead
+	sixel *sixelTerm        // the playfield is one sixel image (see sixel.go)
+}
+
+// kitty placeholders: the character, and the diacritics numbering the
+// placement's rows and columns from 0
+const kittyPlaceholder = "\U0010EEEE"
+
+var kittyDiacritics = []string{"̅", "̍"}
+
+// newGraphics sets the renderer up, or returns nil for text
+func newGraphics(renderer string, getenv func(string) string) *graphics {
+	if renderer == rendererAuto {
+		renderer = detectRenderer(getenv)
+	}
+	if renderer == rendererSixel {
+		return &graphics{setup: sixelScrollOff + cellSizeAsk + sixelAsk, sixel: &sixelTerm{}}
+	}
+	glyphs := make([]string, 0, len(spriteArt))
+	for g := range spriteArt {
+		glyphs = append(glyphs, g)
+	}
+	slices.Sort(glyphs) // image ids stay put from run to run
+	g := &graphics{cells: map[string]string{}}
+	var setup strings.Builder
+	for i, glyph := range glyphs {
+		data := base64.StdEncoding.EncodeToString(spritePNG(spriteArt[glyph]))
+		switch renderer {
+		case rendererKitty:
+			id := i + 1
+			setup.WriteString(passThrough(kittyTransmit(id, data), getenv))
+			setup.WriteString(passThrough(fmt.Sprintf("\x1b_Ga=p,U=1,i=%d,c=2,r=1,q=2\x1b\\", id), getenv))
+			var cell strings.Builder
+			fmt.Fprintf(&cell, "\x1b[38;5;%dm", id)
+			for col := range 2 {
+				cell.WriteString(kittyPlaceholder + kittyDiacritics[0] + kittyDiacritics[col])
+			}
+			cell.WriteString("\x1b[39m")
+			g.cells[glyph] = cell.String()
+		case rendererITerm:
+			g.cells[glyph] = "  \x1b[2D" + fmt.Sprintf(
+				"\x1b]1337;File=inline=1;width=2;height=1;preserveAspectRatio=0:%s\x07", data)
+		default:
+			return nil
+		}
+	}
+	g.setup = setup.String()
+	return g
+}
+
+// kittyTransmit sends a PNG to kitty as image id, in chunks it can take
+func kittyTransmit(id int, data string) string {
+	var b strings.Builder
+	for first := true; first || data != ""; first = false {
+		chunk := data[:min(len(data), kittyChunk)]
+		data = data[len(chunk):]
+		more := 0
+		if data != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=t,f=100,t=d,i=%d,q=2,m=%d;%s\x1b\\", id, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// cell is what draws a playfield cell: its sprite if there's art for it
+func (g *graphics) cell(c string) string {
+	if g != nil {
+		if s, ok := g.cells[c]; ok {
+			return s
+		}
+	}
+	return c
+}
+
+// sendSprites hands the sprites, or sixel's questions, to the terminal
+// before the first frame
+func (g *graphics) sendSprites() tea.Cmd {
+	if g == nil || g.setup == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		_, _ = desktopOut.Write([]byte(g.setup))
+		return nil
+	}
+}
+
+// restore puts back what the setup changed, once the game's done
+func (g *graphics) restore() {
+	if g != nil && g.sixel != nil {
+		_, _ = desktopOut.Write([]byte(sixelScrollOn))
+	}
+}
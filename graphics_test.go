@@ -0,0 +1,99 @@
+package gopherdash
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestDetectRenderer(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, rendererKitty},
+		{map[string]string{"TERM_PROGRAM": "ghostty"}, rendererKitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, rendererITerm},
+		{map[string]string{"TERM_PROGRAM": "tmux", "LC_TERMINAL": "iTerm2"}, rendererITerm},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, rendererITerm},
+		{map[string]string{"TERM": "foot"}, rendererSixel}, // if it says so
+	} {
+		if got := detectRenderer(func(k string) string { return tc.env[k] }); got != tc.want {
+			t.Errorf("%v: %s, want %s", tc.env, got, tc.want)
+		}
+	}
+	if newGraphics(rendererText, func(string) string { return "" }) != nil {
+		t.Error("text drew sprites")
+	}
+}
+
+// TestSpriteCells keeps every sprite two columns wide, so the playfield's
+// layout is the same whatever draws it
+func TestSpriteCells(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	for _, renderer := range []string{rendererKitty, rendererITerm} {
+		g := newGraphics(renderer, noEnv)
+		if len(g.cells) != len(spriteArt) {
+			t.Fatalf("%s: %d sprites, want %d", renderer, len(g.cells), len(spriteArt))
+		}
+		for glyph, cell := range g.cells {
+			if w := lipgloss.Width(cell); w != 2 {
+				t.Errorf("%s: %s is %d columns wide, want 2", renderer, glyph, w)
+			}
+		}
+		if got := g.cell("  "); got != "  " {
+			t.Errorf("%s: a blank cell drew %q", renderer, got)
+		}
+	}
+	if got := (*graphics)(nil).cell(rockChar); got != rockChar {
+		t.Errorf("text drew a rock as %q", got)
+	}
+
+	// kitty gets every image and placement once, up front
+	g := newGraphics(rendererKitty, noEnv)
+	if n := strings.Count(g.setup, "a=p,"); n != len(spriteArt) {
+		t.Errorf("%d placements, want %d", n, len(spriteArt))
+	}
+	if g.setup != newGraphics(rendererKitty, noEnv).setup {
+		t.Error("image ids moved between runs")
+	}
+	tmux := newGraphics(rendererKitty, func(k string) string {
+		return map[string]string{"TMUX": "1"}[k]
+	})
+	if !strings.HasPrefix(tmux.setup, "\x1bPtmux;") {
+		t.Error("images not passed through tmux")
+	}
+}
+
+func TestSpritePNG(t *testing.T) {
+	for glyph, art := range spriteArt {
+		img, err := png.Decode(bytes.NewReader(spritePNG(art)))
+		if err != nil {
+			t.Fatalf("%s: %v", glyph, err)
+		}
+		if b := img.Bounds(); b.Dx() != 8*spriteScale || b.Dy() != 8*spriteScale {
+			t.Errorf("%s: %v, want %d square", glyph, b, 8*spriteScale)
+		}
+		for _, row := range art {
+			for _, c := range []byte(row) {
+				if _, ok := spritePalette[c]; !ok && c != '.' {
+					t.Errorf("%s: no colour for %q", glyph, c)
+				}
+			}
+		}
+	}
+}
+
+func TestKittyTransmitChunks(t *testing.T) {
+	data := strings.Repeat("A", kittyChunk*2+10)
+	got := kittyTransmit(3, data)
+	if n := strings.Count(got, "\x1b_G"); n != 3 {
+		t.Fatalf("%d chunks, want 3", n)
+	}
+	if !strings.Contains(got, "i=3,q=2,m=1;") || !strings.HasSuffix(got, "\x1b_Gm=0;"+strings.Repeat("A", 10)+"\x1b\\") {
+		t.Errorf("chunks badly marked: %.60q…", got)
+	}
+}
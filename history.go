@@ -0,0 +1,122 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// RUN HISTORY
+// ----------------------------------------------------------------------------
+
+const (
+	historyFile   = ".gopherdash_history"
+	maxHistory    = 100 // runs kept on disk
+	sparklineRuns = 20  // runs shown on the game-over sparkline
+)
+
+// runRecord is one finished run as stored in the history file
+type runRecord struct {
+	Distance int       `json:"distance"`
+	Jumps    int       `json:"jumps"`
+	Bonus    int       `json:"bonus,omitempty"` // speed-pad score on top of the distance
+	Cause    string    `json:"cause"`           // obstacle type that ended the run, or "quit"
+	Speed    float64   `json:"speed"`           // multiple of the starting speed at the end
+	Mods     []string  `json:"mods,omitempty"`  // the run's modifiers; empty for a plain run
+	At       time.Time `json:"at"`
+}
+
+func historyPath() string { return dataPath(historyFile) }
+
+func loadHistory(s Store) []runRecord { return s.readRuns() }
+
+func (f fileStore) readRuns() []runRecord {
+	data, err := os.ReadFile(f.path(historyFile))
+	if err != nil {
+		return nil
+	}
+	var runs []runRecord
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil
+	}
+	return runs
+}
+
+func (f fileStore) addRun(r runRecord) error {
+	data, err := json.Marshal(trimHistory(append(f.readRuns(), r)))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path(historyFile), data, 0o644)
+}
+
+// trimHistory keeps the latest maxHistory runs
+func trimHistory(runs []runRecord) []runRecord {
+	if len(runs) > maxHistory {
+		runs = runs[len(runs)-maxHistory:]
+	}
+	return runs
+}
+
+// appendRun adds r to the history and returns the history as stored
+func appendRun(s Store, r runRecord) []runRecord {
+	_ = s.addRun(r)
+	return loadHistory(s)
+}
+
+// recentDistances returns the distances of the last n runs, oldest first
+func recentDistances(runs []runRecord, n int) []int {
+	if len(runs) > n {
+		runs = runs[len(runs)-n:]
+	}
+	out := make([]int, len(runs))
+	for i, r := range runs {
+		out[i] = r.Distance
+	}
+	return out
+}
+
+// ----------------------------------------------------------------------------
+// SPARKLINE
+// ----------------------------------------------------------------------------
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders vals as a row of block glyphs scaled to the largest value
+func sparkline(vals []int) string {
+	if len(vals) == 0 {
+		return ""
+	}
+	hi := 0
+	for _, v := range vals {
+		hi = max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range vals {
+		i := 0
+		if hi > 0 {
+			i = v * (len(sparkBlocks) - 1) / hi
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// causeOfDeath describes how a run ended, e.g. "Tripped on a rock at 312"
+func causeOfDeath(cause string, dist int) string {
+	if k, ok := kindByName(cause); ok {
+		return k.Death(dist)
+	}
+	switch cause {
+	case "quit":
+		return fmt.Sprintf("Quit at %d", dist)
+	case wallCause:
+		return fmt.Sprintf("Ran into a ledge at %d", dist)
+	case foxCause:
+		return fmt.Sprintf("Caught by the fox at %d", dist)
+	}
+	return fmt.Sprintf("Stopped at %d", dist)
+}
@@ -0,0 +1,96 @@
+package gopherdash
+
+import "github.com/charmbracelet/lipgloss"
+
+// ----------------------------------------------------------------------------
+// HITBOX OVERLAY
+// ----------------------------------------------------------------------------
+
+// The overlay shows what the collision check in step sees: the gopher's
+// cell, every obstacle's cell, and the column where the two are compared.
+// Each gets a background colour. An obstacle in that column that the
+// gopher is meeting as step would judge it (fatal, or a ledge) turns
+// magenta, so a death can be read straight off the frame it happened on,
+// while a hole cleared in the air keeps its usual colour. The overlay is
+// on with -hitboxes, and X toggles it in practice runs.
+
+// hitMark is what a cell of the overlay shows
+type hitMark int
+
+const (
+	markZone   hitMark = iota // the checked column
+	markPlayer                // the gopher
+	markHazard                // ends the run if met
+	markTile                  // tiles and pickups
+	markMet                   // hitting the gopher right now
+)
+
+var hitStyles = map[hitMark]lipgloss.Style{
+	markZone:   lipgloss.NewStyle().Background(lipgloss.Color("17")),
+	markPlayer: lipgloss.NewStyle().Background(lipgloss.Color("178")),
+	markHazard: lipgloss.NewStyle().Background(lipgloss.Color("124")),
+	markTile:   lipgloss.NewStyle().Background(lipgloss.Color("28")),
+	markMet:    lipgloss.NewStyle().Background(lipgloss.Color("201")),
+}
+
+// hitCell is a playfield cell, column x of row y
+type hitCell struct{ x, y int }
+
+// toggleHitboxes flips the overlay, where it's allowed
+func (m *model) toggleHitboxes() {
+	if !m.cfg.Practice && !m.cfg.Hitboxes {
+		return
+	}
+	m.hitboxes = !m.hitboxes
+	if m.hitboxes {
+		m.notify("Hitboxes on")
+	} else {
+		m.notify("Hitboxes off")
+	}
+}
+
+// hitMarks marks the collision cells of a cols×rows playfield
+func (m model) hitMarks(cols, rows int) map[hitCell]hitMark {
+	marks := map[hitCell]hitMark{}
+	if rows == 0 || playerCol >= cols {
+		return marks
+	}
+	for y := range rows {
+		marks[hitCell{playerCol, y}] = markZone
+	}
+	if py := m.playerY; py >= 0 && py < rows {
+		marks[hitCell{playerCol, py}] = markPlayer
+	}
+	p := m.hitPlayer()
+	groundY := rows - 1
+	cam := m.camera()
+	for _, ob := range m.obstacles {
+		_, lift := m.sprite(ob.kind)
+		mark := markTile
+		switch h := ob.kind.Collides(p); {
+		case ob.extent().overlaps(m.body()) && (h == fatal || h == ledge):
+			mark = markMet
+		case hazardous(ob.kind):
+			mark = markHazard
+		}
+		for w := ob.x; w <= ob.extent().hi; w++ {
+			for r := range ob.kind.Height() {
+				x, y := cam.toScreen(w), groundY-m.terrainAt(w)-lift-r
+				if x >= 0 && x < cols && y >= 0 {
+					marks[hitCell{x, y}] = mark
+				}
+			}
+		}
+	}
+	return marks
+}
+
+// applyHitboxes colours the collision cells of the grid in place
+func (m model) applyHitboxes(rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	for c, mark := range m.hitMarks(len(rows[0]), len(rows)) {
+		rows[c.y][c.x] = hitStyles[mark].Render(rows[c.y][c.x])
+	}
+}
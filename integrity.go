@@ -0,0 +1,73 @@
+package gopherdash
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// SAVE INTEGRITY (HMAC-signed profiles)
+// ----------------------------------------------------------------------------
+
+// keyFile holds this install's signing key. It never leaves the machine:
+// doctor bundles leave it out, so a shared bundle can't be used to forge a
+// profile.
+const keyFile = ".gopherdash_key"
+
+func keyPath() string { return dataPath(keyFile) }
+
+// installKey reads the signing key, creating one if create is set and there
+// isn't one yet
+func installKey(create bool) []byte {
+	if data, err := os.ReadFile(keyPath()); err == nil {
+		if key, err := hex.DecodeString(strings.TrimSpace(string(data))); err == nil && len(key) >= 16 {
+			return key
+		}
+		return nil // a damaged key verifies nothing; don't replace it silently
+	}
+	if !create {
+		return nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil
+	}
+	if os.WriteFile(keyPath(), []byte(hex.EncodeToString(key)+"\n"), 0o600) != nil {
+		return nil
+	}
+	return key
+}
+
+// mac signs every field of p except the signature itself
+func (p profile) mac(key []byte) []byte {
+	p.MAC = ""
+	data, _ := json.Marshal(p) // struct field order makes this canonical
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// sign stamps p with this install's key, creating the key on first use
+func (p *profile) sign() {
+	p.MAC = ""
+	if key := installKey(true); key != nil {
+		p.MAC = hex.EncodeToString(p.mac(key))
+	}
+}
+
+// verified reports whether p carries an intact signature from this install.
+// Profiles from newer builds can't be checked, as they may have fields this
+// one doesn't know, and migrated files were never signed.
+func (p profile) verified() bool {
+	if p.MAC == "" || p.newer() {
+		return false
+	}
+	key := installKey(false)
+	sum, err := hex.DecodeString(p.MAC)
+	return key != nil && err == nil && hmac.Equal(sum, p.mac(key))
+}
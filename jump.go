@@ -0,0 +1,84 @@
+package gopherdash
+
+// ----------------------------------------------------------------------------
+// JUMP FORGIVENESS
+// ----------------------------------------------------------------------------
+
+// grounded reports whether the gopher is standing on the ground under it
+func (m model) grounded() bool { return m.playerY == m.groundRow() }
+
+func (m *model) jump() {
+	m.launch(m.character().JumpVel)
+	m.jumps++
+	m.tele.jumps++
+	m.jumpBuf = 0
+	if m.coyote > 0 {
+		m.coyoteSave()
+	}
+}
+
+// coyoteSave closes a coyote window the gopher jumped out of in time
+func (m *model) coyoteSave() {
+	m.coyote = 0
+	m.logDebug("coyote jump")
+	m.scoreStyle()
+	m.foxCloser("coyote")
+}
+
+// pressJump jumps straight away when possible; in the air the press is
+// buffered for a few ticks and fires on touchdown
+func (m *model) pressJump() {
+	m.logDebug("jump pressed", "grounded", m.grounded())
+	if m.clinging {
+		m.wallJump()
+		return
+	}
+	if m.grounded() {
+		m.jump()
+		return
+	}
+	if m.airJump() {
+		return
+	}
+	m.jumpBuf = m.cfg.JumpBuffer
+}
+
+// stepJumpAssist runs once per tick after physics: it fires buffered jumps
+// on landing and ends a coyote window, killing the run if it ran out with
+// the gopher still over the hole's edge
+func (m *model) stepJumpAssist() {
+	if m.grounded() {
+		m.airJumps = 0
+	}
+	if m.jumpBuf > 0 {
+		m.jumpBuf--
+		if m.grounded() {
+			m.logDebug("buffered jump fired")
+			m.jump()
+		}
+	}
+	if m.coyote > 0 {
+		if !m.grounded() {
+			m.coyoteSave() // launched clear some other way
+			return
+		}
+		if m.coyote--; m.coyote == 0 {
+			m.logInfo("coyote time ran out", "kind", m.ledge)
+			m.setGameOver(m.ledge)
+		}
+	}
+}
+
+// overLedge is called when the gopher runs onto a hazard such as a hole;
+// without coyote time the run ends at once, otherwise a jump is still
+// accepted for a few ticks
+func (m *model) overLedge(cause string) {
+	if m.cfg.Coyote <= 0 {
+		m.setGameOver(cause)
+		return
+	}
+	if m.coyote == 0 {
+		m.coyote = m.cfg.Coyote
+		m.ledge = cause
+	}
+}
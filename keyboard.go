@@ -0,0 +1,224 @@
+package gopherdash
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// KITTY KEYBOARD PROTOCOL
+// ----------------------------------------------------------------------------
+
+// Legacy terminal input is presses only: a held key is a stream of
+// repeats, and holding a second key stops the first one's. Terminals with
+// the kitty keyboard protocol (kitty, Ghostty, foot, WezTerm) can report
+// repeats and releases too, so the game asks for them when it starts and
+// keeps track of which keys are down. With that:
+//
+//   - a jump is as high as the jump key is held: letting go on the way up
+//     cuts it short (momentum physics), so a tap is a hop
+//   - the dive key dives every step it's down (see keyrepeat.go)
+//   - Space held on the game-over screen restarts once the hold's long
+//     enough, whatever else is pressed meanwhile
+//
+// Every key then comes as a CSI sequence, presses included, as only with
+// all of them escaped are the releases of keys that type text reported;
+// Bubble Tea passes those on unparsed, and the game makes the presses into
+// the key messages the legacy encoding would have. A terminal without the
+// protocol never answers the question, and everything stays as it was.
+
+const (
+	kittyEventsOn  = "\x1b[>26u" // push: every key escaped, with repeats, releases and the text typed
+	kittyEventsOff = "\x1b[<u"   // pop it again
+	kittyAsk       = "\x1b[?u"   // the flags in force; no answer, no protocol
+	kittyEvents    = 2           // the flag for repeats and releases
+
+	kittyPress   = 1
+	kittyRepeat  = 2
+	kittyRelease = 3
+
+	kittyShift = 1 // modifier bits, one less than the modifiers field
+	kittyAlt   = 2
+	kittyCtrl  = 4
+
+	kittyKP0      = 57399 // the number pad's 0; its other digits follow
+	kittyKPEnter  = 57414
+	kittyFunction = 57344 // from here on the codes are keys that type nothing
+)
+
+// kittyNames are the keys with names of their own, by code
+var kittyNames = map[int]string{
+	'\r': "enter", '\t': "tab", 0x1b: "esc", 0x7f: "backspace", ' ': " ",
+	kittyKPEnter: "enter",
+}
+
+// actCut is letting go of jump on the way up, on a replay's tape
+const actCut = "cut"
+
+// kittyKey reads a kitty keyboard protocol key event: a CSI u sequence
+// with the key's code, or an arrow's CSI 1;…A–D, either with the
+// modifiers and event type after it and, for a press that types
+// something, the text. It returns the key as Bubble Tea names it, shift
+// aside, and the text.
+func kittyKey(seq string) (key, text string, event int, ok bool) {
+	if !strings.HasPrefix(seq, "\x1b[") || len(seq) < 4 || strings.HasPrefix(seq, "\x1b[?") {
+		return "", "", 0, false
+	}
+	final := seq[len(seq)-1]
+	params := strings.Split(seq[2:len(seq)-1], ";")
+	event, mods := kittyPress, 0
+	if len(params) > 1 {
+		fields := strings.Split(params[1], ":")
+		n, err := strconv.Atoi(fields[0])
+		if err != nil && fields[0] != "" {
+			return "", "", 0, false
+		}
+		mods = max(n-1, 0)
+		if len(fields) > 1 {
+			if event, err = strconv.Atoi(fields[1]); err != nil {
+				return "", "", 0, false
+			}
+		}
+	}
+	if len(params) > 2 {
+		for _, cp := range strings.Split(params[2], ":") {
+			if r, err := strconv.Atoi(cp); err == nil {
+				text += string(rune(r))
+			}
+		}
+	}
+	code, err := strconv.Atoi(strings.Split(params[0], ":")[0])
+	if err != nil {
+		return "", "", 0, false
+	}
+	switch {
+	case final == 'u' && kittyNames[code] != "":
+		key = kittyNames[code]
+		if key == "tab" && mods&kittyShift != 0 {
+			key = "shift+tab"
+		}
+	case final == 'u' && code > ' ' && code < kittyFunction:
+		key = strings.ToLower(string(rune(code)))
+	case final == 'u' && code >= kittyKP0 && code <= kittyKP0+9:
+		key = string(rune('0' + code - kittyKP0))
+	case final >= 'A' && final <= 'D' && code == 1:
+		key = [...]string{"up", "down", "right", "left"}[final-'A']
+	default:
+		return "", "", 0, false
+	}
+	if mods&kittyCtrl != 0 {
+		key, text = "ctrl+"+key, ""
+	}
+	if mods&kittyAlt != 0 {
+		key, text = "alt+"+key, ""
+	}
+	return key, text, event, true
+}
+
+// kittyMsg is a press of key, typing text, as the key message a legacy
+// press would have made
+func kittyMsg(key, text string) tea.KeyMsg {
+	if rest, ok := strings.CutPrefix(key, "alt+"); ok {
+		msg := kittyMsg(rest, "")
+		msg.Alt = true
+		return msg
+	}
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok && len(rest) == 1 && rest[0] >= 'a' && rest[0] <= 'z' {
+		return tea.KeyMsg{Type: tea.KeyCtrlA + tea.KeyType(rest[0]-'a')}
+	}
+	switch key {
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "shift+tab":
+		return tea.KeyMsg{Type: tea.KeyShiftTab}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEscape}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "ctrl+up":
+		return tea.KeyMsg{Type: tea.KeyCtrlUp}
+	case "ctrl+down":
+		return tea.KeyMsg{Type: tea.KeyCtrlDown}
+	case "ctrl+right":
+		return tea.KeyMsg{Type: tea.KeyCtrlRight}
+	case "ctrl+left":
+		return tea.KeyMsg{Type: tea.KeyCtrlLeft}
+	}
+	if text != "" {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// keyboardReply takes the terminal's answer to kittyAsk and the key events
+// that come as CSI sequences. It reports whether reply was one of them,
+// and a press to handle like any other key, if it was one.
+func (m *model) keyboardReply(reply string) (handled bool, press tea.Msg) {
+	if strings.HasPrefix(reply, "\x1b[?") && strings.HasSuffix(reply, "u") {
+		flags, err := strconv.Atoi(reply[3 : len(reply)-1])
+		m.held.releases = err == nil && flags&kittyEvents != 0
+		m.logInfo("keyboard protocol", "flags", flags, "releases", m.held.releases)
+		return true, nil
+	}
+	key, text, event, ok := kittyKey(reply)
+	if !ok {
+		return false, nil
+	}
+	switch event {
+	case kittyPress:
+		return true, kittyMsg(key, text)
+	case kittyRelease:
+		m.releaseKey(key)
+	case kittyRepeat:
+		if m.palette != nil { // held keys repeat in the command line
+			return true, kittyMsg(key, text)
+		}
+	}
+	return true, nil // a repeat: what's down is already known
+}
+
+// releaseKey forgets key; letting go of jump while rising cuts the jump
+// short
+func (m *model) releaseKey(key string) {
+	if _, down := m.held.down[key]; !down {
+		return
+	}
+	delete(m.held.down, key)
+	if m.isJumpKey(key) {
+		m.holdStart = time.Time{} // a hold to restart is over
+		if !m.jumpHeld() && m.playback == nil && m.cutJump() {
+			m.record(actCut)
+		}
+	}
+}
+
+// askKeyboard turns the protocol's events on and asks whether they took
+func (m model) askKeyboard() tea.Cmd {
+	if !m.keyboard {
+		return nil
+	}
+	return func() tea.Msg {
+		_, _ = desktopOut.Write([]byte(kittyEventsOn + kittyAsk))
+		return nil
+	}
+}
+
+// releaseKeyboard puts the terminal's keyboard back, once the game's done
+func releaseKeyboard() {
+	_, _ = desktopOut.Write([]byte(kittyEventsOff))
+}
@@ -0,0 +1,140 @@
+package gopherdash
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKittyKey(t *testing.T) {
+	for _, c := range []struct {
+		seq   string
+		key   string
+		text  string
+		event int
+		ok    bool
+	}{
+		{"\x1b[115;1:3u", "s", "", kittyRelease, true},
+		{"\x1b[32;1:2u", " ", "", kittyRepeat, true},
+		{"\x1b[87;2u", "w", "", kittyPress, true},
+		{"\x1b[119u", "w", "", kittyPress, true},
+		{"\x1b[119;;119u", "w", "w", kittyPress, true},
+		{"\x1b[59;2;58u", ";", ":", kittyPress, true},
+		{"\x1b[13u", "enter", "", kittyPress, true},
+		{"\x1b[27;1:3u", "esc", "", kittyRelease, true},
+		{"\x1b[9;2u", "shift+tab", "", kittyPress, true},
+		{"\x1b[99;5u", "ctrl+c", "", kittyPress, true},
+		{"\x1b[120;3;120u", "alt+x", "", kittyPress, true},
+		{"\x1b[97;65;97u", "a", "a", kittyPress, true}, // caps lock on
+		{"\x1b[1;1:3B", "down", "", kittyRelease, true},
+		{"\x1b[57407;1:3u", "8", "", kittyRelease, true},
+		{"\x1b[57441;2u", "", "", 0, false}, // left shift on its own
+		{"\x1b[?1u", "", "", 0, false},
+		{"\x1b[6;20;10t", "", "", 0, false},
+		{"\x1b[x;1u", "", "", 0, false},
+	} {
+		key, text, event, ok := kittyKey(c.seq)
+		if key != c.key || text != c.text || event != c.event || ok != c.ok {
+			t.Errorf("%q: %q typing %q, event %d, %v", c.seq, key, text, event, ok)
+		}
+	}
+}
+
+func TestKittyPress(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	for seq, want := range map[string]string{
+		"\x1b[119;2u":     "w", // shift held with it
+		"\x1b[87;2;87u":   "W",
+		"\x1b[59;2;58u":   ":",
+		"\x1b[32;1:1u":    " ",
+		"\x1b[13u":        "enter",
+		"\x1b[27u":        "esc",
+		"\x1b[99;5u":      "ctrl+c",
+		"\x1b[120;3;120u": "alt+x",
+		"\x1b[1;1:1B":     "down",
+		"\x1b[1;5:1B":     "ctrl+down",
+		"\x1b[115;1:2u":   "", // a repeat
+	} {
+		ok, press := m.keyboardReply(seq)
+		if !ok {
+			t.Errorf("%q: not taken for a key", seq)
+			continue
+		}
+		got := ""
+		if press != nil {
+			got = press.(tea.KeyMsg).String()
+		}
+		if got != want {
+			t.Errorf("%q: pressed %q, want %q", seq, got, want)
+		}
+	}
+}
+
+func TestJumpCut(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Physics = 0, "momentum"
+	m, _ := clockedModel(t, cfg)
+	clearHazards(&m)
+	m.keyboardReply("\x1b[?2u")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	m = next.(model)
+	m.step(m.now())
+	rising := m.velFx
+	if rising >= 0 || !m.jumpHeld() {
+		t.Fatalf("not rising with Space down: velocity %d", rising)
+	}
+	m.keyboardReply("\x1b[32;1:3u")
+	if m.jumpHeld() || m.velFx != rising/2 {
+		t.Fatalf("released on the way up: velocity %d from %d", m.velFx, rising)
+	}
+	if in := m.tape.Inputs; in[len(in)-1].Act != actCut {
+		t.Errorf("the cut isn't on the tape: %v", in)
+	}
+	m.keyboardReply("\x1b[32;1:3u") // up already: nothing to cut
+	if m.velFx != rising/2 {
+		t.Error("a second release cut the jump again")
+	}
+}
+
+func TestReleaseRestart(t *testing.T) {
+	isolateSaves(t)
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, c := clockedModel(t, cfg)
+	m.keyboardReply("\x1b[?2u")
+	space := func() {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+		m = next.(model)
+	}
+	tick := func() {
+		next, _ := m.Update(tickMsg{m.tickGen, c.t})
+		m = next.(model)
+	}
+	space() // held through the crash and the cooldown: no restart
+	m.setGameOver("rock")
+	c.t = m.restartAt.Add(time.Duration(cfg.RestartHold) * time.Millisecond)
+	space()
+	tick()
+	if !m.gameOver {
+		t.Fatal("a key held since before the crash restarted the run")
+	}
+
+	m.keyboardReply("\x1b[32;1:3u")
+	space()
+	c.t = c.t.Add(time.Duration(cfg.RestartHold) * time.Millisecond / 2)
+	m.keyboardReply("\x1b[32;1:3u") // let go halfway
+	tick()
+	if !m.gameOver || !m.holdStart.IsZero() {
+		t.Fatal("a hold let go of early restarted the run")
+	}
+
+	space()
+	c.t = c.t.Add(time.Duration(cfg.RestartHold) * time.Millisecond)
+	tick() // no repeats come, the tick sees the hold through
+	if m.gameOver {
+		t.Error("a full hold didn't restart the run")
+	}
+}
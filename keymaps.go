@@ -0,0 +1,70 @@
+package gopherdash
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// CONTROL SCHEMES (-keys)
+// ----------------------------------------------------------------------------
+
+// The usual keys sit under the left hand. -keys picks another built-in
+// layout for the run: ijkl puts jump, dive and throw under the right hand
+// (I, K and L), numpad puts them on the number pad (8 or 0, 5 or 2, and 6,
+// or the arrows with Num Lock off), and one plays the whole game on Space,
+// which jumps when the gopher can and dives when it can't (under momentum
+// physics; there's no throwing on one key). Every layout keeps Space for
+// jump, and co-op keeps its own two-player keys. The tape records what the
+// gopher did rather than the keys, so replays play back under any layout.
+
+const (
+	keysDefault = "default"
+	keysIJKL    = "ijkl"
+	keysNumpad  = "numpad"
+	keysOne     = "one"
+)
+
+var keymaps = map[string]keymap{
+	keysDefault: soloKeys,
+	keysIJKL: {
+		jump: []string{" ", "i"}, dive: []string{"k"}, throw: []string{"l"},
+		bar:     "I/Space = jump   L = throw acorn   F = frame times   P = photo   Q = quit",
+		diveBar: "K = dive",
+	},
+	keysNumpad: {
+		jump: []string{" ", "8", "0", "up", "insert"}, dive: []string{"5", "2", "down"}, throw: []string{"6", "right"},
+		bar:     "8/0 = jump   6 = throw acorn   F = frame times   P = photo   Q = quit",
+		diveBar: "5/2 = dive",
+	},
+	keysOne: {
+		jump: []string{" "}, oneKey: true,
+		bar:     "Space = jump   F = frame times   P = photo   Q = quit",
+		diveBar: "Space in mid-air = dive",
+	},
+}
+
+// validKeys checks name is a control scheme; "" is the default
+func validKeys(name string) error {
+	if _, ok := keymaps[name]; ok || name == "" {
+		return nil
+	}
+	names := []string{keysDefault, keysIJKL, keysNumpad, keysOne}
+	return fmt.Errorf("unknown keys %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// bound reports whether key does something in the keymap
+func (k keymap) bound(key string) bool {
+	return slices.Contains(k.jump, key) || slices.Contains(k.dive, key) || slices.Contains(k.throw, key)
+}
+
+// oneKeyAct is what a press of the one key does now: a jump while there's
+// a jump to be had, otherwise a dive
+func (m model) oneKeyAct() string {
+	if m.momentum() && !m.clinging && !m.grounded() &&
+		m.airJumps >= m.character().AirJumps+m.perk(perkJump) {
+		return actDive
+	}
+	return actJump
+}
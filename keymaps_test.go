@@ -0,0 +1,63 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeymaps(t *testing.T) {
+	for _, tc := range []struct {
+		keys                    string
+		jump, dive, throw, dead string
+	}{
+		{keysDefault, "w", "s", "d", "i"},
+		{keysIJKL, "i", "k", "l", "d"},
+		{keysNumpad, "8", "5", "6", "w"},
+	} {
+		cfg := defaultConfig()
+		cfg.Countdown, cfg.Keys = 0, tc.keys
+		m, _ := clockedModel(t, cfg)
+		if !m.isJumpKey(tc.jump) || !m.isJumpKey(" ") || !m.isDiveKey(tc.dive) || !m.isThrowKey(tc.throw) {
+			t.Errorf("%s: %q doesn't jump, %q dive or %q throw", tc.keys, tc.jump, tc.dive, tc.throw)
+		}
+		if m.keys().bound(tc.dead) {
+			t.Errorf("%s: %q does something", tc.keys, tc.dead)
+		}
+		if !strings.Contains(m.View(), m.keys().bar) {
+			t.Errorf("%s: the controls bar isn't %q", tc.keys, m.keys().bar)
+		}
+	}
+	if err := validKeys("dvorak"); err == nil {
+		t.Error("took an unknown scheme")
+	}
+}
+
+// On one key, Space jumps from the ground and dives when there's no jump
+// left, and the tape has which it was.
+func TestOneKey(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Keys, cfg.Physics = 0, keysOne, physicsMomentum
+	m, c := clockedModel(t, cfg)
+	clearHazards(&m)
+	press := func() string {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+		m = next.(model)
+		return m.tape.Inputs[len(m.tape.Inputs)-1].Act
+	}
+	if act := press(); act != actJump || m.jumps != 1 {
+		t.Fatalf("on the ground Space taped %q, %d jumps", act, m.jumps)
+	}
+	c.t = c.t.Add(time.Second) // past the debounce
+	m.step(m.now())
+	if act := press(); act != actDive || m.seats.dives != 1 {
+		t.Errorf("in mid-air Space taped %q, %d dives", act, m.seats.dives)
+	}
+
+	m.mods = []string{modMirror}
+	if !m.isJumpKey(" ") {
+		t.Error("inverted controls took the one key's jump away")
+	}
+}
@@ -0,0 +1,71 @@
+package gopherdash
+
+import "time"
+
+// ----------------------------------------------------------------------------
+// KEY REPEAT AND HELD KEYS
+// ----------------------------------------------------------------------------
+
+// Holding a key down makes the OS send it again and again. For jump that
+// would be a second jump the moment the first lands, so during a run a
+// jump key arriving within -debounce of the last one (repeats included)
+// is taken for a repeat and dropped; a held Space still restarts on the
+// game-over screen, which counts on the repeats (see restart.go).
+//
+// Terminals with the kitty keyboard protocol can say which presses are
+// repeats and when a key comes up (see keyboard.go). There every repeat is
+// dropped, and the dive key keeps diving for as long as it's down.
+// Elsewhere a held dive key dives on each repeat, as it always has.
+
+// heldKeys is what's known about the keys that are down
+type heldKeys struct {
+	releases bool                 // the terminal reports repeats and releases
+	jumpAt   time.Time            // the latest jump key, repeats included
+	down     map[string]time.Time // with releases: the keys down, since when
+}
+
+// pressKey notes key going down at now; with releases reported, a press
+// of a key that's already down is a repeat
+func (m *model) pressKey(key string, now time.Time) (repeat bool) {
+	if !m.held.releases {
+		return false
+	}
+	if _, down := m.held.down[key]; down {
+		return true
+	}
+	if m.held.down == nil {
+		m.held.down = map[string]time.Time{}
+	}
+	m.held.down[key] = now
+	return false
+}
+
+// jumpHeld and diveHeld report whether a key for the action is down
+func (m model) jumpHeld() bool { return m.anyHeld(m.isJumpKey) }
+func (m model) diveHeld() bool { return m.anyHeld(m.isDiveKey) }
+
+func (m model) anyHeld(is func(string) bool) bool {
+	for key := range m.held.down {
+		if is(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// jumpRepeat records a jump key at now and reports whether it came too
+// soon after the last to be a fresh press
+func (m *model) jumpRepeat(now time.Time) bool {
+	last := m.held.jumpAt
+	m.held.jumpAt = now
+	window := time.Duration(m.cfg.Debounce) * time.Millisecond
+	return !last.IsZero() && now.Sub(last) < window
+}
+
+// holdDive keeps diving every step the dive key stays down
+func (m *model) holdDive() {
+	if m.diveHeld() && m.momentum() && !m.grounded() && m.playback == nil {
+		m.record(actDive)
+		m.dive()
+	}
+}
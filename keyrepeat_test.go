@@ -0,0 +1,69 @@
+package gopherdash
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestJumpDebounce(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, c := clockedModel(t, cfg)
+	clearHazards(&m)
+	space := tea.KeyMsg{Type: tea.KeySpace}
+	press := func() {
+		next, _ := m.Update(space)
+		m = next.(model)
+	}
+	press()
+	if m.jumps != 1 {
+		t.Fatalf("%d jumps from one press", m.jumps)
+	}
+	c.t = c.t.Add(30 * time.Millisecond)
+	press() // the OS repeating it
+	if m.jumps != 1 || m.jumpBuf != 0 {
+		t.Fatal("a key repeat was taken for a jump")
+	}
+	c.t = c.t.Add(30 * time.Millisecond)
+	press() // still repeating: the window runs from the latest
+	c.t = c.t.Add(time.Duration(cfg.Debounce) * time.Millisecond)
+	press()
+	if m.jumps != 2 {
+		t.Errorf("a fresh press after the window made %d jumps in all", m.jumps)
+	}
+}
+
+func TestHoldDive(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Physics = 0, "momentum"
+	m, _ := clockedModel(t, cfg)
+	clearHazards(&m)
+	m.keyboardReply("\x1b[?2u")
+	if !m.held.releases {
+		t.Fatal("the terminal's flags didn't turn releases on")
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	m = next.(model)
+	m.step(m.now())
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = next.(model)
+	if !m.diveHeld() {
+		t.Fatal("the dive key isn't down")
+	}
+	dives := len(m.tape.Inputs)
+	m.holdDive()
+	m.holdDive()
+	if got := len(m.tape.Inputs) - dives; got != 2 {
+		t.Errorf("%d dives taped over two held steps", got)
+	}
+	m.keyboardReply("\x1b[115;1:3u")
+	if m.diveHeld() {
+		t.Fatal("released, but the dive key is still down")
+	}
+	m.holdDive()
+	if len(m.tape.Inputs)-dives != 2 {
+		t.Error("still diving after the release")
+	}
+}
@@ -0,0 +1,226 @@
+package gopherdash
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// INPUT LATENCY (`gopherdash latency`)
+// ----------------------------------------------------------------------------
+
+// `gopherdash latency` flashes a light on a steady beat and has the player
+// tap Space along with it. Tapping to a beat takes reaction time out of
+// it: once the rhythm's found, taps land on the flash as the player sees
+// it, so how late they reach the game is the round trip through the
+// terminal, the flash out to the screen and the key back in. The first
+// few beats are for finding the rhythm; the median of the rest is the
+// calibration, kept in the profile. -latency-comp then draws the course
+// that many steps ahead of the gopher, so a jump timed to the screen
+// lands on time when it arrives.
+
+const (
+	latencyBeat   = 750 * time.Millisecond // between flashes
+	latencyFlash  = 150 * time.Millisecond // a flash stays up this long
+	latencyWarmup = 4                      // beats to find the rhythm, not measured
+	latencyLead   = 4                      // most steps the course is drawn ahead
+)
+
+// latencyTick is beat n's flash
+type latencyTick struct{ n int }
+
+// latencyModel is the calibration screen
+type latencyModel struct {
+	beats int       // beats measured after the warm-up
+	start time.Time // beat 0
+	beat  int       // the latest flash
+	taps  []time.Duration
+	done  bool // all the beats are in
+	w, h  int
+}
+
+func (l latencyModel) Init() tea.Cmd { return l.tickAt(1) }
+
+// tickAt waits for beat n
+func (l latencyModel) tickAt(n int) tea.Cmd {
+	return tea.Tick(time.Until(l.start.Add(time.Duration(n)*latencyBeat)),
+		func(time.Time) tea.Msg { return latencyTick{n} })
+}
+
+func (l latencyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		l.w, l.h = msg.Width, msg.Height
+	case latencyTick:
+		l.beat = msg.n
+		if l.beat > latencyWarmup+l.beats {
+			l.done = true
+			return l, tea.Quit
+		}
+		return l, l.tickAt(l.beat + 1)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return l, tea.Quit
+		}
+		if n, off := nearestBeat(l.start, time.Now()); n > latencyWarmup && n <= latencyWarmup+l.beats {
+			l.taps = append(l.taps, off)
+		}
+	}
+	return l, nil
+}
+
+func (l latencyModel) View() string {
+	light := "○"
+	if l.beat > 0 && time.Since(l.start.Add(time.Duration(l.beat)*latencyBeat)) < latencyFlash {
+		light = bannerStyle.Render("●")
+	}
+	status := fmt.Sprintf("Finding the beat… %d/%d", min(l.beat, latencyWarmup), latencyWarmup)
+	if l.beat > latencyWarmup {
+		status = fmt.Sprintf("Measuring: beat %d of %d, %d taps", l.beat-latencyWarmup, l.beats, len(l.taps))
+	}
+	text := strings.Join([]string{
+		"Tap Space in time with the light",
+		"",
+		light,
+		"",
+		status,
+		"Q = give up",
+	}, "\n")
+	return lipgloss.Place(l.w, l.h, lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().Align(lipgloss.Center).Render(text))
+}
+
+// nearestBeat is the beat closest to t and how far after it t came
+func nearestBeat(start, t time.Time) (int, time.Duration) {
+	since := t.Sub(start)
+	n := int((since + latencyBeat/2) / latencyBeat)
+	return n, since - time.Duration(n)*latencyBeat
+}
+
+// calibrate is the median tap offset and the median distance from it; nil
+// taps give zeros
+func calibrate(taps []time.Duration) (median, spread time.Duration) {
+	mid := func(ds []time.Duration) time.Duration {
+		if len(ds) == 0 {
+			return 0
+		}
+		s := slices.Sorted(slices.Values(ds))
+		if n := len(s); n%2 == 0 {
+			return (s[n/2-1] + s[n/2]) / 2
+		}
+		return s[len(s)/2]
+	}
+	median = mid(taps)
+	devs := make([]time.Duration, len(taps))
+	for i, d := range taps {
+		devs[i] = max(d-median, median-d)
+	}
+	return median, mid(devs)
+}
+
+// latencyAdvice is what to play with at a latency of ms
+func latencyAdvice(ms int) []string {
+	switch {
+	case ms < 40:
+		return []string{"Your terminal keeps up: no compensation needed, play at any speed."}
+	case ms < 100:
+		return []string{
+			"Play with -latency-comp to draw the course a step ahead.",
+			"Otherwise the defaults suit you.",
+		}
+	case ms < 200:
+		return []string{
+			"Play with -latency-comp, and -jump-buffer 5 to remember early presses for longer.",
+			"Over SSH, -render-fps 30 sends fewer frames to fall behind.",
+		}
+	}
+	return []string{
+		"That's a slow link: play with -latency-comp -jump-buffer 5 -render-fps 30,",
+		"and -class heavy, whose slower speed-up leaves more time to react.",
+	}
+}
+
+// latencyMain runs `gopherdash latency [-beats N]`
+func latencyMain(args []string) int {
+	cfg := loadConfig()
+	fs := flag.NewFlagSet("gopherdash latency", flag.ContinueOnError)
+	beats := fs.Int("beats", 16, "beats to measure, after "+fmt.Sprint(latencyWarmup)+" to find the rhythm")
+	fs.StringVar(&cfg.Store, "store", cfg.Store, "store the profile is in: file, sqlite or memory")
+	fs.StringVar(&cfg.DB, "db", cfg.DB, "database file for -store sqlite")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *beats < 1 {
+		fmt.Fprintln(fs.Output(), "latency: -beats must be positive")
+		return exitUsage
+	}
+	saves, err := openStore(cfg)
+	if err != nil {
+		return exitCode(err)
+	}
+	l := latencyModel{beats: *beats, start: time.Now().Add(latencyBeat)}
+	final, err := tea.NewProgram(l, tea.WithAltScreen()).Run()
+	if err != nil {
+		return exitCode(err)
+	}
+	l = final.(latencyModel)
+	if !l.done {
+		fmt.Println("Calibration abandoned; nothing saved.")
+		return 0
+	}
+	if len(l.taps) < (*beats+1)/2 {
+		fmt.Printf("Only %d taps of %d beats: not enough to go on, nothing saved.\n", len(l.taps), *beats)
+		return 0
+	}
+	median, spread := calibrate(l.taps)
+	ms := max(int(median.Milliseconds()), 0)
+	fmt.Printf("Latency: %d ms (±%d ms over %d taps)\n", ms, spread.Milliseconds(), len(l.taps))
+	for _, line := range latencyAdvice(ms) {
+		fmt.Println(line)
+	}
+	if err := saveLatency(saves, cfg, ms); err != nil {
+		return exitCode(err)
+	}
+	fmt.Println("Saved to your profile.")
+	return 0
+}
+
+// saveLatency keeps the calibration in the profile in saves, re-signing it
+// if it was signed
+func saveLatency(saves Store, cfg config, ms int) error {
+	var err error
+	p := loadProfile(saves) // migrated first, which takes the lock itself
+	saves.lock(func() {
+		if disk, ok := saves.readProfile(); ok {
+			p = disk
+		}
+		signed := p.verified()
+		p.LatencyMS, p.MAC = ms, ""
+		if cfg.SignSaves && signed {
+			p.sign()
+		}
+		err = saveProfile(saves, p)
+	})
+	return err
+}
+
+// lead is how many steps ahead -latency-comp draws the course. The
+// hitbox overlay shows where things really are, so it goes without.
+func (m model) lead() int {
+	if !m.cfg.LatencyComp || m.profile.LatencyMS <= 0 || m.hitboxes || m.ghost || m.saver || m.playback != nil {
+		return 0
+	}
+	d := m.tickDur()
+	if d <= 0 {
+		return 0
+	}
+	lag := time.Duration(m.profile.LatencyMS) * time.Millisecond
+	return min(int((lag+d/2)/d), latencyLead)
+}
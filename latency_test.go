@@ -0,0 +1,85 @@
+package gopherdash
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalibrate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		at   time.Duration
+		n    int
+		late time.Duration
+	}{
+		{3*latencyBeat + 80*time.Millisecond, 3, 80 * time.Millisecond},
+		{5*latencyBeat - 30*time.Millisecond, 5, -30 * time.Millisecond},
+	} {
+		n, off := nearestBeat(start, start.Add(c.at))
+		if n != c.n || off != c.late {
+			t.Errorf("tap at %v: beat %d, %v off; want %d, %v", c.at, n, off, c.n, c.late)
+		}
+	}
+
+	ms := func(v ...int) []time.Duration {
+		ds := make([]time.Duration, len(v))
+		for i, x := range v {
+			ds[i] = time.Duration(x) * time.Millisecond
+		}
+		return ds
+	}
+	median, spread := calibrate(ms(60, 90, 70, 400, 80))
+	if median != 80*time.Millisecond || spread != 10*time.Millisecond {
+		t.Errorf("median %v ± %v, want 80ms ± 10ms: one wild tap shouldn't count", median, spread)
+	}
+	if median, _ := calibrate(ms(60, 70)); median != 65*time.Millisecond {
+		t.Errorf("median of two %v", median)
+	}
+	if median, spread := calibrate(nil); median != 0 || spread != 0 {
+		t.Error("no taps, but a calibration")
+	}
+}
+
+func TestLatencyLead(t *testing.T) {
+	m, _ := clockedModel(t, defaultConfig())
+	m.profile.LatencyMS = 120
+	if m.lead() != 0 {
+		t.Fatal("the course drawn ahead without -latency-comp")
+	}
+	m.cfg.LatencyComp = true
+	m.frameDur = 60 * time.Millisecond
+	if got := m.lead(); got != 2 {
+		t.Errorf("120ms at 60ms a step leads by %d", got)
+	}
+	m.frameDur = time.Millisecond
+	if got := m.lead(); got != latencyLead {
+		t.Errorf("lead %d past the cap", got)
+	}
+	m.hitboxes = true
+	if m.lead() != 0 {
+		t.Error("the hitbox overlay drawn ahead")
+	}
+	m.hitboxes = false
+
+	m.frameDur = 60 * time.Millisecond
+	clearHazards(&m)
+	x := m.dist + playerCol + 6
+	m.obstacles = append(m.obstacles, obstacle{x, rock{}})
+	rows := m.gameCells(false)
+	if got := rows[m.gameRows-2][playerCol+4]; got != rockChar {
+		t.Errorf("rock six cells out drawn at %q four out", got)
+	}
+}
+
+func TestSaveLatency(t *testing.T) {
+	saves := isolateSaves(t)
+	if err := saveLatency(saves, defaultConfig(), 85); err != nil {
+		t.Fatal(err)
+	}
+	if p := loadProfile(saves); p.LatencyMS != 85 || p.Version != profileVersion {
+		t.Errorf("profile after calibrating: %+v", p)
+	}
+	if advice := latencyAdvice(85); len(advice) == 0 || advice[0] == latencyAdvice(10)[0] {
+		t.Errorf("85ms gets the advice for a fast terminal: %q", advice)
+	}
+}
@@ -0,0 +1,51 @@
+package gopherdash
+
+import "github.com/charmbracelet/lipgloss"
+
+// ----------------------------------------------------------------------------
+// LETTERBOX (capped playfield)
+// ----------------------------------------------------------------------------
+
+// A playfield stretched across an ultra-wide terminal shows obstacles far
+// sooner than a small one, so the same seed plays easier there. max_cols and
+// max_rows cap the playfield; the game is laid out at the capped size and
+// centred, with a hatched border filling the rest of the window.
+
+const (
+	letterboxChar   = "╱"
+	letterboxColour = lipgloss.Color("237")
+	minMaxCols      = 20 // smallest cap on playfield cells across
+	minMaxRows      = 5  // smallest cap on playfield rows
+)
+
+// capPlayfield applies the configured caps to the playfield and sizes the
+// frame the game is drawn in to match; panes is how many playfields are
+// stacked, two in a lockstep race
+func (m *model) capPlayfield(panes int) {
+	m.fw, m.fh = m.w, m.h
+	if c := m.cfg.MaxCols; c > 0 && m.gameCols > c {
+		m.gameCols = c
+		m.fw = 2*c + 2
+		if m.cfg.Minimal {
+			m.fw = 2 * c // no side borders
+		}
+	}
+	if r := m.cfg.MaxRows; r > 0 && m.gameRows > r {
+		m.fh -= (m.gameRows - r) * panes
+		m.gameRows = r
+	}
+}
+
+// letterboxed reports whether the frame is smaller than the window
+func (m model) letterboxed() bool {
+	return m.fw > 0 && m.fh > 0 && (m.fw < m.w || m.fh < m.h)
+}
+
+// letterbox draws the game at its frame size, centred in the window
+func (m model) letterbox() string {
+	inner := m
+	inner.w, inner.h = m.fw, m.fh
+	return lipgloss.Place(m.w, m.h, lipgloss.Center, lipgloss.Center, inner.view(),
+		lipgloss.WithWhitespaceChars(letterboxChar),
+		lipgloss.WithWhitespaceForeground(letterboxColour))
+}
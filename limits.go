@@ -0,0 +1,89 @@
+package gopherdash
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// SERVER LIMITS (-max-sessions, -max-per-ip, -idle-kick)
+// ----------------------------------------------------------------------------
+
+// A public server hosting a game per connection can be run out of memory
+// and CPU by anyone opening enough of them. With -max-sessions, Join turns
+// a session away while that many are already playing; with -max-per-ip it
+// does so too while that many come from the session's address, given with
+// WithRemote. The host says so to the player and closes the connection.
+// -idle-kick ends a hosted session nobody has pressed a key in for that
+// long, on the game-over screen or paused as much as mid-run, by sending
+// the host a DoneMsg with Idle set.
+
+// ErrServerFull is Join turning a session away under -max-sessions
+var ErrServerFull = errors.New("gopherdash: the server is full, try again later")
+
+// ErrTooManyFromAddr is Join turning a session away under -max-per-ip
+var ErrTooManyFromAddr = errors.New("gopherdash: too many sessions from your address")
+
+// sessionGate counts the sessions let in, in all and by address; like
+// records it's shared by every session in the process
+type sessionGate struct {
+	mu     sync.Mutex
+	open   int
+	byAddr map[string]int
+}
+
+var gate = &sessionGate{byAddr: map[string]int{}}
+
+// enter lets a session from addr in, unless cfg's caps are reached
+func (g *sessionGate) enter(addr string, cfg config) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case cfg.MaxSessions > 0 && g.open >= cfg.MaxSessions:
+		return ErrServerFull
+	case cfg.MaxPerIP > 0 && addr != "" && g.byAddr[addr] >= cfg.MaxPerIP:
+		return ErrTooManyFromAddr
+	}
+	g.open++
+	if addr != "" {
+		g.byAddr[addr]++
+	}
+	return nil
+}
+
+// exit lets the session from addr go
+func (g *sessionGate) exit(addr string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.open--
+	if addr == "" {
+		return
+	}
+	if g.byAddr[addr]--; g.byAddr[addr] <= 0 {
+		delete(g.byAddr, addr)
+	}
+}
+
+// remoteHost is the host part of a remote address, so every connection
+// from one machine counts together whatever its port
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// idleKicked reports whether a hosted session has gone without input for
+// longer than -idle-kick
+func (m model) idleKicked() bool {
+	if !m.embedded || m.cfg.IdleKick <= 0 {
+		return false
+	}
+	since := m.lastInput
+	if m.started.After(since) {
+		since = m.started
+	}
+	return m.now().Sub(since) > time.Duration(m.cfg.IdleKick)*time.Second
+}
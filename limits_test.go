@@ -0,0 +1,82 @@
+package gopherdash
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestSessionCaps turns sessions away past -max-sessions and -max-per-ip,
+// and lets them in again once others leave
+func TestSessionCaps(t *testing.T) {
+	isolateSaves(t)
+	gate = &sessionGate{byAddr: map[string]int{}}
+	records = &recordBus{subs: map[int]func(tea.Msg){}}
+	join := func(addr string) (func(), error) {
+		g, err := New(WithRemote(addr), WithArgs("-store", "memory", "-max-sessions", "3", "-max-per-ip", "2"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, leave, err := g.Join(func(tea.Msg) {})
+		return leave, err
+	}
+	first, _ := join("203.0.113.7:50123")
+	if _, err := join("203.0.113.7:50124"); err != nil {
+		t.Fatalf("a second session from one address: %v", err)
+	}
+	if _, err := join("203.0.113.7:50125"); !errors.Is(err, ErrTooManyFromAddr) {
+		t.Errorf("a third session from one address: %v", err)
+	}
+	last, err := join("198.51.100.2:40000")
+	if err != nil {
+		t.Fatalf("a session from elsewhere: %v", err)
+	}
+	if _, err := join("192.0.2.9:40000"); !errors.Is(err, ErrServerFull) {
+		t.Errorf("a fourth session: %v", err)
+	}
+	first()
+	if _, err := join("203.0.113.7:50126"); err != nil {
+		t.Errorf("after one left, its address was still turned away: %v", err)
+	}
+	last()
+	if gate.open != 2 || gate.byAddr["198.51.100.2"] != 0 {
+		t.Errorf("gate counts %d open, %v", gate.open, gate.byAddr)
+	}
+}
+
+// TestIdleKick ends a hosted session left alone past -idle-kick, on the
+// game-over screen as in a run
+func TestIdleKick(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.IdleKick = 0, 60
+	m, c := clockedModel(t, cfg)
+	clearHazards(&m)
+	m.embedded = true
+	tick := func() tea.Cmd {
+		next, cmd := m.Update(tickMsg{m.tickGen, c.t})
+		m = next.(model)
+		return cmd
+	}
+	c.t = c.t.Add(59 * time.Second)
+	if m.idleKicked() {
+		t.Fatal("kicked before -idle-kick")
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m = next.(model)
+	m.setGameOver("rock")
+	c.t = c.t.Add(59 * time.Second)
+	tick()
+	if m.idleKicked() {
+		t.Fatal("a key didn't count as input")
+	}
+	c.t = c.t.Add(2 * time.Second)
+	cmd := tick()
+	if cmd == nil {
+		t.Fatal("nothing sent on the kick")
+	}
+	if done, ok := cmd().(DoneMsg); !ok || !done.Idle {
+		t.Errorf("kicked with %#v, want an idle DoneMsg", done)
+	}
+}
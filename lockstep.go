@@ -0,0 +1,131 @@
+package gopherdash
+
+import "time"
+
+// ----------------------------------------------------------------------------
+// LOCKSTEP RACE
+// ----------------------------------------------------------------------------
+
+// In a lockstep race both players send their input for every step instead of
+// their distance. A press is scheduled lockDelay steps ahead, so by the time
+// a step is due the opponent's input for it is usually already here; the
+// course is seeded and the engine is deterministic, so replaying those
+// inputs on a local copy of their run reproduces it exactly. Neither side
+// may step further ahead than it has inputs for. Inputs are keyed by step
+// rather than distance: clinging to a wall takes steps without running.
+
+const (
+	lockDelay     = 3 // steps between a press and the step it applies to
+	lockWait      = 5 * time.Millisecond
+	lockStallShow = 300 * time.Millisecond // before "Waiting for opponent…" shows
+)
+
+// raceInputMsg is the opponent's input for one step
+type raceInputMsg struct {
+	tick int
+	jump bool
+}
+
+// startLockstep sets up the simulated opponent on the same course and rules
+func (m *model) startLockstep() {
+	cfg := m.cfg
+	cfg.Practice, cfg.Timer, cfg.Twitch = false, false, ""
+	cfg.Seed, cfg.Daily = 0, false // keeps the heatmap strip off its pane
+	cfg.Mods, cfg.Weekly = m.mods, false
+	opp := &model{cfg: cfg, clock: m.clock, frameDur: startFrame, ghost: true, event: m.event}
+	opp.reseed(m.seed)
+	m.race.lockstep = true
+	m.race.opp = opp
+	m.race.mine = map[int]bool{}
+	m.race.theirs = map[int]bool{}
+}
+
+// resizeOpp gives the opponent's playfield our grid size; a run depends on
+// the seed and inputs only, so the size doesn't change how it plays out
+func (m *model) resizeOpp() {
+	if m.race.opp == nil {
+		return
+	}
+	m.sizeGhost(m.race.opp)
+	m.advanceOpp() // the first size lets the opening steps run
+}
+
+// sizeGhost gives a simulated run in the second pane our grid size
+func (m *model) sizeGhost(o *model) {
+	air := 0
+	if o.gameRows > 0 {
+		air = o.gameRows - 2 - o.playerY
+	}
+	o.w, o.h = m.w, m.h
+	o.gameRows, o.gameCols = m.gameRows, m.gameCols
+	o.playerY = o.gameRows - 2 - air
+	o.fillObstacles()
+}
+
+// lockInput applies our input for the coming step and sends the press made
+// since the last one, scheduled lockDelay steps ahead
+func (m *model) lockInput() {
+	r := &m.race
+	if !r.lockstep {
+		return
+	}
+	t := m.steps + 1
+	if r.mine[t] {
+		m.pressJump()
+	}
+	delete(r.mine, t)
+	at := t + lockDelay
+	r.mine[at] = r.pending
+	if !r.oppGone {
+		r.link.queue(raceWire{Type: "input", Tick: at, Jump: r.pending})
+	}
+	r.pending = false
+}
+
+// lockReady reports whether our next step may run: the opponent's run has
+// to have reached it, unless that run is over or the connection is gone
+func (m *model) lockReady() bool {
+	r := &m.race
+	if r.oppGone || r.opp.gameOver || r.opp.steps > m.steps {
+		r.waitSince = time.Time{}
+		return true
+	}
+	if r.waitSince.IsZero() {
+		r.waitSince = m.now()
+	}
+	return false
+}
+
+// lockStalled reports whether we have been waiting long enough to say so
+func (m model) lockStalled() bool {
+	return m.race.lockstep && !m.race.waitSince.IsZero() &&
+		m.now().Sub(m.race.waitSince) > lockStallShow
+}
+
+// advanceOpp steps the simulated opponent through every input received;
+// the first lockDelay steps have no input by construction
+func (m *model) advanceOpp() {
+	r := &m.race
+	o := r.opp
+	if o == nil || o.gameRows == 0 {
+		return
+	}
+	for !o.gameOver {
+		t := o.steps + 1
+		jump, ok := r.theirs[t]
+		if !ok && t > lockDelay {
+			break
+		}
+		delete(r.theirs, t)
+		if jump {
+			o.pressJump()
+		}
+		o.step(m.now())
+	}
+	r.oppDist, r.oppAlive = o.dist, !o.gameOver
+}
+
+// oppPane renders the opponent's playfield in its own box
+func (m model) oppPane() string {
+	return m.pane(m.race.opp.renderGame())
+}
@@ -0,0 +1,60 @@
+package gopherdash
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// DEBUG LOG
+// ----------------------------------------------------------------------------
+
+// logger is where gameplay events go; it discards everything unless -log
+// names a file
+var logger = slog.New(slog.DiscardHandler)
+
+// openLog points logger at cfg.Log, appending, and returns a function that
+// closes the file
+func openLog(cfg config) (func(), error) {
+	if cfg.Log == "" {
+		return func() {}, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return nil, fmt.Errorf("log level %q: want debug, info, warn or error", cfg.LogLevel)
+	}
+	f, err := os.OpenFile(cfg.Log, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	logger = slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: level}))
+	logger.Info("session started", "args", strings.Join(os.Args[1:], " "), "seed", cfg.Seed,
+		"daily", cfg.Daily, "practice", cfg.Practice, "jump_buffer", cfg.JumpBuffer,
+		"coyote", cfg.Coyote, "grace", cfg.GraceCells)
+	return func() {
+		logger.Info("session ended")
+		logger = slog.New(slog.DiscardHandler)
+		f.Close()
+	}, nil
+}
+
+// logAttrs are the attributes every gameplay event is logged with
+func (m model) logAttrs(args ...any) []any {
+	return append([]any{"dist", m.dist, "height", m.gameRows - 2 - m.playerY,
+		"vel_y", m.velY, "frame", m.frameDur}, args...)
+}
+
+func (m model) logInfo(msg string, args ...any) {
+	if !m.ghost {
+		logger.Info(msg, m.logAttrs(args...)...)
+	}
+}
+
+func (m model) logDebug(msg string, args ...any) {
+	if !m.ghost && logger.Enabled(context.Background(), slog.LevelDebug) {
+		logger.Debug(msg, m.logAttrs(args...)...)
+	}
+}
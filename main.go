@@ -1,12 +1,13 @@
-package main
+package gopherdash
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,11 +21,164 @@ import (
    Endless‑runner mini-game built with Bubble Tea + Lip Gloss.
 
    ✦ Emoji sprites (🐹 jump‑gopher, 🪨 rock, 🟫 ground)
-   ✦ Persistent high‑score stored in CWD (./.gopherdash_highscore)
+   ✦ Logs (🪵) three cells long and two-cell holes, cleared in one jump
+   ✦ Rock stacks two and three high, spawned only where the jump clears them
+   ✦ Persistent high‑score in a versioned profile (./.gopherdash_profile),
+     optionally HMAC-signed (-sign-saves) with a "verified" badge
    ✦ Mild speed‑up that resets every run
    ✦ Game‑over screen with 2‑second cooldown & countdown; <Q> quits anytime
+   ✦ A grade (S/A/B/C) for every run, with a tip from its near-misses and
+     crash, e.g. "You jump too early on logs"
+   ✦ Hold Space to restart (fill bar) so mashing jump at death can't skip
+     the summary; -restart-hold 0 brings back the instant restart
    ✦ Middle pane shrinks during game‑over for a compact layout
-   ✦ Controls: <W> or <Space> to jump, <Q> to quit
+   ✦ Compact layout for narrow terminals, down to one-column glyphs and a
+     20-cell playfield
+   ✦ Borderless -minimal layout: a status line over the bare playfield
+   ✦ Playfield capped at -max-cols × -max-rows and letterboxed on huge
+     terminals, so difficulty doesn't depend on window size
+   ✦ Run history (./.gopherdash_history) with a sparkline of recent scores
+   ✦ Confetti & banner on a new high score (off with -reduced-motion)
+   ✦ 3‑2‑1‑GO countdown before each run; any key skips it
+   ✦ Auto‑pause when the terminal loses focus or after an idle timeout;
+     refocusing resumes behind a countdown
+   ✦ Ctrl+Z suspends cleanly; `fg` brings the run back paused behind a countdown
+   ✦ SIGINT/SIGTERM/SIGHUP save the run in progress before exiting
+   ✦ Fixed (-seed) and daily (-daily) courses with a heatmap of where past
+     runs on that seed died
+   ✦ Jump buffering & coyote time for forgiving input at speed
+   ✦ Speed-run timer (-timer) with splits, best segments and PB deltas
+   ✦ Twitch chaos mode (-twitch channel): chat votes !invert, !speed, !wave
+     or !night every 30 seconds
+   ✦ Head-to-head races over TCP (`gopherdash race -host` / `-join host:port`)
+     on a shared seed, with a ghost bar of the opponent and a results screen
+     (-lockstep exchanges inputs and shows the opponent's playfield), and
+     emotes (1-3) to send across, which M mutes
+   ✦ Prometheus metrics (-metrics-addr) for hosted instances, and a HUD toast
+     when another session in the same process sets a server record
+   ✦ Springboards (🟨) launch a higher jump; speed pads (🟦) double the score
+     rate for a while
+   ✦ Fox mode (-fox): a 🦊 chaser creeps a cell closer on every near-miss
+     and drops back while you run cleanly; if it catches you the run ends
+   ✦ Biomes: the course comes in seeded 64-cell chunks of meadow, quarry,
+     marsh and woods, each with its own mix of hazards
+   ✦ Terrain mode (-terrain): gentle hills to run up, raised platforms
+     that have to be jumped onto and brick walls to wall-jump up
+   ✦ Night mode (-night, or chat's !night): only a flashlight cone ahead of
+     the gopher is lit, with the odd lightning flash revealing everything
+   ✦ Character classes (-class): heavy (higher jump, slower speed-up), ninja
+     (double jump, smaller hitbox) and tank (one free hit)
+   ✦ Momentum physics (-physics momentum): fixed-point arcs, a terminal
+     velocity, and <S> to cut a jump short or dive
+   ✦ Smooth scrolling (-smooth): a frame half a cell on between ticks,
+     on colour terminals
+   ✦ Separate tick (-tick-rate) and render (-render-fps) caps, with a
+     performance screen (F on game over) and a live frame-time readout (F)
+   ✦ Prestige (R, endless runs past 1000): bank the score and start over on
+     a denser course, with a multiplier that grows every loop
+   ✦ Difficulty director (-director): clusters of hazards and breathers,
+     paced by how hard the player is finding it
+   ✦ Multiplier zones (-zones): shaded stretches of track that score
+     double, with more hazards in them
+   ✦ Perks (-perks): every 200 distance a pick of two – shorter cooldown,
+     higher jump, +1 shield, more acorns – for the rest of the run
+   ✦ Command line (:) with tab completion: :seed, :theme, :speed,
+     :replay, :quit
+   ✦ Control schemes (-keys): right-handed IJKL, the number pad, or one
+     key that jumps or dives as the moment needs
+   ✦ Local co-op (-coop): two players on one gopher, P1 on the jump and P2
+     on the acorns (→) and dives (↓), with a tally for each in the HUD
+   ✦ Race the bot (-bot): the screensaver's bot on your course in a second
+     box, easy to perfect, first to -bot-target wins
+   ✦ Key repeats ignored for jump (-debounce); on terminals that report
+     key releases (kitty keyboard protocol) a jump goes as high as jump is
+     held, and holding dive keeps diving
+   ✦ Speed floor and ceiling (-min-tick, -max-tick) for slower reactions;
+     capped runs keep score tables of their own
+   ✦ `gopherdash latency`: tap along to a flashing beat to measure the
+     terminal's input lag; -latency-comp draws the course ahead by it
+   ✦ Distance milestones: a banner across the HUD and a fanfare every 100,
+     with achievements at 500, 1000 and 2500
+   ✦ Scenery on the ground line (flowers, mushrooms, footprints, signs)
+     from the course's seed, so replays and daily runs look the same
+   ✦ A sky on truecolor terminals: a gradient behind the playfield that
+     goes from morning to noon, dusk and night as the run goes on
+   ✦ Pixel sprites (-renderer): real pixel art for the gopher, obstacles
+     and tiles in kitty, Ghostty, iTerm2 and WezTerm, or the whole
+     playfield as a sixel image in foot and mlterm; emoji elsewhere
+   ✦ Background music (-music): a chiptune loop of square-wave beeps that
+     speeds up and fills out with the game; <N> mutes it
+   ✦ Battery saver (-battery-saver): half the redraws, no decorative
+     layers, and the HUD is only rebuilt when it changes
+   ✦ `gopherdash screensaver`: a bot plays endless zen-mode runs under
+     random themes, saving nothing; any key exits
+   ✦ Weekly challenge (-weekly): a seed and a set of modifiers (low gravity,
+     fog, double speed) for the week, with its own board
+   ✦ Photo mode (P): freeze a run, hide the HUD and save the frame as ANSI
+     text, or SVG too with -photo-svg
+   ✦ Streak mode (-streak): beat a rising target distance every run, or
+     the streak starts over
+   ✦ Run modifiers (M on the game-over screen, or -mods): mirror controls,
+     tiny gopher, big rocks, no cooldown, one-hit shield; modified runs
+     keep bests of their own
+   ✦ Scoring rules (-scoring arcade) from ./.gopherdash_scoring: points for
+     clears, acorns and near-misses with a combo multiplier, and a table of
+     bests per mode in ./.gopherdash_scores
+   ✦ Seasonal events from an editable calendar (./.gopherdash_events):
+     pumpkins for rocks in late October, snow in December, and an
+     achievement for each; -events=false switches them off
+   ✦ Practice mode (-practice) with a radar of obstacles two screens ahead
+     and a hitbox overlay (X, or -hitboxes) showing what collisions see
+   ✦ Desktop notifications (-notify, OSC 9/777) for new records and
+     achievements, even with the terminal in the background
+   ✦ <C> on game over copies a shareable score line to the clipboard
+     (OSC 52), and prints it on exit too
+   ✦ Lifetime death stats (./.gopherdash_stats) with a bar-chart screen (S)
+   ✦ Session summary on quit: runs, best, distance and time played, kept
+     in the stats
+   ✦ `gopherdash export FILE` / `import FILE` back up or move every save,
+     merging on import (-prefer mine|theirs for conflicts)
+   ✦ Cloud sync (-sync-url) of the archive to WebDAV or S3 on startup and
+     exit: scores merge, the rest goes to whoever wrote last
+   ✦ Gist sync (gist_token) of scores and stats to a private GitHub Gist
+   ✦ Profile, stats and history behind a store: files, a SQLite database
+     (-store sqlite) keeping every run, or -store memory to keep nothing
+     on disk
+   ✦ Instances sharing a directory merge their saves under a lock file and
+     pick up each other's new bests
+   ✦ Live runs are autosaved (./.gopherdash_autosave) and offered for resume
+     after a crash
+   ✦ Panics restore the terminal and leave a crash report; `gopherdash doctor
+     -bundle` (or B on the next launch) zips diagnostics for bug reports
+   ✦ `gopherdash fsck` checks every save against its format, repairing
+     truncated or nonsensical ones and moving the unsalvageable aside
+   ✦ Opt-in anonymous telemetry (-telemetry): totals counted locally and
+     sent only by `gopherdash telemetry send`, after showing the payload
+   ✦ Opt-in debug log (-log FILE, -log-level) of spawns, collisions, resizes
+     and state changes, via log/slog
+   ✦ Ctrl+D dumps the exact game state to JSON for bug reports; -load-state
+     picks it back up
+   ✦ Acorns (🌰) to throw with <D>, knocking out the next rock; pick more up
+     along the way
+   ✦ Opt-in update check (check_updates, or -check-update) against GitHub
+     releases; it only ever tells you, and the version shows on game over
+   ✦ Yesterday's best daily run raced as a ghost on today's daily, with
+     the HUD showing the score gap
+   ✦ Gopherdex (G on game over): each hazard and tile met so far, how
+     often, and how many runs it has ended
+   ✦ About screen (A on game over) with the version, commit, build date,
+     where the saves are and what the terminal looks like
+   ✦ Replays of the last and best runs (`gopherdash replay`), played back
+     at 1×, 2× or 4× with pause and frame stepping both ways, or rendered
+     to an animated GIF with -gif
+   ✦ Embeddable: gopherdash.New gives other Bubble Tea programs the game
+     as a tea.Model widget, with the saves in a Store of their choosing;
+     the command lives in cmd/gopherdash
+   ✦ Player accounts for servers: WithPlayer keeps each SSH key's saves
+     apart, and `gopherdash players list|prune` looks after them
+   ✦ Server limits: -max-sessions, -max-per-ip and -idle-kick keep a public
+     instance from being run into the ground
+   ✦ Controls: <W> or <Space> to jump, <D> to throw an acorn, <Q> to quit
 */
 
 // ----------------------------------------------------------------------------
@@ -45,175 +199,790 @@ const (
 	playerChar = "🐹"
 	groundChar = "🟫"
 	rockChar   = "🪨"
+	logChar    = "🪵"
 
 	// gameplay
 	minGapCells = 6 // logical cells between hazards
+	playerCol   = 2 // column the gopher runs in; hazards are checked here
 
 	// UI strings
-	controlsRunning  = "W/Space = jump   Q = quit"
-	controlsGameOver = "Q = quit"
-
-	initialSafeTiles = 30 // initial number of safe tiles at the start of the game
+	controlsRunning  = "W/Space = jump   D = throw acorn   F = frame times   P = photo   Q = quit"
+	controlsCoop     = "P1: W/Space = jump   P2: → = throw acorn   F = frame times   P = photo   Q = quit"
+	controlsGameOver = "S = stats   G = gopherdex   F = performance   M = modifiers   A = about   C = copy score   Q = quit"
+	controlsStats    = "S/Esc = back   Q = quit"
+	controlsAbout    = "A/Esc = back   Q = quit"
+	controlsPerf     = "↑↓ = select   ←→ = change   F/Esc = back   Q = quit"
+	controlsPhoto    = "H = hide HUD   E = save photo   P/Esc = carry on   Q = quit"
+	controlsMods     = "↑↓ = select   Space = toggle   M/Esc = back   Q = quit"
+	controlsResume   = "Y = resume   N = new run   Q = quit"
+	controlsCrash    = "B = save diagnostics bundle   any other key = continue"
+
+	defaultGraceCells = 30 // obstacle-free cells ahead of the gopher when a run starts
+
+	// layout
+	gameOverRows = 10 // inner height of the game-over pane
 )
 
+// banner shown in place of "Game over!" when a run sets a new record
+var bannerStyle = lipgloss.NewStyle().Bold(true).
+	Foreground(lipgloss.Color("0")).Background(lipgloss.Color("11")).Padding(0, 1)
+
 // ----------------------------------------------------------------------------
 // TYPES & GLOBALS
 // ----------------------------------------------------------------------------
 
-// scoped RNG (avoids deprecated package‑level rand)
+// scoped RNG (avoids deprecated package‑level rand); decorative only –
+// gameplay draws from the run's seeded spawner
 var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
 
-// tick message tagged with the run generation
-type tickMsg struct{ gen int }
+// reseed starts the obstacle stream of a run from seed
+func (m *model) reseed(seed int64) {
+	m.seed = seed
+	m.mods = m.cfg.runMods()
+	m.spawn = newSpawner(seed, playerCol+1, m.cfg.GraceCells)
+	m.fitSpawner()
+	m.dir = nil // a new run starts calm
+	m.startDirector()
+	m.startTape()
+}
 
-// obstacle in the world grid
-type obstacle struct {
-	x   int    // horizontal logical cell (emoji = 2 columns)
-	typ string // "hole" or "rock"
+// tick message tagged with the run generation and the time it fired
+type tickMsg struct {
+	gen int
+	at  time.Time
 }
 
 // model holds the complete program state
 type model struct {
-	// terminal size
-	w, h int
+	// terminal size, and the frame the game is laid out in (see letterbox.go)
+	w, h   int
+	fw, fh int
 
 	// derived grid size
 	gameRows int
 	gameCols int
 
 	// timing
-	frameDur time.Duration
-	tickGen  int // generation id; increments on every restart
+	clock      gameClock // ticks and timers run on this (see clock.go)
+	frameDur   time.Duration
+	tickGen    int       // generation id; increments on every restart
+	introUntil time.Time // world stays frozen until the countdown ends
 
 	// gameplay
+	seed      int64 // RNG seed the run started from
+	steps     int   // gameplay steps taken, clinging to walls included
 	dist      int
 	playerY   int
 	velY      int
+	velFx     int // momentum physics: speed and row fraction in fixedOne units (see physics.go)
+	subY      int
 	obstacles []obstacle
-	seeded    bool
+	spawn     spawner
+	jumps     int      // jumps made this run
+	bonus     int      // score earned on top of distance (see tiles.go)
+	boostLeft int      // ticks left on a speed pad's boost
+	fox       int      // cells the fox is behind the gopher (see fox.go)
+	foxCalm   int      // cells run since the fox last got closer
+	ammo      int      // acorns left to throw (see acorns.go)
+	acorns    []acorn  // acorns in flight
+	jumpBuf   int      // ticks left on a buffered jump press
+	coyote    int      // ticks left to jump after running onto a hole
+	airJumps  int      // mid-air jumps used since leaving the ground (see classes.go)
+	hits      int      // fatal hits a shield has absorbed this run
+	clinging  bool     // hanging on the face of a wall (see terrain.go)
+	slide     int      // ticks since a clinging gopher last slid a row
+	ledge     string   // kind the coyote window is running over
+	cause     string   // obstacle kind that ended the run
+	mods      []string // the run's modifiers (see modifiers.go)
+
+	tele runTelemetry   // what the run did, for its grade (see grade.go)
+	met  map[string]int // hazards and tiles met this run, for the Gopherdex (see dex.go)
+
+	banner   string    // milestone banner across the HUD (see milestones.go)
+	bannerAt time.Time // when it went up
+
+	loop   int // prestige loops this run (see prestige.go)
+	banked int // score banked by them
+
+	seats coopSeats // player two's tally in co-op (see coop.go)
+	bot   *botRace  // the race with the bot, if there's one (see botrace.go)
+
+	dir *director // paces the stream, with -director (see director.go)
+
+	yesterday *yesterday // yesterday's best daily run alongside (see dailyghost.go)
+
+	perks     []string // perks taken this run, with -perks (see perks.go)
+	perkOffer []string // the two on offer, while the run waits for a pick
+
+	palette *palette // the `:` command line, while it's open (see palette.go)
+
+	keyboard bool     // the game owns the terminal's keyboard modes (see keyboard.go)
+	held     heldKeys // keys known to be down
+
+	// scoring (see scoring.go)
+	rules   scoringRules // what the run scores for
+	points  int          // scored on top of distance and bonus under those rules
+	combo   int          // hazards cleared in a row
+	cleared int          // hazards the current jump has cleared
 
 	// meta
-	highScore int
-	gameOver  bool
-	restartAt time.Time // earliest time a restart is allowed
+	cfg         config
+	saves       Store     // where the profile, stats, history and replays are kept (see store.go)
+	profile     profile   // high score and other persistent progress (see profile.go)
+	verified    bool      // the profile's signature checked out (see integrity.go)
+	watch       saveWatch // when other instances last touched the shared saves
+	logged      int       // runs logged, so older reads of the stats are dropped
+	unsaved     []tea.Cmd // runs logged but not yet written (see flushSaves)
+	session     int       // id on the server-record bus (see records.go)
+	prevBest    int       // high score as it stood before the last run ended
+	newRecord   bool      // last run beat the previous high score
+	weeklyPlace int       // last weekly run's place on its board; 0 = off it
+	prevModBest int       // best with the last run's modifiers before it
+	tablePlace  int       // last run's place in its score table; 0 = off it
+	tableBest   int       // that table's best before the run
+	streak      streak    // -streak progress (see streak.go)
+	streakBeat  bool      // last run beat its streak target
+	particles   []particle
+	lightning   bool   // this frame is a lightning flash (see night.go)
+	half        bool   // draw the world half a cell on from the last step (see smooth.go)
+	event       *event // seasonal event that's on, if any (see seasons.go)
+	history     []runRecord
+	stats       stats
+	deaths      deathMap // where runs on fixed seeds ended
+	showStats   bool     // stats screen is open (game-over only)
+	showPerf    bool     // performance screen is open (game-over only; see perf.go)
+	showMods    bool     // modifiers menu is open (game-over only; see modifiers.go)
+	showAbout   bool     // about screen is open (game-over only; see about.go)
+	modRow      int      // modifier selected in the menu
+	showDex     bool     // Gopherdex is open (game-over only; see dex.go)
+	dexRow      int      // entry selected in the Gopherdex
+	photoClean  bool     // photo mode with the HUD and controls hidden
+	perfRow     int      // setting selected on the performance screen
+	readout     bool     // frame times in the HUD
+	hitboxes    bool     // collision overlay (see hitbox.go)
+	landed      bool     // the gopher touched down on the last step
+	perf        *perfMeter
+	gameOver    bool
+	restartAt   time.Time // earliest time a restart is allowed
+
+	// the session so far (see session.go)
+	started time.Time     // when the game was launched
+	sitting sessionRecord // runs since then
+	leaving bool          // quitting: the summary is up
+
+	// speed-run timer (see splits.go)
+	runTime   time.Duration   // wall time spent actually running
+	lastStep  time.Time       // previous gameplay step; zero after a pause
+	splits    []time.Duration // cumulative time at every splitEvery distance
+	splitAt   time.Time       // when the latest split was taken
+	splitBook splitStore
+
+	// hold-to-restart tracking (see restart.go)
+	holdStart   time.Time
+	holdLast    time.Time
+	holdRepeats int
+
+	chaos     chaos // Twitch chat votes and the active chaos event
+	paused    bool
+	pauseWhy  string       // reason shown under the resume prompt
+	lastInput time.Time    // last key press, for idle detection
+	lastSave  time.Time    // last autosave of the live run
+	offer     *snapshot    // run left by a previous session, awaiting Y/N
+	crashNote *crashReport // previous session's crash, awaiting B or any key
+	syncNote  string       // how the startup syncs went (see cloud.go)
+	newer     *release     // a newer release than this build, if the check found one (see update.go)
+	notice    string       // one-off HUD message, e.g. where a state dump went
+	noticeAt  time.Time
+
+	race  raceState // opponent in a head-to-head race (see race.go)
+	ghost bool      // a simulated lockstep opponent: saves nothing
+	saver bool      // the screensaver's bot run: saves nothing (see screensaver.go)
+
+	tape     *replay   // the run's inputs so far, for its replay (see replay.go)
+	playback *playback // watching a replay: saves nothing
+
+	embedded bool // a widget in another program: quitting hands back control (see embed.go)
+
+	music  *musicBox // background loop; nil when off (see music.go)
+	shared string    // summary last copied to the clipboard, printed on exit (see share.go)
+	gfx    *graphics // pixel sprites; nil draws text (see graphics.go)
 }
 
 // ----------------------------------------------------------------------------
 // ENTRY POINT & INITIALISATION
 // ----------------------------------------------------------------------------
 
-func initialModel() model {
-	return model{
+func initialModel(cfg config, saves Store) model {
+	m := model{
+		cfg:       cfg,
+		saves:     saves,
+		clock:     wallClock{},
 		frameDur:  startFrame,
-		highScore: loadHighScore(),
+		profile:   loadProfile(saves),
+		history:   loadHistory(saves),
+		stats:     loadStats(saves),
+		deaths:    loadDeaths(),
+		splitBook: loadSplits(),
+		perf:      &perfMeter{},
+		offer:     loadAutosave(),
+		crashNote: loadCrashNote(),
+		watch:     saves.changed(),
+		fox:       foxStart,
+		ammo:      acornStart,
+		hitboxes:  cfg.Hitboxes,
+		rules:     rulesByName(cfg.Scoring),
+	}
+	if cfg.Twitch != "" {
+		m.chaos.status = "connecting…"
+		m.chaos.round = m.now().Add(chaosRound)
+	}
+	if cfg.Events {
+		m.event = activeEvent(loadEvents(), m.now())
 	}
+	if cfg.Streak {
+		m.streak = loadStreak()
+		if m.streak.Target == 0 {
+			m.streak.Target = streakTarget(m.stats, 0)
+		}
+	}
+	m.frameDur = m.capFrame(m.frameDur)
+	m.started = m.now()
+	m.verified = m.profile.verified()
+	if m.profile.newer() {
+		m.notify("Your profile is from a newer Gopher-Dash; high scores won't be saved")
+	}
+	m.reseed(m.runSeed())
+	m.startBot()
+	m.startYesterday()
+	m.startIntro()
+	metrics.runStarted()
+	return m
 }
 
-func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	// Run returns (finalModel, error). Ignore the model if you don’t need it.
-	if _, err := p.Run(); err != nil {
-		fmt.Println("error:", err)
-		os.Exit(1)
+// Main runs the gopherdash command with args, the command line without the
+// program name, and returns its exit code (see cmd/gopherdash)
+func Main(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "race":
+			return raceMain(args[1:])
+		case "doctor":
+			return doctorMain(args[1:])
+		case "screensaver":
+			return screensaverMain(args[1:])
+		case "export":
+			return exportMain(args[1:])
+		case "import":
+			return importMain(args[1:])
+		case "replay":
+			return replayMain(args[1:])
+		case "latency":
+			return latencyMain(args[1:])
+		case "fsck":
+			return fsckMain(args[1:])
+		case "telemetry":
+			return telemetryMain(args[1:])
+		case "players":
+			return playersMain(args[1:])
+		}
+	}
+	cfg, err := parseFlags(loadConfig(), args)
+	if err != nil {
+		return exitUsage // flag package already printed the usage
+	}
+	if cfg.CheckNow {
+		return checkUpdateMain()
+	}
+	saves, err := openStore(cfg)
+	if err != nil {
+		return exitCode(err)
+	}
+	syncs, err := openSyncs(cfg)
+	if err != nil {
+		return exitCode(err)
+	}
+	var notes []string
+	for _, s := range syncs {
+		notes = append(notes, s.pull(saves, cfg.SignSaves))
+	}
+	m := initialModel(cfg, saves)
+	if len(notes) > 0 {
+		m.syncNote = strings.Join(notes, "\n")
+		m.notify(notes[len(notes)-1])
 	}
+	if cfg.LoadState != "" {
+		if err := m.loadState(cfg.LoadState); err != nil {
+			return exitCode(err)
+		}
+	}
+	err = runProgram(cfg, m)
+	for _, s := range syncs {
+		if perr := s.push(saves); perr != nil {
+			fmt.Printf("Sync: couldn't upload to %s: %v\n", s.host(), perr)
+		} else {
+			fmt.Println("Sync: saves uploaded to", s.host())
+		}
+	}
+	if err != nil {
+		return exitCode(err)
+	}
+	return 0
+}
+
+// runProgram plays m until the user quits, along with whatever side
+// services cfg asks for
+func runProgram(cfg config, m model) error {
+	if cfg.MetricsAddr != "" {
+		if err := serveMetrics(cfg.MetricsAddr); err != nil {
+			return err
+		}
+	}
+	closeLog, err := openLog(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	metrics.sessionStarted()
+	defer metrics.sessionEnded()
+	tallySession(cfg)
+	m.session = records.join(m.profile.HighScore)
+	if cfg.Music {
+		box, err := startMusic()
+		if err != nil {
+			m.notify("No music: " + err.Error())
+			logger.Warn("music unavailable", "err", err)
+		}
+		m.music = box
+		defer box.stop()
+	}
+	m.gfx = newGraphics(cfg.Renderer, os.Getenv)
+	defer m.gfx.restore()
+	m.keyboard = true
+	defer releaseKeyboard()
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithReportFocus(),
+		tea.WithoutSignalHandler())
+	records.listen(m.session, p.Send)
+	defer records.leave(m.session)
+	stop := forwardSignals(p)
+	defer stop()
+	if cfg.Twitch != "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go runTwitch(ctx, cfg.Twitch, p.Send)
+	}
+	if cfg.CheckUpdates {
+		go checkUpdate(p.Send)
+	}
+	if m.racing() {
+		go m.race.link.read(p.Send)
+	}
+	// Run returns (finalModel, error); the model has the score to print if
+	// it was shared. A panic comes back as tea.ErrProgramPanic; see crash.go.
+	final, err := p.Run()
+	if fm, ok := final.(model); ok && fm.shared != "" {
+		fmt.Println(fm.shared)
+	}
+	return err
 }
 
 // ----------------------------------------------------------------------------
-// HIGH‑SCORE PERSISTENCE
+// SAVE FILES
 // ----------------------------------------------------------------------------
 
-func highscorePath() string {
+// dataDir holds the save files when set; embedders choose it with
+// WithDataDir
+var dataDir string
+
+// dataPath places a save file in dataDir, or next to the running binary
+func dataPath(name string) string {
+	if dataDir != "" {
+		return filepath.Join(dataDir, name)
+	}
 	exe, err := os.Executable() // full path to the running binary
 	if err != nil {
 		// fallback: use CWD so the game still works during `go run`
-		return ".gopherdash_highscore"
+		return name
 	}
 	dir := filepath.Dir(exe)
-	return filepath.Join(dir, ".gopherdash_highscore")
-}
-
-func loadHighScore() int {
-	data, err := os.ReadFile(highscorePath())
-	if err != nil {
-		return 0
-	}
-	s, err := strconv.Atoi(strings.TrimSpace(string(data)))
-	if err != nil || s < 0 {
-		return 0
-	}
-	return s
-}
-
-func saveHighScore(score int) {
-	_ = os.WriteFile(highscorePath(), []byte(strconv.Itoa(score)), 0o644)
+	return filepath.Join(dir, name)
 }
 
 // ----------------------------------------------------------------------------
 // TEA HELPERS
 // ----------------------------------------------------------------------------
 
-func tickAfter(d time.Duration, gen int) tea.Cmd {
-	return tea.Tick(d, func(time.Time) tea.Msg { return tickMsg{gen} })
+func (m model) tickAfter(d time.Duration, gen int) tea.Cmd {
+	return m.after(d, func(t time.Time) tea.Msg { return tickMsg{gen, t} })
 }
 
 // recompute grid on resize
 func (m *model) recalcSizes() {
 	topRows, bottomRows := 1, 1 // inner heights for HUD & control bars
 	borders := 2 * 3            // three boxes, two border rows each
-	m.gameRows = max(m.h-topRows-bottomRows-borders, 5)
-
-	m.gameCols = max((m.w-2)/2, 10)
+	oppBorders := 2             // the opponent's box in a lockstep race or the bot's
+	switch {
+	case m.cfg.Minimal:
+		bottomRows, borders, oppBorders = 0, 0, 0 // a status line and bare playfields
+	case m.compact():
+		topRows, borders = compactHUDRows, 2 // bare HUD & controls lines
+	}
+	strips := 0
+	if m.showHeatmap() {
+		strips++ // death heatmap under the playfield
+	}
+	if m.cfg.Practice {
+		strips++ // radar above the playfield
+	}
+	air := 0 // keep a jump in progress across the resize
+	if m.gameRows > 0 {
+		air = m.gameRows - 2 - m.playerY
+	}
+	panes := 1
+	if m.splitPanes() {
+		// the opponent's or the bot's playfield gets its own box below ours
+		m.gameRows = max((m.h-topRows-bottomRows-borders-oppBorders-strips)/2, 5)
+		panes = 2
+	} else {
+		m.gameRows = max(m.h-topRows-bottomRows-borders-strips, 5)
+	}
 
-	m.playerY = m.gameRows - 2 // one row above ground
+	switch {
+	case m.narrow():
+		m.gameCols = max(m.w, compactCells)
+	case m.compact() || m.cfg.Minimal:
+		m.gameCols = m.w / 2
+	default:
+		m.gameCols = max((m.w-2)/2, 10)
+	}
+	m.capPlayfield(panes)
 
-	// one-time seeding for the very first run
-	if !m.seeded && m.gameCols > 0 {
-		m.seedInitialObstacles()
-		m.seeded = true
+	m.playerY = m.gameRows - 2 - air // one row above ground when running
+	m.resizeOpp()
+	if m.bot != nil {
+		m.sizeGhost(m.bot.run)
 	}
+	if m.yesterday != nil {
+		m.sizeGhost(m.yesterday.run)
+	}
+
+	// a wider window needs more of the stream straight away
+	m.fillObstacles()
 }
 
 // restart a new run
 func (m *model) restart() tea.Cmd {
-	m.dist = 0
+	m.steps, m.dist = 0, 0
+	m.landed = false
 	m.playerY = m.gameRows - 2
-	m.velY = 0
+	m.halt()
+	m.jumps = 0
+	m.bonus, m.boostLeft = 0, 0
+	m.points, m.combo, m.cleared = 0, 0, 0
+	m.tele = runTelemetry{}
+	m.banner = ""
+	m.loop, m.banked = 0, 0
+	m.seats = coopSeats{}
+	m.fox, m.foxCalm = foxStart, 0
+	m.ammo, m.acorns = acornStart, nil
+	m.runTime = 0
+	m.lastStep = time.Time{}
+	m.splits = nil
+	m.jumpBuf = 0
+	m.coyote = 0
+	m.clinging, m.slide = false, 0
+	m.airJumps, m.hits = 0, 0
+	m.perks, m.perkOffer = nil, nil
+	m.met = nil
+	m.cause = ""
 	m.obstacles = nil
-	m.frameDur = startFrame
+	m.frameDur = m.capFrame(startFrame)
+	m.music.setSpeed(speedFactor(m.frameDur))
 	m.gameOver = false
+	m.holdStart = time.Time{}
+	m.showStats = false
+	m.showPerf = false
+	m.showMods = false
+	m.showAbout = false
+	m.showDex = false
+	m.paused = false
+	m.newRecord = false
+	m.particles = nil
 	m.tickGen++ // invalidate all pending ticks from previous run
-	m.seedInitialObstacles()
-	m.seeded = true
-	return tickAfter(m.frameDur, m.tickGen)
+	m.reseed(m.runSeed())
+	m.fillObstacles()
+	m.startBot()
+	m.startYesterday()
+	m.startIntro()
+	metrics.runStarted()
+	m.logInfo("run started", "seed", m.seed)
+	return m.tickAfter(m.frameDur, m.tickGen)
 }
 
 // ----------------------------------------------------------------------------
 // TEA IMPLEMENTATION
 // ----------------------------------------------------------------------------
 
-func (m model) Init() tea.Cmd { return tickAfter(m.frameDur, m.tickGen) }
+func (m model) Init() tea.Cmd {
+	m.logInfo("run started", "seed", m.seed)
+	if m.cfg.Twitch != "" {
+		return tea.Batch(m.gfx.sendSprites(), m.askKeyboard(), m.tickAfter(m.frameDur, m.tickGen), m.watchTick(), m.chaosTick())
+	}
+	return tea.Batch(m.gfx.sendSprites(), m.askKeyboard(), m.tickAfter(m.frameDur, m.tickGen), m.watchTick())
+}
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// terminal replies and kitty key events, from a message type Bubble Tea
+	// doesn't export (checked against v1.3.5, see terminalReply)
+	if reply := terminalReply(msg); reply != "" {
+		if ok, press := m.keyboardReply(reply); ok {
+			if press != nil {
+				return m.update(press)
+			}
+			return m, nil
+		}
+		gfx, why := m.gfx.answer(reply)
+		if why != "" && m.cfg.Renderer == rendererSixel {
+			m.notify("Text playfield: " + why)
+		}
+		if gfx != m.gfx {
+			m.logInfo("terminal replied", "reply", fmt.Sprintf("%q", reply), "sixel", why == "" && gfx != nil)
+		}
+		m.gfx = gfx
+		return m, nil
+	}
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.w, m.h = msg.Width, msg.Height
 		m.recalcSizes()
-		// no new command
+		m.logInfo("resize", "w", m.w, "h", m.h, "rows", m.gameRows, "cols", m.gameCols)
+		return m, m.gfx.askCellSize()
+
+	case tea.BlurMsg:
+		m.pause(pauseBlur)
+		m.held.down = nil // releases go to whichever window has focus
+		return m, nil
+
+	case recordMsg:
+		m.notify(msg.toast())
+		return m, nil
+
+	case watchMsg:
+		if !m.saver {
+			return m, m.watchShared()
+		}
+		return m, m.watchTick()
+
+	case sharedMsg:
+		m.applyShared(msg)
+		return m, m.watchTick()
+
+	case loggedMsg:
+		m.applyLogged(msg)
+		return m, nil
+
+	case chatVoteMsg:
+		m.vote(msg.event)
+		return m, nil
+
+	case chatStatusMsg:
+		m.chaos.status = msg.status
+		return m, nil
+
+	case updateMsg:
+		m.newer = &msg.rel
+		m.notify(fmt.Sprintf("Gopher-Dash %s is out (you have %s)", msg.rel.Tag, buildVersion()))
+		return m, nil
+
+	case chaosRoundMsg:
+		return m, m.closeRound()
+
+	case raceInputMsg:
+		if m.race.lockstep {
+			m.race.theirs[msg.tick] = msg.jump
+			m.advanceOpp()
+		}
+		return m, nil
+
+	case raceOppMsg:
+		if m.race.lockstep {
+			return m, nil // the local simulation is authoritative
+		}
+		m.race.oppDist, m.race.oppAlive = msg.dist, msg.alive
+		return m, nil
+
+	case raceEmoteMsg:
+		m.gotEmote(msg.emote)
+		return m, nil
+
+	case raceGoneMsg:
+		m.race.oppGone, m.race.err = true, msg.err
+		logger.Warn("opponent disconnected", "err", msg.err, "opp_dist", m.race.oppDist)
+		return m, nil
+
+	case shutdownMsg:
+		m.flushRun()
+		m.raceReport()
+		m.endSession()
+		return m, tea.Quit
+
+	case leaveMsg:
+		return m, m.quit()
+
+	case replayDoneMsg:
+		if msg.err != nil {
+			m.notify("replay: " + msg.err.Error())
+		}
+		return m, m.askKeyboard()
+
+	case tea.ResumeMsg:
+		// back from Ctrl+Z; the terminal has been restored by Bubble Tea
+		if m.paused && m.pauseWhy == pauseSuspend {
+			return m, m.resume()
+		}
+		return m, nil
+
+	case tea.FocusMsg:
+		// only undo pauses the blur caused; an idle pause waits for a key
+		if m.paused && m.pauseWhy == pauseBlur {
+			return m, m.resume()
+		}
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case " ", "w":
+		m.lastInput = m.now()
+		if m.saver {
+			return m, tea.Quit // any key ends the screensaver
+		}
+		if m.playback != nil {
+			return m, m.replayKey(msg.String())
+		}
+		key := msg.String()
+		repeat := m.pressKey(key, m.lastInput)
+		switch {
+		case m.leaving:
+			return m, m.quit() // any key skips the summary
+		case m.palette != nil:
+			return m, m.paletteKey(msg)
+		case key == "q" || key == "ctrl+c":
+			m.flushRun()
+			m.raceReport()
+			return m, m.leave()
+		case key == "ctrl+d":
+			m.dumpState()
+			return m, nil
+		case m.crashNote != nil:
+			m.answerCrash(key)
+			return m, nil
+		case m.offer != nil:
+			return m, m.answerOffer(key)
+		case m.perkOffer != nil:
+			return m, m.answerPerk(key)
+		case m.showStats:
+			if key == "s" || key == "esc" {
+				m.showStats = false
+			}
+			return m, nil
+		case m.gameOver && key == "s":
+			m.showStats = true
+			return m, nil
+		case m.showPerf:
+			m.perfKey(key)
+			return m, nil
+		case m.gameOver && key == "f":
+			m.showPerf = true
+			return m, nil
+		case m.showMods:
+			m.modsMenuKey(key)
+			return m, nil
+		case m.gameOver && key == "m" && !m.racing():
+			m.showMods = true
+			return m, nil
+		case m.showDex:
+			m.dexKey(key)
+			return m, nil
+		case m.gameOver && key == "g":
+			m.showDex = true
+			return m, nil
+		case m.showAbout:
+			if key == "a" || key == "esc" {
+				m.showAbout = false
+			}
+			return m, nil
+		case m.gameOver && key == "a":
+			m.showAbout = true
+			return m, nil
+		case m.gameOver && key == "c" && !m.embedded:
+			m.share()
+			return m, nil
+		case m.racing() && emoteKey(key) != "" && !m.keys().bound(key):
+			m.sendEmote(emoteKey(key))
+			return m, nil
+		case m.racing() && key == "m":
+			m.toggleMute()
+			return m, nil
+		case key == ":" && m.paletteOK():
+			m.openPalette()
+			return m, nil
+		case key == "ctrl+z":
+			// freeze the run first so no ticks land while we're stopped
+			m.pause(pauseSuspend)
+			return m, tea.Suspend
+		case m.photo():
+			return m, m.photoKey(key)
+		case key == "p" && m.live() && !m.inIntro() && !m.racing():
+			m.pause(pausePhoto)
+			return m, nil
+		case m.paused:
+			// any other key resumes without jumping
+			return m, m.resume()
+		case !m.gameOver && m.inIntro():
+			// any other key skips the countdown without jumping
+			m.skipIntro()
+			return m, nil
+		case key == "r" && m.canPrestige():
+			m.record(actPrestige)
+			m.prestige()
+		case m.isThrowKey(key) && !m.gameOver:
+			m.record(actAcorn)
+			m.throwAcorn()
+		case key == "f":
+			m.readout = !m.readout
+		case key == "x":
+			m.toggleHitboxes()
+		case key == "n" && m.music != nil:
+			if m.music.toggle() {
+				m.notify("Music on")
+			} else {
+				m.notify("Music muted")
+			}
+		case m.isDiveKey(key) && !m.gameOver:
+			if repeat {
+				return m, nil // held down: holdDive has it
+			}
+			m.record(actDive)
+			m.dive()
+		case m.isJumpKey(key):
 			if m.gameOver {
-				if time.Now().After(m.restartAt) {
+				if m.racing() {
+					return m, nil // one run per race
+				}
+				if now := m.now(); !repeat && now.After(m.restartAt) && m.pressRestart(now) {
 					return m, m.restart()
 				}
 				return m, nil
 			}
-			if m.playerY == m.gameRows-2 {
-				m.velY = jumpVel
+			if repeat || !m.held.releases && m.jumpRepeat(m.now()) {
+				return m, nil // the OS repeating a held key
+			}
+			switch {
+			case m.race.lockstep:
+				m.race.pending = true // sent and applied with the next tick's input
+			case m.keys().oneKey && m.oneKeyAct() == actDive:
+				m.record(actDive)
+				m.dive()
+			default:
+				m.record(actJump)
+				m.pressJump()
 			}
 		}
 
@@ -222,82 +991,289 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.gen != m.tickGen {
 			return m, nil
 		}
+		metrics.tickLatency(m.now().Sub(msg.at))
+		if m.idleKicked() {
+			m.logInfo("idle kick", "seconds", m.cfg.IdleKick)
+			m.flushRun()
+			return m, m.leave()
+		}
+		if m.saver {
+			if cmd := m.saverTick(); cmd != nil {
+				return m, cmd
+			}
+		}
 
 		if m.gameOver {
+			if m.heldRestart(m.now()) {
+				return m, m.restart()
+			}
+			if len(m.particles) > 0 {
+				m.particles = stepParticles(m.particles)
+				return m, m.tickAfter(particleFrame, m.tickGen)
+			}
 			// refresh countdown every gameOverTick
-			return m, tickAfter(gameOverTick, m.tickGen)
+			return m, m.tickAfter(gameOverTick, m.tickGen)
 		}
-		if m.gameRows == 0 || m.gameCols == 0 {
-			return m, tickAfter(m.frameDur, m.tickGen)
+		if m.paused || m.offer != nil || m.perkOffer != nil || m.crashNote != nil || (m.playback != nil && m.playback.paused) {
+			return m, m.tickAfter(gameOverTick, m.tickGen)
 		}
-
-		// --- gameplay step ---
-		m.dist++
-
-		// physics
-		m.velY += gravity
-		m.playerY += m.velY
-		if m.playerY >= m.gameRows-2 {
-			m.playerY = m.gameRows - 2
-			m.velY = 0
+		if m.idle() {
+			m.pause(pauseIdle)
+			return m, m.tickAfter(gameOverTick, m.tickGen)
+		}
+		if m.gameRows <= 0 || m.gameCols <= 0 || m.inIntro() {
+			return m, m.tickAfter(m.frameDur, m.tickGen)
 		}
 
-		// shift obstacles
-		kept := m.obstacles[:0]
-		for _, ob := range m.obstacles {
-			ob.x--
-			if ob.x >= -1 {
-				kept = append(kept, ob)
-			}
+		if m.race.lockstep && !m.lockReady() {
+			return m, m.tickAfter(lockWait, m.tickGen)
+		}
+		m.lockInput()
+		m.perf.ticked(time.Now())
+		n := m.stepsPerTick()
+		for i := 0; i < n && !m.gameOver && m.perkOffer == nil; i++ {
+			m.playInputs()
+			m.holdDive()
+			m.step(m.now())
+			m.stepBot()
+			m.stepYesterday()
+			m.stepLightning()
+			m.snapTape()
+		}
+		if !m.gameOver {
+			m.autosave()
 		}
-		m.obstacles = kept
+		return m, m.nextTick(n)
 
-		// spawn new obstacle if last is far enough
-		furthest := -1
-		for _, ob := range m.obstacles {
-			if ob.x > furthest {
-				furthest = ob.x
+	case halfMsg:
+		m.half = msg.gen == m.tickGen
+		return m, nil
+	}
+	return m, nil
+}
+
+// step advances the run by one cell: physics, the obstacle stream and
+// collisions
+func (m *model) step(now time.Time) {
+	m.half = false
+	m.steps++
+	if m.stepCling(now) {
+		m.raceReport()
+		return
+	}
+	defer m.stepZone(m.loopScore())
+	alt0 := m.gameRows - 2 - m.playerY
+	p0 := m.hitPlayer()
+	m.dist++
+	m.stepTimer(now)
+	if m.dist%milestoneEvery == 0 {
+		m.emit(eventMilestone, m.dist)
+	}
+
+	// physics
+	m.fall()
+	m.meetTerrain(alt0)
+	if !m.gameOver { // unless it ran into a wall
+		m.stepJumpAssist()
+	}
+
+	// move hazards that move, then forget those the camera has left behind
+	moved := m.advanceObstacles()
+
+	// extend the stream up to the spawn horizon
+	m.fillObstacles()
+	m.stepAcorns()
+
+	// collision
+	m.landed = m.groundRow() == m.playerY && alt0 > m.groundUnder()
+	p := m.hitPlayer()
+	var collected, smashed []obstacle
+	for _, c := range m.reached(moved, p0, p) {
+		ob := c.ob
+		m.meet(ob)
+		switch c.hit {
+		case miss:
+			if !hazardous(ob.kind) {
+				break
 			}
-		}
-		if furthest < m.gameCols-minGapCells-1 && rng.Float64() < 0.12 {
-			kind := "hole"
-			if rng.Float64() < 0.5 {
-				kind = "rock"
+			if ob.extent().hi == m.body().hi {
+				m.scoreClear(ob.kind) // its last cell is under the gopher
+				m.tele.clears++
+				if c.p.height <= nearMissHeight {
+					m.tele.close++
+				}
 			}
-			spawn := m.gameCols + rng.Intn(4)
-			m.obstacles = append(m.obstacles, obstacle{spawn, kind})
-		}
-
-		// collision
-		for _, ob := range m.obstacles {
-			if ob.x == 2 {
-				switch ob.typ {
-				case "hole":
-					if m.playerY >= m.gameRows-2 {
-						m.setGameOver()
-					}
-				case "rock":
-					if m.playerY == m.gameRows-2 {
-						m.setGameOver()
-					}
+			if c.p.height <= nearMissHeight {
+				m.scoreStyle()
+				m.foxCloser(ob.kind.Name())
+				if p.height != p0.height { // not at the top of the arc
+					m.tele.note(ob.kind.Name(), p.height < p0.height)
 				}
 			}
+		case fatal:
+			m.tele.note(ob.kind.Name(), p.height < p0.height) // came down on it
+			if m.shrugOff(ob) {
+				smashed = append(smashed, ob)
+				continue
+			}
+			m.logInfo("collision", "kind", ob.kind.Name(), "x", ob.x, "hit", "fatal")
+			m.setGameOver(ob.kind.Name())
+		case ledge:
+			m.tele.note(ob.kind.Name(), p.height < p0.height) // landed in it
+			m.logInfo("collision", "kind", ob.kind.Name(), "x", ob.x, "hit", "ledge", "coyote", m.coyote)
+			m.overLedge(ob.kind.Name())
+		case launch:
+			m.logDebug("springboard", "x", ob.x)
+			m.launch(springVel)
+		case boost:
+			m.logDebug("speed pad", "x", ob.x)
+			m.boostLeft = boostTicks
+		case pickup:
+			collected = append(collected, ob)
 		}
-
-		// accelerate
-		m.frameDur = time.Duration(float64(m.frameDur) * accelFactor)
-		return m, tickAfter(m.frameDur, m.tickGen)
 	}
-	return m, nil
+	for _, ob := range collected {
+		m.collectAcorn(ob)
+	}
+	if m.landed {
+		if m.cleared > 0 {
+			m.tele.useful++
+		}
+		m.scoreLanding()
+	}
+	for _, ob := range smashed {
+		m.removeObstacle(ob)
+		if m.spawn.big {
+			m.removeObstacle(obstacle{ob.x + 1, ob.kind}) // the rest of a big rock
+		}
+	}
+
+	m.stepBoost()
+	m.stepFox()
+	m.raceReport()
+
+	// accelerate
+	m.frameDur = m.capFrame(time.Duration(float64(m.frameDur) * m.character().Accel))
+	m.music.setSpeed(speedFactor(m.frameDur))
 }
 
-func (m *model) setGameOver() {
+func (m *model) setGameOver(cause string) {
+	if m.gameOver {
+		return // already dead; a second hazard on the same tick changes nothing
+	}
 	m.gameOver = true
-	m.restartAt = time.Now().Add(cooldownSeconds * time.Second)
-	if m.dist > m.highScore {
-		m.highScore = m.dist
-		saveHighScore(m.highScore)
+	m.logInfo("game over", "cause", cause, "jumps", m.jumps)
+	if m.ghost {
+		m.cause = cause // the opponent's own game keeps their records
+		return
+	}
+	if m.saver {
+		m.cause, m.restartAt = cause, m.now().Add(saverRestart)
+		return
+	}
+	if m.playback != nil {
+		m.cause = cause
+		return
+	}
+	m.restartAt = m.now().Add(m.cooldown())
+	m.recordDeath()
+	m.recordRun(cause)
+	if m.newRecord {
+		m.desktop("Gopher-Dash: new high score!", fmt.Sprintf("%d, beating %d", m.score(), m.prevBest))
+	}
+	if m.newRecord && m.decorative() {
+		m.particles = spawnConfetti(m.fw-2, gameOverRows)
+	}
+}
+
+// recordRun writes the current run to the history and saves a new high score
+func (m *model) recordRun(cause string) {
+	m.cause = cause
+	m.refreshShared() // compare against a best set in another window
+	m.prevBest = m.profile.HighScore
+	m.logRun(runRecord{
+		Distance: m.dist,
+		Bonus:    m.bonus,
+		Jumps:    m.jumps,
+		Cause:    cause,
+		Speed:    speedFactor(m.frameDur),
+		Mods:     m.mods,
+		At:       m.now(),
+	})
+	m.finishSplits()
+	m.sitting.tally(m.dist, m.score())
+	m.tallyRun(cause)
+	metrics.runEnded(m.dist)
+	// modified and capped runs keep boards of their own; runs with perks set
+	// no bests at all
+	perked := len(m.perks) > 0
+	ranked := !m.cfg.Practice && len(m.mods) == 0 && !perked && !m.capped() && m.ranksClassic()
+	if m.score() > m.profile.HighScore && ranked {
+		m.profile.HighScore = m.score()
+		m.newRecord = m.saveProfile()
+	}
+	m.awardSeasonal()
+	if ranked && m.session != 0 { // 0: an embedded game that never joined
+		records.publish(m.session, m.score())
+	}
+	if !m.cfg.Practice && !perked && m.cfg.Store != "memory" {
+		m.tablePlace, m.tableBest = m.recordScore(m.now())
+	}
+	if len(m.mods) > 0 && !m.cfg.Practice && !perked && !m.capped() && m.ranksClassic() {
+		m.prevModBest = m.profile.ModBests[modsKey(m.mods)]
+		if m.score() > m.prevModBest {
+			m.profile.ModBests = mergeBests(m.profile.ModBests, map[string]int{modsKey(m.mods): m.score()})
+			m.saveProfile()
+		}
+	}
+	if m.cfg.Weekly && !m.cfg.Practice && !perked && !m.capped() && m.ranksClassic() {
+		m.weeklyPlace = m.recordWeekly(m.now())
+	}
+	if m.cfg.Streak && !m.cfg.Practice && !m.racing() {
+		m.streakBeat = m.recordStreak(m.now())
+	}
+	if !m.racing() {
+		clearAutosave()
 	}
+	m.saveReplay()
+}
+
+// answerOffer handles the Y/N prompt for resuming a previous session's run
+func (m *model) answerOffer(key string) tea.Cmd {
+	switch key {
+	case "y", "enter":
+		m.restoreSnapshot(*m.offer)
+	case "n":
+		// keep the abandoned run in the history rather than losing it
+		m.logRun(runRecord{
+			Distance: m.offer.Dist,
+			Bonus:    m.offer.Bonus,
+			Jumps:    m.offer.Jumps,
+			Cause:    "quit",
+			Speed:    speedFactor(m.offer.FrameDur),
+			At:       m.offer.SavedAt,
+		})
+		if classicScoring(m.offer.Scoring) && m.offer.Dist+m.offer.Bonus > m.profile.HighScore {
+			m.profile.HighScore = m.offer.Dist + m.offer.Bonus
+			m.saveProfile()
+		}
+		clearAutosave()
+	default:
+		return nil
+	}
+	m.offer = nil
+	m.lastInput = m.now()
+	m.startIntro()
+	return nil
+}
+
+// flushRun persists a run that is still in progress, e.g. when quitting
+func (m *model) flushRun() {
+	if m.gameOver || m.dist == 0 || m.playback != nil {
+		return // finished runs are already on disk
+	}
+	m.recordRun("quit")
+	m.gameOver = true
 }
 
 // ----------------------------------------------------------------------------
@@ -306,6 +1282,7 @@ func (m *model) setGameOver() {
 
 // pad right to n runes (assumes width‑1 runes)
 func pad(s string, n int) string {
+	n = max(n, 0)
 	r := []rune(s)
 	if len(r) >= n {
 		return string(r[:n])
@@ -313,121 +1290,422 @@ func pad(s string, n int) string {
 	return s + strings.Repeat(" ", n-len(r))
 }
 
-// build grid when game is running
-func (m model) renderGame() string {
-	if m.gameRows == 0 || m.gameCols == 0 {
-		return ""
+// bestComparison reports how a run's distance compares to the previous best
+func bestComparison(dist, best int) string {
+	switch {
+	case dist > best:
+		return fmt.Sprintf("New best! +%d over %d", dist-best, best)
+	case dist == best:
+		return fmt.Sprintf("Matched your best of %d", best)
+	default:
+		return fmt.Sprintf("Best: %d (%d short)", best, best-dist)
 	}
+}
+
+// gameCells lays the playfield out as rows of two-column cells, overlays
+// and stamped text included; with half, each row has an extra cell for the
+// one scrolling in (see smooth.go)
+func (m model) gameCells(half bool) [][]string {
 	blank := "  "
+	cols := m.gameCols
+	if half {
+		cols++ // the cell scrolling in (see smooth.go)
+	}
 	rows := make([][]string, m.gameRows)
 	for i := range rows {
-		rows[i] = make([]string, m.gameCols)
+		rows[i] = make([]string, cols)
 		for j := range rows[i] {
 			rows[i][j] = blank
 		}
 	}
 
 	groundY := m.gameRows - 1
-	for x := 0; x < m.gameCols; x++ {
-		rows[groundY][x] = groundChar
+	cam := m.viewCamera()
+	for x := 0; x < cols; x++ {
+		h := m.terrainAt(cam.toWorld(x))
+		tile := m.groundTile()
+		if h >= wallHeight {
+			tile = wallChar
+		}
+		for y := max(groundY-h, 0); y <= groundY; y++ {
+			rows[y][x] = tile
+		}
 	}
+	m.decorate(rows)
 	for _, ob := range m.obstacles {
-		if ob.x < 0 || ob.x >= m.gameCols {
+		glyph, lift := m.sprite(ob.kind)
+		for w := ob.x; w <= ob.extent().hi; w++ {
+			x := cam.toScreen(w)
+			if x < 0 || x >= cols {
+				continue
+			}
+			for r := range ob.kind.Height() {
+				if y := groundY - m.terrainAt(w) - lift - r; y >= 0 {
+					rows[y][x] = glyph
+				}
+			}
+		}
+	}
+
+	for _, a := range m.acorns {
+		x := cam.toScreen(a.X)
+		if x < 0 || x >= cols {
 			continue
 		}
-		switch ob.typ {
-		case "hole":
-			rows[groundY][ob.x] = blank
-		case "rock":
-			if groundY-1 >= 0 {
-				rows[groundY-1][ob.x] = rockChar
-			}
+		if y := groundY - m.terrainAt(a.X) - 1; y >= 0 {
+			rows[y][x] = acornChar
+		}
+	}
+	if fx := m.foxColumn(); m.cfg.Fox && fx >= 0 && fx < m.gameCols {
+		if y := groundY - m.terrainAt(cam.toWorld(fx)) - 1; y >= 0 {
+			rows[y][fx] = foxChar
 		}
 	}
 
-	px, py := 2, m.playerY
-	if py >= 0 && py < m.gameRows && px < m.gameCols {
-		rows[py][px] = playerChar
+	px, py := playerCol, m.playerY
+	if py >= 0 && py < m.gameRows && px < m.gameCols && !half {
+		rows[py][px] = m.character().Sprite
+	}
+	if m.spawn.zones {
+		m.applyZones(rows, cam)
+	}
+	if m.dark() {
+		m.applyDarkness(rows)
+	}
+	if m.mod(modFog) {
+		m.applyFog(rows)
+	}
+	if m.hitboxes && !half {
+		m.applyHitboxes(rows)
 	}
 
-	lines := make([]string, m.gameRows)
-	for i, cells := range rows {
-		var b strings.Builder
-		for _, c := range cells {
-			b.WriteString(c)
-		}
-		lines[i] = b.String()
+	if m.photo() {
+		// nothing stamped over the shot
+	} else if m.paused {
+		stampText(rows, m.gameRows/2-1, "PAUSED")
+		stampText(rows, m.gameRows/2, m.pauseWhy)
+		stampText(rows, m.gameRows/2+1, "Press any key to resume")
+	} else if label := m.introLabel(); label != "" {
+		stampText(rows, m.gameRows/2-1, label)
+	} else if m.lockStalled() {
+		stampText(rows, m.gameRows/2-1, "Waiting for opponent…")
 	}
-	return strings.Join(lines, "\n")
+	return rows
 }
 
-func (m *model) seedInitialObstacles() {
-	// wipe any leftovers
-	m.obstacles = nil
-
-	safeUntil := 2 + initialSafeTiles // first 15 tiles after player
-	lastX := -minGapCells             // ensures first spawn passes gap check
+// build grid when game is running
+func (m model) renderGame() string {
+	if m.gameRows <= 0 || m.gameCols <= 0 {
+		return "" // not sized yet, or a state dump with a broken grid
+	}
+	half := m.halfShown()
+	rows := m.gameCells(half)
+	py := m.playerY
 
-	for x := safeUntil; x < m.gameCols; x++ {
-		if x-lastX < minGapCells { // keep spacing fair
+	sixel := m.sixelShown()
+	lines := make([]string, m.gameRows)
+	for i, cells := range rows {
+		if sixel {
+			lines[i] = sixelBlank(m.gameCols)
 			continue
 		}
-		if rng.Float64() < 0.12 { // same spawn probability
-			kind := "hole"
-			if rng.Float64() < 0.5 {
-				kind = "rock"
+		if half {
+			sprite := ""
+			if i == py {
+				sprite = m.character().Sprite
 			}
-			m.obstacles = append(m.obstacles, obstacle{x, kind})
-			lastX = x
+			lines[i] = halfStep(cells, sprite)
+			continue
+		}
+		if m.narrow() {
+			lines[i] = narrowRow(cells)
+			continue
 		}
+		var b strings.Builder
+		for _, c := range cells {
+			b.WriteString(m.gfx.cell(c))
+		}
+		lines[i] = b.String()
+	}
+	if sky := m.skyRows(); sky != nil && !sixel {
+		for i := range lines {
+			lines[i] = paintSky(lines[i], sky[i])
+		}
+	}
+	if sixel {
+		img := sixelEncode(m.sixelFrame(rows, half))
+		lines[len(lines)-1] += sixelOver(img, len(lines), m.gameCols)
+	}
+	if m.cfg.Practice {
+		lines = append([]string{m.renderRadar()}, lines...)
 	}
+	if m.showHeatmap() {
+		lines = append(lines, m.renderHeatmap())
+	}
+	return strings.Join(lines, "\n")
 }
 
 // ----------------------------------------------------------------------------
 // VIEW
 // ----------------------------------------------------------------------------
 
-func (m model) View() string {
+func (m model) view() string {
 	if m.w < 4 || m.h < 4 {
 		return "Resizing…"
 	}
-
-	border := lipgloss.NormalBorder()
+	if m.saver {
+		return m.saverView()
+	}
+	if m.letterboxed() {
+		return m.letterbox()
+	}
 
 	// top HUD
-	hud := lipgloss.NewStyle().Border(border).Width(m.w).
-		Align(lipgloss.Left).Render(pad(fmt.Sprintf("Distance: %d", m.dist), m.w-2))
+	status := fmt.Sprintf("Distance: %d   %s", m.dist, m.acornHUD())
+	if m.bonus > 0 {
+		status += fmt.Sprintf("   Bonus: +%d", m.bonus)
+		if m.boostLeft > 0 {
+			status += " ⚡"
+		}
+	}
+	if !m.ranksClassic() || m.spawn.zones {
+		status += "   " + m.scoreHUD()
+	}
+	if _, ok := m.cfg.fixedSeed(); ok {
+		status += fmt.Sprintf("   Seed: %d", m.seed)
+	}
+	if m.cfg.Timer {
+		status += "   " + m.timerHUD()
+	}
+	if m.cfg.Fox {
+		status += "   " + m.foxHUD()
+	}
+	if m.shields() > 0 {
+		status += "   " + m.shieldHUD()
+	}
+	if m.event != nil {
+		status += "   " + m.eventHUD()
+	}
+	if m.cfg.Streak && !m.cfg.Practice && !m.racing() {
+		status += "   " + m.streakHUD()
+	}
+	if m.cfg.Weekly {
+		status += "   " + m.weeklyHUD()
+	} else if len(m.mods) > 0 {
+		status += "   Mods: " + m.modsLabel()
+	}
+	if len(m.perks) > 0 {
+		status += "   Perks: " + m.perksLabel()
+	}
+	if m.capped() {
+		status += "   " + m.capHUD()
+	}
+	if m.racing() {
+		status += "   " + m.raceBar()
+	} else if m.bot != nil {
+		status += "   " + m.botBar()
+	}
+	if m.yesterday != nil {
+		status += "   " + m.yesterdayHUD()
+	}
+	if p := m.prestigeHUD(); p != "" {
+		status += "   " + p
+	}
+	if m.coopOn() {
+		status += "   " + m.coopHUD()
+	}
+	if m.inZone() {
+		status += "   " + m.zoneHUD()
+	}
+	if m.readout {
+		status += "   " + m.perfHUD()
+	}
+	if m.playback != nil {
+		status += "   " + m.replayHUD()
+	}
+	if n := m.hudNotice(); n != "" {
+		status += "   " + n
+	}
+	if b := m.bannerText(m.w - 2); b != "" && m.live() {
+		status = b // across the whole HUD while it's up
+	}
+	hud := m.hudBox(status)
 
 	var centerPane, ctrl string
 
-	if m.gameOver {
+	if m.leaving {
+		centerPane, ctrl = m.sessionView()
+	} else if m.showPerf {
+		msg := strings.Join(m.perfLines(), "\n")
+		inner := lipgloss.NewStyle().Padding(0, 1).
+			Height(m.gameRows).MaxHeight(m.gameRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsPerf)
+	} else if m.showMods {
+		msg := strings.Join(m.modsLines(), "\n")
+		inner := lipgloss.NewStyle().Padding(0, 1).
+			Height(m.gameRows).MaxHeight(m.gameRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsMods)
+	} else if m.showDex {
+		msg := strings.Join(m.dexLines(), "\n")
+		inner := lipgloss.NewStyle().Padding(0, 1).
+			Height(m.gameRows).MaxHeight(m.gameRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsDex)
+	} else if m.showAbout {
+		msg := strings.Join(m.aboutLines(), "\n")
+		inner := lipgloss.NewStyle().Padding(0, 1).
+			Height(m.gameRows).MaxHeight(m.gameRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsAbout)
+	} else if m.showStats {
+		lines := m.stats.statsLines(m.w - 4)
+		if m.syncNote != "" {
+			lines = append(lines, "", m.syncNote)
+		}
+		msg := strings.Join(lines, "\n")
+		inner := lipgloss.NewStyle().Padding(0, 1).
+			Height(m.gameRows).MaxHeight(m.gameRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsStats)
+	} else if m.crashNote != nil {
+		msg := strings.Join([]string{
+			"Gopher-Dash crashed last time – sorry!",
+			fmt.Sprintf("%s, %s", m.crashNote.Panic, m.crashNote.At.Format("Jan 2 15:04")),
+			"",
+			"A diagnostics bundle makes it much easier to fix.",
+		}, "\n")
+		inner := lipgloss.NewStyle().Align(lipgloss.Center).
+			Height(gameOverRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsCrash)
+	} else if m.offer != nil {
+		msg := strings.Join([]string{
+			"Resume previous run?",
+			fmt.Sprintf("Distance %d, saved %s", m.offer.Dist,
+				m.offer.SavedAt.Format("Jan 2 15:04")),
+		}, "\n")
+		inner := lipgloss.NewStyle().Align(lipgloss.Center).
+			Height(gameOverRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsResume)
+	} else if m.perkOffer != nil {
+		msg := strings.Join(m.perkLines(), "\n")
+		inner := lipgloss.NewStyle().Align(lipgloss.Center).
+			Height(gameOverRows).Width(m.w - 2).Render(msg)
+		centerPane = m.pane(inner)
+		ctrl = m.bar(controlsPerks)
+	} else if m.playback != nil {
+		centerPane = m.pane(m.renderGame())
+		ctrl = m.bar(controlsReplay)
+	} else if m.gameOver {
 		// remaining cooldown seconds (ceil)
-		countdown := max(int(math.Ceil(time.Until(m.restartAt).Seconds())), 0)
+		countdown := max(int(math.Ceil(m.restartAt.Sub(m.now()).Seconds())), 0)
 
+		title := "Game over!"
+		if m.newRecord {
+			title = bannerStyle.Render("★ NEW HIGH SCORE ★")
+		}
+		best := bestComparison(m.score(), m.prevBest)
+		if m.cfg.Practice {
+			best = fmt.Sprintf("Practice run (best stays %d)", m.profile.HighScore)
+		} else if len(m.perks) > 0 {
+			best = fmt.Sprintf("Run with perks (best stays %d)", m.profile.HighScore)
+		} else if !m.ranksClassic() || m.capped() {
+			best = m.tableLine()
+		} else if m.cfg.Weekly {
+			best = m.weeklyLine()
+		} else if len(m.mods) > 0 {
+			best = "Modified run: " + bestComparison(m.score(), m.prevModBest)
+		}
+		if m.verified {
+			best += "  ✓ verified"
+		}
+		jumps := fmt.Sprintf("Jumps: %d", m.jumps)
+		if m.coopOn() {
+			jumps = m.coopTally()
+		}
 		lines := []string{
-			"Game over!",
-			fmt.Sprintf("Distance: %d", m.dist),
-			fmt.Sprintf("High score: %d", m.highScore),
+			title,
+			causeOfDeath(m.cause, m.dist),
+			jumps + m.prestigeLine(),
+			best,
+			m.gradeLine(),
+		}
+		if m.coopOn() {
+			lines = slices.Insert(lines, 2, m.coopBlame())
+		}
+		if m.bot != nil && m.bot.result != "" {
+			lines = slices.Insert(lines, 1, m.botResult()...)
+		}
+		if m.yesterday != nil {
+			lines = slices.Insert(lines, slices.Index(lines, best)+1, m.yesterdayLine())
+		}
+		if m.cfg.Streak && !m.cfg.Practice {
+			lines = append(lines, m.streakLine())
 		}
-		if countdown > 0 {
+		lines = append(lines, fmt.Sprintf("Last %d: %s", sparklineRuns,
+			sparkline(recentDistances(m.history, sparklineRuns))))
+		if m.racing() {
+			lines = append(m.raceResult(), causeOfDeath(m.cause, m.dist))
+		} else if countdown > 0 {
 			lines = append(lines, fmt.Sprintf("You can go again in %d…", countdown))
+		} else if m.restartHold() > 0 {
+			lines = append(lines, "Hold Space to go again "+m.holdBar())
 		} else {
 			lines = append(lines, "Press Space to go again")
 		}
+		lines = append(lines, m.updateLine())
 		msg := strings.Join(lines, "\n")
 
 		inner := lipgloss.NewStyle().Align(lipgloss.Center).
-			Height(7).Width(m.w - 2).Render(msg)
-		centerPane = lipgloss.NewStyle().Border(border).Width(m.w).Render(inner)
+			Height(gameOverRows).Width(m.w - 2).Render(msg)
+		inner = overlayParticles(inner, m.particles)
+		centerPane = m.pane(inner)
+		if m.race.lockstep && !m.race.oppFinished() {
+			centerPane += "\n" + m.oppPane() // watch them finish
+		}
 
-		ctrl = lipgloss.NewStyle().Border(border).Width(m.w).
-			Align(lipgloss.Left).Render(pad(controlsGameOver, m.w-2))
+		ctrl = m.bar(controlsGameOver)
 	} else {
-		centerPane = lipgloss.NewStyle().Border(border).Width(m.w).
-			Render(m.renderGame())
-		ctrl = lipgloss.NewStyle().Border(border).Width(m.w).
-			Align(lipgloss.Left).Render(pad(controlsRunning, m.w-2))
+		centerPane = m.pane(m.renderGame())
+		if m.race.lockstep {
+			centerPane += "\n" + m.oppPane()
+		} else if m.bot != nil {
+			centerPane += "\n" + m.botPane()
+		}
+		if m.photo() && m.photoClean {
+			return centerPane
+		}
+		controls := m.keys().bar
+		if m.momentum() {
+			controls += "   " + m.keys().diveBar
+		}
+		if m.music != nil {
+			controls += "   N = music"
+		}
+		if m.photo() {
+			controls = controlsPhoto
+		}
+		if m.racing() {
+			controls += "   1-3 = emote   M = mute"
+		}
+		if m.cfg.Twitch != "" {
+			controls += "   │ " + m.chaosHUD()
+		}
+		ctrl = m.bar(controls)
 	}
 
+	if m.palette != nil {
+		if ctrl == "" {
+			hud = m.paletteLine() // the minimal layout's status line makes way
+		} else {
+			ctrl = m.bar(m.paletteLine())
+		}
+	}
+	if ctrl == "" {
+		return hud + "\n" + centerPane // minimal layout
+	}
 	return strings.Join([]string{hud, centerPane, ctrl}, "\n")
 }
@@ -0,0 +1,204 @@
+package gopherdash
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fuzzKeys are the key presses a fuzz input can pick from
+var fuzzKeys = []tea.KeyMsg{
+	{Type: tea.KeySpace},
+	{Type: tea.KeyRunes, Runes: []rune("w")},
+	{Type: tea.KeyRunes, Runes: []rune("s")},
+	{Type: tea.KeyRunes, Runes: []rune("y")},
+	{Type: tea.KeyRunes, Runes: []rune("n")},
+	{Type: tea.KeyRunes, Runes: []rune("x")},
+	{Type: tea.KeyRunes, Runes: []rune("d")},
+	{Type: tea.KeyRunes, Runes: []rune("f")},
+	{Type: tea.KeyRunes, Runes: []rune("m")},
+	{Type: tea.KeyRunes, Runes: []rune("p")},
+	{Type: tea.KeyRunes, Runes: []rune("h")},
+	{Type: tea.KeyRunes, Runes: []rune("a")},
+	{Type: tea.KeyRight},
+	{Type: tea.KeyEsc},
+	{Type: tea.KeyEnter},
+	{Type: tea.KeyDown},
+	{Type: tea.KeyCtrlZ},
+}
+
+// fuzzMsg decodes one message from the front of data: key presses, resizes
+// (including zero and negative sizes), ticks from the current, a past or a
+// future generation, focus changes and stray chaos messages. One op has no
+// message and instead lets the game-over cooldown run out, which a fuzz
+// run is too fast to reach otherwise.
+func fuzzMsg(m *model, data []byte) (tea.Msg, []byte) {
+	op, data := data[0], data[1:]
+	arg := func() int {
+		if len(data) == 0 {
+			return 0
+		}
+		b := int(int8(data[0]))
+		data = data[1:]
+		return b
+	}
+	switch op % 8 {
+	case 0, 1:
+		return fuzzKeys[uint(arg())%uint(len(fuzzKeys))], data
+	case 2:
+		return tea.WindowSizeMsg{Width: arg(), Height: arg()}, data
+	case 3, 4:
+		return tickMsg{m.tickGen, time.Now()}, data
+	case 5:
+		return tickMsg{m.tickGen + arg(), time.Now()}, data
+	case 6:
+		switch uint(arg()) % 4 {
+		case 0:
+			return tea.BlurMsg{}, data
+		case 1:
+			return tea.FocusMsg{}, data
+		case 2:
+			return tea.ResumeMsg{}, data
+		}
+		return chatVoteMsg{"speed"}, data
+	}
+	if arg()%2 == 0 {
+		return chaosRoundMsg{}, data
+	}
+	m.restartAt = time.Time{}
+	return nil, data
+}
+
+func FuzzUpdate(f *testing.F) {
+	f.Add([]byte{2, 80, 24, 3, 3, 3, 0, 0, 3, 3, 3, 3})
+	f.Add([]byte{2, 1, 1, 3, 0, 0, 2, 0, 0, 3})
+	f.Add([]byte{2, 0xff, 0xfe, 3, 5, 0xff, 5, 1, 3})
+	f.Add([]byte{2, 40, 10, 0, 9, 6, 2, 3, 6, 1, 3, 0, 2})
+	f.Add([]byte{2, 120, 4, 6, 0, 3, 6, 1, 3, 7, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cfg := defaultConfig()
+		cfg.Countdown = 0
+		cfg.RestartHold = 0
+		m := initialModel(cfg, MemoryStore())
+		m.offer = nil
+		for len(data) > 0 {
+			var msg tea.Msg
+			if msg, data = fuzzMsg(&m, data); msg == nil {
+				continue
+			}
+			next, _ := m.Update(msg)
+			m = next.(model)
+			_ = m.View()
+		}
+	})
+}
+
+func TestEmbed(t *testing.T) {
+	t.Cleanup(func() { dataDir = "" })
+	g, err := New(WithSize(30, 12), WithTheme("halloween"), WithDataDir(t.TempDir()),
+		WithArgs("-countdown", "0", "-store", "memory"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, _ := g.Update(tea.WindowSizeMsg{Width: 200, Height: 60}) // the host's window
+	if g = next.(Model); g.m.w != 30 || g.m.h != 12 {
+		t.Fatalf("embedded game resized to %dx%d with the host", g.m.w, g.m.h)
+	}
+	if lines := strings.Count(g.View(), "\n") + 1; lines != 12 {
+		t.Errorf("view is %d lines, want 12", lines)
+	}
+	_, cmd := g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if _, ok := cmd().(DoneMsg); !ok {
+		t.Error("quitting an embedded game should send DoneMsg, not end the host")
+	}
+	if _, err := New(WithTheme("no such theme")); err == nil {
+		t.Error("an unknown theme should be an error")
+	}
+}
+
+// TestEmbedStore gives two games a store each: each sees its own high
+// score, and a run ends up only in its own game's store
+func TestEmbedStore(t *testing.T) {
+	t.Cleanup(func() { dataDir = "" })
+	dir := t.TempDir()
+	var games [2]Model
+	for i := range games {
+		s := MemoryStore()
+		_ = saveProfile(s, profile{Version: profileVersion, HighScore: 10 * (i + 1)})
+		g, err := New(WithDataDir(dir), WithStore(s), WithArgs("-countdown", "0"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		games[i] = g
+	}
+	if a, b := games[0].m.profile.HighScore, games[1].m.profile.HighScore; a != 10 || b != 20 {
+		t.Fatalf("the games loaded bests %d and %d, want 10 and 20", a, b)
+	}
+	m := games[0].m
+	m.dist = 5
+	m.setGameOver("rock")
+	for _, save := range m.unsaved {
+		save()
+	}
+	if a, b := loadStats(games[0].m.saves).Runs, loadStats(games[1].m.saves).Runs; a != 1 || b != 0 {
+		t.Errorf("the stores hold %d and %d runs, want 1 and 0", a, b)
+	}
+}
+
+// TestServerSessions plays two embedded games joined in one process, as a
+// server hosting a game per connection would
+func TestServerSessions(t *testing.T) {
+	t.Cleanup(func() { dataDir = "" })
+	records = &recordBus{subs: map[int]func(tea.Msg){}}
+	metrics = &gameMetrics{latCounts: make([]int, len(latencyBuckets))}
+	var games [2]Model
+	var inbox [2]chan tea.Msg
+	for i := range games {
+		g, err := New(WithSize(40, 12), WithDataDir(t.TempDir()), WithArgs("-countdown", "0", "-store", "memory"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		inbox[i] = make(chan tea.Msg, 1)
+		var leave func()
+		if games[i], leave, err = g.Join(func(msg tea.Msg) { inbox[i] <- msg }); err != nil {
+			t.Fatal(err)
+		}
+		defer leave()
+	}
+
+	a := &games[0].m
+	a.dist = 500
+	a.setGameOver("rock")
+	select {
+	case msg := <-inbox[1]:
+		if rec, ok := msg.(recordMsg); !ok || rec.dist != 500 {
+			t.Errorf("the other session got %#v, want a record of 500", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the other session never heard of the record")
+	}
+	select {
+	case msg := <-inbox[0]:
+		t.Errorf("the session that set the record was told about it: %#v", msg)
+	default:
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.tickLatency(3 * time.Millisecond)
+	metrics.ServeHTTP(rec, nil)
+	for _, want := range []string{
+		"gopherdash_active_sessions 2\n",
+		"gopherdash_run_distance_sum 500\n",
+		"gopherdash_run_distance_count 1\n",
+		`gopherdash_tick_latency_seconds_bucket{le="0.002"} 0` + "\n",
+		`gopherdash_tick_latency_seconds_bucket{le="0.005"} 1` + "\n",
+		`gopherdash_tick_latency_seconds_bucket{le="+Inf"} 1` + "\n",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("/metrics is missing %q:\n%s", want, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gopherdash
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// METRICS (Prometheus text format)
+// ----------------------------------------------------------------------------
+
+// tick latency buckets, seconds
+var latencyBuckets = []float64{0.001, 0.002, 0.005, 0.01, 0.02, 0.05, 0.1, 0.25}
+
+// gameMetrics is shared by every session in the process; the model updates
+// it from Update while the HTTP handler reads it from its own goroutine
+type gameMetrics struct {
+	mu          sync.Mutex
+	sessions    int
+	runsStarted int
+	runCount    int
+	runSum      int   // total distance of finished runs
+	latCounts   []int // per bucket, non-cumulative
+	latCount    int
+	latSum      float64
+}
+
+var metrics = &gameMetrics{latCounts: make([]int, len(latencyBuckets))}
+
+func (g *gameMetrics) sessionStarted() { g.mu.Lock(); g.sessions++; g.mu.Unlock() }
+func (g *gameMetrics) sessionEnded()   { g.mu.Lock(); g.sessions--; g.mu.Unlock() }
+func (g *gameMetrics) runStarted()     { g.mu.Lock(); g.runsStarted++; g.mu.Unlock() }
+
+func (g *gameMetrics) runEnded(dist int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.runCount++
+	g.runSum += dist
+}
+
+// tickLatency records how late a tick was handled after it fired
+func (g *gameMetrics) tickLatency(d time.Duration) {
+	s := d.Seconds()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, b := range latencyBuckets {
+		if s <= b {
+			g.latCounts[i]++
+			break
+		}
+	}
+	g.latCount++
+	g.latSum += s
+}
+
+// ServeHTTP writes every metric in the Prometheus text exposition format
+func (g *gameMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP gopherdash_active_sessions Games currently being played.\n")
+	fmt.Fprintf(&b, "# TYPE gopherdash_active_sessions gauge\n")
+	fmt.Fprintf(&b, "gopherdash_active_sessions %d\n", g.sessions)
+	fmt.Fprintf(&b, "# HELP gopherdash_runs_started_total Runs started.\n")
+	fmt.Fprintf(&b, "# TYPE gopherdash_runs_started_total counter\n")
+	fmt.Fprintf(&b, "gopherdash_runs_started_total %d\n", g.runsStarted)
+	fmt.Fprintf(&b, "# HELP gopherdash_run_distance Distance of finished runs.\n")
+	fmt.Fprintf(&b, "# TYPE gopherdash_run_distance summary\n")
+	fmt.Fprintf(&b, "gopherdash_run_distance_sum %d\n", g.runSum)
+	fmt.Fprintf(&b, "gopherdash_run_distance_count %d\n", g.runCount)
+	fmt.Fprintf(&b, "# HELP gopherdash_tick_latency_seconds Delay between a tick firing and Update handling it.\n")
+	fmt.Fprintf(&b, "# TYPE gopherdash_tick_latency_seconds histogram\n")
+	cum := 0
+	for i, le := range latencyBuckets {
+		cum += g.latCounts[i]
+		fmt.Fprintf(&b, "gopherdash_tick_latency_seconds_bucket{le=\"%g\"} %d\n", le, cum)
+	}
+	fmt.Fprintf(&b, "gopherdash_tick_latency_seconds_bucket{le=\"+Inf\"} %d\n", g.latCount)
+	fmt.Fprintf(&b, "gopherdash_tick_latency_seconds_sum %g\n", g.latSum)
+	fmt.Fprintf(&b, "gopherdash_tick_latency_seconds_count %d\n", g.latCount)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// metricsServed is the address /metrics is already served on, if any
+var (
+	metricsMu     sync.Mutex
+	metricsServed string
+)
+
+// serveMetrics exposes /metrics on addr in the background, once per
+// process however many games ask for it
+func serveMetrics(addr string) error {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if metricsServed != "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() { _ = srv.Serve(ln) }()
+	metricsServed = addr
+	return nil
+}
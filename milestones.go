@@ -0,0 +1,91 @@
+package gopherdash
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// DISTANCE MILESTONES
+// ----------------------------------------------------------------------------
+
+// Every milestoneEvery of distance a banner flashes across the HUD, the
+// music plays a fanfare and rules with a milestone bonus (arcade's, say)
+// add it to the score; reaching the distances in distanceAchievements
+// unlocks an achievement for good. They all hang off eventMilestone on the
+// run's event bus (see bus.go).
+
+const (
+	milestoneEvery = 100
+	bannerFor      = 1500 * time.Millisecond // how long a banner stays up
+	bannerFrame    = 150 * time.Millisecond  // how long each colour of its flash lasts
+)
+
+// bannerColours are the colours a banner flashes through
+var bannerColours = []lipgloss.Color{"11", "214", "201", "51", "46"}
+
+// distanceAchievements are earned the first time a run gets this far
+var distanceAchievements = []struct {
+	dist int
+	name string
+}{
+	{500, "Half a klick"},
+	{1000, "Kilometre gopher"},
+	{2500, "Ultra gopher"},
+}
+
+// milestoneBanner puts the milestone's banner up
+func (m *model) milestoneBanner(dist int) {
+	m.banner, m.bannerAt = fmt.Sprintf("%dm! 🎉", dist), m.now()
+}
+
+// milestoneBonus scores the rules' bonus for a milestone
+func (m *model) milestoneBonus(int) {
+	m.points += m.scoring().Milestone
+}
+
+// milestoneFanfare cues the music's fanfare
+func (m *model) milestoneFanfare(int) {
+	m.music.fanfare()
+}
+
+// milestoneAchievement unlocks an achievement for reaching its distance
+func (m *model) milestoneAchievement(dist int) {
+	if m.cfg.Practice || m.ghost || m.saver || m.playback != nil {
+		return // runs that save nothing earn nothing
+	}
+	for _, a := range distanceAchievements {
+		if a.dist != dist {
+			continue
+		}
+		if _, ok := m.profile.Achievements[a.name]; ok {
+			return
+		}
+		if m.profile.Achievements == nil {
+			m.profile.Achievements = map[string]string{}
+		}
+		m.profile.Achievements[a.name] = m.now().Format(time.DateOnly)
+		m.saveProfile()
+		m.notify("🏅 Achievement: " + a.name)
+		m.desktop("Gopher-Dash: achievement unlocked", fmt.Sprintf("%s (%d distance)", a.name, dist))
+		m.logInfo("achievement", "name", a.name, "dist", dist)
+	}
+}
+
+// bannerText is the banner while it's up, in this frame's colour, centred
+// in width; "" once it's gone
+func (m model) bannerText(width int) string {
+	since := m.now().Sub(m.bannerAt)
+	if m.banner == "" || since > bannerFor {
+		return ""
+	}
+	style := lipgloss.NewStyle().Bold(true).Foreground(bannerColours[0])
+	if m.decorative() {
+		style = style.Foreground(bannerColours[int(since/bannerFrame)%len(bannerColours)])
+	}
+	text := "✦ " + m.banner + " ✦"
+	left := max((width-lipgloss.Width(text))/2, 0)
+	return fmt.Sprintf("%*s%s", left, "", style.Render(text))
+}
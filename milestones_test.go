@@ -0,0 +1,60 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMilestoneBanner steps over a milestone and watches the banner come
+// and go across the HUD
+func TestMilestoneBanner(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, c := clockedModel(t, cfg)
+	m.dist = milestoneEvery - 2
+	m.fillObstacles()
+	clearHazards(&m)
+	m.step(m.now())
+	if m.banner != "" {
+		t.Fatalf("banner %q short of the milestone", m.banner)
+	}
+	m.step(m.now())
+	if !strings.Contains(m.bannerText(m.w), "100m! 🎉") {
+		t.Fatalf("no banner at %d: %q", m.dist, m.bannerText(m.w))
+	}
+	if !strings.Contains(m.View(), "100m!") {
+		t.Error("the banner isn't in the HUD")
+	}
+	if m.points != 0 {
+		t.Errorf("classic rules scored %d for a milestone", m.points)
+	}
+	c.t = c.t.Add(bannerFor + time.Millisecond)
+	if got := m.bannerText(m.w); got != "" {
+		t.Errorf("banner still up: %q", got)
+	}
+}
+
+func TestMilestoneRewards(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Scoring = "arcade"
+	m, _ := clockedModel(t, cfg)
+	m.emit(eventMilestone, 200)
+	if want := defaultScoring["arcade"].Milestone; m.points != want {
+		t.Errorf("arcade scored %d for a milestone, want %d", m.points, want)
+	}
+	m.emit(eventMilestone, 500)
+	if _, ok := m.profile.Achievements["Half a klick"]; !ok {
+		t.Errorf("no achievement at 500: %v", m.profile.Achievements)
+	}
+	if !strings.Contains(m.hudNotice(), "Half a klick") {
+		t.Errorf("notice %q", m.hudNotice())
+	}
+
+	m, _ = clockedModel(t, cfg)
+	m.cfg.Practice = true
+	m.emit(eventMilestone, 1000)
+	if len(m.profile.Achievements) != 0 {
+		t.Errorf("practice earned %v", m.profile.Achievements)
+	}
+}
@@ -0,0 +1,227 @@
+package gopherdash
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// RUN MODIFIERS
+// ----------------------------------------------------------------------------
+
+// Modifiers change the rules of a run. A run's modifiers are fixed when it
+// starts and saved with it; scores from modified runs don't count towards
+// the high score, since they aren't comparable with plain runs, but each
+// combination keeps a best of its own in the profile.
+const (
+	modLowGravity  = "low-gravity"
+	modFog         = "fog"
+	modDoubleSpeed = "double-speed"
+	modMirror      = "mirror"
+	modTiny        = "tiny"
+	modBig         = "big-obstacles"
+	modNoCooldown  = "no-cooldown"
+	modShield      = "shield"
+
+	lowGravity = 11   // momentum gravity per row of class gravity; normally momentumGravity
+	fogReach   = 12   // cells ahead of the gopher the fog lets through
+	fogChar    = "░░" // fogged-over ground
+)
+
+// modifier is one entry in the registry
+type modifier struct {
+	name   string // saved with runs, e.g. "fog"
+	label  string // shown in the HUD
+	about  string // shown in the modifiers menu
+	weekly bool   // part of the weekly challenge's rotation
+}
+
+// modifiers is the registry. The weekly rotation is drawn from the weekly
+// entries in this order, so reordering those changes every week's set.
+var modifiers = []modifier{
+	{modLowGravity, "Low gravity", "floatier jumps that clear more ground", true},
+	{modFog, "Fog", "nothing is visible far ahead", true},
+	{modDoubleSpeed, "Double speed", "the world scrolls twice as fast", true},
+	{modMirror, "Mirror", "S/↓ jumps and W/Space dives", false},
+	{modTiny, "Tiny", "a gopher small enough to land on rocks", false},
+	{modBig, "Big obstacles", "rocks come two cells wide", false},
+	{modNoCooldown, "No cooldown", "go again straight after a crash", false},
+	{modShield, "Shield", "survive one hit", false},
+}
+
+// mod reports whether the run has modifier name
+func (m model) mod(name string) bool { return slices.Contains(m.mods, name) }
+
+// validMods checks names against the registry
+func validMods(names []string) error {
+	for _, name := range names {
+		if !slices.ContainsFunc(modifiers, func(md modifier) bool { return md.name == name }) {
+			return fmt.Errorf("unknown modifier %q (want %s)", name, modNames())
+		}
+	}
+	return nil
+}
+
+// modNames lists the registry for error messages and -help
+func modNames() string {
+	names := make([]string, len(modifiers))
+	for i, md := range modifiers {
+		names[i] = md.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// runMods collects the modifiers the next run starts with: those asked for,
+// plus this week's if it's the weekly challenge
+func (c config) runMods() []string {
+	mods := slices.Clone(c.Mods)
+	if c.Weekly {
+		mods = append(mods, weeklyMods(time.Now())...)
+	}
+	slices.Sort(mods)
+	return slices.Compact(mods)
+}
+
+// modsLabel lists the run's modifiers for the HUD
+func (m model) modsLabel() string {
+	var labels []string
+	for _, md := range modifiers {
+		if m.mod(md.name) {
+			labels = append(labels, md.label)
+		}
+	}
+	return strings.Join(labels, " + ")
+}
+
+// modsKey names a combination of modifiers in the profile's bests
+func modsKey(mods []string) string { return strings.Join(mods, "+") }
+
+// fitSpawner tells the obstacle stream about the run's jump and modifiers;
+// it's needed whenever the spawner is rebuilt
+func (m *model) fitSpawner() {
+	m.spawn.arc = m.jumpArc()
+	m.spawn.air = len(m.spawn.arc)
+	m.spawn.big = m.mod(modBig)
+	m.spawn.zones = m.cfg.Zones
+	m.spawn.chance = prestigeChance(m.loop)
+}
+
+// slim reports whether the gopher gets the smaller hitbox
+func (m model) slim() bool { return m.character().Slim || m.mod(modTiny) }
+
+// shields is how many hits the gopher can take this run
+func (m model) shields() int {
+	n := m.character().Shields + m.perk(perkShield)
+	if m.mod(modShield) {
+		return n + 1
+	}
+	return n
+}
+
+// mirrored reports whether jump and dive are swapped, by the mirror
+// modifier or by chat; both at once cancel out
+func (m model) mirrored() bool {
+	return m.mod(modMirror) != m.chaos.on("invert", m.now()) && !m.gameOver
+}
+
+// modsLines is the modifiers menu, opened with M on the game-over screen.
+// Changes apply from the next run.
+func (m model) modsLines() []string {
+	lines := []string{"Modifiers for the next run", ""}
+	for i, md := range modifiers {
+		mark := "  "
+		if i == m.modRow {
+			mark = "▸ "
+		}
+		box := "[ ]"
+		if slices.Contains(m.cfg.Mods, md.name) {
+			box = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %-14s %s", mark, box, md.label, md.about))
+	}
+	lines = append(lines, "")
+	if m.cfg.Weekly {
+		lines = append(lines, "These go on top of the weekly challenge's own")
+	}
+	return append(lines, "Modified runs keep a best of their own, apart from your high score")
+}
+
+// modsMenuKey handles keys in the modifiers menu
+func (m *model) modsMenuKey(key string) {
+	switch key {
+	case "up":
+		m.modRow = (m.modRow + len(modifiers) - 1) % len(modifiers)
+	case "down":
+		m.modRow = (m.modRow + 1) % len(modifiers)
+	case " ", "enter":
+		name := modifiers[m.modRow].name
+		if i := slices.Index(m.cfg.Mods, name); i >= 0 {
+			m.cfg.Mods = slices.Delete(m.cfg.Mods, i, i+1)
+		} else {
+			m.cfg.Mods = append(m.cfg.Mods, name)
+		}
+		m.logInfo("modifiers", "mods", m.cfg.runMods())
+	case "m", "esc":
+		m.showMods = false
+	}
+}
+
+// arc is the class's take-off speed, gravity and fastest fall in the units
+// of the physics in use: rows for classic, fixedOne parts of a row for
+// momentum
+func (m model) arc() (lift, g, terminal int) {
+	c := m.character()
+	if !m.momentum() {
+		return c.JumpVel, c.Gravity, math.MaxInt
+	}
+	grav := momentumGravity
+	if m.mod(modLowGravity) {
+		grav = lowGravity
+	}
+	return c.JumpVel * momentumLift, c.Gravity * grav, c.Gravity * momentumTerminal
+}
+
+// hangTime is how many steps a jump keeps the gopher off the ground
+func (m model) hangTime() int { return len(m.jumpArc()) }
+
+// jumpArc is how high a jump from level ground takes the gopher, in rows,
+// on each step it's in the air
+func (m model) jumpArc() []int {
+	lift, g, terminal := m.arc()
+	unit := 1
+	if m.momentum() {
+		unit = fixedOne
+	}
+	return jumpArc(lift, g, terminal, unit)
+}
+
+func jumpArc(lift, g, terminal, unit int) []int {
+	var rows []int
+	for v, pos := lift, 0; ; {
+		v = min(v+g, terminal)
+		if pos += v; pos >= 0 {
+			return rows
+		}
+		q, _ := floorDiv(pos, unit)
+		rows = append(rows, -q)
+	}
+}
+
+// applyFog hides everything more than fogReach cells ahead of the gopher,
+// holes included
+func (m model) applyFog(rows [][]string) {
+	ground := nightStyle.Render(fogChar)
+	groundY := len(rows) - 1
+	for y, cells := range rows {
+		for x := playerCol + fogReach + 1; x < len(cells); x++ {
+			if y == groundY {
+				cells[x] = ground
+			} else {
+				cells[x] = "  "
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package gopherdash
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os/exec"
+	"sync/atomic"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// BACKGROUND MUSIC (-music)
+// ----------------------------------------------------------------------------
+
+// The music is a chiptune loop written as MIDI note numbers and played as
+// square-wave beeps: a tone sequencer renders it a step at a time to 8-bit
+// PCM and streams that to the system's audio player (paplay on PulseAudio
+// and PipeWire, aplay on ALSA), which paces it in real time. The loop
+// speeds up with the game, and past musicBass and musicSparkle a bass line
+// and an octave above the tune join in. A distance milestone cues a
+// fanfare, played ahead of the loop's next step. N mutes it.
+
+const (
+	musicRate    = 22050 // samples a second, mono, unsigned 8-bit
+	musicStep    = 150 * time.Millisecond
+	musicBass    = 1.5 // speed the bass line comes in at
+	musicSparkle = 2.0 // speed the octave above comes in at
+	musicTempo   = 2.5 // fastest the loop gets, as a multiple of its tempo
+	musicVolume  = 24  // amplitude of one voice, out of 127
+)
+
+// tuneStep is one step of the loop: a note for the tune and one for the
+// bass, as MIDI note numbers; 0 rests
+type tuneStep struct{ lead, bass int }
+
+// tune is the loop: C, D minor, C again and a G turnaround
+var tune = []tuneStep{
+	{72, 48}, {76, 0}, {79, 48}, {76, 0},
+	{74, 50}, {77, 0}, {81, 50}, {77, 0},
+	{72, 48}, {76, 0}, {79, 48}, {84, 0},
+	{83, 43}, {79, 0}, {74, 43}, {0, 0},
+}
+
+// fanfare is the milestone flourish: a quick arpeggio up to the top C
+var fanfare = []tuneStep{{72, 48}, {76, 48}, {79, 48}, {84, 48}}
+
+// musicPlayers are the players tried in turn, reading raw PCM on stdin
+var musicPlayers = [][]string{
+	{"paplay", "--raw", "--rate=22050", "--channels=1", "--format=u8"},
+	{"aplay", "-q", "-t", "raw", "-r", "22050", "-c", "1", "-f", "U8"},
+}
+
+// noteFreq is the frequency of MIDI note n in Hz
+func noteFreq(n int) float64 { return 440 * math.Pow(2, float64(n-69)/12) }
+
+// renderStep renders step s of the loop at speed (a multiple of the start
+// speed): shorter steps the faster the game, with more voices
+func renderStep(s tuneStep, speed float64, muted bool) []byte {
+	speed = min(max(speed, 1), musicTempo)
+	buf := make([]byte, int(musicRate*musicStep.Seconds()/speed))
+	var voices []float64
+	if s.lead != 0 {
+		voices = append(voices, noteFreq(s.lead))
+		if speed >= musicSparkle {
+			voices = append(voices, noteFreq(s.lead+12))
+		}
+	}
+	if s.bass != 0 && speed >= musicBass {
+		voices = append(voices, noteFreq(s.bass))
+	}
+	beep := len(buf) * 4 / 5 // a gap after each note keeps them apart
+	for i := range buf {
+		level := 128
+		if !muted && i < beep {
+			for _, f := range voices {
+				if int(float64(i)*f*2/musicRate)%2 == 0 {
+					level += musicVolume
+				} else {
+					level -= musicVolume
+				}
+			}
+		}
+		buf[i] = byte(min(max(level, 0), 255))
+	}
+	return buf
+}
+
+// musicBox is the loop playing in the background
+type musicBox struct {
+	cmd   *exec.Cmd
+	in    io.WriteCloser
+	speed atomic.Int64 // game speed in hundredths
+	muted atomic.Bool
+	cued  atomic.Bool // the fanfare's due
+}
+
+// startMusic starts the first player found and the loop feeding it
+func startMusic() (*musicBox, error) {
+	for _, p := range musicPlayers {
+		path, err := exec.LookPath(p[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, p[1:]...)
+		in, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		b := &musicBox{cmd: cmd, in: in}
+		b.speed.Store(100)
+		go b.play()
+		return b, nil
+	}
+	return nil, errors.New("no audio player (paplay or aplay) found")
+}
+
+// play feeds the player step by step until it goes away
+func (b *musicBox) play() {
+	for i := 0; ; i = (i + 1) % len(tune) {
+		if b.cued.Swap(false) {
+			for _, s := range fanfare {
+				if _, err := b.in.Write(renderStep(s, musicTempo, b.muted.Load())); err != nil {
+					return
+				}
+			}
+		}
+		speed := float64(b.speed.Load()) / 100
+		if _, err := b.in.Write(renderStep(tune[i], speed, b.muted.Load())); err != nil {
+			return
+		}
+	}
+}
+
+// stop silences the music for good
+func (b *musicBox) stop() {
+	if b == nil {
+		return
+	}
+	b.in.Close()
+	_ = b.cmd.Process.Kill()
+	_ = b.cmd.Wait()
+}
+
+// setSpeed has the loop keep up with the game
+func (b *musicBox) setSpeed(speed float64) {
+	if b != nil {
+		b.speed.Store(int64(speed * 100))
+	}
+}
+
+// fanfare cues the milestone fanfare
+func (b *musicBox) fanfare() {
+	if b != nil {
+		b.cued.Store(true)
+	}
+}
+
+// toggle mutes or unmutes the music and reports whether it's now on
+func (b *musicBox) toggle() bool {
+	if b == nil {
+		return false
+	}
+	muted := !b.muted.Load()
+	b.muted.Store(muted)
+	return !muted
+}
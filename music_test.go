@@ -0,0 +1,65 @@
+package gopherdash
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"testing"
+)
+
+// voices counts the square waves in a rendered step from how far it
+// swings: each adds musicVolume either way
+func voices(buf []byte) int {
+	return (int(slices.Max(buf)) - int(slices.Min(buf))) / (2 * musicVolume)
+}
+
+func TestRenderStep(t *testing.T) {
+	if f := noteFreq(69); f != 440 {
+		t.Errorf("A4 is %v Hz", f)
+	}
+	if f := noteFreq(81); math.Abs(f-880) > 1e-9 {
+		t.Errorf("A5 is %v Hz", f)
+	}
+
+	step := tuneStep{lead: 72, bass: 48}
+	for _, tc := range []struct {
+		speed  float64
+		voices int
+	}{
+		{1, 1},   // the tune alone
+		{1.5, 2}, // and the bass
+		{2, 3},   // and an octave above
+	} {
+		buf := renderStep(step, tc.speed, false)
+		if want := int(musicRate * musicStep.Seconds() / tc.speed); len(buf) != want {
+			t.Errorf("speed %v: %d samples, want %d", tc.speed, len(buf), want)
+		}
+		if got := voices(buf); got != tc.voices {
+			t.Errorf("speed %v: %d voices, want %d", tc.speed, got, tc.voices)
+		}
+		if buf[len(buf)-1] != 128 {
+			t.Errorf("speed %v: no gap after the note", tc.speed)
+		}
+	}
+	if fast, top := renderStep(step, 9, false), renderStep(step, musicTempo, false); len(fast) != len(top) {
+		t.Errorf("the loop outran its top tempo: %d samples a step, want %d", len(fast), len(top))
+	}
+	if buf := renderStep(step, 1, true); !bytes.Equal(buf, bytes.Repeat([]byte{128}, len(buf))) {
+		t.Error("muted, but not silent")
+	}
+}
+
+func TestMusicWithoutPlayer(t *testing.T) {
+	saved := musicPlayers
+	musicPlayers = [][]string{{"gopherdash-no-such-player"}}
+	t.Cleanup(func() { musicPlayers = saved })
+	box, err := startMusic()
+	if err == nil || box != nil {
+		t.Fatalf("started music without a player: %v", err)
+	}
+	box.setSpeed(2) // a game without music carries on regardless
+	if box.toggle() {
+		t.Error("no music, but the toggle turned it on")
+	}
+	box.stop()
+}
@@ -0,0 +1,63 @@
+package gopherdash
+
+import "github.com/charmbracelet/lipgloss"
+
+// ----------------------------------------------------------------------------
+// NIGHT (flashlight & lightning)
+// ----------------------------------------------------------------------------
+
+const (
+	flashReach      = 10        // lit cells ahead of the gopher
+	lightningChance = 1.0 / 150 // per tick, while it's dark
+	nightGroundChar = "▁▁"      // unlit ground: holes can't be told apart
+)
+
+// nightStyle draws the unlit parts of the playfield
+var nightStyle = lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color("238"))
+
+// night reports whether the playfield is dark, either for the whole run
+// (-night) or for a chat-voted stretch of it
+func (m model) night() bool {
+	return m.cfg.Night || m.chaos.on("night", m.now())
+}
+
+// dark reports whether this frame is drawn by flashlight: the run is still
+// going and no lightning is lighting everything up
+func (m model) dark() bool {
+	return m.night() && !m.gameOver && !m.lightning
+}
+
+// stepLightning decides whether the next frame is a lightning flash
+func (m *model) stepLightning() {
+	m.lightning = m.night() && rng.Float64() < lightningChance
+}
+
+// lit reports whether playfield cell (x, y) is inside the flashlight's cone,
+// which widens by a row either way every three cells ahead of the gopher
+func (m model) lit(x, y int) bool {
+	dx := x - playerCol
+	if dx < 0 || dx > flashReach {
+		return false
+	}
+	dy := y - m.playerY
+	return max(dy, -dy) <= dx/3+1
+}
+
+// applyDarkness blanks every cell outside the cone, styling them one by one;
+// the gopher itself always shows
+func (m model) applyDarkness(rows [][]string) {
+	ground := nightStyle.Render(nightGroundChar)
+	groundY := len(rows) - 1
+	for y, cells := range rows {
+		for x := range cells {
+			if m.lit(x, y) || (x == playerCol && y == m.playerY) {
+				continue
+			}
+			if y == groundY {
+				cells[x] = ground
+			} else {
+				cells[x] = "  "
+			}
+		}
+	}
+}
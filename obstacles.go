@@ -0,0 +1,157 @@
+package gopherdash
+
+import "fmt"
+
+// ----------------------------------------------------------------------------
+// OBSTACLE KINDS
+// ----------------------------------------------------------------------------
+
+// obstacle in the world grid
+type obstacle struct {
+	x    int // world cell (emoji = 2 columns); see camera for screen mapping
+	kind ObstacleKind
+}
+
+// player is what a hazard gets to see of the gopher when it reaches it
+type player struct {
+	height  int  // rows above the running line; 0 = on the ground
+	landing bool // touched down this step
+	slim    bool // smaller hitbox (see classes.go)
+}
+
+// hitPlayer is the gopher as hazards see it now; landing is m.landed, set
+// by the last step
+func (m model) hitPlayer() player {
+	return player{height: m.groundRow() - m.playerY, landing: m.landed, slim: m.slim()}
+}
+
+// hit is the outcome of the gopher meeting a hazard
+type hit int
+
+const (
+	miss   hit = iota
+	fatal      // the run ends
+	ledge      // the run ends unless the gopher jumps within the coyote window
+	launch     // a springboard throws the gopher into a high jump
+	boost      // a speed pad starts a score boost
+	pickup     // the gopher collects it
+)
+
+// ObstacleKind is the behaviour of one type of hazard. Adding a hazard means
+// implementing it and listing it in obstacleKinds; the renderer, radar,
+// collisions, saves and death messages all go through it.
+type ObstacleKind interface {
+	Name() string                     // saved in autosaves and stats, e.g. "rock"
+	Sprite() (glyph string, lift int) // lift 0 replaces the ground tile, 1 sits on it
+	Radar() string                    // glyph on the practice radar
+	Width() int                       // world cells it covers from its own on, 1 to 3
+	Height() int                      // rows it stands from its lift up, all of which a jump must clear
+	Collides(p player) hit
+	Advance(x int) int     // world cell after one tick; static hazards return x
+	Weight() float64       // share of random spawns in the meadow (see biomes.go); 0 = placed only by events
+	Death(dist int) string // game-over line, e.g. "Tripped on a rock at 312"
+}
+
+// obstacleKinds is the registry. Spawns are drawn by their biome's weights
+// in this order, so reordering it changes every seeded course.
+var obstacleKinds = []ObstacleKind{rock{}, hole{}, fallenLog{}, wideHole{}, rockStack{2}, rockStack{3}}
+
+// kindByName looks a registered kind or a ground tile up by its saved name
+func kindByName(name string) (ObstacleKind, bool) {
+	for _, k := range append(obstacleKinds, tileKinds...) {
+		if k.Name() == name {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// rock sits on the running line and has to be jumped
+type rock struct{}
+
+func (rock) Name() string          { return "rock" }
+func (rock) Sprite() (string, int) { return rockChar, 1 }
+func (rock) Radar() string         { return "▴ " }
+func (rock) Width() int            { return 1 }
+func (rock) Height() int           { return 1 }
+func (rock) Advance(x int) int     { return x }
+func (rock) Weight() float64       { return 0.5 }
+func (rock) Death(dist int) string { return fmt.Sprintf("Tripped on a rock at %d", dist) }
+func (rock) Collides(p player) hit {
+	if p.height == 0 && !(p.slim && p.landing) {
+		return fatal
+	}
+	return miss
+}
+
+// hole is a missing ground tile; running onto it starts the coyote window
+type hole struct{}
+
+func (hole) Name() string          { return "hole" }
+func (hole) Sprite() (string, int) { return "  ", 0 }
+func (hole) Radar() string         { return "▿ " }
+func (hole) Width() int            { return 1 }
+func (hole) Height() int           { return 1 }
+func (hole) Advance(x int) int     { return x }
+func (hole) Weight() float64       { return 0.5 }
+func (hole) Death(dist int) string { return fmt.Sprintf("Fell into a hole at %d", dist) }
+func (hole) Collides(p player) hit {
+	if p.height <= 0 {
+		return ledge
+	}
+	return miss
+}
+
+// fallenLog lies across three cells of the running line; the whole jump has
+// to clear it
+type fallenLog struct{}
+
+func (fallenLog) Name() string          { return "log" }
+func (fallenLog) Sprite() (string, int) { return logChar, 1 }
+func (fallenLog) Radar() string         { return "▬ " }
+func (fallenLog) Width() int            { return 3 }
+func (fallenLog) Height() int           { return 1 }
+func (fallenLog) Advance(x int) int     { return x }
+func (fallenLog) Weight() float64       { return 0.1 }
+func (fallenLog) Death(dist int) string { return fmt.Sprintf("Tripped over a log at %d", dist) }
+func (fallenLog) Collides(p player) hit { return rock{}.Collides(p) }
+
+// wideHole is two missing ground tiles; coyote time still saves a late jump
+// at its near edge
+type wideHole struct{}
+
+func (wideHole) Name() string          { return "wide hole" }
+func (wideHole) Sprite() (string, int) { return "  ", 0 }
+func (wideHole) Radar() string         { return "▿ " }
+func (wideHole) Width() int            { return 2 }
+func (wideHole) Height() int           { return 1 }
+func (wideHole) Advance(x int) int     { return x }
+func (wideHole) Weight() float64       { return 0.1 }
+func (wideHole) Death(dist int) string { return fmt.Sprintf("Fell into a wide hole at %d", dist) }
+func (wideHole) Collides(p player) hit { return hole{}.Collides(p) }
+
+// rockStack is rocks piled high rows high; only the top of a jump clears a
+// tall one, and the stream leaves them out when the run's jump can't
+type rockStack struct{ high int }
+
+func (s rockStack) Name() string {
+	if s.high > 2 {
+		return "tall stack"
+	}
+	return "stack"
+}
+func (rockStack) Sprite() (string, int) { return rockChar, 1 }
+func (rockStack) Radar() string         { return "▲ " }
+func (rockStack) Width() int            { return 1 }
+func (s rockStack) Height() int         { return s.high }
+func (rockStack) Advance(x int) int     { return x }
+func (rockStack) Weight() float64       { return 0.06 }
+func (s rockStack) Death(dist int) string {
+	return fmt.Sprintf("Crashed into a %s of rocks at %d", s.Name(), dist)
+}
+func (s rockStack) Collides(p player) hit {
+	if p.height < s.high {
+		return fatal
+	}
+	return miss
+}
@@ -0,0 +1,354 @@
+package gopherdash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// COMMAND PALETTE (`:`)
+// ----------------------------------------------------------------------------
+
+// `:` opens a command line in place of the controls bar, as in vim, for
+// the things there's no key for: `:seed 42` starts a run on seed 42,
+// `:theme halloween` dresses the game in an event's look (`night` or
+// `off` too), `:speed 1.5` holds the run at 1.5× the starting speed,
+// `:replay last` plays the last run's tape and comes back, and `:quit`
+// quits. Tab completes a command, or its argument, and a unique prefix is
+// enough (`:q`); the rest of the editing, readline's keys included, is
+// Bubbles' text input. Opening the palette mid-run pauses the run. Races keep
+// their keys for emotes, so there's no palette in them.
+
+// pausePalette is why the run stopped while the palette's open
+const pausePalette = "Paused for the command line"
+
+// palette is the command line being typed
+type palette struct {
+	input  textinput.Model
+	hint   string // completions on offer
+	paused bool   // the palette paused the run, so closing it resumes it
+}
+
+// paletteCommand is a command the palette knows
+type paletteCommand struct {
+	name, usage string
+	args        func(m model) []string // completions for the argument
+	run         func(m *model, args []string) (tea.Cmd, error)
+}
+
+var paletteCommands []paletteCommand
+
+func init() {
+	// set here rather than where it's declared, as :help reads it back
+	paletteCommands = []paletteCommand{
+		{name: "seed", usage: "seed N|random", run: (*model).cmdSeed,
+			args: func(model) []string { return []string{"random"} }},
+		{name: "theme", usage: "theme NAME|night|off", run: (*model).cmdTheme, args: themeNames},
+		{name: "speed", usage: "speed X|off", run: (*model).cmdSpeed,
+			args: func(model) []string { return []string{"off"} }},
+		{name: "replay", usage: "replay last|best", run: (*model).cmdReplay,
+			args: func(model) []string { return []string{replayLast, replayBest} }},
+		{name: "help", usage: "help", run: (*model).cmdHelp},
+		{name: "quit", usage: "quit", run: (*model).cmdQuit},
+	}
+}
+
+// replayDoneMsg is the palette's replay having finished
+type replayDoneMsg struct{ err error }
+
+// paletteOK reports whether `:` opens the palette now
+func (m model) paletteOK() bool {
+	return !m.racing() && !m.photo() && !m.inIntro()
+}
+
+// openPalette starts a command line, pausing the run if it's going
+func (m *model) openPalette() {
+	in := textinput.New()
+	in.Prompt = ":"
+	in.Cursor.SetMode(cursor.CursorStatic) // a blink would redraw the frame
+	in.Focus()
+	m.palette = &palette{input: in, paused: m.live()}
+	m.pause(pausePalette)
+}
+
+// closePalette puts the command line away
+func (m *model) closePalette() tea.Cmd {
+	p := m.palette
+	m.palette = nil
+	return m.resumeAfter(p)
+}
+
+// resumeAfter resumes the run p paused, unless something's happened to
+// it since
+func (m *model) resumeAfter(p *palette) tea.Cmd {
+	if p.paused && m.paused && m.pauseWhy == pausePalette {
+		return m.resume()
+	}
+	return nil
+}
+
+// paletteKey runs the command line on Enter, completes it on Tab and
+// leaves the editing to the text input
+func (m *model) paletteKey(msg tea.KeyMsg) tea.Cmd {
+	p := m.palette
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return m.closePalette()
+	case tea.KeyEnter:
+		return m.runPalette(p.input.Value())
+	case tea.KeyTab:
+		m.complete()
+		return nil
+	case tea.KeyBackspace:
+		if p.input.Value() == "" {
+			return m.closePalette() // backing out over the colon, as vim does
+		}
+	}
+	p.hint = ""
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+// complete finishes the word before the cursor from the commands, or from
+// the command's arguments, as far as the candidates agree; if they part
+// ways it lists them
+func (m *model) complete() {
+	p := m.palette
+	line, at := []rune(p.input.Value()), p.input.Position()
+	head := string(line[:at])
+	var candidates []string
+	word := head
+	if name, arg, ok := strings.Cut(head, " "); ok {
+		cmd, err := lookupCommand(name)
+		if err != nil || cmd.args == nil {
+			return
+		}
+		word = strings.TrimLeft(arg, " ")
+		candidates = cmd.args(*m)
+	} else {
+		for _, c := range paletteCommands {
+			candidates = append(candidates, c.name)
+		}
+	}
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+	fill := matches[0]
+	for _, c := range matches[1:] {
+		for !strings.HasPrefix(c, fill) {
+			fill = fill[:len(fill)-1]
+		}
+	}
+	if len(matches) == 1 {
+		fill += " "
+	}
+	fill = fill[len(word):]
+	p.input.SetValue(head + fill + string(line[at:]))
+	p.input.SetCursor(at + len([]rune(fill)))
+	p.hint = ""
+	if len(matches) > 1 {
+		p.hint = strings.Join(matches, "  ")
+	}
+}
+
+// lookupCommand finds a command by its name or a prefix only it has
+func lookupCommand(name string) (paletteCommand, error) {
+	var found []paletteCommand
+	for _, c := range paletteCommands {
+		if c.name == name {
+			return c, nil
+		}
+		if strings.HasPrefix(c.name, name) {
+			found = append(found, c)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return paletteCommand{}, fmt.Errorf("not a command: %s", name)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, c := range found {
+		names[i] = c.name
+	}
+	return paletteCommand{}, fmt.Errorf("%s could be %s", name, strings.Join(names, " or "))
+}
+
+// runPalette puts the palette away and runs line; what went wrong, if
+// anything, goes up as a notice
+func (m *model) runPalette(line string) tea.Cmd {
+	p := m.palette
+	m.palette = nil
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return m.resumeAfter(p)
+	}
+	cmd, err := lookupCommand(fields[0])
+	if err != nil {
+		m.notify(err.Error())
+		return m.resumeAfter(p)
+	}
+	m.logInfo("palette", "line", line)
+	next, err := cmd.run(m, fields[1:])
+	if err != nil {
+		m.notify(fmt.Sprintf("%s (usage: :%s)", err, cmd.usage))
+	}
+	if next != nil {
+		return next // the command's taken over from the paused run
+	}
+	return m.resumeAfter(p)
+}
+
+// paletteLine is the command line as it's drawn
+func (m model) paletteLine() string {
+	p := m.palette
+	line := p.input.View()
+	if p.hint != "" {
+		line += "   " + p.hint
+	}
+	return line
+}
+
+// cmdSeed starts a new run on a seed, or on random seeds again
+func (m *model) cmdSeed(args []string) (tea.Cmd, error) {
+	if len(args) != 1 {
+		return nil, errors.New("which seed?")
+	}
+	seed := int64(0)
+	if args[0] != "random" {
+		n, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("not a seed: %s", args[0])
+		}
+		seed = n
+	}
+	m.flushRun()
+	m.cfg.Seed, m.cfg.Daily, m.cfg.Weekly = seed, false, false
+	return m.restart(), nil
+}
+
+// themeNames is what :theme takes
+func themeNames(model) []string {
+	names := []string{"night", "off"}
+	for _, ev := range loadEvents() {
+		names = append(names, strings.ToLower(ev.Name))
+	}
+	return names
+}
+
+// cmdTheme dresses the game in an event's look, the night, or neither.
+// The look only: an event's achievement can't be had out of season.
+func (m *model) cmdTheme(args []string) (tea.Cmd, error) {
+	name := strings.Join(args, " ")
+	switch {
+	case name == "":
+		return nil, errors.New("which theme?")
+	case strings.EqualFold(name, "off"):
+		m.event, m.cfg.Night = nil, false
+	case strings.EqualFold(name, "night"):
+		m.cfg.Night = true
+	default:
+		ev := findEvent(name)
+		if ev == nil {
+			return nil, fmt.Errorf("no such event in the calendar: %s", name)
+		}
+		look := *ev
+		look.Achievement = achievement{}
+		m.event = &look
+	}
+	return nil, nil
+}
+
+// cmdSpeed holds the run at a multiple of the starting speed by setting
+// both caps on it, so the run is ranked apart as any capped run is
+func (m *model) cmdSpeed(args []string) (tea.Cmd, error) {
+	if len(args) != 1 {
+		return nil, errors.New("how fast?")
+	}
+	if args[0] == "off" {
+		if !m.gameOver {
+			return nil, errors.New("a capped run stays capped; try again once it's over")
+		}
+		m.cfg.MinTick, m.cfg.MaxTick = 0, 0
+		return nil, nil
+	}
+	x, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || x < 0.5 || x > 3 {
+		return nil, fmt.Errorf("speed %s: want 0.5 to 3", args[0])
+	}
+	ms := int(float64(startFrame.Milliseconds())/x + 0.5)
+	m.cfg.MinTick, m.cfg.MaxTick = ms, ms
+	m.frameDur = m.capFrame(m.frameDur)
+	m.music.setSpeed(speedFactor(m.frameDur))
+	return nil, nil
+}
+
+// cmdReplay plays a taped run with `gopherdash replay`, handing it the
+// terminal until it's done
+func (m *model) cmdReplay(args []string) (tea.Cmd, error) {
+	name := replayLast
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name != replayLast && name != replayBest {
+		return nil, fmt.Errorf("no %s replay", name)
+	}
+	switch {
+	case m.embedded:
+		return nil, errors.New("replays play from the gopherdash command")
+	case m.cfg.Store == "memory":
+		return nil, errors.New("nothing's taped with -store memory")
+	}
+	if _, ok := m.saves.readReplay(name); !ok {
+		return nil, fmt.Errorf("no %s run taped yet", name)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	args = []string{"replay", "-store", m.cfg.Store}
+	if m.cfg.DB != "" {
+		args = append(args, "-db", m.cfg.DB)
+	}
+	if name == replayLast {
+		args = append(args, "-last")
+	}
+	run := exec.Command(exe, args...)
+	return tea.Sequence(
+		func() tea.Msg { releaseKeyboard(); return nil },
+		tea.ExecProcess(run, func(err error) tea.Msg { return replayDoneMsg{err} }),
+	), nil
+}
+
+// cmdHelp lists the commands
+func (m *model) cmdHelp([]string) (tea.Cmd, error) {
+	usages := make([]string, len(paletteCommands))
+	for i, c := range paletteCommands {
+		usages[i] = ":" + c.usage
+	}
+	m.notify(strings.Join(usages, "  "))
+	return nil, nil
+}
+
+// cmdQuit quits, as Q does
+func (m *model) cmdQuit([]string) (tea.Cmd, error) {
+	m.flushRun()
+	m.raceReport()
+	return m.leave(), nil
+}
@@ -0,0 +1,72 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLookupCommand(t *testing.T) {
+	for name, want := range map[string]string{"quit": "quit", "q": "quit", "th": "theme", "s": "", "zap": ""} {
+		cmd, err := lookupCommand(name)
+		if cmd.name != want || (err == nil) != (want != "") {
+			t.Errorf("%q: %q, %v", name, cmd.name, err)
+		}
+	}
+}
+
+// `:` pauses the run, Tab fills in what it can, Enter runs the line and
+// the run carries on.
+func TestPalette(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	clearHazards(&m)
+	send := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	typed := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	send(typed(":"))
+	if m.palette == nil || !m.paused {
+		t.Fatal("`:` didn't open a paused command line")
+	}
+	send(typed("s"), tea.KeyMsg{Type: tea.KeyTab})
+	if m.palette.hint != "seed  speed" {
+		t.Errorf("completing s offered %q", m.palette.hint)
+	}
+	send(typed("e"), tea.KeyMsg{Type: tea.KeyTab}, typed("7"), tea.KeyMsg{Type: tea.KeyCtrlW}, typed("42"))
+	if line := m.palette.input.Value(); line != "seed 42" {
+		t.Fatalf("typed %q", line)
+	}
+	if view := m.View(); !strings.Contains(view, ":seed 42") {
+		t.Errorf("the command line isn't drawn:\n%s", view)
+	}
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.palette != nil || m.paused || m.seed != 42 || m.dist != 0 {
+		t.Errorf("after :seed 42: palette %v, paused %v, seed %d", m.palette, m.paused, m.seed)
+	}
+
+	send(typed(":"), typed("speed 1.5"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.frameDur != 30*time.Millisecond || !m.capped() {
+		t.Errorf("after :speed 1.5: %v steps, capped %v", m.frameDur, m.capped())
+	}
+	send(typed(":"), typed("speed off"), tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.capped() || !strings.Contains(m.hudNotice(), "stays capped") {
+		t.Errorf("uncapped a live run; notice %q", m.hudNotice())
+	}
+
+	send(typed(":"), typed("nope"), tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(m.hudNotice(), "not a command") {
+		t.Errorf("notice %q", m.hudNotice())
+	}
+	send(typed(":"), tea.KeyMsg{Type: tea.KeyBackspace})
+	if m.palette != nil || m.paused {
+		t.Error("backspace over the colon left the command line open")
+	}
+}
@@ -0,0 +1,105 @@
+package gopherdash
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// PARTICLE LAYER
+// ----------------------------------------------------------------------------
+
+const (
+	confettiCount = 40
+	confettiLife  = 30 // frames
+	particleFrame = 60 * time.Millisecond
+)
+
+var (
+	confettiGlyphs = []rune("*•+✦~")
+	confettiColors = []lipgloss.Color{"9", "10", "11", "12", "13", "14"}
+)
+
+// particle is a single decorative cell drawn on top of a pane; it never
+// interacts with gameplay
+type particle struct {
+	x, y   float64
+	vx, vy float64
+	glyph  rune
+	color  lipgloss.Color
+	life   int // frames left
+}
+
+// spawnConfetti scatters a burst of particles across the top of a w×h pane
+func spawnConfetti(w, h int) []particle {
+	ps := make([]particle, confettiCount)
+	for i := range ps {
+		ps[i] = particle{
+			x:     rng.Float64() * float64(w),
+			y:     -rng.Float64() * float64(h) / 2,
+			vx:    (rng.Float64() - 0.5) * 0.6,
+			vy:    0.2 + rng.Float64()*0.3,
+			glyph: confettiGlyphs[rng.Intn(len(confettiGlyphs))],
+			color: confettiColors[rng.Intn(len(confettiColors))],
+			life:  confettiLife - rng.Intn(confettiLife/3),
+		}
+	}
+	return ps
+}
+
+// stepParticles advances every particle one frame and drops expired ones
+func stepParticles(ps []particle) []particle {
+	kept := ps[:0]
+	for _, p := range ps {
+		p.x += p.vx
+		p.y += p.vy
+		p.vy += 0.02 // light gravity
+		p.life--
+		if p.life > 0 {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// isGap reports whether rs[x] is a space away from any text, so confetti
+// never lands between two words
+func isGap(rs []rune, x int) bool {
+	for i := max(x-1, 0); i <= min(x+1, len(rs)-1); i++ {
+		if rs[i] != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// overlayParticles draws particles onto the blank cells of a plain-text
+// block; lines already carrying ANSI styling are left untouched
+func overlayParticles(block string, ps []particle) string {
+	if len(ps) == 0 {
+		return block
+	}
+	lines := strings.Split(block, "\n")
+	at := make(map[[2]int]particle, len(ps))
+	for _, p := range ps {
+		at[[2]int{int(p.y), int(p.x)}] = p
+	}
+	for y, line := range lines {
+		if strings.ContainsRune(line, '\x1b') {
+			continue
+		}
+		rs := []rune(line)
+		var b strings.Builder
+		for x, r := range rs {
+			if p, ok := at[[2]int{y, x}]; ok && isGap(rs, x) {
+				b.WriteString(lipgloss.NewStyle().Foreground(p.color).Render(string(p.glyph)))
+				continue
+			}
+			b.WriteRune(r)
+		}
+		lines[y] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,57 @@
+package gopherdash
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// AUTO-PAUSE
+// ----------------------------------------------------------------------------
+
+// pause reasons
+const (
+	pauseBlur    = "Terminal lost focus"
+	pauseIdle    = "Paused after inactivity"
+	pauseSuspend = "Resumed from suspend"
+)
+
+// live reports whether a run is in progress and could be paused
+func (m model) live() bool { return !m.gameOver && !m.paused }
+
+// pause freezes the simulation; why is shown under the resume prompt
+func (m *model) pause(why string) {
+	if !m.live() {
+		return
+	}
+	m.paused = true
+	m.pauseWhy = why
+	m.lastStep = time.Time{} // the speed-run clock stops too
+	m.logInfo("paused", "why", why)
+}
+
+// resume unfreezes the run behind a fresh countdown and restarts the tick
+// chain at the current speed
+func (m *model) resume() tea.Cmd {
+	m.logInfo("resumed", "why", m.pauseWhy)
+	m.paused = false
+	m.pauseWhy = ""
+	m.lastInput = m.now()
+	m.startIntro()
+	m.tickGen++ // drop the slow paused tick
+	return m.tickAfter(m.frameDur, m.tickGen)
+}
+
+// idle reports whether the player has been away longer than the configured
+// idle timeout
+func (m model) idle() bool {
+	if m.cfg.IdlePause <= 0 {
+		return false
+	}
+	since := m.lastInput
+	if m.introUntil.After(since) {
+		since = m.introUntil // the countdown doesn't count as idling
+	}
+	return m.now().Sub(since) > time.Duration(m.cfg.IdlePause)*time.Second
+}
@@ -0,0 +1,162 @@
+package gopherdash
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// PERFORMANCE (tick & render caps)
+// ----------------------------------------------------------------------------
+
+// The simulation and the screen run at separate rates. -tick-rate caps how
+// often the game wakes up to step; when the game is faster than that, each
+// wakeup runs as many steps as it takes to keep the speed right. -render-fps
+// caps how often the screen is redrawn during a run, for slow links: frames
+// in between re-use the last one, which Bubble Tea then doesn't resend.
+
+const perfSmoothing = 0.1 // weight of the newest sample in the averages
+
+// the values the performance screen steps through; 0 = uncapped
+var (
+	tickRates   = []int{0, 60, 30, 20, 15}
+	renderRates = []int{0, 60, 30, 20, 15, 10}
+)
+
+// perfMeter measures frame times and holds the last frame for the render
+// cap. The model is copied on every update, so it lives behind a pointer.
+type perfMeter struct {
+	lastTick, lastDraw time.Time
+	tickGap, drawGap   float64 // averages, in milliseconds
+	drawCost           float64 // building a frame, in milliseconds
+	frame              string
+
+	hudStatus, hud string // the last HUD bar, for the battery saver
+	hudWidth       int
+}
+
+// ticked records a simulation wakeup
+func (p *perfMeter) ticked(now time.Time) {
+	if p == nil {
+		return
+	}
+	if !p.lastTick.IsZero() {
+		p.tickGap = average(p.tickGap, now.Sub(p.lastTick))
+	}
+	p.lastTick = now
+}
+
+// draw returns the frame to show: a fresh one from view or, during a run
+// and too soon after the last, that same frame again. Only frames drawn
+// during a run are measured.
+func (p *perfMeter) draw(fps int, running bool, view func() string) string {
+	if p == nil {
+		return view()
+	}
+	now := time.Now()
+	if !running {
+		p.lastDraw = time.Time{} // the next run's first gap isn't a frame time
+		return view()
+	}
+	if fps > 0 && p.frame != "" && now.Sub(p.lastDraw) < time.Second/time.Duration(fps) {
+		return p.frame
+	}
+	p.frame = view()
+	p.drawCost = average(p.drawCost, time.Since(now))
+	if !p.lastDraw.IsZero() {
+		p.drawGap = average(p.drawGap, now.Sub(p.lastDraw))
+	}
+	p.lastDraw = now
+	return p.frame
+}
+
+func average(avg float64, d time.Duration) float64 {
+	ms := float64(d) / float64(time.Millisecond)
+	if avg == 0 {
+		return ms
+	}
+	return avg + perfSmoothing*(ms-avg)
+}
+
+// stepsPerTick is how many steps the next wakeup runs: one, unless the tick
+// cap is slower than the game, in which case each wakeup catches up on the
+// steps it skipped. Lockstep races exchange input every step, so they are
+// never capped.
+func (m model) stepsPerTick() int {
+	if m.cfg.TickRate <= 0 || m.race.lockstep {
+		return 1
+	}
+	gap := time.Second / time.Duration(m.cfg.TickRate)
+	return max(1, int(math.Ceil(float64(gap)/float64(m.tickDur()))))
+}
+
+// perfHUD is the live readout F toggles in the HUD during a run
+func (m model) perfHUD() string {
+	if m.perf == nil {
+		return ""
+	}
+	return fmt.Sprintf("tick %.0fms  frame %.0fms (%.1fms to draw)",
+		m.perf.tickGap, m.perf.drawGap, m.perf.drawCost)
+}
+
+// perfLines is the performance screen
+func (m model) perfLines() []string {
+	setting := func(i int, name string, v int) string {
+		mark := "  "
+		if i == m.perfRow {
+			mark = "▸ "
+		}
+		val := "uncapped"
+		if v > 0 {
+			val = fmt.Sprintf("%d per second", v)
+		}
+		return fmt.Sprintf("%s%-12s ◂ %-15s ▸", mark, name, val)
+	}
+	lines := []string{
+		"Performance",
+		"",
+		setting(0, "Tick rate", m.cfg.TickRate),
+		setting(1, "Render rate", m.cfg.RenderFPS),
+		"",
+	}
+	if m.perf != nil && m.perf.tickGap > 0 {
+		lines = append(lines, "Last run: "+m.perfHUD())
+	} else {
+		lines = append(lines, "Play a run to measure frame times")
+	}
+	if m.cfg.BatterySaver {
+		lines = append(lines, "The battery saver halves the render rate")
+	}
+	return append(lines, "Keep changes with tick_rate / render_fps in the config")
+}
+
+// adjustPerf moves the selected setting to the next (dir 1) or previous
+// (dir -1) step
+func (m *model) adjustPerf(dir int) {
+	rates, v := tickRates, &m.cfg.TickRate
+	if m.perfRow == 1 {
+		rates, v = renderRates, &m.cfg.RenderFPS
+	}
+	i := slices.Index(rates, *v)
+	if i < 0 {
+		i = 0 // a value from the config that isn't on the list
+	}
+	*v = rates[(i+dir+len(rates))%len(rates)]
+	m.logInfo("performance setting", "tick_rate", m.cfg.TickRate, "render_fps", m.cfg.RenderFPS)
+}
+
+// perfKey handles keys on the performance screen
+func (m *model) perfKey(key string) {
+	switch key {
+	case "up", "down":
+		m.perfRow = 1 - m.perfRow
+	case "left":
+		m.adjustPerf(-1)
+	case "right":
+		m.adjustPerf(1)
+	case "f", "esc":
+		m.showPerf = false
+	}
+}
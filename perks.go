@@ -0,0 +1,151 @@
+package gopherdash
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// PERKS (-perks)
+// ----------------------------------------------------------------------------
+
+// With -perks, every perkEvery of distance the run stops for a choice of
+// two perks, and the one picked lasts the rest of the run. Unlike the
+// modifiers (see modifiers.go) they're earned along the way, and the same
+// one can be picked more than once to stack. Which two are offered is a
+// hash of the seed and the distance, and the pick is taped, so replays
+// take the same ones at the same steps. Perks make a run easier in ways
+// plain runs can't match, so a run that took any sets no bests.
+//
+// Races, the bot's and the ghosts' runs go without; a resumed run keeps
+// the perks it had.
+const (
+	perkCooldown = "cooldown"
+	perkJump     = "jump"
+	perkShield   = "shield"
+	perkAcorns   = "acorns"
+
+	perkEvery = 200 // distance between offers
+	perkSalt  = 0x9e2c17
+	actPerk   = "perk:" // taped as actPerk and the perk's name
+
+	controlsPerks = "1/← = first   2/→ = second   Q = quit"
+)
+
+// perk is one entry in the registry
+type perk struct {
+	name  string // saved with runs and taped, e.g. "shield"
+	label string // shown in the offer and the HUD
+	about string // shown in the offer
+}
+
+// perks is the registry; the offers are drawn by index, so reordering it
+// changes which seeds offer what
+var perks = []perk{
+	{perkCooldown, "Short cooldown", "go again in half the time after this run"},
+	{perkJump, "Higher jump", "one more jump in mid-air, to climb higher"},
+	{perkShield, "+1 shield", "survive one more hit"},
+	{perkAcorns, "More acorns", "each acorn picked up counts twice"},
+}
+
+// perkByName looks a perk up in the registry
+func perkByName(name string) (perk, bool) {
+	i := slices.IndexFunc(perks, func(p perk) bool { return p.name == name })
+	if i < 0 {
+		return perk{}, false
+	}
+	return perks[i], true
+}
+
+// perk is how many times the run has taken perk name
+func (m model) perk(name string) int {
+	n := 0
+	for _, p := range m.perks {
+		if p == name {
+			n++
+		}
+	}
+	return n
+}
+
+// perksOn reports whether the run is offered perks
+func (m model) perksOn() bool {
+	return m.cfg.Perks && m.cfg.Bot == "" && !m.racing() && !m.ghost && !m.saver && m.playback == nil
+}
+
+// perkChoices are the two perks offered at distance dist in the world of
+// seed
+func perkChoices(seed int64, dist int) []string {
+	h := terrainHash(seed^perkSalt, dist/perkEvery)
+	n := uint64(len(perks))
+	first := h % n
+	second := (first + 1 + h>>16%(n-1)) % n
+	return []string{perks[first].name, perks[second].name}
+}
+
+// milestonePerks stops the run for a choice of perks every perkEvery
+func (m *model) milestonePerks(dist int) {
+	if dist%perkEvery != 0 || !m.perksOn() {
+		return
+	}
+	m.perkOffer = perkChoices(m.seed, dist)
+	m.lastStep = time.Time{} // the speed-run clock stops while choosing
+	m.logInfo("perks offered", "dist", dist, "perks", m.perkOffer)
+}
+
+// answerPerk takes the offered perk key picks, and starts the run again
+// behind a countdown
+func (m *model) answerPerk(key string) tea.Cmd {
+	var name string
+	switch key {
+	case "1", "left":
+		name = m.perkOffer[0]
+	case "2", "right":
+		name = m.perkOffer[1]
+	default:
+		return nil
+	}
+	m.record(actPerk + name)
+	m.takePerk(name)
+	m.perkOffer = nil
+	return m.resume() // as from a pause, which also clears one made while choosing
+}
+
+// takePerk adds perk name to the run
+func (m *model) takePerk(name string) {
+	p, ok := perkByName(name)
+	if !ok {
+		return // a tape from a version with other perks
+	}
+	m.perks = append(m.perks, name)
+	m.notify(p.label + "!")
+	m.logInfo("perk taken", "perk", name)
+}
+
+// perksLabel lists the run's perks for the HUD
+func (m model) perksLabel() string {
+	var labels []string
+	for _, p := range perks {
+		switch n := m.perk(p.name); {
+		case n == 1:
+			labels = append(labels, p.label)
+		case n > 1:
+			labels = append(labels, fmt.Sprintf("%s ×%d", p.label, n))
+		}
+	}
+	return strings.Join(labels, " + ")
+}
+
+// perkLines is the choice on offer
+func (m model) perkLines() []string {
+	lines := []string{fmt.Sprintf("%dm – pick a perk for the rest of the run", m.dist), ""}
+	for i, name := range m.perkOffer {
+		p, _ := perkByName(name)
+		lines = append(lines, fmt.Sprintf("%d  %-14s %s", i+1, p.label, p.about))
+	}
+	return lines
+}
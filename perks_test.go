@@ -0,0 +1,111 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPerkChoices(t *testing.T) {
+	offered := map[string]bool{}
+	for seed := int64(1); seed <= 20; seed++ {
+		for dist := perkEvery; dist <= 20*perkEvery; dist += perkEvery {
+			two := perkChoices(seed, dist)
+			if len(two) != 2 || two[0] == two[1] {
+				t.Fatalf("seed %d at %d: offered %v", seed, dist, two)
+			}
+			for _, name := range two {
+				if _, ok := perkByName(name); !ok {
+					t.Errorf("seed %d at %d: offered %q, which isn't a perk", seed, dist, name)
+				}
+				offered[name] = true
+			}
+			if again := perkChoices(seed, dist); again[0] != two[0] || again[1] != two[1] {
+				t.Errorf("seed %d at %d: offered %v, then %v", seed, dist, two, again)
+			}
+		}
+	}
+	for _, p := range perks {
+		if !offered[p.name] {
+			t.Errorf("%s never offered", p.name)
+		}
+	}
+}
+
+// The run waits at the offer for a pick, tapes it, and a replay takes it
+// at the same step without being asked.
+func TestPerkOffer(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Perks = 0, true
+	m, c := clockedModel(t, cfg)
+	m.dist = perkEvery - 1
+	m.fillObstacles() // lay out the course it jumped to, or the step spawns on the gopher
+	clearHazards(&m)
+	m.step(m.now())
+	if len(m.perkOffer) != 2 {
+		t.Fatalf("offered %v at %d", m.perkOffer, m.dist)
+	}
+	want, _ := perkByName(m.perkOffer[1])
+	if view := m.View(); !strings.Contains(view, want.label) {
+		t.Errorf("the offer doesn't show %q:\n%s", want.label, view)
+	}
+	steps := m.steps
+	next, _ := m.Update(tickMsg{m.tickGen, c.t})
+	if m = next.(model); m.steps != steps {
+		t.Errorf("the run stepped on to %d while choosing", m.steps)
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	m = next.(model)
+	if m.perkOffer != nil || m.perk(want.name) != 1 {
+		t.Fatalf("picked the second: offer %v, perks %v", m.perkOffer, m.perks)
+	}
+	taped := m.tape.Inputs[len(m.tape.Inputs)-1]
+	if taped != (replayInput{steps, actPerk + want.name}) {
+		t.Errorf("taped %+v", taped)
+	}
+
+	pb := newPlayback(*m.tape)
+	pb.steps = steps
+	pb.emit(eventMilestone, perkEvery)
+	pb.playInputs()
+	if pb.perkOffer != nil || pb.perk(want.name) != 1 {
+		t.Errorf("the replay: offer %v, perks %v", pb.perkOffer, pb.perks)
+	}
+}
+
+func TestPerkEffects(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	shields, cooldown, hold := m.shields(), m.cooldown(), m.restartHold()
+	for _, p := range perks {
+		m.takePerk(p.name)
+	}
+	if m.shields() != shields+1 {
+		t.Errorf("%d shields with the perk, %d without", m.shields(), shields)
+	}
+	if m.cooldown() != cooldown/2 || m.restartHold() != hold/2 {
+		t.Errorf("cooldown %v and hold %v with the perk, from %v and %v", m.cooldown(), m.restartHold(), cooldown, hold)
+	}
+	m.ammo = 0
+	points := m.points
+	m.collectAcorn(obstacle{m.dist, acornPickup{}})
+	if m.ammo != 2 || m.points != points+2*m.scoring().Coin {
+		t.Errorf("an acorn with the perk: ammo %d, scored %d", m.ammo, m.points-points)
+	}
+	m.jump()
+	if !m.airJump() {
+		t.Error("no jump in mid-air with the perk")
+	}
+	if !strings.Contains(m.perksLabel(), "+1 shield") {
+		t.Errorf("HUD label %q", m.perksLabel())
+	}
+
+	best := m.profile.HighScore
+	m.dist = best + 100
+	m.setGameOver("rock")
+	if m.profile.HighScore != best || m.newRecord {
+		t.Errorf("a run with perks set a high score of %d", m.profile.HighScore)
+	}
+}
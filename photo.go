@@ -0,0 +1,218 @@
+package gopherdash
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// PHOTO MODE
+// ----------------------------------------------------------------------------
+
+// P freezes a run for a photo. The frozen frame is shown without the pause
+// banner; H hides the HUD and controls for a clean shot of the playfield,
+// and E saves what's on screen as text with its ANSI colours, plus an SVG
+// with -photo-svg. P or Esc carries on behind the usual countdown.
+
+const pausePhoto = "Photo mode"
+
+// SVG layout, in pixels per terminal column and row
+const (
+	svgCol  = 10
+	svgRow  = 20
+	svgFont = 16
+	svgFg   = "#d0d0d0"
+	svgBg   = "#1c1c1c"
+)
+
+// photo reports whether the run is frozen for a photo
+func (m model) photo() bool { return m.paused && m.pauseWhy == pausePhoto }
+
+// photoKey handles keys in photo mode
+func (m *model) photoKey(key string) tea.Cmd {
+	switch key {
+	case "h":
+		m.photoClean = !m.photoClean
+	case "e", "enter":
+		m.savePhoto()
+	case "p", "esc":
+		m.photoClean = false
+		return m.resume()
+	}
+	return nil
+}
+
+// savePhoto writes the frame on screen next to the binary
+func (m *model) savePhoto() {
+	base := dataPath("gopherdash-photo-" + time.Now().Format("20060102-150405"))
+	frame := m.view()
+	err := os.WriteFile(base+".txt", []byte(frame+"\x1b[0m\n"), 0o644)
+	if err == nil && m.cfg.PhotoSVG {
+		err = os.WriteFile(base+".svg", []byte(frameSVG(frame)), 0o644)
+	}
+	if err != nil {
+		m.notify(fmt.Sprintf("Photo not saved: %v", err))
+		return
+	}
+	m.logInfo("photo saved", "path", base)
+	m.notify("Photo saved to " + base + ".txt")
+}
+
+// svgCell is one glyph of a frame at its terminal column
+type svgCell struct {
+	col, width int
+	text       string
+	fg, bg     string
+}
+
+// frameSVG draws a rendered frame, ANSI colours and all, as an SVG of
+// monospace text. Only SGR colour sequences are understood; anything else
+// is dropped.
+func frameSVG(frame string) string {
+	lines := strings.Split(frame, "\n")
+	rows := make([][]svgCell, len(lines))
+	cols := 0
+	for y, line := range lines {
+		rows[y] = parseANSI(line)
+		if n := len(rows[y]); n > 0 {
+			cols = max(cols, rows[y][n-1].col+rows[y][n-1].width)
+		}
+	}
+	var b strings.Builder
+	w, h := cols*svgCol, len(lines)*svgRow
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", w, h, w, h)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgBg)
+	fmt.Fprintf(&b, `<g font-family="monospace" font-size="%d" dominant-baseline="central">`+"\n", svgFont)
+	for y, cells := range rows {
+		for _, c := range cells {
+			x := c.col * svgCol
+			if c.bg != "" {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+					x, y*svgRow, c.width*svgCol, svgRow, c.bg)
+			}
+			if strings.TrimSpace(c.text) == "" {
+				continue
+			}
+			fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s">%s</text>`+"\n",
+				x, y*svgRow+svgRow/2, c.fg, html.EscapeString(c.text))
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
+
+// parseANSI splits one line of a frame into glyphs with their colours
+func parseANSI(line string) []svgCell {
+	var cells []svgCell
+	fg, bg := svgFg, ""
+	col := 0
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' && i+1 < len(line) && line[i+1] == '[' {
+			j := i + 2
+			for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+				j++
+			}
+			if j < len(line) && line[j] == 'm' {
+				fg, bg = applySGR(line[i+2:j], fg, bg)
+			}
+			i = j + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		w := lipgloss.Width(string(r))
+		if w == 0 && len(cells) > 0 {
+			cells[len(cells)-1].text += string(r) // a combining mark or variation selector
+			continue
+		}
+		cells = append(cells, svgCell{col, w, string(r), fg, bg})
+		col += w
+	}
+	return cells
+}
+
+// applySGR updates the colours with one SGR sequence's parameters
+func applySGR(params, fg, bg string) (string, string) {
+	ps := strings.Split(params, ";")
+	for i := 0; i < len(ps); i++ {
+		n, _ := strconv.Atoi(ps[i])
+		switch {
+		case n == 0:
+			fg, bg = svgFg, ""
+		case n == 39:
+			fg = svgFg
+		case n == 49:
+			bg = ""
+		case n >= 30 && n <= 37:
+			fg = xtermColour(n - 30)
+		case n >= 90 && n <= 97:
+			fg = xtermColour(n - 90 + 8)
+		case n >= 40 && n <= 47:
+			bg = xtermColour(n - 40)
+		case n >= 100 && n <= 107:
+			bg = xtermColour(n - 100 + 8)
+		case (n == 38 || n == 48) && i+1 < len(ps):
+			var c string
+			switch ps[i+1] {
+			case "5":
+				if i+2 < len(ps) {
+					v, _ := strconv.Atoi(ps[i+2])
+					c = xtermColour(v)
+				}
+				i += 2
+			case "2":
+				if i+4 < len(ps) {
+					r, _ := strconv.Atoi(ps[i+2])
+					g, _ := strconv.Atoi(ps[i+3])
+					bl, _ := strconv.Atoi(ps[i+4])
+					c = fmt.Sprintf("#%02x%02x%02x", r, g, bl)
+				}
+				i += 4
+			}
+			switch {
+			case c == "":
+			case n == 38:
+				fg = c
+			default:
+				bg = c
+			}
+		}
+	}
+	return fg, bg
+}
+
+// xtermBase are the 16 system colours as xterm draws them
+var xtermBase = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// xtermColour is the hex value of 256-colour palette entry n
+func xtermColour(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return svgFg
+	case n < 16:
+		return xtermBase[n]
+	case n < 232:
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	default:
+		g := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", g, g, g)
+	}
+}
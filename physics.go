@@ -0,0 +1,105 @@
+package gopherdash
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ----------------------------------------------------------------------------
+// PHYSICS PROFILES (classic & momentum)
+// ----------------------------------------------------------------------------
+
+// Classic physics moves the gopher a whole row at a time: velY is rows per
+// tick and gravity adds a row per tick to it. Momentum physics keeps the
+// vertical position and speed in fixed point (fixedOne units to the row),
+// which allows arcs the integer model can't express, caps the fall speed
+// and lets the player cut a jump short or dive. Take-offs are scaled so a
+// jump still spends jumpCells steps in the air, which the spawner's
+// fairness rules depend on.
+const (
+	physicsClassic  = "classic"
+	physicsMomentum = "momentum"
+
+	fixedOne         = 16 // momentum units per row
+	momentumGravity  = 14 // per tick², for each row of the class's gravity
+	momentumLift     = 13 // take-off speed per row of a classic take-off
+	momentumTerminal = 40 // fastest fall, for each row of the class's gravity
+	diveKick         = 16 // extra fall speed from a dive
+)
+
+var physicsNames = []string{physicsClassic, physicsMomentum}
+
+// validPhysics rejects names that aren't a physics profile; "" is classic
+func validPhysics(name string) error {
+	if name == "" || slices.Contains(physicsNames, name) {
+		return nil
+	}
+	return fmt.Errorf("unknown physics %q (want %s or %s)", name, physicsClassic, physicsMomentum)
+}
+
+// momentum reports whether the run uses fixed-point physics
+func (m model) momentum() bool {
+	return m.cfg.Physics == physicsMomentum || m.mod(modLowGravity)
+}
+
+// launch sends the gopher upward at a classic speed of v rows per tick
+func (m *model) launch(v int) {
+	m.velY = v
+	m.velFx, m.subY = v*momentumLift, 0
+}
+
+// halt stops vertical movement, on landing or against a wall
+func (m *model) halt() {
+	m.velY, m.velFx, m.subY = 0, 0, 0
+}
+
+// fall applies one tick of gravity and vertical movement
+func (m *model) fall() {
+	_, g, terminal := m.arc()
+	if !m.momentum() {
+		m.velY += g
+		m.playerY += m.velY
+		return
+	}
+	m.velFx = min(m.velFx+g, terminal)
+	pos := m.playerY*fixedOne + m.subY + m.velFx
+	m.playerY, m.subY = floorDiv(pos, fixedOne)
+	m.velY = m.velFx / fixedOne // whole rows, for the terrain checks
+}
+
+// dive is air control under momentum physics: a rising gopher loses half
+// its upward speed, a falling one drops faster. Lockstep races only
+// exchange jumps, so it's off there.
+func (m *model) dive() {
+	if !m.momentum() || m.race.lockstep || !m.live() || m.grounded() {
+		return
+	}
+	m.seats.dives++
+	if m.velFx < 0 {
+		m.velFx /= 2
+	} else {
+		_, _, terminal := m.arc()
+		m.velFx = min(m.velFx+diveKick, terminal)
+	}
+	m.logDebug("dive", "vel", m.velFx)
+}
+
+// cutJump cuts a rising jump short when jump comes up early (see
+// keyboard.go), halving the speed as a dive would; it reports whether it did
+func (m *model) cutJump() bool {
+	if !m.momentum() || m.race.lockstep || !m.live() || m.grounded() || m.velFx >= 0 {
+		return false
+	}
+	m.velFx /= 2
+	m.logDebug("jump cut", "vel", m.velFx)
+	return true
+}
+
+// floorDiv splits a into a whole number of b and a non-negative remainder
+func floorDiv(a, b int) (q, r int) {
+	q, r = a/b, a%b
+	if r < 0 {
+		q, r = q-1, r+b
+	}
+	return q, r
+}
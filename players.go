@@ -0,0 +1,180 @@
+package gopherdash
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// PLAYER ACCOUNTS (`gopherdash players list|prune`)
+// ----------------------------------------------------------------------------
+
+// A server hosting a game per connection (see Model.Join) can keep each
+// player's saves apart, keyed to whatever it knows them by, usually their
+// SSH public key: WithPlayer gives the game a file store of its own in
+// .gopherdash_players/ID, the ID being a hash of the key, so a returning
+// player keeps their best, achievements, stats and replays whatever -store
+// says. The key is written beside the saves each time the player joins,
+// which is how `gopherdash players list` says whose they are and when they
+// were last here, and how `gopherdash players prune` finds the ones who
+// haven't been back.
+
+const (
+	playersDir    = ".gopherdash_players"
+	playerKeyFile = "key"
+	playerIDLen   = 16 // hex digits of the key's SHA-256 naming its directory
+)
+
+func playersPath() string { return dataPath(playersDir) }
+
+// playerDir is where the player with key keeps their saves
+func playerDir(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return filepath.Join(playersPath(), hex.EncodeToString(sum[:])[:playerIDLen])
+}
+
+// playerStore opens the saves of the player with key, making room for them
+// the first time, and notes that they've been
+func playerStore(key string) (Store, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, errors.New("a player needs a key")
+	}
+	dir := playerDir(key)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, playerKeyFile), []byte(key+"\n"), 0o644); err != nil {
+		return nil, err
+	}
+	return fileStore{dir: dir}, nil
+}
+
+// playerInfo is what `players list` says about a player
+type playerInfo struct {
+	id, key  string
+	seen     time.Time
+	best     int
+	runs     int
+	achieved int
+}
+
+// readPlayers lists the players with saves, the latest to have been first
+func readPlayers() ([]playerInfo, error) {
+	entries, err := os.ReadDir(playersPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var players []playerInfo
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(playersPath(), e.Name())
+		info := playerInfo{id: e.Name()}
+		if data, err := os.ReadFile(filepath.Join(dir, playerKeyFile)); err == nil {
+			info.key = strings.TrimSpace(string(data))
+		}
+		info.seen = modTime(filepath.Join(dir, playerKeyFile))
+		saves := fileStore{dir: dir}
+		if p, ok := saves.readProfile(); ok {
+			info.best, info.achieved = p.HighScore, len(p.Achievements)
+		}
+		info.runs = saves.readStats().Runs
+		players = append(players, info)
+	}
+	slices.SortFunc(players, func(a, b playerInfo) int { return b.seen.Compare(a.seen) })
+	return players, nil
+}
+
+// keyFingerprint shows an SSH public key as ssh-keygen -l does; other keys
+// are shown as they are, cut short
+func keyFingerprint(key string) string {
+	fields := strings.Fields(key)
+	if len(fields) >= 2 {
+		if blob, err := base64.StdEncoding.DecodeString(fields[1]); err == nil {
+			sum := sha256.Sum256(blob)
+			return fields[0] + " SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+		}
+	}
+	if len(key) > 40 {
+		return key[:39] + "…"
+	}
+	return key
+}
+
+// playersMain runs `gopherdash players list|prune`
+func playersMain(args []string) int {
+	return playersCommand(args, os.Stdout, time.Now())
+}
+
+func playersCommand(args []string, out io.Writer, now time.Time) int {
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("gopherdash players", flag.ContinueOnError)
+	days := fs.Int("days", 90, "prune removes the players not seen for this many days")
+	dry := fs.Bool("n", false, "prune only says who it would remove")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	players, err := readPlayers()
+	if err != nil {
+		return exitCode(err)
+	}
+	switch action {
+	case "list":
+		if len(players) == 0 {
+			fmt.Fprintln(out, "No players yet.")
+			return 0
+		}
+		fmt.Fprintf(out, "%d players in %s\n", len(players), playersPath())
+		fmt.Fprintf(out, "  %-*s  %6s  %5s  %4s  %-10s  %s\n", playerIDLen, "ID", "BEST", "RUNS", "ACH", "SEEN", "KEY")
+		for _, p := range players {
+			fmt.Fprintf(out, "  %-*s  %6d  %5d  %4d  %-10s  %s\n", playerIDLen, p.id, p.best, p.runs, p.achieved,
+				p.seen.Format(time.DateOnly), keyFingerprint(p.key))
+		}
+	case "prune":
+		if *days < 1 {
+			fmt.Fprintln(out, "players: -days must be at least 1")
+			return exitUsage
+		}
+		cutoff := now.AddDate(0, 0, -*days)
+		pruned := 0
+		for _, p := range players {
+			if p.seen.After(cutoff) {
+				continue
+			}
+			if !*dry {
+				if err := os.RemoveAll(filepath.Join(playersPath(), p.id)); err != nil {
+					return exitCode(err)
+				}
+			}
+			fmt.Fprintf(out, "  %s  best %d, last seen %s\n", p.id, p.best, p.seen.Format(time.DateOnly))
+			pruned++
+		}
+		verb := "Removed"
+		if *dry {
+			verb = "Would remove"
+		}
+		fmt.Fprintf(out, "%s %d of %d players, none seen in %d days.\n", verb, pruned, len(players), *days)
+	default:
+		fmt.Fprintf(out, "players: unknown action %q (want list or prune)\n", action)
+		return exitUsage
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package gopherdash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	aliceKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+	bobKey   = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBaXWwhJU3zGYrqQVFWoBzLXg6nMIxTdUJy7fJr4kX3w"
+)
+
+// TestPlayerStores keeps each player's saves apart from the others' and
+// from the install's own
+func TestPlayerStores(t *testing.T) {
+	isolateSaves(t)
+	g, err := New(WithPlayer(aliceKey + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveProfile(g.m.saves, profile{Version: profileVersion, HighScore: 120}); err != nil {
+		t.Fatal(err)
+	}
+	saveStats(g.m.saves, stats{Runs: 1})
+
+	again, _ := New(WithPlayer(aliceKey))
+	if best := loadProfile(again.m.saves).HighScore; best != 120 {
+		t.Errorf("back again, alice's best is %d", best)
+	}
+	if runs := loadStats(again.m.saves).Runs; runs != 1 {
+		t.Errorf("back again, alice has %d runs", runs)
+	}
+	bob, _ := New(WithPlayer(bobKey))
+	if best := loadProfile(bob.m.saves).HighScore; best != 0 {
+		t.Errorf("bob has alice's best, %d", best)
+	}
+	if best := loadProfile(FileStore()).HighScore; best != 0 {
+		t.Errorf("the install's own profile has %d", best)
+	}
+	if _, err := New(WithPlayer("  ")); err == nil {
+		t.Error("a blank key made a player")
+	}
+}
+
+func TestPlayersCommand(t *testing.T) {
+	isolateSaves(t)
+	now := time.Now()
+	for key, best := range map[string]int{aliceKey: 300, bobKey: 40} {
+		s, err := playerStore(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = saveProfile(s, profile{Version: profileVersion, HighScore: best})
+	}
+	long := now.AddDate(0, 0, -120)
+	_ = os.Chtimes(filepath.Join(playerDir(bobKey), playerKeyFile), long, long)
+
+	var out strings.Builder
+	if code := playersCommand(nil, &out, now); code != 0 {
+		t.Fatalf("list: exit %d", code)
+	}
+	list := out.String()
+	for _, want := range []string{"2 players", "   300", keyFingerprint(aliceKey), filepath.Base(playerDir(bobKey))} {
+		if !strings.Contains(list, want) {
+			t.Errorf("no %q in the list:\n%s", want, list)
+		}
+	}
+	if strings.Index(list, "   300") > strings.Index(list, "    40") {
+		t.Errorf("bob, seen long ago, is listed first:\n%s", list)
+	}
+
+	out.Reset()
+	if code := playersCommand([]string{"prune", "-n"}, &out, now); code != 0 || !strings.Contains(out.String(), "Would remove 1 of 2") {
+		t.Errorf("prune -n: exit %d\n%s", code, out.String())
+	}
+	if _, err := os.Stat(playerDir(bobKey)); err != nil {
+		t.Fatal("prune -n removed bob")
+	}
+	out.Reset()
+	if code := playersCommand([]string{"prune", "-days", "90"}, &out, now); code != 0 {
+		t.Fatalf("prune: exit %d\n%s", code, out.String())
+	}
+	if _, err := os.Stat(playerDir(bobKey)); !os.IsNotExist(err) {
+		t.Error("bob, not seen in 120 days, is still there")
+	}
+	if _, err := os.Stat(playerDir(aliceKey)); err != nil {
+		t.Error("alice, here today, was pruned")
+	}
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	// ssh-keygen -l -E sha256 gives SHA256:<unpadded base64 of the blob's hash>
+	fp := keyFingerprint(aliceKey + " alice@laptop")
+	if !strings.HasPrefix(fp, "ssh-ed25519 SHA256:") || len(fp) != len("ssh-ed25519 SHA256:")+43 {
+		t.Errorf("fingerprint %q", fp)
+	}
+	if got := keyFingerprint("player-7"); got != "player-7" {
+		t.Errorf("a plain key shown as %q", got)
+	}
+}
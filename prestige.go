@@ -0,0 +1,98 @@
+package gopherdash
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// PRESTIGE LOOPS (endless runs)
+// ----------------------------------------------------------------------------
+
+// Once an endless run's loop scores prestigeAt, R prestiges: the score so
+// far is banked, the distance, speed and course start over, and every
+// point from then on counts prestigeStep more for each loop, for the rest
+// of the run. Each loop's course is denser than the last (prestigeTables),
+// and the HUD wears a star per loop. Seeded, daily and weekly runs and
+// races share a course with someone else, so they don't loop.
+
+const (
+	prestigeAt   = 1000 // a loop's score that unlocks the next prestige
+	prestigeStep = 50   // extra multiplier per loop, in hundredths
+)
+
+// prestigeTables is the spawn chance of each loop's course; later loops
+// keep the last
+var prestigeTables = []float64{spawnChance, 0.15, 0.18, 0.21, 0.24}
+
+// actPrestige is a prestige on a replay's tape
+const actPrestige = "prestige"
+
+// prestigeChance is the spawn chance for loop
+func prestigeChance(loop int) float64 {
+	return prestigeTables[min(loop, len(prestigeTables)-1)]
+}
+
+// loopMultiplier is the run's multiplier in hundredths
+func (m model) loopMultiplier() int { return 100 + prestigeStep*m.loop }
+
+// endless reports whether the run is on a course of its own
+func (m model) endless() bool {
+	_, fixed := m.cfg.fixedSeed()
+	return !fixed && !m.racing()
+}
+
+// canPrestige reports whether R would prestige now
+func (m model) canPrestige() bool {
+	return m.endless() && m.live() && m.loopScore() >= prestigeAt
+}
+
+// prestige banks the score and starts the next loop on a fresh course
+func (m *model) prestige() {
+	m.banked = m.score()
+	m.loop++
+	m.dist, m.bonus, m.boostLeft = 0, 0, 0
+	m.points, m.combo, m.cleared = 0, 0, 0
+	m.fox, m.foxCalm = foxStart, 0
+	m.acorns = nil
+	m.frameDur = m.capFrame(startFrame)
+	m.music.setSpeed(speedFactor(m.frameDur))
+	m.obstacles = nil
+	m.spawn = newSpawner(m.seed+int64(m.loop), playerCol+1, m.cfg.GraceCells)
+	m.fitSpawner()
+	m.startDirector()
+	m.fillObstacles()
+	m.banner, m.bannerAt = fmt.Sprintf("Prestige %s ×%s", m.stars(), multiplierLabel(m.loopMultiplier())), m.now()
+	m.logInfo("prestige", "loop", m.loop, "banked", m.banked)
+}
+
+// stars is the run's badge: a star per loop
+func (m model) stars() string { return strings.Repeat("★", m.loop) }
+
+// multiplierLabel writes a multiplier in hundredths as 1.5 or 2
+func multiplierLabel(hundredths int) string {
+	if hundredths%100 == 0 {
+		return fmt.Sprint(hundredths / 100)
+	}
+	return strings.TrimRight(fmt.Sprintf("%.2f", float64(hundredths)/100), "0")
+}
+
+// prestigeLine is the run's badge for the game-over screen
+func (m model) prestigeLine() string {
+	if m.loop == 0 {
+		return ""
+	}
+	return fmt.Sprintf("   Prestige %s ×%s", m.stars(), multiplierLabel(m.loopMultiplier()))
+}
+
+// prestigeHUD is the badge and the offer, if there's either
+func (m model) prestigeHUD() string {
+	var parts []string
+	if m.loop > 0 {
+		parts = append(parts, fmt.Sprintf("%s ×%s: %d", m.stars(), multiplierLabel(m.loopMultiplier()), m.score()))
+	}
+	if m.canPrestige() {
+		parts = append(parts, "R = prestige")
+	}
+	return strings.Join(parts, "   ")
+}
@@ -0,0 +1,72 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPrestige(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	m.dist = prestigeAt - 1
+	if m.canPrestige() {
+		t.Fatal("prestige offered short of the threshold")
+	}
+	m.dist, m.bonus = prestigeAt, 20
+	if !m.canPrestige() {
+		t.Fatal("no prestige at the threshold")
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(model)
+	if m.loop != 1 || m.banked != prestigeAt+20 || m.dist != 0 || m.bonus != 0 {
+		t.Fatalf("after R: loop %d, banked %d, dist %d, bonus %d", m.loop, m.banked, m.dist, m.bonus)
+	}
+	if m.frameDur != startFrame || m.spawn.chance != prestigeTables[1] {
+		t.Errorf("loop 1 runs at %v with spawn chance %v", m.frameDur, m.spawn.chance)
+	}
+	if got := m.tape.Inputs[len(m.tape.Inputs)-1].Act; got != actPrestige {
+		t.Errorf("tape ends with %q", got)
+	}
+	m.dist = 10
+	if got, want := m.score(), prestigeAt+20+15; got != want {
+		t.Errorf("score %d at ×1.5, want %d", got, want)
+	}
+	if m.canPrestige() {
+		t.Error("prestige offered again straight away")
+	}
+
+	// a state dump keeps the loop, and the denser course with it
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back model
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.loop != 1 || back.banked != m.banked || back.spawn.chance != m.spawn.chance || back.score() != m.score() {
+		t.Errorf("restored loop %d, banked %d, chance %v", back.loop, back.banked, back.spawn.chance)
+	}
+
+	seeded := cfg
+	seeded.Seed = 7
+	m, _ = clockedModel(t, seeded)
+	m.dist = prestigeAt
+	if m.canPrestige() {
+		t.Error("a seeded run can prestige")
+	}
+	if got := prestigeChance(50); got != prestigeTables[len(prestigeTables)-1] {
+		t.Errorf("loop 50's chance %v", got)
+	}
+}
+
+func TestMultiplierLabel(t *testing.T) {
+	for in, want := range map[int]string{100: "1", 150: "1.5", 200: "2", 125: "1.25"} {
+		if got := multiplierLabel(in); got != want {
+			t.Errorf("%d: %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// PROFILE (versioned save format)
+// ----------------------------------------------------------------------------
+
+const (
+	profileFile    = ".gopherdash_profile"
+	profileVersion = 4 // bump and add a migration when the format changes
+
+	// legacyHighscoreFile is the pre-profile save: one plain-text integer
+	legacyHighscoreFile = ".gopherdash_highscore"
+)
+
+// profile is the player's persistent record. Version 0 is the legacy
+// highscore file; migrations bring anything older than profileVersion up
+// to date, and a profile from a newer build is read but never written, so
+// running an old binary can't throw away fields it doesn't know about.
+type profile struct {
+	Version   int    `json:"version"`
+	HighScore int    `json:"high_score"`
+	MAC       string `json:"mac,omitempty"` // HMAC-SHA256 with the install key (see integrity.go)
+
+	// Achievements maps seasonal achievement names to the day they were
+	// earned (see seasons.go)
+	Achievements map[string]string `json:"achievements,omitempty"`
+
+	// ModBests holds the best score for each combination of run modifiers,
+	// keyed by modsKey (see modifiers.go)
+	ModBests map[string]int `json:"mod_bests,omitempty"`
+
+	// LatencyMS is the input round trip `gopherdash latency` measured
+	// (see latency.go)
+	LatencyMS int `json:"latency_ms,omitempty"`
+}
+
+// migrations[v] upgrades a version v profile to version v+1
+var migrations = []func(profile) profile{
+	// 0 → 1: the bare high score becomes a JSON profile
+	func(p profile) profile { return p },
+	// 1 → 2: achievements; older builds would drop them on their next save
+	func(p profile) profile { return p },
+	// 2 → 3: bests for modified runs, likewise
+	func(p profile) profile { return p },
+	// 3 → 4: the latency calibration, likewise
+	func(p profile) profile { return p },
+}
+
+func profilePath() string { return dataPath(profileFile) }
+
+// newer reports whether p was written by a later gopherdash
+func (p profile) newer() bool { return p.Version > profileVersion }
+
+// loadProfile reads the profile from s, migrating older formats on the way
+func loadProfile(s Store) profile {
+	p, ok := s.readProfile()
+	if !ok || p.Version >= profileVersion {
+		return p
+	}
+	from := p.Version
+	for p.Version < profileVersion {
+		p = migrations[p.Version](p)
+		p.Version++
+	}
+	s.lock(func() { s.upgradeProfile(p, from) })
+	return p
+}
+
+func (f fileStore) readProfile() (profile, bool) {
+	p, _, ok := f.readProfileFile()
+	return p, ok
+}
+
+// upgradeProfile keeps the file it migrated from as a .v<N>.bak copy
+func (f fileStore) upgradeProfile(p profile, from int) {
+	_, src, ok := f.readProfileFile()
+	if !ok {
+		return
+	}
+	data, err := os.ReadFile(src)
+	if err != nil || os.WriteFile(fmt.Sprintf("%s.v%d.bak", src, from), data, 0o644) != nil {
+		return // no backup, so leave the old file alone and retry next time
+	}
+	if f.writeProfile(p) == nil && src != f.path(profileFile) {
+		_ = os.Remove(src)
+	}
+}
+
+// readProfileFile finds the current save, falling back to the legacy
+// highscore file, and returns it with the path it came from
+func (f fileStore) readProfileFile() (p profile, src string, ok bool) {
+	if data, err := os.ReadFile(f.path(profileFile)); err == nil {
+		if json.Unmarshal(data, &p) != nil || p.HighScore < 0 {
+			return profile{Version: profileVersion}, "", false
+		}
+		return p, f.path(profileFile), true
+	}
+	data, err := os.ReadFile(f.path(legacyHighscoreFile))
+	if err != nil {
+		return profile{Version: profileVersion}, "", false
+	}
+	s, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || s < 0 {
+		return profile{Version: profileVersion}, "", false
+	}
+	return profile{Version: 0, HighScore: s}, f.path(legacyHighscoreFile), true
+}
+
+// saveProfile stores p in s, unless the saved profile is from a newer build
+func saveProfile(s Store, p profile) error {
+	if p.newer() {
+		return fmt.Errorf("profile version %d is newer than this build (%d)", p.Version, profileVersion)
+	}
+	return s.writeProfile(p)
+}
+
+// writeProfile writes p atomically
+func (f fileStore) writeProfile(p profile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := f.path(profileFile) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(profileFile))
+}
+
+// saveProfile persists the model's profile, signing it if -sign-saves is
+// on. Another instance may have written a better score in the meantime; if
+// so that one is kept and adopted, and saveProfile reports false.
+// Achievements and modified-run bests from both are kept either way.
+func (m *model) saveProfile() (kept bool) {
+	kept = true
+	m.saves.lock(func() {
+		trusted := true
+		if disk, ok := m.saves.readProfile(); ok && !disk.newer() {
+			earned := mergeAchievements(disk.Achievements, m.profile.Achievements)
+			bests := mergeBests(disk.ModBests, m.profile.ModBests)
+			if disk.HighScore >= m.profile.HighScore {
+				kept = disk.HighScore == m.profile.HighScore
+				if len(earned) == len(disk.Achievements) && maps.Equal(bests, disk.ModBests) {
+					m.profile = disk // nothing new to write
+					return
+				}
+				// only re-sign a score we didn't set if it was signed already
+				m.profile.HighScore, trusted = disk.HighScore, disk.verified()
+			}
+			m.profile.Achievements, m.profile.ModBests = earned, bests
+			m.profile.LatencyMS = disk.LatencyMS // only `gopherdash latency` sets it
+		}
+		if !m.profile.newer() {
+			m.profile.MAC = ""
+			if m.cfg.SignSaves && trusted {
+				m.profile.sign()
+			}
+		}
+		if err := saveProfile(m.saves, m.profile); err != nil {
+			m.notify("High score not saved: " + err.Error())
+		}
+	})
+	m.verified = m.profile.verified()
+	return kept
+}
+
+// mergeAchievements is the union of two achievement sets, keeping the
+// earlier date for any earned in both
+func mergeAchievements(a, b map[string]string) map[string]string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(a)+len(b))
+	for name, day := range a {
+		out[name] = day
+	}
+	for name, day := range b {
+		if d, ok := out[name]; !ok || day < d {
+			out[name] = day
+		}
+	}
+	return out
+}
+
+// mergeBests keeps the higher score for every key in either set
+func mergeBests(a, b map[string]int) map[string]int {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	out := maps.Clone(a)
+	if out == nil {
+		out = make(map[string]int, len(b))
+	}
+	for key, score := range b {
+		out[key] = max(out[key], score)
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+package gopherdash
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// onDisk lists the files in the data dir
+func onDisk(t *testing.T) []string {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestProfileMigration(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string // save files before loading
+		best  int
+		after []string // the files there afterwards
+	}{
+		{"nothing saved", nil, 0, nil},
+		{"legacy highscore",
+			map[string]string{legacyHighscoreFile: "42\n"}, 42,
+			[]string{profileFile, legacyHighscoreFile + ".v0.bak"}},
+		{"unreadable legacy highscore",
+			map[string]string{legacyHighscoreFile: "lots"}, 0,
+			[]string{legacyHighscoreFile}},
+		{"version 1 profile",
+			map[string]string{profileFile: `{"version": 1, "high_score": 7}`}, 7,
+			[]string{profileFile, profileFile + ".v1.bak"}},
+		{"current profile",
+			map[string]string{profileFile: `{"version": 4, "high_score": 9}`}, 9,
+			[]string{profileFile}},
+		{"profile beside a legacy highscore",
+			map[string]string{profileFile: `{"version": 4, "high_score": 9}`, legacyHighscoreFile: "42"}, 9,
+			[]string{profileFile, legacyHighscoreFile}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			isolateSaves(t)
+			saves := FileStore()
+			for name, data := range tc.files {
+				if err := os.WriteFile(filepath.Join(dataDir, name), []byte(data), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p := loadProfile(saves)
+			if p.HighScore != tc.best || p.Version != profileVersion {
+				t.Errorf("loaded best %d at version %d, want %d at %d", p.HighScore, p.Version, tc.best, profileVersion)
+			}
+			got, want := onDisk(t), slices.Sorted(slices.Values(tc.after))
+			if !slices.Equal(got, want) {
+				t.Errorf("files afterwards %q, want %q", got, want)
+			}
+			if data, ok := tc.files[legacyHighscoreFile]; ok && slices.Contains(got, legacyHighscoreFile+".v0.bak") {
+				backup, _ := os.ReadFile(filepath.Join(dataDir, legacyHighscoreFile+".v0.bak"))
+				if string(backup) != data {
+					t.Errorf("backup holds %q, want %q", backup, data)
+				}
+			}
+			if again := loadProfile(saves); again.HighScore != tc.best {
+				t.Errorf("loaded again, best %d", again.HighScore)
+			}
+		})
+	}
+}
+
+func TestNewerProfileKept(t *testing.T) {
+	isolateSaves(t)
+	saves := FileStore()
+	newer := `{"version": 99, "high_score": 5, "shiny": true}`
+	if err := os.WriteFile(profilePath(), []byte(newer), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := loadProfile(saves)
+	if p.HighScore != 5 || !p.newer() {
+		t.Fatalf("loaded %+v", p)
+	}
+	p.HighScore = 50
+	if saveProfile(saves, p) == nil {
+		t.Error("saved over a profile from a newer build")
+	}
+	if data, _ := os.ReadFile(profilePath()); string(data) != newer {
+		t.Errorf("the profile now holds %s", data)
+	}
+}
+
+func TestProfileSignature(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(p *profile)
+		ok     bool
+	}{
+		{"as signed", func(*profile) {}, true},
+		{"score edited", func(p *profile) { p.HighScore++ }, false},
+		{"achievement added", func(p *profile) { p.Achievements["Halloween"] = "2026-10-31" }, false},
+		{"modified-run best edited", func(p *profile) { p.ModBests["ice"] = 1000 }, false},
+		{"signature stripped", func(p *profile) { p.MAC = "" }, false},
+		{"signature garbled", func(p *profile) { p.MAC = "zz" + p.MAC[2:] }, false},
+		{"from a newer build", func(p *profile) { p.Version++ }, false},
+		{"key lost", func(*profile) { _ = os.Remove(keyPath()) }, false},
+		{"key damaged", func(*profile) { _ = os.WriteFile(keyPath(), []byte("short\n"), 0o600) }, false},
+		{"signed by another install", func(*profile) {
+			_ = os.Remove(keyPath())
+			installKey(true)
+		}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			isolateSaves(t)
+			p := profile{Version: profileVersion, HighScore: 120,
+				Achievements: map[string]string{}, ModBests: map[string]int{"ice": 80}}
+			p.sign()
+			if !p.verified() {
+				t.Fatal("a freshly signed profile doesn't verify")
+			}
+			tc.change(&p)
+			if got := p.verified(); got != tc.ok {
+				t.Errorf("verified = %v, want %v", got, tc.ok)
+			}
+		})
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	isolateSaves(t)
+	if installKey(false) != nil {
+		t.Fatal("found a key before one was made")
+	}
+	key := installKey(true)
+	if len(key) != 32 || !slices.Equal(installKey(false), key) {
+		t.Fatalf("made key %x, read back %x", key, installKey(false))
+	}
+	if info, err := os.Stat(keyPath()); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file: %v, %v", info, err)
+	}
+	_ = os.WriteFile(keyPath(), []byte("not hex\n"), 0o600)
+	if installKey(true) != nil {
+		t.Error("a damaged key was replaced")
+	}
+}
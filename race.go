@@ -0,0 +1,342 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// HEAD-TO-HEAD RACE (TCP)
+// ----------------------------------------------------------------------------
+
+const (
+	raceProto       = 3 // bumped whenever raceWire or the courses change incompatibly
+	raceDefaultAddr = ":7777"
+	raceDialTimeout = 10 * time.Second
+	raceReportEvery = 5 // gameplay steps between distance updates
+	raceBarWidth    = 10
+)
+
+// raceWire is one message on the wire; the connection carries one JSON
+// object per line
+type raceWire struct {
+	Type     string     `json:"type"` // "hello" (host → guest, once), "state", "input" or "emote"
+	Proto    int        `json:"proto,omitempty"`
+	Seed     int64      `json:"seed,omitempty"`
+	Rules    *raceRules `json:"rules,omitempty"`
+	Lockstep bool       `json:"lockstep,omitempty"`
+	Dist     int        `json:"dist"`
+	Alive    bool       `json:"alive"`
+	Tick     int        `json:"tick,omitempty"` // input: the step it applies to
+	Jump     bool       `json:"jump,omitempty"`
+	Emote    string     `json:"emote,omitempty"` // emote: one of emotes (see emote.go)
+}
+
+// raceRules are the host's difficulty settings, which both players use
+type raceRules struct {
+	JumpBuffer int  `json:"jump_buffer"`
+	Coyote     int  `json:"coyote"`
+	GraceCells int  `json:"grace_cells"`
+	Terrain    bool `json:"terrain,omitempty"`
+	Fox        bool `json:"fox,omitempty"`
+	Zones      bool `json:"zones,omitempty"`
+
+	Class   string   `json:"class,omitempty"` // everyone runs as the host's character
+	Physics string   `json:"physics,omitempty"`
+	Mods    []string `json:"mods,omitempty"`
+}
+
+func (c config) raceRules() *raceRules {
+	return &raceRules{c.JumpBuffer, c.Coyote, c.GraceCells, c.Terrain, c.Fox, c.Zones, c.Class, c.Physics, c.runMods()}
+}
+
+func (c *config) applyRaceRules(r *raceRules) {
+	if r == nil {
+		return
+	}
+	c.JumpBuffer, c.Coyote, c.GraceCells = r.JumpBuffer, r.Coyote, r.GraceCells
+	c.Terrain, c.Fox, c.Zones = r.Terrain, r.Fox, r.Zones
+	if _, ok := characterByName(r.Class); ok {
+		c.Class = r.Class
+	}
+	if validPhysics(r.Physics) == nil {
+		c.Physics = r.Physics
+	}
+	c.Mods = r.Mods
+}
+
+// raceOppMsg carries the opponent's latest state into Update
+type raceOppMsg struct {
+	dist  int
+	alive bool
+}
+
+// raceGoneMsg reports that the connection to the opponent dropped
+type raceGoneMsg struct{ err error }
+
+// raceLink is the connection to the opponent; writes go through a buffered
+// channel so Update doesn't wait on the network
+type raceLink struct {
+	conn net.Conn
+	dec  *json.Decoder
+	out  chan raceWire
+	done chan struct{}
+}
+
+func newRaceLink(conn net.Conn) *raceLink {
+	l := &raceLink{
+		conn: conn,
+		dec:  json.NewDecoder(conn),
+		out:  make(chan raceWire, 256),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(l.done)
+		enc := json.NewEncoder(conn)
+		var err error
+		for w := range l.out {
+			if err == nil { // after an error keep draining so queue never blocks
+				err = enc.Encode(w)
+			}
+		}
+	}()
+	return l
+}
+
+// send queues w, dropping it if the writer has fallen behind; the next state
+// update supersedes it anyway
+func (l *raceLink) send(w raceWire) {
+	select {
+	case l.out <- w:
+	default:
+	}
+}
+
+// queue sends w even if it has to wait for room; lockstep inputs must all
+// arrive or the opponent stalls
+func (l *raceLink) queue(w raceWire) { l.out <- w }
+
+// close flushes queued messages (briefly) and hangs up
+func (l *raceLink) close() {
+	close(l.out)
+	select {
+	case <-l.done:
+	case <-time.After(time.Second):
+	}
+	l.conn.Close()
+}
+
+// read forwards the opponent's state until the connection ends
+func (l *raceLink) read(send func(tea.Msg)) {
+	for {
+		var w raceWire
+		if err := l.dec.Decode(&w); err != nil {
+			send(raceGoneMsg{err})
+			return
+		}
+		switch w.Type {
+		case "state":
+			send(raceOppMsg{w.Dist, w.Alive})
+		case "input":
+			send(raceInputMsg{w.Tick, w.Jump})
+		case "emote":
+			send(raceEmoteMsg{w.Emote})
+		}
+	}
+}
+
+// hostRace waits for one opponent on addr and greets them with hello, which
+// carries the course seed and rules
+func hostRace(addr string, hello raceWire) (*raceLink, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer ln.Close()
+	fmt.Printf("Waiting for an opponent on %s (Ctrl+C to give up)…\n", ln.Addr())
+	conn, err := ln.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l := newRaceLink(conn)
+	hello.Type, hello.Proto = "hello", raceProto
+	l.queue(hello)
+	return l, nil
+}
+
+// joinRace connects to a host and returns its greeting
+func joinRace(addr string) (*raceLink, raceWire, error) {
+	var hello raceWire
+	conn, err := net.DialTimeout("tcp", addr, raceDialTimeout)
+	if err != nil {
+		return nil, hello, err
+	}
+	l := newRaceLink(conn)
+	_ = conn.SetReadDeadline(time.Now().Add(raceDialTimeout))
+	if err := l.dec.Decode(&hello); err != nil {
+		l.close()
+		return nil, hello, fmt.Errorf("no greeting from host: %w", err)
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	if hello.Type != "hello" || hello.Proto != raceProto {
+		l.close()
+		return nil, hello, fmt.Errorf("host speaks race protocol %d, want %d", hello.Proto, raceProto)
+	}
+	return l, hello, nil
+}
+
+// raceMain runs `gopherdash race -host [-addr :port] [-lockstep]` or
+// `gopherdash race -join host:port`; game flags are accepted as usual
+func raceMain(args []string) int {
+	cfg := loadConfig()
+	fs := flag.NewFlagSet("gopherdash race", flag.ContinueOnError)
+	cfg.bindFlags(fs)
+	host := fs.Bool("host", false, "wait for an opponent to join")
+	addr := fs.String("addr", raceDefaultAddr, "address to listen on with -host")
+	join := fs.String("join", "", "join the race hosted at host:port")
+	lockstep := fs.Bool("lockstep", false,
+		"with -host: exchange inputs every tick and show the opponent's playfield")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage // flag package already printed the usage
+	}
+	cfg.clamp()
+	if *host == (*join != "") {
+		fmt.Fprintln(os.Stderr, "race: pass exactly one of -host or -join host:port")
+		return exitUsage
+	}
+
+	var (
+		link  *raceLink
+		hello raceWire
+		err   error
+	)
+	if *host {
+		hello = raceWire{Seed: time.Now().UnixNano(), Rules: cfg.raceRules(), Lockstep: *lockstep}
+		if s, ok := cfg.fixedSeed(); ok {
+			hello.Seed = s
+		}
+		link, err = hostRace(*addr, hello)
+	} else {
+		link, hello, err = joinRace(*join)
+	}
+	if err != nil {
+		return exitCode(err)
+	}
+	defer link.close()
+
+	// both sides run the host's course under the host's rules
+	cfg.Seed, cfg.Daily, cfg.Weekly = hello.Seed, false, false
+	cfg.Director = false // it would shape the two courses apart
+	cfg.MinTick, cfg.MaxTick = 0, 0
+	cfg.applyRaceRules(hello.Rules)
+	if hello.Lockstep {
+		cfg.Twitch = "" // chat events can't be replayed on the other side
+	}
+	saves, err := openStore(cfg)
+	if err != nil {
+		return exitCode(err)
+	}
+	m := initialModel(cfg, saves)
+	m.offer = nil // a resumed solo run has no place in a race
+	m.race = raceState{link: link, oppAlive: true}
+	if hello.Lockstep {
+		m.startLockstep()
+	}
+	if err := runProgram(cfg, m); err != nil {
+		return exitCode(err)
+	}
+	return 0
+}
+
+// raceState is the model's view of the opponent
+type raceState struct {
+	link     *raceLink // nil outside race mode
+	oppDist  int
+	oppAlive bool
+	oppGone  bool  // connection lost; oppDist is the last distance heard
+	err      error // why the connection was lost
+
+	// emotes (see emote.go)
+	emote     string    // the opponent's latest
+	emoteAt   time.Time // when it came
+	emoteSent time.Time // when we last sent one
+	muted     bool      // the opponent's emotes aren't shown
+
+	// lockstep only (see lockstep.go)
+	lockstep  bool
+	opp       *model       // the opponent's run, simulated from their inputs
+	mine      map[int]bool // our jump presses by the step they apply to
+	theirs    map[int]bool // the opponent's, likewise
+	pending   bool         // jump pressed since the last step
+	waitSince time.Time    // when we started waiting for the opponent
+}
+
+func (m model) racing() bool { return m.race.link != nil }
+
+// raceReport tells the opponent how far we are; called every gameplay step,
+// it only sends every raceReportEvery steps and whenever the run ends
+func (m model) raceReport() {
+	if !m.racing() || m.race.oppGone || m.race.lockstep {
+		return
+	}
+	if !m.gameOver && m.dist%raceReportEvery != 0 {
+		return
+	}
+	m.race.link.send(raceWire{Type: "state", Dist: m.dist, Alive: !m.gameOver})
+}
+
+// oppFinished reports whether the opponent's final distance is known
+func (r raceState) oppFinished() bool { return !r.oppAlive || r.oppGone }
+
+// raceBar is the HUD ghost bar comparing both distances
+func (m model) raceBar() string {
+	scale := max(m.dist, m.race.oppDist, 1)
+	bar := func(d int) string {
+		n := d * raceBarWidth / scale
+		return strings.Repeat("█", n) + strings.Repeat("░", raceBarWidth-n)
+	}
+	opp := fmt.Sprintf("Opp %s %d", bar(m.race.oppDist), m.race.oppDist)
+	switch {
+	case m.race.oppGone:
+		opp += " (disconnected)"
+	case !m.race.oppAlive:
+		opp += " ✗"
+	}
+	if e := m.oppEmote(); e != "" {
+		opp += " " + e
+	}
+	return fmt.Sprintf("You %s %d  │ %s", bar(m.dist), m.dist, opp)
+}
+
+// raceResult is the results-screen headline and detail lines
+func (m model) raceResult() []string {
+	opp := fmt.Sprintf("Opponent: %d", m.race.oppDist)
+	if m.race.oppGone {
+		opp += " (disconnected)"
+		// a clean hang-up is not worth explaining
+		if err := m.race.err; err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+			opp += fmt.Sprintf(" – %v", err)
+		}
+	}
+	lines := []string{"", fmt.Sprintf("You: %d", m.dist), opp}
+	switch {
+	case !m.race.oppFinished():
+		lines[0] = "Waiting for your opponent…"
+	case m.dist > m.race.oppDist:
+		lines[0] = bannerStyle.Render("★ YOU WIN ★")
+	case m.dist < m.race.oppDist:
+		lines[0] = "You lose"
+	default:
+		lines[0] = "Dead heat!"
+	}
+	return lines
+}
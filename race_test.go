@@ -0,0 +1,115 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestLockstepCling plays a lockstep race against a mirror of ourselves on
+// terrain: every input we send comes straight back as the opponent's, so
+// the opponent's copy has to retrace our run exactly, wall clings and
+// wall jumps included
+func TestLockstepCling(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Terrain, cfg.Seed = 0, true, 1
+	m, _ := clockedModel(t, cfg)
+	m.startLockstep()
+	link := &raceLink{out: make(chan raceWire, 64)}
+	m.race.link = link
+	m.resizeOpp()
+
+	clung, wallJumps, sent := 0, 0, map[int]bool{}
+	theirDist := map[int]int{} // the mirror's distance by step
+	for m.steps < 3000 && !m.gameOver {
+		if !m.lockReady() {
+			t.Fatalf("stalled at step %d waiting for an input we sent", m.steps)
+		}
+		switch {
+		case m.clinging:
+			clung++
+			if clung%3 == 0 {
+				m.race.pending, wallJumps = true, wallJumps+1
+			}
+		case m.terrainAhead():
+			m.race.pending = true
+		}
+		m.lockInput()
+		m.step(m.now())
+		for len(link.out) > 0 {
+			w := <-link.out
+			if sent[w.Tick] {
+				t.Fatalf("input for step %d sent twice", w.Tick)
+			}
+			sent[w.Tick] = true
+			m.race.theirs[w.Tick] = w.Jump
+		}
+		m.advanceOpp()
+		theirDist[m.race.opp.steps] = m.race.opp.dist
+		if d, ok := theirDist[m.steps]; ok && d != m.dist {
+			t.Fatalf("after %d steps we've run %d, the mirror %d", m.steps, m.dist, d)
+		}
+		clearHazards(&m)
+		clearHazards(m.race.opp)
+	}
+	if clung == 0 || wallJumps == 0 {
+		t.Fatalf("the run never clung to a wall (%d steps, %s); pick another seed", m.steps, m.cause)
+	}
+}
+
+// terrainAhead reports whether the ground rises just ahead of where the
+// gopher will be when an input sent now applies
+func (m model) terrainAhead() bool {
+	x := m.camera().toWorld(playerCol) + lockDelay
+	return m.terrainAt(x+1) > m.terrainAt(x)
+}
+
+// clearHazards takes the rocks and holes out of a run, leaving the terrain
+func clearHazards(m *model) {
+	kept := m.obstacles[:0]
+	for _, ob := range m.obstacles {
+		if !hazardous(ob.kind) {
+			kept = append(kept, ob)
+		}
+	}
+	m.obstacles = kept
+}
+
+func TestRaceEmotes(t *testing.T) {
+	m, c := clockedModel(t, defaultConfig())
+	link := &raceLink{out: make(chan raceWire, 8)}
+	m.race = raceState{link: link, oppAlive: true}
+	press := func(key string) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(model)
+	}
+
+	press("2")
+	press("3") // too soon after the last
+	if len(link.out) != 1 {
+		t.Fatalf("sent %d emotes, want 1", len(link.out))
+	}
+	if w := <-link.out; w.Type != "emote" || w.Emote != "😱" {
+		t.Errorf("2 sent %+v", w)
+	}
+
+	for _, e := range []string{"🏁", "\x1b[2J"} { // the second isn't ours to show
+		next, _ := m.Update(raceEmoteMsg{e})
+		m = next.(model)
+	}
+	if !strings.HasSuffix(m.raceBar(), " 🏁") {
+		t.Errorf("opponent's emote missing from %q", m.raceBar())
+	}
+	c.t = c.t.Add(emoteShow + time.Second)
+	if strings.Contains(m.raceBar(), "🏁") {
+		t.Error("the emote outstayed its welcome")
+	}
+
+	press("m")
+	next, _ := m.Update(raceEmoteMsg{"👍"})
+	if m = next.(model); m.oppEmote() != "" {
+		t.Errorf("muted, but %s shows", m.oppEmote())
+	}
+}
@@ -0,0 +1,47 @@
+package gopherdash
+
+import "strings"
+
+// ----------------------------------------------------------------------------
+// PRACTICE RADAR
+// ----------------------------------------------------------------------------
+
+const radarScreens = 3 // radar spans the visible screen plus two ahead
+
+// spawnHorizon is how far right of the player's screen obstacles exist; in
+// practice mode the stream is generated two screens ahead so the radar has
+// something to show
+func (m model) spawnHorizon() int {
+	if m.cfg.Practice {
+		return m.gameCols * radarScreens
+	}
+	return m.gameCols
+}
+
+// renderRadar squeezes the whole spawn horizon into one row of the play pane:
+// each radar cell covers radarScreens world cells, with a tick where the
+// visible screen ends
+func (m model) renderRadar() string {
+	cells := make([]string, max(m.gameCols, 0))
+	for i := range cells {
+		cells[i] = "  "
+	}
+	if edge := m.gameCols / radarScreens; edge >= 0 && edge < len(cells) {
+		cells[edge] = "┆ "
+	}
+	cam := m.camera()
+	for _, ob := range m.obstacles {
+		for w := ob.x; w <= ob.extent().hi; w++ {
+			sx := cam.toScreen(w)
+			x := sx / radarScreens
+			if sx < 0 || x >= len(cells) {
+				continue
+			}
+			cells[x] = ob.kind.Radar()
+		}
+	}
+	if m.narrow() {
+		return narrowRow(cells)
+	}
+	return strings.Join(cells, "")
+}
@@ -0,0 +1,73 @@
+package gopherdash
+
+import (
+	"fmt"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// SERVER RECORDS (pub/sub between sessions in one process)
+// ----------------------------------------------------------------------------
+
+// A hosted server runs many sessions in one process: a program embedding
+// one game per connection (see Model.Join in embed.go). Whenever one of
+// them sets a new record for the whole server, every other session gets a
+// recordMsg and shows it as a HUD toast. A single local game is just a
+// server with one session, so it never hears its own records.
+
+// recordMsg tells a session that another one set a new server record
+type recordMsg struct{ dist int }
+
+// recordBus is shared by every session in the process, like metrics
+type recordBus struct {
+	mu     sync.Mutex
+	best   int
+	nextID int
+	subs   map[int]func(tea.Msg)
+}
+
+var records = &recordBus{subs: map[int]func(tea.Msg){}}
+
+// join registers a session whose own best is best and returns its id
+func (b *recordBus) join(best int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	b.best = max(b.best, best)
+	return b.nextID
+}
+
+// listen starts delivering records to session id through send
+func (b *recordBus) listen(id int, send func(tea.Msg)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[id] = send
+}
+
+func (b *recordBus) leave(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, id)
+}
+
+// publish offers dist from session id; if it beats the server record,
+// every other session is told
+func (b *recordBus) publish(id, dist int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if dist <= b.best {
+		return
+	}
+	b.best = dist
+	for sub, send := range b.subs {
+		if sub != id {
+			go send(recordMsg{dist}) // Send blocks until that session's Update takes it
+		}
+	}
+}
+
+func (msg recordMsg) toast() string {
+	return fmt.Sprintf("🏆 Another player just set a server record of %d!", msg.dist)
+}
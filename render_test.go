@@ -0,0 +1,181 @@
+package gopherdash
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tinySizes covers empty, negative, single-cell and just-too-small
+// terminals as well as a couple of normal ones
+var tinySizes = []int{-1, 0, 1, 2, 3, 4, 5, 7, 9, 13, 24, 80}
+
+// screens puts a fresh model into each state View draws differently
+var screens = map[string]func(*model){
+	"running": func(m *model) {},
+	"intro":   func(m *model) { m.cfg.Countdown = 3; m.startIntro() },
+	"paused":  func(m *model) { m.pause(pauseBlur) },
+	"over":    func(m *model) { m.dist = 40; m.setGameOver("rock") },
+	"confetti": func(m *model) {
+		m.newRecord = true
+		m.setGameOver("hole")
+		m.particles = spawnConfetti(m.w-2, gameOverRows)
+	},
+	"stats":     func(m *model) { m.setGameOver("rock"); m.showStats = true },
+	"perf":      func(m *model) { m.setGameOver("rock"); m.showPerf = true },
+	"readout":   func(m *model) { m.readout = true },
+	"offer":     func(m *model) { m.offer = &snapshot{Dist: 12, FrameDur: startFrame} },
+	"night":     func(m *model) { m.cfg.Night = true },
+	"lightning": func(m *model) { m.cfg.Night, m.lightning = true, true },
+	"terrain":   func(m *model) { m.cfg.Terrain = true; m.dist = 2000 },
+	"fox":       func(m *model) { m.cfg.Fox, m.fox = true, 1 },
+	"halloween": func(m *model) { m.event = &defaultEvents[0] },
+	"winter":    func(m *model) { m.event = &defaultEvents[1] },
+	"tank":      func(m *model) { m.cfg.Class = "tank" },
+	"momentum":  func(m *model) { m.cfg.Physics = physicsMomentum },
+	"fog":       func(m *model) { m.mods = []string{modFog} },
+	"hitboxes":  func(m *model) { m.cfg.Terrain, m.hitboxes = true, true; m.dist = 2000 },
+	"weekly":    func(m *model) { m.cfg.Weekly = true; m.mods = weeklyMods(time.Now()) },
+	"streak":    func(m *model) { m.cfg.Streak, m.streak = true, streak{Length: 3, Best: 5, Target: 240} },
+	"photo":     func(m *model) { m.paused, m.pauseWhy = true, pausePhoto },
+	"photo clean": func(m *model) {
+		m.paused, m.pauseWhy, m.photoClean = true, pausePhoto, true
+	},
+	"compact": func(m *model) { m.cfg.Practice, m.cfg.Timer = true, true },
+	"minimal": func(m *model) { m.cfg.Minimal, m.cfg.Practice = true, true },
+	"minimal lockstep": func(m *model) {
+		m.cfg.Minimal = true
+		m.startLockstep()
+		m.race.oppGone = true
+	},
+	"letterbox": func(m *model) { m.cfg.MaxCols, m.cfg.MaxRows = minMaxCols, minMaxRows },
+	"mods menu": func(m *model) { m.gameOver, m.showMods = true, true },
+	"about":     func(m *model) { m.setGameOver("rock"); m.showAbout = true },
+	"leaving": func(m *model) {
+		m.leaving, m.sitting = true, sessionRecord{Runs: 4, Best: 412, Distance: 1200, Played: time.Hour}
+	},
+	"big+shield": func(m *model) {
+		m.mods = []string{modBig, modShield}
+		m.reseed(m.seed)
+		m.fillObstacles()
+	},
+	"lockstep": func(m *model) {
+		m.startLockstep()
+		m.race.oppGone = true // no connection behind it
+	},
+	"kitty":  func(m *model) { m.gfx = newGraphics(rendererKitty, os.Getenv) },
+	"iterm2": func(m *model) { m.gfx = newGraphics(rendererITerm, os.Getenv) },
+	"sixel":  func(m *model) { m.gfx = &graphics{sixel: &sixelTerm{cellW: 1, cellH: 2, ok: true}} },
+	"practice+seed": func(m *model) {
+		m.cfg.Practice, m.cfg.Seed = true, 7
+		m.deaths = deathMap{seedKey(m.seed): {1, 5, 5, 30}}
+	},
+}
+
+// isolateSaves gives a test a store in memory, and keeps anything else it
+// writes in a temporary directory rather than next to the test binary
+func isolateSaves(t *testing.T) Store {
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir = "" })
+	return MemoryStore()
+}
+
+func tinyModel() model {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Store = 0, "memory"
+	m := initialModel(cfg, MemoryStore())
+	m.offer = nil
+	return m
+}
+
+// TestTinyTerminals renders every screen at every size in the matrix, and
+// again after ticks and a resize storm, failing on any panic
+func TestTinyTerminals(t *testing.T) {
+	isolateSaves(t)
+	for name, setup := range screens {
+		for _, w := range tinySizes {
+			for _, h := range tinySizes {
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							t.Errorf("%s at %dx%d: %v", name, w, h, r)
+						}
+					}()
+					m := tinyModel()
+					setup(&m)
+					next, _ := m.Update(tea.WindowSizeMsg{Width: w, Height: h})
+					m = next.(model)
+					_ = m.View()
+					for i := 0; i < 3; i++ {
+						next, _ = m.Update(tickMsg{m.tickGen, time.Now()})
+						m = next.(model)
+						_ = m.View()
+					}
+					next, _ = m.Update(tea.WindowSizeMsg{Width: h, Height: w})
+					m = next.(model)
+					_ = m.View()
+				}()
+			}
+		}
+	}
+}
+
+// TestRenderGameDegenerateGrid calls the playfield renderers directly with
+// grid sizes recalcSizes would never produce, as a state dump can
+func TestRenderGameDegenerateGrid(t *testing.T) {
+	isolateSaves(t)
+	for _, rows := range []int{-3, -1, 0, 1, 2, 3} {
+		for _, cols := range []int{-3, -1, 0, 1, 2, 3} {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("grid %dx%d: %v", cols, rows, r)
+					}
+				}()
+				m := tinyModel()
+				m.cfg.Practice, m.cfg.Seed = true, 7
+				m.w, m.h = 2*cols+2, rows+8
+				m.gameRows, m.gameCols = rows, cols
+				m.playerY = rows - 2
+				m.obstacles = []obstacle{{1, rock{}}, {playerCol, hole{}}, {4, rock{}}}
+				_ = m.renderGame()
+				_ = m.renderRadar()
+				_ = m.renderHeatmap()
+				_ = m.View()
+			}()
+		}
+	}
+}
+
+func TestHitMarks(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m, _ := clockedModel(t, cfg)
+	groundY := m.gameRows - 1
+	at := m.camera().toWorld(playerCol)
+	for _, tc := range []struct {
+		name string
+		kind ObstacleKind
+		air  int // rows the gopher is above the ground
+		y    int // row of the obstacle's cell
+		want hitMark
+	}{
+		{"rock on the ground", rock{}, 0, groundY - 1, markMet},
+		{"rock cleared", rock{}, 2, groundY - 1, markHazard},
+		{"hole on the ground", hole{}, 0, groundY, markMet},
+		{"hole cleared", hole{}, 2, groundY, markHazard},
+		{"springboard", springboard{}, 0, groundY, markTile},
+	} {
+		m.obstacles = []obstacle{{at, tc.kind}}
+		m.playerY = m.gameRows - 2 - tc.air
+		marks := m.hitMarks(m.gameCols, m.gameRows)
+		if got := marks[hitCell{playerCol, tc.y}]; got != tc.want {
+			t.Errorf("%s: marked %d, want %d", tc.name, got, tc.want)
+		}
+		if tc.air > 0 && marks[hitCell{playerCol, m.playerY}] != markPlayer {
+			t.Errorf("%s: the gopher's cell isn't marked", tc.name)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// REPLAYS (`gopherdash replay [-last] [FILE]`)
+// ----------------------------------------------------------------------------
+
+// Every solo run is taped: its seed and settings, and each jump, acorn,
+// dive and perk (see perks.go) with the step it came before. The engine is
+// deterministic (lockstep races rely on the same thing), so playing the
+// inputs back on the same course reproduces the run exactly. Every
+// replaySnapEvery steps the tape also keeps a state dump (see state.go),
+// which lets playback step backwards: it restores the nearest dump before
+// the wanted step and plays forward from there.
+//
+// The last run is kept in the store (see store.go), as
+// gopherdash-last.replay next to the binary with the file store, and a new
+// high score as gopherdash-best.replay as well; archives and cloud sync
+// carry both. `gopherdash replay` plays one back: Space pauses, ← and →
+// step a frame either way, and 1, 2 and 4 pick the speed. With -gif it
+// renders the run to an animated GIF instead (see gif.go).
+
+const (
+	replayFormat    = 2   // bump when the tape changes incompatibly, or the courses do
+	replaySnapEvery = 100 // steps between state dumps on the tape
+
+	// the taped runs kept in the store, and their files in the file store
+	replayLast     = "last"
+	replayBest     = "best"
+	lastReplayFile = "gopherdash-last.replay"
+	bestReplayFile = "gopherdash-best.replay"
+
+	controlsReplay = "Space = pause   ←→ = step   1/2/4 = speed   Q = quit"
+)
+
+// replay actions
+const (
+	actJump  = "jump"
+	actAcorn = "acorn"
+	actDive  = "dive"
+)
+
+// replay is a taped run
+type replay struct {
+	Format int           `json:"format"`
+	Seed   int64         `json:"seed"`
+	Mods   []string      `json:"mods,omitempty"`
+	Config config        `json:"config"`
+	Inputs []replayInput `json:"inputs"`
+	Snaps  []replaySnap  `json:"snapshots,omitempty"`
+	Steps  int           `json:"steps"` // steps the run lasted
+	Score  int           `json:"score"`
+	Cause  string        `json:"cause,omitempty"`
+	At     time.Time     `json:"at"`
+}
+
+// replayInput is one action, taken after Step steps of the run
+type replayInput struct {
+	Step int    `json:"step"`
+	Act  string `json:"act"`
+}
+
+// replaySnap is the run's state after Step steps, before that step's inputs
+type replaySnap struct {
+	Step  int             `json:"step"`
+	State json.RawMessage `json:"state"`
+}
+
+// playback is a replay being watched
+type playback struct {
+	tape   replay
+	next   int // index of the next input to apply
+	speed  int // 1, 2 or 4
+	paused bool
+}
+
+// replayNames are the tapes in the store (see also dailyghost.go)
+var replayNames = []string{replayLast, replayBest, replayDaily, replayYesterday}
+
+func replayPath(name string) string { return dataPath(replayFile(name)) }
+
+// replayFile is the file store's name for a tape
+func replayFile(name string) string {
+	switch name {
+	case replayBest:
+		return bestReplayFile
+	case replayDaily:
+		return dailyReplayFile
+	case replayYesterday:
+		return yesterdayReplayFile
+	}
+	return lastReplayFile
+}
+
+func (f fileStore) readReplay(name string) ([]byte, bool) {
+	data, err := os.ReadFile(f.path(replayFile(name)))
+	return data, err == nil
+}
+
+func (f fileStore) writeReplay(name string, data []byte) error {
+	return os.WriteFile(f.path(replayFile(name)), data, 0o644)
+}
+
+// startTape begins taping the run that's about to start; runs whose inputs
+// don't come from this keyboard alone aren't taped
+func (m *model) startTape() {
+	m.tape = nil
+	if m.ghost || m.saver || m.playback != nil || m.cfg.Twitch != "" {
+		return
+	}
+	cfg := m.cfg.redacted()
+	cfg.LoadState, cfg.Log = "", ""
+	m.tape = &replay{Format: replayFormat, Seed: m.seed, Mods: m.mods, Config: cfg}
+}
+
+// record tapes an action taken before the next step
+func (m *model) record(act string) {
+	if m.tape == nil || m.racing() {
+		return
+	}
+	m.tape.Inputs = append(m.tape.Inputs, replayInput{m.steps, act})
+}
+
+// snapTape puts a state dump on the tape every replaySnapEvery steps
+func (m *model) snapTape() {
+	if m.tape == nil || m.steps%replaySnapEvery != 0 || m.gameOver {
+		return
+	}
+	data, err := m.snapState()
+	if err != nil {
+		return
+	}
+	m.tape.Snaps = append(m.tape.Snaps, replaySnap{m.steps, data})
+}
+
+// snapState is a state dump without the settings, which the tape holds once
+func (m model) snapState() (json.RawMessage, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "config")
+	return json.Marshal(fields)
+}
+
+// saveReplay puts the finished run's tape in the store as the last run,
+// and as the best too if it set a new high score
+func (m *model) saveReplay() {
+	if m.tape == nil || m.racing() {
+		return
+	}
+	m.tape.Steps, m.tape.Score, m.tape.Cause = m.steps, m.score(), m.cause
+	m.tape.At = m.now()
+	data, err := json.Marshal(m.tape)
+	if err != nil {
+		return
+	}
+	if err := m.saves.writeReplay(replayLast, data); err != nil {
+		logger.Warn("saving replay", "err", err)
+		return
+	}
+	if m.newRecord {
+		_ = m.saves.writeReplay(replayBest, data)
+	}
+	m.saveDaily(data)
+	m.tape = nil
+}
+
+// parseReplay decodes a tape written by saveReplay; from names it in errors
+func parseReplay(data []byte, from string) (replay, error) {
+	var r replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("%s: %w", from, err)
+	}
+	if r.Format > replayFormat {
+		return r, fmt.Errorf("%s: recorded by a newer Gopher-Dash", from)
+	}
+	if r.Format == 0 || r.Seed == 0 {
+		return r, fmt.Errorf("%s: not a replay", from)
+	}
+	if r.Format < replayFormat {
+		return r, fmt.Errorf("%s: recorded on an older Gopher-Dash's courses", from)
+	}
+	sort.Slice(r.Snaps, func(i, j int) bool { return r.Snaps[i].Step < r.Snaps[j].Step })
+	return r, nil
+}
+
+// loadReplay reads a tape from a file
+func loadReplay(path string) (replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return replay{}, err
+	}
+	return parseReplay(data, path)
+}
+
+// storedReplay reads the best run's tape, or the last's, from cfg's store
+func storedReplay(cfg config, last bool) (replay, error) {
+	saves, err := openStore(cfg)
+	if err != nil {
+		return replay{}, err
+	}
+	name := replayBest
+	if last {
+		name = replayLast
+	}
+	data, ok := saves.readReplay(name)
+	if !ok {
+		return replay{}, fmt.Errorf("no %s run taped yet", name)
+	}
+	return parseReplay(data, name+" replay")
+}
+
+// replayMain runs `gopherdash replay [-last] [FILE]`
+func replayMain(args []string) int {
+	cfg := loadConfig()
+	fs := flag.NewFlagSet("gopherdash replay", flag.ContinueOnError)
+	last := fs.Bool("last", false, "play the last run rather than the best")
+	fs.StringVar(&cfg.Store, "store", cfg.Store, "store the taped runs are in: file, sqlite or memory")
+	fs.StringVar(&cfg.DB, "db", cfg.DB, "database file for -store sqlite")
+	out := fs.String("gif", "", "render the replay to this animated GIF instead of playing it")
+	cols := fs.Int("cols", 40, "playfield width of the GIF, in cells")
+	rows := fs.Int("rows", 12, "playfield height of the GIF, in cells")
+	scale := fs.Int("scale", gifCell, "pixels per cell in the GIF")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	path := fs.Arg(0)
+	if path != "" {
+		if err := fs.Parse(fs.Args()[1:]); err != nil { // flags after the file
+			return exitUsage
+		}
+	}
+	var (
+		tape replay
+		err  error
+	)
+	if path != "" {
+		tape, err = loadReplay(path)
+	} else {
+		tape, err = storedReplay(cfg, *last)
+	}
+	if err != nil {
+		return exitCode(err)
+	}
+	if *out != "" {
+		if *cols < 1 || *rows < 1 || *scale < 1 {
+			fmt.Fprintln(os.Stderr, "replay: -cols, -rows and -scale must be positive")
+			return exitUsage
+		}
+		if err := writeGIF(tape, *out, *cols, *rows, *scale); err != nil {
+			return exitCode(err)
+		}
+		fmt.Println("wrote", *out)
+		return 0
+	}
+	m := newPlayback(tape)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		return exitCode(err)
+	}
+	return 0
+}
+
+// newPlayback sets up a run on the tape's course and settings that writes
+// nothing to disk
+func newPlayback(tape replay) model {
+	cfg := tape.Config
+	cfg.Seed, cfg.Daily, cfg.Weekly, cfg.Mods = tape.Seed, false, false, tape.Mods
+	cfg.Countdown, cfg.IdlePause = 0, 0
+	cfg.Twitch, cfg.MetricsAddr, cfg.Log = "", "", ""
+	cfg.Store = "memory"
+	m := initialModel(cfg, MemoryStore())
+	m.offer, m.crashNote = nil, nil
+	m.playback = &playback{tape: tape, speed: 1}
+	m.tape = nil
+	return m
+}
+
+// playInputs applies the tape's inputs for the coming step
+func (m *model) playInputs() {
+	pb := m.playback
+	if pb == nil {
+		return
+	}
+	for ; pb.next < len(pb.tape.Inputs) && pb.tape.Inputs[pb.next].Step <= m.steps; pb.next++ {
+		if pb.tape.Inputs[pb.next].Step < m.steps {
+			continue // already behind us
+		}
+		switch act := pb.tape.Inputs[pb.next].Act; act {
+		case actJump:
+			m.pressJump()
+		case actAcorn:
+			m.throwAcorn()
+		case actDive:
+			m.dive()
+		case actCut:
+			m.cutJump()
+		case actPrestige:
+			m.prestige()
+		default:
+			if name, ok := strings.CutPrefix(act, actPerk); ok {
+				m.takePerk(name)
+			}
+		}
+	}
+}
+
+// replayStep plays one step of the tape
+func (m *model) replayStep() {
+	if m.gameOver {
+		return
+	}
+	m.playInputs()
+	m.step(m.now())
+}
+
+// seek puts the replay at step, from the nearest state dump before it or
+// from the start of the run
+func (m *model) seek(step int) tea.Cmd {
+	pb := m.playback
+	var cmd tea.Cmd
+	i := sort.Search(len(pb.tape.Snaps), func(i int) bool { return pb.tape.Snaps[i].Step > step }) - 1
+	if i < 0 || m.restore(pb.tape.Snaps[i]) != nil {
+		cmd = m.restart()
+	}
+	pb.next = sort.Search(len(pb.tape.Inputs), func(i int) bool { return pb.tape.Inputs[i].Step >= m.steps })
+	for m.steps < step && !m.gameOver {
+		m.replayStep()
+	}
+	return cmd
+}
+
+// restore puts the run back as a tape's state dump has it
+func (m *model) restore(s replaySnap) error {
+	cfg := m.cfg
+	if err := m.UnmarshalJSON(s.State); err != nil {
+		return err
+	}
+	m.cfg = cfg
+	m.steps = s.Step
+	m.particles = nil
+	if m.w > 0 && m.h > 0 {
+		m.recalcSizes()
+	}
+	return nil
+}
+
+// replayKey handles a key during playback
+func (m *model) replayKey(key string) tea.Cmd {
+	pb := m.playback
+	switch key {
+	case "q", "ctrl+c", "esc":
+		return tea.Quit
+	case " ":
+		pb.paused = !pb.paused
+		if !pb.paused {
+			m.tickGen++ // drop the slow paused tick
+			return m.tickAfter(m.tickDur(), m.tickGen)
+		}
+	case "1", "2", "4":
+		pb.speed = int(key[0] - '0')
+	case "right", "l", ".":
+		pb.paused = true
+		m.replayStep()
+	case "left", "h", ",":
+		pb.paused = true
+		if m.steps > 0 {
+			return m.seek(m.steps - 1)
+		}
+	}
+	return nil
+}
+
+// replayHUD shows where playback is and how fast it's going
+func (m model) replayHUD() string {
+	pb := m.playback
+	state := fmt.Sprintf("▶ %d×", pb.speed)
+	if pb.paused {
+		state = "⏸"
+	}
+	return fmt.Sprintf("Replay %s  step %d/%d", state, m.steps, pb.tape.Steps)
+}
@@ -0,0 +1,126 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tapedRun has the screensaver's bot play a run to the end, taping it
+func tapedRun(t *testing.T) (model, replay) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Seed = 0, 42
+	m, _ := clockedModel(t, cfg)
+	for !m.gameOver && m.steps < 5000 {
+		if m.botJump() {
+			m.record(actJump)
+			m.pressJump()
+		}
+		if m.steps%37 == 0 {
+			m.record(actAcorn)
+			m.throwAcorn()
+		}
+		m.step(m.now())
+		m.snapTape()
+	}
+	if !m.gameOver {
+		m.saveReplay() // as a run ending does
+	}
+	data, ok := m.saves.readReplay(replayLast)
+	if !ok {
+		t.Fatal("the run's tape wasn't saved")
+	}
+	tape, err := parseReplay(data, "last replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, tape
+}
+
+// runState is the run's state dump, minus when it was taken
+func runState(t *testing.T, m model) string {
+	data, err := m.snapState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(data, &fields)
+	delete(fields, "saved_at")
+	out, _ := json.Marshal(fields)
+	return string(out)
+}
+
+func TestReplayPlaysBackTheRun(t *testing.T) {
+	live, tape := tapedRun(t)
+	if len(tape.Snaps) < 2 {
+		t.Fatalf("a %d-step run left %d snapshots", live.steps, len(tape.Snaps))
+	}
+	m := newPlayback(tape)
+	m.clock = live.clock
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30}) // not the size it was taped at
+	m = next.(model)
+	for !m.gameOver && m.steps < live.steps {
+		m.replayStep()
+	}
+	if m.steps != live.steps || m.dist != live.dist || m.cause != live.cause || m.score() != live.score() {
+		t.Fatalf("replay ended at step %d, distance %d (%s, score %d); the run at %d, %d (%s, score %d)",
+			m.steps, m.dist, m.cause, m.score(), live.steps, live.dist, live.cause, live.score())
+	}
+
+	// step back across a snapshot and play forward again
+	target := tape.Snaps[1].Step + 5
+	m.seek(target)
+	ahead := runState(t, m)
+	m.replayKey("left")
+	m.replayKey("right")
+	if m.steps != target || runState(t, m) != ahead {
+		t.Errorf("stepping back and forth from %d came back to step %d in another state", target, m.steps)
+	}
+	m.seek(3) // before the first snapshot
+	if m.steps != 3 || m.gameOver {
+		t.Errorf("seeking to step 3 got to step %d (game over: %v)", m.steps, m.gameOver)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	_, tape := tapedRun(t)
+	m := newPlayback(tape)
+	d := m.tickDur()
+	m.replayKey("4")
+	if got := m.tickDur(); got != d/4 {
+		t.Errorf("4× ticks every %v, want %v", got, d/4)
+	}
+	m.replayKey(" ")
+	next, _ := m.Update(tickMsg{m.tickGen, time.Now()})
+	if m = next.(model); m.steps != 0 {
+		t.Errorf("a paused replay stepped to %d", m.steps)
+	}
+}
+
+func TestReplayGIF(t *testing.T) {
+	live, tape := tapedRun(t)
+	path := filepath.Join(t.TempDir(), "run.gif")
+	if err := writeGIF(tape, path, 30, 10, 4); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != live.steps+1 { // a frame a step, plus the end held
+		t.Errorf("%d frames for a %d-step run", len(anim.Image), live.steps)
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 30*4 || b.Dy() != 10*4 {
+		t.Errorf("frames are %dx%d, want %dx%d", b.Dx(), b.Dy(), 30*4, 10*4)
+	}
+}
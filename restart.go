@@ -0,0 +1,86 @@
+package gopherdash
+
+import (
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// HOLD-TO-RESTART
+// ----------------------------------------------------------------------------
+
+// Terminals report no key releases, so a "hold" is a run of auto-repeated
+// presses: the first repeat may take a while to arrive (the OS repeat delay),
+// later ones come quickly. Mashing rarely keeps up with the repeat rate, so
+// frantic jump presses at the moment of death don't restart the run.
+// Terminals that do report releases (see keyboard.go) need no guessing: the
+// hold lasts from a press after the cooldown until the key comes up.
+const (
+	holdFirstGap  = 650 * time.Millisecond // longest OS repeat delay we accept
+	holdRepeatGap = 150 * time.Millisecond // longest gap between repeats
+	holdBarCells  = 10
+)
+
+// cooldown is how long the game-over screen waits before a restart;
+// each short-cooldown perk halves it
+func (m model) cooldown() time.Duration {
+	if m.mod(modNoCooldown) {
+		return 0
+	}
+	return cooldownSeconds * time.Second >> m.perk(perkCooldown)
+}
+
+// restartHold is how long Space must be held on the game-over screen, also
+// halved by each short-cooldown perk
+func (m model) restartHold() time.Duration {
+	if m.mod(modNoCooldown) {
+		return 0
+	}
+	return time.Duration(m.cfg.RestartHold) * time.Millisecond >> m.perk(perkCooldown)
+}
+
+// holding reports whether the current hold is still unbroken at now
+func (m model) holding(now time.Time) bool {
+	if m.holdStart.IsZero() {
+		return false
+	}
+	if m.held.releases {
+		return m.jumpHeld()
+	}
+	limit := holdRepeatGap
+	if m.holdRepeats == 0 {
+		limit = holdFirstGap
+	}
+	return now.Sub(m.holdLast) <= limit
+}
+
+// pressRestart handles Space/W on the game-over screen once the cooldown is
+// over; it reports whether the run should restart now
+func (m *model) pressRestart(now time.Time) bool {
+	if m.restartHold() <= 0 {
+		return true // classic instant restart
+	}
+	if m.holding(now) {
+		m.holdRepeats++
+	} else {
+		m.holdStart, m.holdRepeats = now, 0
+	}
+	m.holdLast = now
+	return now.Sub(m.holdStart) >= m.restartHold()
+}
+
+// heldRestart reports whether a hold known from key releases has gone on
+// long enough to restart; repeats don't come in to say so
+func (m model) heldRestart(now time.Time) bool {
+	return m.held.releases && !m.racing() && m.holding(now) &&
+		now.Sub(m.holdStart) >= m.restartHold()
+}
+
+// holdBar is the fill bar shown while Space is being held
+func (m model) holdBar() string {
+	filled := 0
+	if now := m.now(); m.holding(now) {
+		filled = min(int(now.Sub(m.holdStart)*holdBarCells/m.restartHold()), holdBarCells)
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", holdBarCells-filled) + "]"
+}
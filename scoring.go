@@ -0,0 +1,224 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// SCORING RULES
+// ----------------------------------------------------------------------------
+
+// A run's score is weighed by a named set of scoring rules from
+// .gopherdash_scoring, picked with -scoring. Classic rules score distance
+// plus the speed-pad bonus, as Gopher-Dash always has; they are the only
+// ones the high score, the weekly boards and the live records rank. Every
+// set of rules also keeps its own table of best scores per mode, in
+// .gopherdash_scores.
+
+const (
+	scoringFile    = ".gopherdash_scoring"
+	scoresFile     = ".gopherdash_scores"
+	scoringClassic = "classic"
+	scoresKeep     = 10 // scores kept per mode
+)
+
+// scoringRules are the points a run earns for each thing it does
+type scoringRules struct {
+	Distance  int `json:"distance"`   // per cell run
+	SpeedPad  int `json:"speed_pad"`  // per cell of speed-pad bonus
+	Clear     int `json:"clear"`      // per hazard cell jumped clear, e.g. 3 for a log
+	Coin      int `json:"coin"`       // per acorn picked up
+	Style     int `json:"style"`      // per near-miss or coyote jump
+	ComboStep int `json:"combo_step"` // clears in a row that add 1 to the multiplier; 0 = no combos
+	ComboMax  int `json:"combo_max"`  // highest multiplier
+	Milestone int `json:"milestone"`  // per distance milestone (see milestones.go)
+}
+
+// defaultScoring is written to .gopherdash_scoring the first time a run
+// asks for rules other than classic; edit it to add more
+var defaultScoring = map[string]scoringRules{
+	scoringClassic: {Distance: 1, SpeedPad: 1},
+	"arcade":       {Distance: 1, SpeedPad: 2, Clear: 10, Coin: 25, Style: 15, ComboStep: 5, ComboMax: 5, Milestone: 50},
+}
+
+func scoringPath() string { return dataPath(scoringFile) }
+
+// loadScoring reads every set of rules by name; classic can't be changed
+func loadScoring() map[string]scoringRules {
+	data, err := os.ReadFile(scoringPath())
+	if os.IsNotExist(err) {
+		if data, err := json.MarshalIndent(defaultScoring, "", "  "); err == nil {
+			_ = os.WriteFile(scoringPath(), data, 0o644)
+		}
+		return defaultScoring
+	}
+	var rules map[string]scoringRules
+	if err != nil || json.Unmarshal(data, &rules) != nil {
+		return defaultScoring
+	}
+	rules[scoringClassic] = defaultScoring[scoringClassic]
+	return rules
+}
+
+// classicScoring reports whether name picks the classic rules
+func classicScoring(name string) bool { return name == "" || name == scoringClassic }
+
+// validScoring checks name against the rules on disk
+func validScoring(name string) error {
+	if classicScoring(name) {
+		return nil
+	}
+	rules := loadScoring()
+	if _, ok := rules[name]; ok {
+		return nil
+	}
+	names := slices.Sorted(maps.Keys(rules))
+	return fmt.Errorf("unknown scoring %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// rulesByName resolves name, falling back to classic
+func rulesByName(name string) scoringRules {
+	if classicScoring(name) {
+		return defaultScoring[scoringClassic]
+	}
+	if r, ok := loadScoring()[name]; ok {
+		return r
+	}
+	return defaultScoring[scoringClassic]
+}
+
+// scoring is the run's rules; a model that never resolved any scores classic
+func (m model) scoring() scoringRules {
+	if m.rules == (scoringRules{}) {
+		return defaultScoring[scoringClassic]
+	}
+	return m.rules
+}
+
+// ranksClassic reports whether the run counts for the high score and the
+// boards that rank it
+func (m model) ranksClassic() bool { return classicScoring(m.cfg.Scoring) }
+
+// multiplier is what the combo multiplies clears and style points by
+func (m model) multiplier() int {
+	r := m.scoring()
+	if r.ComboStep <= 0 {
+		return 1
+	}
+	return max(min(1+m.combo/r.ComboStep, r.ComboMax), 1)
+}
+
+// scoreClear pays for a hazard the gopher has just got past in the air
+func (m *model) scoreClear(k ObstacleKind) {
+	m.points += m.scoring().Clear * k.Width() * k.Height() * m.multiplier()
+	m.combo++
+	m.cleared++
+}
+
+// scoreStyle pays for a near-miss or a coyote jump
+func (m *model) scoreStyle() { m.points += m.scoring().Style * m.multiplier() }
+
+// scoreCoin pays for an acorn picked up
+func (m *model) scoreCoin() { m.points += m.scoring().Coin }
+
+// scoreLanding breaks the combo when a jump lands without clearing anything
+func (m *model) scoreLanding() {
+	if m.cleared == 0 {
+		m.combo = 0
+	}
+	m.cleared = 0
+}
+
+// scoreHUD shows the score and the multiplier it's building, under rules
+// that score more than the distance
+func (m model) scoreHUD() string {
+	s := fmt.Sprintf("Score: %d", m.score())
+	if x := m.multiplier(); x > 1 {
+		s += fmt.Sprintf(" ×%d", x)
+	}
+	return s
+}
+
+// ----------------------------------------------------------------------------
+// SCORE TABLES
+// ----------------------------------------------------------------------------
+
+// scoreTables are the best scores kept under each set of rules, by mode
+type scoreTables map[string]map[string][]weeklyScore
+
+func scoresPath() string { return dataPath(scoresFile) }
+
+func loadScores() scoreTables {
+	t := scoreTables{}
+	if data, err := os.ReadFile(scoresPath()); err == nil {
+		_ = json.Unmarshal(data, &t)
+	}
+	return t
+}
+
+// scoreMode names the table a run goes in: how its course was picked, and
+// its modifiers, e.g. "daily" or "endless+big"
+func (m model) scoreMode() string {
+	mode := "endless"
+	switch {
+	case m.racing():
+		mode = "race"
+	case m.cfg.Weekly:
+		mode = "weekly"
+	case m.cfg.Daily:
+		mode = "daily"
+	case m.cfg.Seed != 0:
+		mode = "seeded"
+	}
+	if len(m.mods) > 0 {
+		mode += "+" + modsKey(m.mods)
+	}
+	if m.capped() {
+		mode += "+capped"
+	}
+	return mode
+}
+
+// scoringName is the name of the run's rules
+func (m model) scoringName() string {
+	if classicScoring(m.cfg.Scoring) {
+		return scoringClassic
+	}
+	return m.cfg.Scoring
+}
+
+// recordScore adds the run to its table and returns its place, 0 if it
+// didn't make it, and the best the table had before
+func (m *model) recordScore(at time.Time) (place, best int) {
+	rules, mode := m.scoringName(), m.scoreMode()
+	withSaveLock(func() {
+		t := loadScores()
+		if t[rules] == nil {
+			t[rules] = map[string][]weeklyScore{}
+		}
+		if s := t[rules][mode]; len(s) > 0 {
+			best = s[0].Score
+		}
+		t[rules][mode], place = rankScore(t[rules][mode], weeklyScore{m.score(), at}, scoresKeep)
+		if data, err := json.MarshalIndent(t, "", "  "); err == nil {
+			_ = os.WriteFile(scoresPath(), data, 0o644)
+		}
+	})
+	m.logInfo("score table", "rules", rules, "mode", mode, "place", place)
+	return place, best
+}
+
+// tableLine is the game-over line for a run under rules other than classic
+func (m model) tableLine() string {
+	line := fmt.Sprintf("%s %s: ", m.scoringName(), m.scoreMode())
+	if m.tablePlace == 0 {
+		return line + fmt.Sprintf("off the top %d (best %d)", scoresKeep, m.tableBest)
+	}
+	return line + bestComparison(m.score(), m.tableBest)
+}
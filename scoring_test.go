@@ -0,0 +1,85 @@
+package gopherdash
+
+import (
+	"testing"
+	"time"
+)
+
+// TestScoringRules pays a run out under the arcade rules: clears are worth
+// their cells, the combo multiplies them until it caps, and a jump that
+// clears nothing, or a shield hit, starts it over
+func TestScoringRules(t *testing.T) {
+	m := model{dist: 100, bonus: 4}
+	if got := m.score(); got != 104 {
+		t.Fatalf("classic score %d, want distance plus bonus 104", got)
+	}
+
+	m.rules = defaultScoring["arcade"]
+	m.scoreClear(fallenLog{}) // 3 cells
+	m.scoreCoin()
+	m.scoreStyle()
+	if got, want := m.score(), 100+2*4+10*3+25+15; got != want {
+		t.Fatalf("arcade score %d, want %d", got, want)
+	}
+
+	for range 30 {
+		m.scoreClear(rock{})
+	}
+	if got := m.multiplier(); got != 5 {
+		t.Errorf("after %d clears in a row the multiplier is %d, want the cap of 5", m.combo, got)
+	}
+	m.scoreLanding()
+	if m.combo == 0 {
+		t.Error("a jump that cleared hazards broke the combo")
+	}
+	m.scoreLanding()
+	if got := m.multiplier(); m.combo != 0 || got != 1 {
+		t.Errorf("a jump that cleared nothing left combo %d ×%d", m.combo, got)
+	}
+
+	m.cfg.Class = "tank"
+	m.scoreClear(rock{})
+	if !m.shrugOff(obstacle{0, rock{}}) {
+		t.Fatal("the tank's shield didn't take the hit")
+	}
+	if m.combo != 0 {
+		t.Errorf("a shield hit left combo %d", m.combo)
+	}
+}
+
+// TestScoreTables keeps arcade runs in a table per mode of their own, away
+// from the high score
+func TestScoreTables(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Scoring, cfg.Daily = "arcade", true
+	m, c := clockedModel(t, cfg)
+	m.cfg.Store = "file" // clockedModel keeps the saves in memory
+	if m.rules != defaultScoring["arcade"] {
+		t.Fatalf("resolved rules %+v, want arcade", m.rules)
+	}
+	for i, pts := range []int{50, 200, 120} {
+		m.dist, m.points = 10, pts
+		c.t = c.t.Add(time.Minute)
+		m.recordRun("rock")
+		if i == 2 && (m.tablePlace != 2 || m.tableBest != 210) {
+			t.Errorf("third run placed #%d under a best of %d, want #2 under 210", m.tablePlace, m.tableBest)
+		}
+	}
+	if m.profile.HighScore != 0 {
+		t.Errorf("an arcade run set the high score to %d", m.profile.HighScore)
+	}
+	tables := loadScores()
+	if got := tables["arcade"]["daily"]; len(got) != 3 || got[0].Score != 210 {
+		t.Errorf("arcade daily table %+v", got)
+	}
+	if len(tables[scoringClassic]) != 0 {
+		t.Errorf("classic tables %+v from arcade runs", tables[scoringClassic])
+	}
+
+	if err := validScoring("arcade"); err != nil {
+		t.Error(err)
+	}
+	if validScoring("golf") == nil {
+		t.Error("unknown rules accepted")
+	}
+}
@@ -0,0 +1,112 @@
+package gopherdash
+
+import (
+	"flag"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// SCREENSAVER (`gopherdash screensaver`)
+// ----------------------------------------------------------------------------
+
+const (
+	saverRestart  = 2 * time.Second // pause on a crash before the next run
+	saverNightOdd = 4               // about one run in saverNightOdd is at night
+)
+
+// screensaverMain runs `gopherdash screensaver`: a bot plays endless runs in
+// zen mode, with nothing on screen but the playfield, under a random theme
+// each run, and writes nothing to disk. Any key exits.
+func screensaverMain(args []string) int {
+	fs := flag.NewFlagSet("gopherdash screensaver", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	cfg := defaultConfig()
+	cfg.Countdown = 0
+	m := model{cfg: cfg, frameDur: startFrame, saver: true, perf: &perfMeter{},
+		fox: foxStart, ammo: acornStart}
+	m.pickTheme()
+	m.reseed(m.runSeed())
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		return exitCode(err)
+	}
+	return 0
+}
+
+// pickTheme dresses the next screensaver run in a random seasonal theme,
+// or none, and now and then makes it a night run
+func (m *model) pickTheme() {
+	m.event = nil
+	if i := rng.Intn(len(defaultEvents) + 1); i < len(defaultEvents) {
+		m.event = &defaultEvents[i]
+	}
+	m.cfg.Night = rng.Intn(saverNightOdd) == 0
+}
+
+// botJump decides whether the screensaver's bot jumps this tick
+func (m model) botJump() bool {
+	return m.grounded() && m.botJumpFrom(m.camera().toWorld(playerCol))
+}
+
+// botJumpFrom decides whether to jump from world cell x. From each cell the
+// gopher stands on it can run on a cell or jump and come down jumpCells+1
+// cells later; the bot only jumps when running on leaves no safe way
+// through the hazards decided so far.
+func (m model) botJumpFrom(x int) bool {
+	hazards := map[int]int{} // the height to clear over each cell
+	for _, ob := range m.obstacles {
+		for x := ob.x; hazardous(ob.kind) && x <= ob.extent().hi; x++ {
+			hazards[x] = ob.kind.Height()
+		}
+	}
+	// jumping from p clears the hazards under the arc and lands on p+air+1
+	arc := m.spawn.arc
+	over := func(p int) bool {
+		for k, h := range arc {
+			if h < hazards[p+k+1] {
+				return false
+			}
+		}
+		return hazards[p+len(arc)+1] == 0
+	}
+	memo := map[int]bool{}
+	var clear func(p int) bool // standing on p leaves a way through
+	clear = func(p int) bool {
+		if p >= m.spawn.next {
+			return true // nothing decided this far out yet
+		}
+		if ok, seen := memo[p]; seen {
+			return ok
+		}
+		ok := (hazards[p+1] == 0 && clear(p+1)) ||
+			(over(p) && clear(p+len(arc)+1))
+		memo[p] = ok
+		return ok
+	}
+	return hazards[x+1] > 0 || !clear(x+1)
+}
+
+// saverTick plays the bot's move before a step, and once a run is over
+// starts the next one under a new theme
+func (m *model) saverTick() tea.Cmd {
+	if m.gameOver {
+		if m.now().Before(m.restartAt) {
+			return m.tickAfter(gameOverTick, m.tickGen)
+		}
+		m.pickTheme()
+		return m.restart()
+	}
+	if m.botJump() {
+		m.pressJump()
+	}
+	return nil
+}
+
+// saverView is the zen-mode screen: the playfield alone, centred
+func (m model) saverView() string {
+	return lipgloss.Place(m.w, m.h, lipgloss.Center, lipgloss.Center, m.renderGame())
+}
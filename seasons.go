@@ -0,0 +1,138 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// SEASONAL EVENTS (themes & achievements from the event calendar)
+// ----------------------------------------------------------------------------
+
+const eventsFile = ".gopherdash_events"
+
+// event is one entry in the calendar: a date range, the sprites it swaps in
+// while it's on, and an achievement that can only be earned during it
+type event struct {
+	Name    string            `json:"name"`
+	Badge   string            `json:"badge"`             // shown in the HUD while the event is on
+	From    string            `json:"from"`              // MM-DD, inclusive
+	To      string            `json:"to"`                // MM-DD, inclusive; may wrap past new year
+	Enabled bool              `json:"enabled"`           // switch an event off without deleting it
+	Sprites map[string]string `json:"sprites,omitempty"` // obstacle kind name → replacement glyph
+	Ground  string            `json:"ground,omitempty"`  // replacement ground tile
+
+	Achievement achievement `json:"achievement"`
+}
+
+// achievement is earned by scoring at least Score in a run while its event
+// is on
+type achievement struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// defaultEvents is the calendar a fresh install starts with; it's written
+// out to eventsFile the first time so players can edit or switch it off
+var defaultEvents = []event{
+	{
+		Name: "Halloween", Badge: "🎃", From: "10-20", To: "10-31", Enabled: true,
+		Sprites:     map[string]string{"rock": "🎃"},
+		Achievement: achievement{Name: "Pumpkin Patch", Score: 300},
+	},
+	{
+		Name: "Winter", Badge: "❄️", From: "12-01", To: "12-31", Enabled: true,
+		Ground:      "⬜",
+		Achievement: achievement{Name: "Snow Runner", Score: 500},
+	},
+}
+
+func eventsPath() string { return dataPath(eventsFile) }
+
+// loadEvents reads the calendar, writing the default one if there is none.
+// A broken file falls back to the defaults without touching it.
+func loadEvents() []event {
+	data, err := os.ReadFile(eventsPath())
+	if os.IsNotExist(err) {
+		if data, err := json.MarshalIndent(defaultEvents, "", "  "); err == nil {
+			_ = os.WriteFile(eventsPath(), data, 0o644)
+		}
+		return defaultEvents
+	}
+	var events []event
+	if err != nil || json.Unmarshal(data, &events) != nil {
+		return defaultEvents
+	}
+	return events
+}
+
+// covers reports whether t falls inside the event's dates
+func (e event) covers(t time.Time) bool {
+	from, err1 := time.Parse("01-02", e.From)
+	to, err2 := time.Parse("01-02", e.To)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	day := func(t time.Time) int { return int(t.Month())*100 + t.Day() }
+	d, f, l := day(t), day(from), day(to)
+	if f <= l {
+		return d >= f && d <= l
+	}
+	return d >= f || d <= l // e.g. 12-20 to 01-05
+}
+
+// activeEvent is the first enabled event covering t, or nil
+func activeEvent(events []event, t time.Time) *event {
+	for i, e := range events {
+		if e.Enabled && e.covers(t) {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+// sprite is the glyph for an obstacle kind, with the event's swaps applied
+func (m model) sprite(k ObstacleKind) (string, int) {
+	glyph, lift := k.Sprite()
+	if m.event != nil {
+		if g, ok := m.event.Sprites[k.Name()]; ok {
+			glyph = g
+		}
+	}
+	return glyph, lift
+}
+
+// groundTile is the ground glyph, snow and all
+func (m model) groundTile() string {
+	if m.event != nil && m.event.Ground != "" {
+		return m.event.Ground
+	}
+	return groundChar
+}
+
+// eventHUD names the event that's on
+func (m model) eventHUD() string { return m.event.Badge + " " + m.event.Name }
+
+// awardSeasonal unlocks the event's achievement once a run scores enough
+func (m *model) awardSeasonal() {
+	if m.event == nil || m.cfg.Practice {
+		return
+	}
+	a := m.event.Achievement
+	if a.Name == "" || m.score() < a.Score {
+		return
+	}
+	if _, ok := m.profile.Achievements[a.Name]; ok {
+		return
+	}
+	if m.profile.Achievements == nil {
+		m.profile.Achievements = map[string]string{}
+	}
+	m.profile.Achievements[a.Name] = m.now().Format(time.DateOnly)
+	m.saveProfile()
+	m.notify(fmt.Sprintf("🏅 %s achievement: %s", m.event.Name, a.Name))
+	m.desktop("Gopher-Dash: achievement unlocked", fmt.Sprintf("%s (%s)", a.Name, m.event.Name))
+	m.logInfo("achievement", "name", a.Name, "event", m.event.Name)
+}
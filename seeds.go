@@ -0,0 +1,117 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// FIXED SEEDS & DEATH HEATMAP
+// ----------------------------------------------------------------------------
+
+const deathsFile = ".gopherdash_deaths"
+
+// heat glyphs from "nobody died here" to "everybody dies here"
+var heatGlyphs = []string{"  ", "░░", "▒▒", "▓▓", "██"}
+
+// dailySeed is the same for everyone on a given calendar day, e.g. 20240601
+func dailySeed(t time.Time) int64 {
+	y, mo, d := t.Date()
+	return int64(y*10000 + int(mo)*100 + d)
+}
+
+// fixedSeed returns the seed every run should start from, if one is set
+func (c config) fixedSeed() (int64, bool) {
+	switch {
+	case c.Weekly:
+		return weeklySeed(time.Now()), true
+	case c.Daily:
+		return dailySeed(time.Now()), true
+	case c.Seed != 0:
+		return c.Seed, true
+	}
+	return 0, false
+}
+
+// runSeed picks the seed for the next run
+func (m model) runSeed() int64 {
+	if s, ok := m.cfg.fixedSeed(); ok {
+		return s
+	}
+	return time.Now().UnixNano()
+}
+
+// deathMap maps a seed to every distance a run on it has ended at
+type deathMap map[string][]int
+
+func deathsPath() string { return dataPath(deathsFile) }
+
+func loadDeaths() deathMap {
+	d := deathMap{}
+	if data, err := os.ReadFile(deathsPath()); err == nil {
+		_ = json.Unmarshal(data, &d)
+	}
+	return d
+}
+
+func saveDeaths(d deathMap) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(deathsPath(), data, 0o644)
+}
+
+func seedKey(seed int64) string { return strconv.FormatInt(seed, 10) }
+
+// recordDeath notes where a run on a fixed seed ended
+func (m *model) recordDeath() {
+	if _, ok := m.cfg.fixedSeed(); !ok {
+		return
+	}
+	k := seedKey(m.seed)
+	withSaveLock(func() {
+		m.deaths = loadDeaths() // other instances may have died on it too
+		m.deaths[k] = append(m.deaths[k], m.dist)
+		saveDeaths(m.deaths)
+	})
+}
+
+// showHeatmap reports whether the strip under the playfield is drawn
+func (m model) showHeatmap() bool {
+	_, ok := m.cfg.fixedSeed()
+	return ok
+}
+
+// renderHeatmap draws one row aligned with the playfield: each column shows
+// how many runs on this seed died at the distance that column will reach
+// the player at
+func (m model) renderHeatmap() string {
+	counts := make([]int, max(m.gameCols, 0))
+	hi := 0
+	cam := m.viewCamera()
+	for _, d := range m.deaths[seedKey(m.seed)] {
+		// a run that died at distance d died on world cell d+playerCol
+		x := cam.toScreen(d + playerCol)
+		if x < 0 || x >= m.gameCols {
+			continue
+		}
+		counts[x]++
+		hi = max(hi, counts[x])
+	}
+	cells := make([]string, len(counts))
+	for x, n := range counts {
+		i := 0
+		if n > 0 {
+			i = 1 + (n-1)*(len(heatGlyphs)-2)/max(hi-1, 1)
+		}
+		cells[x] = heatGlyphs[i]
+	}
+	if m.narrow() {
+		return narrowRow(cells)
+	}
+	return strings.Join(cells, "")
+}
@@ -0,0 +1,101 @@
+package gopherdash
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ----------------------------------------------------------------------------
+// SESSION SUMMARY
+// ----------------------------------------------------------------------------
+
+// Quitting after playing shows a summary of the session – the runs since
+// launch – for a few seconds before the game leaves the alt screen, and
+// adds the session to the lifetime stats. Any key skips the summary.
+
+const (
+	sessionShow = 3 * time.Second // how long the summary stays up
+	sessionKeep = 100             // latest sessions kept in the stats
+)
+
+// controlsLeaving is the control bar under the summary
+const controlsLeaving = "any key = quit now"
+
+// sessionRecord is one session, from launch to quit
+type sessionRecord struct {
+	Runs     int           `json:"runs"`
+	Best     int           `json:"best"` // best score
+	Distance int           `json:"distance"`
+	Played   time.Duration `json:"played"` // wall time from launch to quit
+	At       time.Time     `json:"at"`     // when it ended; zero while it's going on
+}
+
+// leaveMsg ends the summary
+type leaveMsg struct{}
+
+// tally counts a finished run towards the session
+func (s *sessionRecord) tally(dist, score int) {
+	s.Runs++
+	s.Distance += dist
+	s.Best = max(s.Best, score)
+}
+
+// addSession adds a finished session to the lifetime stats, keeping the
+// latest sessionKeep of them
+func (st *stats) addSession(s sessionRecord) {
+	st.Sessions++
+	st.PlayTime += s.Played
+	st.Recent = append(st.Recent, s)
+	st.Recent = st.Recent[max(len(st.Recent)-sessionKeep, 0):]
+}
+
+// endSession writes the session to the stats once, if any run was played
+func (m *model) endSession() {
+	if m.sitting.Runs == 0 || !m.sitting.At.IsZero() {
+		return
+	}
+	m.sitting.At = m.now()
+	m.sitting.Played = m.sitting.At.Sub(m.started)
+	m.saves.lock(func() {
+		m.stats = loadStats(m.saves)
+		m.stats.addSession(m.sitting)
+		saveStats(m.saves, m.stats)
+	})
+	m.logInfo("session ended", "runs", m.sitting.Runs, "best", m.sitting.Best, "played", m.sitting.Played)
+}
+
+// leave ends the session, showing the summary before quitting if there's
+// anything to sum up; an embedded game hands back control straight away
+func (m *model) leave() tea.Cmd {
+	m.endSession()
+	if m.sitting.Runs == 0 || m.embedded {
+		return m.quit()
+	}
+	m.leaving = true
+	return m.after(sessionShow, func(time.Time) tea.Msg { return leaveMsg{} })
+}
+
+// sessionLines is the summary's text
+func (m model) sessionLines() []string {
+	s := m.sitting
+	return []string{
+		"Thanks for playing!",
+		"",
+		fmt.Sprintf("Runs: %d", s.Runs),
+		fmt.Sprintf("Best this session: %d", s.Best),
+		fmt.Sprintf("Distance run: %d", s.Distance),
+		"Time played: " + s.Played.Round(time.Second).String(),
+	}
+}
+
+// sessionView draws the summary in place of the playfield
+func (m model) sessionView() (pane, ctrl string) {
+	msg := strings.Join(m.sessionLines(), "\n")
+	inner := lipgloss.NewStyle().Align(lipgloss.Center).
+		Height(gameOverRows).Width(m.w - 2).Render(msg)
+	return m.pane(inner), m.bar(controlsLeaving)
+}
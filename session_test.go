@@ -0,0 +1,83 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestSessionSummary quits after two runs: the summary comes up with the
+// session's numbers, the session goes into the stats, and a key quits
+func TestSessionSummary(t *testing.T) {
+	m, c := clockedModel(t, defaultConfig())
+	press := func(key string) tea.Cmd {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(model)
+		return cmd
+	}
+	for _, dist := range []int{120, 45} {
+		c.t = c.t.Add(2 * time.Minute)
+		m.restart()
+		m.dist = dist
+		m.setGameOver("rock")
+	}
+	c.t = c.t.Add(time.Minute)
+	m.restart()
+	m.dist = 30 // quit part-way: it counts too
+
+	if cmd := press("q"); cmd == nil || !m.leaving {
+		t.Fatal("quitting skipped the summary")
+	}
+	want := sessionRecord{Runs: 3, Best: 120, Distance: 195, Played: 5 * time.Minute, At: c.t}
+	if m.sitting != want {
+		t.Errorf("session %+v, want %+v", m.sitting, want)
+	}
+	view := m.View()
+	for _, line := range []string{"Runs: 3", "Best this session: 120", "Distance run: 195", "Time played: 5m0s"} {
+		if !strings.Contains(view, line) {
+			t.Errorf("summary lacks %q", line)
+		}
+	}
+	st := loadStats(m.saves)
+	if st.Sessions != 1 || st.PlayTime != 5*time.Minute || len(st.Recent) != 1 || st.Recent[0] != want {
+		t.Errorf("stats hold sessions %d, %v, %+v", st.Sessions, st.PlayTime, st.Recent)
+	}
+
+	cmd := press("x")
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("a key during the summary didn't quit")
+	}
+	if m.endSession(); loadStats(m.saves).Sessions != 1 {
+		t.Error("the session was recorded twice")
+	}
+}
+
+// TestSessionNothingPlayed quits straight away when no run was played
+func TestSessionNothingPlayed(t *testing.T) {
+	m, _ := clockedModel(t, defaultConfig())
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if next.(model).leaving {
+		t.Error("summary of an empty session")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("quitting an empty session didn't quit")
+	}
+	if st := loadStats(m.saves); st.Sessions != 0 {
+		t.Errorf("an empty session went into the stats: %d", st.Sessions)
+	}
+}
+
+func TestSessionsKept(t *testing.T) {
+	var st stats
+	for i := range sessionKeep + 5 {
+		st.addSession(sessionRecord{Runs: i + 1, Played: time.Minute})
+	}
+	if st.Sessions != sessionKeep+5 || st.PlayTime != time.Duration(sessionKeep+5)*time.Minute {
+		t.Errorf("%d sessions over %v", st.Sessions, st.PlayTime)
+	}
+	if len(st.Recent) != sessionKeep || st.Recent[0].Runs != 6 {
+		t.Errorf("kept %d sessions from the one with %d runs", len(st.Recent), st.Recent[0].Runs)
+	}
+}
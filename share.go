@@ -0,0 +1,41 @@
+package gopherdash
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ----------------------------------------------------------------------------
+// SHARE A SCORE (C on game over)
+// ----------------------------------------------------------------------------
+
+// C on the game-over screen copies a one-line summary of the run to the
+// system clipboard with OSC 52, which most terminals (and tmux, passed
+// through) turn into a clipboard write, even over SSH. The terminal never
+// says whether it did, so the last line copied is also printed when the
+// game exits, to copy from the scrollback instead.
+
+// shareLine is the summary: the score, anything that changes the course,
+// and the seed to play it on
+func (m model) shareLine() string {
+	line := fmt.Sprintf("🐹 GopherDash: %dm", m.score())
+	if len(m.mods) > 0 {
+		line += " with " + modsKey(m.mods)
+	}
+	return line + fmt.Sprintf(", seed %d — beat me!", m.seed)
+}
+
+// clipboardSequence is the OSC 52 write of s to the clipboard
+func clipboardSequence(s string, getenv func(string) string) string {
+	return passThrough("\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte(s))+"\x07", getenv)
+}
+
+// share copies the summary to the clipboard and keeps it for the exit
+func (m *model) share() {
+	m.shared = m.shareLine()
+	_, _ = io.WriteString(desktopOut, clipboardSequence(m.shared, os.Getenv))
+	m.notify("Copied to the clipboard (and printed on exit)")
+	m.logInfo("score shared", "line", m.shared)
+}
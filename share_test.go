@@ -0,0 +1,43 @@
+package gopherdash
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestShareLine(t *testing.T) {
+	m := model{dist: 400, bonus: 12, seed: 20240601}
+	if got, want := m.shareLine(), "🐹 GopherDash: 412m, seed 20240601 — beat me!"; got != want {
+		t.Errorf("%q, want %q", got, want)
+	}
+	m.mods = []string{modFog, modTiny}
+	if got, want := m.shareLine(), "🐹 GopherDash: 412m with fog+tiny, seed 20240601 — beat me!"; got != want {
+		t.Errorf("%q, want %q", got, want)
+	}
+
+	env := func(k string) string { return map[string]string{"TMUX": "1"}[k] }
+	if got, want := clipboardSequence("hi", env), "\x1bPtmux;\x1b\x1b]52;c;aGk=\x07\x1b\\"; got != want {
+		t.Errorf("through tmux: %q, want %q", got, want)
+	}
+}
+
+// TestShareKey copies the score with C on the game-over screen
+func TestShareKey(t *testing.T) {
+	var out strings.Builder
+	desktopOut = &out
+	t.Cleanup(func() { desktopOut = os.Stdout })
+	m, _ := clockedModel(t, defaultConfig())
+	m.dist = 30
+	m.setGameOver("rock")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	m = next.(model)
+	if m.shared != m.shareLine() {
+		t.Errorf("kept %q for the exit, want %q", m.shared, m.shareLine())
+	}
+	if !strings.Contains(out.String(), "]52;c;") { // wrapped if the tests run in tmux
+		t.Errorf("no clipboard write in %q", out.String())
+	}
+}
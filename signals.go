@@ -0,0 +1,37 @@
+package gopherdash
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// SIGNALS
+// ----------------------------------------------------------------------------
+
+// shutdownMsg asks the model to save the run in progress and quit
+type shutdownMsg struct{ sig os.Signal }
+
+// forwardSignals turns termination signals (closed terminal tab, tmux
+// kill‑session, plain kill) into a shutdownMsg so the model gets a chance to
+// flush before exiting. It replaces Bubble Tea's own handler, which would
+// quit without telling the model. The returned func stops forwarding.
+func forwardSignals(p *tea.Program) func() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case s := <-sig:
+			p.Send(shutdownMsg{s})
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
@@ -0,0 +1,263 @@
+package gopherdash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"reflect"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// SIXEL PLAYFIELD (-renderer sixel)
+// ----------------------------------------------------------------------------
+
+// On terminals with sixel graphics (foot, mlterm, WezTerm, xterm as a
+// VT340) -renderer sixel draws the whole playfield as one image at the
+// pixel size of the terminal's cells: the sprites as their pixel art (see
+// graphics.go), everything else as a block of its colour (see gif.go).
+// The text playfield is left blank, and the image is drawn from its last
+// row, once the rows above it are written, so nothing lands on top of it.
+// Being pixels, the in-between frame of -smooth moves the whole world half
+// a cell, tiles and sprites alike.
+//
+// The terminal is asked before anything is drawn: its device attributes
+// have to list sixel, and it has to say how big its cells are. Until both
+// answers are in, or if either is no, the playfield stays text. -renderer
+// auto asks too when the environment names no terminal it knows. Frames
+// with text on the playfield (paused, the intro, waiting for an opponent)
+// and the hitbox overlay stay text as well.
+
+const (
+	cellSizeAsk    = "\x1b[16t"    // cell size in pixels
+	sixelAsk       = "\x1b[c"      // primary device attributes
+	sixelAttribute = "4"           // in the device attributes: sixel graphics
+	sixelScrollOff = "\x1b[?8452h" // the cursor stays beside an image, not below it
+	sixelScrollOn  = "\x1b[?8452l"
+)
+
+// sixelTerm is what the terminal has said about itself
+type sixelTerm struct {
+	cellW, cellH int  // pixels in a terminal cell; 0 until it says
+	ok           bool // its device attributes list sixel
+}
+
+// sixelPalette has every colour a frame can use, the sky first
+var sixelPalette = func() color.Palette {
+	p := gifPalette()
+	for _, c := range spritePalette {
+		p = append(p, c)
+	}
+	return p
+}()
+
+// terminalReply is the text of the terminal's answer to a question, or of
+// a kitty protocol key event (see keyboard.go). Bubble Tea passes these on
+// as an unknown CSI sequence, a byte slice of a type it doesn't export
+// (unknownCSISequenceMsg, as of v1.3.5), so this is the one place that
+// digs one out; TestTerminalReply runs replies through Bubble Tea's own
+// input reader to catch an upgrade that changes it.
+func terminalReply(msg tea.Msg) string {
+	v := reflect.ValueOf(msg)
+	if !v.IsValid() || v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
+		return ""
+	}
+	if s := string(v.Bytes()); strings.HasPrefix(s, "\x1b[") {
+		return s
+	}
+	return ""
+}
+
+// answer takes the terminal's reply to cellSizeAsk or sixelAsk, returning
+// the renderer to carry on with and, if sixel is ruled out, why
+func (g *graphics) answer(reply string) (*graphics, string) {
+	if g == nil || g.sixel == nil {
+		return g, ""
+	}
+	s := *g.sixel
+	var h, w int
+	switch {
+	case strings.HasSuffix(reply, "t"):
+		if _, err := fmt.Sscanf(reply, "\x1b[6;%d;%dt", &h, &w); err != nil || w <= 0 || h <= 0 {
+			return g, ""
+		}
+		s.cellW, s.cellH = w, h
+	case strings.HasPrefix(reply, "\x1b[?") && strings.HasSuffix(reply, "c"):
+		// answered in order, so the cell size is in by now if it's coming
+		if !slices.Contains(strings.Split(reply[3:len(reply)-1], ";"), sixelAttribute) {
+			return nil, "no sixel graphics"
+		}
+		if s.cellW == 0 {
+			return nil, "no cell size from the terminal"
+		}
+		s.ok = true
+	default:
+		return g, ""
+	}
+	return &graphics{setup: g.setup, sixel: &s}, ""
+}
+
+// askCellSize asks again after a resize, which may be a change of font
+func (g *graphics) askCellSize() tea.Cmd {
+	if g == nil || g.sixel == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		_, _ = desktopOut.Write([]byte(cellSizeAsk))
+		return nil
+	}
+}
+
+// sixelShown reports whether this frame's playfield is drawn as an image
+func (m model) sixelShown() bool {
+	return m.gfx != nil && m.gfx.sixel != nil && m.gfx.sixel.ok &&
+		!m.narrow() && !m.paused && !m.hitboxes && m.introLabel() == "" && !m.lockStalled()
+}
+
+// sixelBlank is a playfield row under the image; it never matches a row of
+// text, so going back to text redraws every row and clears the image
+func sixelBlank(cols int) string { return "\x1b[m" + strings.Repeat(" ", 2*cols) }
+
+// sixelFrame draws rows as an image, with the world moved half a cell on
+// for an in-between frame (half, when rows leave the gopher out)
+func (m model) sixelFrame(rows [][]string, half bool) *image.Paletted {
+	s := m.gfx.sixel
+	cw, ch := 2*s.cellW, s.cellH // a world cell is two terminal columns
+	img := image.NewPaletted(image.Rect(0, 0, m.gameCols*cw, m.gameRows*ch), sixelPalette)
+	tiles := map[string][]uint8{}
+	draw := func(cell string, x0, y0 int) {
+		tile, ok := tiles[cell]
+		if !ok {
+			tile = sixelTile(cell, cw, ch)
+			tiles[cell] = tile
+		}
+		for y := range ch {
+			for x := max(-x0, 0); x < min(cw, img.Rect.Dx()-x0); x++ {
+				if p := tile[y*cw+x]; p != 0 {
+					img.Pix[(y0+y)*img.Stride+x0+x] = p
+				}
+			}
+		}
+	}
+	shift := 0
+	if half {
+		shift = s.cellW
+	}
+	for y, cells := range rows {
+		for x, cell := range cells {
+			draw(cell, x*cw-shift, y*ch)
+		}
+		// a tile running off the right edge carries on into the gap
+		if last := cells[len(cells)-1]; shift > 0 {
+			if _, tile := halfColours[last]; tile {
+				draw(last, len(cells)*cw-shift, y*ch)
+			}
+		}
+	}
+	if half && m.playerY >= 0 && m.playerY < m.gameRows && playerCol < m.gameCols {
+		draw(m.character().Sprite, playerCol*cw, m.playerY*ch)
+	}
+	return img
+}
+
+// sixelTile is cell drawn w×h as palette indexes, 0 (the sky) where it's
+// see-through
+func sixelTile(cell string, w, h int) []uint8 {
+	tile := make([]uint8, w*h)
+	if art, ok := spriteArt[cell]; ok {
+		for y := range h {
+			row := art[y*len(art)/h]
+			for x := range w {
+				if c, ok := spritePalette[row[x*len(row)/w]]; ok {
+					tile[y*w+x] = uint8(sixelPalette.Index(c))
+				}
+			}
+		}
+		return tile
+	}
+	for i := range tile {
+		tile[i] = uint8(sixelPalette.Index(cellColour(cell)))
+	}
+	return tile
+}
+
+// sixelEncode writes img as a sixel image: bands six pixels high, each
+// colour in a band a pass of run-length coded columns
+func sixelEncode(img *image.Paletted) string {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bPq\"1;1;%d;%d", w, h)
+	for i, c := range img.Palette {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+	used := make([]bool, len(img.Palette))
+	band := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		if top > 0 {
+			b.WriteByte('-') // next band
+		}
+		rows := min(6, h-top)
+		clear(used)
+		for y := top; y < top+rows; y++ {
+			for _, p := range img.Pix[y*img.Stride : y*img.Stride+w] {
+				used[p] = true
+			}
+		}
+		first := true
+		for c, in := range used {
+			if !in {
+				continue
+			}
+			if !first {
+				b.WriteByte('$') // back to the band's start for the next colour
+			}
+			first = false
+			for x := range w {
+				bits := byte(0)
+				for dy := range rows {
+					if img.Pix[(top+dy)*img.Stride+x] == uint8(c) {
+						bits |= 1 << dy
+					}
+				}
+				band[x] = '?' + bits
+			}
+			fmt.Fprintf(&b, "#%d", c)
+			writeSixelRuns(&b, band)
+		}
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRuns writes a colour's pass over a band, runs of the same
+// column shortened to !count and the empty columns at the end left off
+func writeSixelRuns(b *strings.Builder, band []byte) {
+	band = bytes.TrimRight(band, "?")
+	for i := 0; i < len(band); {
+		j := i
+		for j < len(band) && band[j] == band[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(b, "!%d%c", n, band[i])
+		} else {
+			b.Write(band[i:j])
+		}
+		i = j
+	}
+}
+
+// sixelOver draws img over the blank rows ending with the current one: up
+// to the first, back to the playfield's left edge and back again after
+func sixelOver(img string, rows, cols int) string {
+	up := ""
+	if rows > 1 {
+		up = fmt.Sprintf("\x1b[%dA", rows-1)
+	}
+	return fmt.Sprintf("\x1b7%s\x1b[%dD%s\x1b8", up, 2*cols, img)
+}
@@ -0,0 +1,182 @@
+package gopherdash
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// csiReply stands in for Bubble Tea's unknown CSI sequence message, a byte
+// slice it doesn't export
+type csiReply []byte
+
+func TestSixelProbe(t *testing.T) {
+	g := newGraphics(rendererSixel, func(string) string { return "" })
+	if !strings.Contains(g.setup, cellSizeAsk+sixelAsk) {
+		t.Fatalf("setup %q doesn't ask the terminal", g.setup)
+	}
+	sized, _ := g.answer("\x1b[6;20;10t")
+	if s := sized.sixel; s.cellW != 10 || s.cellH != 20 || s.ok {
+		t.Errorf("after the cell size: %+v", *s)
+	}
+	if ready, why := sized.answer("\x1b[?62;4;22c"); ready == nil || !ready.sixel.ok {
+		t.Errorf("sixel in the attributes ruled out: %s", why)
+	}
+	for _, tc := range []struct {
+		g     *graphics
+		reply string
+	}{
+		{sized, "\x1b[?62;22c"}, // no sixel
+		{g, "\x1b[?62;4;22c"},   // no cell size
+	} {
+		if next, why := tc.g.answer(tc.reply); next != nil || why == "" {
+			t.Errorf("%q: carried on with sixel", tc.reply)
+		}
+	}
+	if next, _ := g.answer("\x1b[I"); next != g {
+		t.Error("a reply to something else changed the renderer")
+	}
+	if terminalReply(tea.KeyMsg{Type: tea.KeyEnter}) != "" || terminalReply(nil) != "" {
+		t.Error("a key read as a reply")
+	}
+}
+
+// TestSixelPlayfield has the terminal answer, then checks the playfield is
+// an image over blank rows of the usual width
+func TestSixelPlayfield(t *testing.T) {
+	isolateSaves(t)
+	m := tinyModel()
+	m.cfg.Renderer = rendererSixel
+	m.gfx = newGraphics(rendererSixel, func(string) string { return "" })
+	for _, msg := range []tea.Msg{
+		tea.WindowSizeMsg{Width: 40, Height: 16},
+		csiReply("\x1b[6;4;2t"),
+		csiReply("\x1b[?62;4c"),
+	} {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	if !m.sixelShown() {
+		t.Fatal("the playfield isn't an image")
+	}
+	game := m.renderGame()
+	if strings.Count(game, "\x1bPq") != 1 {
+		t.Fatalf("want one image in %.80q…", game)
+	}
+	for i, line := range strings.Split(game, "\n") {
+		if w := lipgloss.Width(line); w != 2*m.gameCols {
+			t.Errorf("row %d is %d columns, want %d", i, w, 2*m.gameCols)
+		}
+	}
+	for _, line := range strings.Split(m.View(), "\n") {
+		if w := lipgloss.Width(line); w > m.w {
+			t.Errorf("%d columns in a %d-column window: %.40q", w, m.w, line)
+		}
+	}
+
+	// the gopher's in its cell, and stays put while the world moves half
+	// a cell under it
+	gopher := uint8(sixelPalette.Index(spritePalette['c']))
+	at := func(img *image.Paletted, col, row int) bool {
+		x0, y0 := col*4, row*4
+		for y := y0; y < y0+4; y++ {
+			for x := x0; x < x0+4; x++ {
+				if img.ColorIndexAt(x, y) == gopher {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, half := range []bool{false, true} {
+		img := m.sixelFrame(m.gameCells(half), half)
+		if got, want := img.Bounds().Size(), image.Pt(m.gameCols*4, m.gameRows*4); got != want {
+			t.Errorf("half %v: image %v, want %v", half, got, want)
+		}
+		if !at(img, playerCol, m.playerY) {
+			t.Errorf("half %v: no gopher in its cell", half)
+		}
+		ground := img.ColorIndexAt(img.Bounds().Dx()-1, img.Bounds().Dy()-1)
+		if ground == 0 {
+			t.Errorf("half %v: the ground stops short of the edge", half)
+		}
+	}
+
+	m.paused = true
+	if m.sixelShown() || strings.Contains(m.renderGame(), "\x1bP") {
+		t.Error("the pause text went under an image")
+	}
+}
+
+func TestSixelEncode(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 3, 7),
+		color.Palette{color.Black, color.RGBA{0xff, 0, 0, 0xff}})
+	img.SetColorIndex(0, 0, 1)
+	for y := range 7 {
+		img.SetColorIndex(2, y, 1)
+	}
+	want := "\x1bPq\"1;1;3;7#0;2;0;0;0#1;2;100;0;0" +
+		"#0}~$#1@?~" + // first band: six rows, colour by colour
+		"-#0@@$#1??@" + // the last row
+		"\x1b\\"
+	if got := sixelEncode(img); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	var b strings.Builder
+	writeSixelRuns(&b, []byte("~~~~~@@@????"))
+	if got := b.String(); got != "!5~@@@" {
+		t.Errorf("runs: %q", got)
+	}
+}
+
+// replyRecorder keeps the terminal replies a program is sent, quitting once
+// it has want of them
+type replyRecorder struct {
+	got  *[]string
+	want int
+}
+
+func (r replyRecorder) Init() tea.Cmd { return nil }
+func (r replyRecorder) View() string  { return "" }
+
+func (r replyRecorder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if reply := terminalReply(msg); reply != "" {
+		*r.got = append(*r.got, reply)
+	}
+	if len(*r.got) == r.want {
+		return r, tea.Quit
+	}
+	return r, nil
+}
+
+// TestTerminalReply feeds replies through Bubble Tea's input reader, to be
+// sure the messages it makes of them are the ones terminalReply reads
+func TestTerminalReply(t *testing.T) {
+	replies := []string{
+		"\x1b[6;20;10t",  // cell size
+		"\x1b[?62;4;22c", // device attributes
+		"\x1b[?26u",      // kitty flags
+		"\x1b[32;1:3u",   // Space released
+		"\x1b[59;2;58u",  // ':' typed
+	}
+	var got []string
+	p := tea.NewProgram(replyRecorder{&got, len(replies)},
+		tea.WithInput(strings.NewReader(strings.Join(replies, ""))), tea.WithOutput(io.Discard),
+		tea.WithoutRenderer(), tea.WithoutSignalHandler())
+	stop := time.AfterFunc(5*time.Second, p.Kill)
+	defer stop.Stop()
+	if _, err := p.Run(); err != nil {
+		t.Fatalf("%v, with %q read", err, got)
+	}
+	if !slices.Equal(got, replies) {
+		t.Errorf("read %q, want %q", got, replies)
+	}
+}
@@ -0,0 +1,94 @@
+package gopherdash
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ----------------------------------------------------------------------------
+// SKY GRADIENT (truecolor terminals)
+// ----------------------------------------------------------------------------
+
+// On a truecolor terminal the playfield has a sky behind it: a background
+// colour per row, fading from the top of the sky down to the horizon. The
+// run is a day: it starts in the morning, the sky goes deep blue at noon,
+// red at dusk and dark at night before dawn comes round again, every
+// skyDay of distance. It's a decorative layer, so the battery saver and
+// reduced motion leave it out, as does the dark of night mode, which has
+// its own darkness; -sky=false turns it off.
+
+const (
+	skyDay   = 3000 // distance from one dawn to the next
+	skyStart = 0.15 // how far into the day a run starts: mid-morning
+)
+
+// skyKey is the sky at a time of day, 0 (dawn) to 1 (the next dawn)
+type skyKey struct {
+	at          float64
+	top, bottom color.RGBA
+}
+
+// skyCycle is the day's skies; the sky between two is a blend of them
+var skyCycle = []skyKey{
+	{0.00, color.RGBA{0x3a, 0x2a, 0x5e, 0xff}, color.RGBA{0xf4, 0x9a, 0x6b, 0xff}}, // dawn
+	{0.25, color.RGBA{0x1e, 0x64, 0xc8, 0xff}, color.RGBA{0x9c, 0xd4, 0xf0, 0xff}}, // day
+	{0.55, color.RGBA{0x1e, 0x64, 0xc8, 0xff}, color.RGBA{0x9c, 0xd4, 0xf0, 0xff}},
+	{0.70, color.RGBA{0x2b, 0x1d, 0x4f, 0xff}, color.RGBA{0xe8, 0x6a, 0x4a, 0xff}}, // dusk
+	{0.85, color.RGBA{0x05, 0x07, 0x18, 0xff}, color.RGBA{0x1a, 0x22, 0x48, 0xff}}, // night
+	{1.00, color.RGBA{0x3a, 0x2a, 0x5e, 0xff}, color.RGBA{0xf4, 0x9a, 0x6b, 0xff}},
+}
+
+// blend is the colour f of the way from a to b
+func blend(a, b color.RGBA, f float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*f + 0.5) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
+
+// skyAt is the top and bottom of the sky at time of day t
+func skyAt(t float64) (top, bottom color.RGBA) {
+	for i, k := range skyCycle[1:] {
+		if t <= k.at {
+			prev := skyCycle[i]
+			f := (t - prev.at) / (k.at - prev.at)
+			return blend(prev.top, k.top, f), blend(prev.bottom, k.bottom, f)
+		}
+	}
+	last := skyCycle[len(skyCycle)-1]
+	return last.top, last.bottom
+}
+
+// timeOfDay is how far through its day the run is, from its distance
+func (m model) timeOfDay() float64 {
+	t := skyStart + float64(m.dist)/skyDay
+	return t - float64(int(t))
+}
+
+// skyRows are the background sequences for each playfield row, top to
+// bottom, or nil when there's no sky to draw
+func (m model) skyRows() []string {
+	if !m.cfg.Sky || !m.decorative() || m.dark() || m.gameRows <= 0 ||
+		lipgloss.ColorProfile() != termenv.TrueColor {
+		return nil
+	}
+	top, bottom := skyAt(m.timeOfDay())
+	rows := make([]string, m.gameRows)
+	for i := range rows {
+		f := 0.0
+		if m.gameRows > 1 {
+			f = float64(i) / float64(m.gameRows-1)
+		}
+		c := blend(top, bottom, f)
+		rows[i] = fmt.Sprintf("\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+	}
+	return rows
+}
+
+// paintSky puts line on the sky bg, which has to be put back after every
+// styled cell's reset
+func paintSky(line, bg string) string {
+	return bg + strings.ReplaceAll(line, "\x1b[0m", "\x1b[0m"+bg) + "\x1b[49m"
+}
@@ -0,0 +1,70 @@
+package gopherdash
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestSkyCycle(t *testing.T) {
+	dawn, day := skyCycle[0], skyCycle[1]
+	if top, bottom := skyAt(0); top != dawn.top || bottom != dawn.bottom {
+		t.Errorf("dawn: %v %v", top, bottom)
+	}
+	if top, _ := skyAt(0.4); top != day.top {
+		t.Errorf("noon: %v, want %v", top, day.top)
+	}
+	if top, _ := skyAt(0.125); top != blend(dawn.top, day.top, 0.5) {
+		t.Errorf("mid-morning: %v", top)
+	}
+	if top, _ := skyAt(1); top != dawn.top {
+		t.Error("the day doesn't come round to dawn again")
+	}
+	if got := blend(color.RGBA{0, 0, 0, 0xff}, color.RGBA{200, 100, 10, 0xff}, 0.5); got != (color.RGBA{100, 50, 5, 0xff}) {
+		t.Errorf("blend: %v", got)
+	}
+
+	m := model{dist: skyDay}
+	if got := m.timeOfDay(); got < skyStart-1e-9 || got > skyStart+1e-9 {
+		t.Errorf("a day on: %v, want %v", got, skyStart)
+	}
+}
+
+func TestSkyRows(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	cfg := defaultConfig()
+	m := model{cfg: cfg, gameRows: 6}
+	rows := m.skyRows()
+	if len(rows) != 6 || rows[0] == rows[5] {
+		t.Fatalf("no gradient: %q", rows)
+	}
+	for _, off := range []func(*model){
+		func(m *model) { m.cfg.Sky = false },
+		func(m *model) { m.cfg.BatterySaver = true },
+		func(m *model) { m.cfg.Night = true },
+	} {
+		m := model{cfg: cfg, gameRows: 6}
+		off(&m)
+		if m.skyRows() != nil {
+			t.Errorf("sky drawn with %+v", m.cfg)
+		}
+	}
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	if m.skyRows() != nil {
+		t.Error("sky drawn without truecolor")
+	}
+
+	// the sky goes back on after every styled cell, and takes up no room
+	cell := lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render("ab")
+	line := paintSky("  "+cell+"  ", rows[0])
+	if n := strings.Count(line, rows[0]); n != 2 {
+		t.Errorf("sky set %d times in %q", n, line)
+	}
+	if w := lipgloss.Width(line); w != 6 {
+		t.Errorf("%d columns, want 6", w)
+	}
+}
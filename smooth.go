@@ -0,0 +1,99 @@
+package gopherdash
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ----------------------------------------------------------------------------
+// SMOOTH MOTION (half-cell scrolling)
+// ----------------------------------------------------------------------------
+
+// A world cell is two terminal columns wide, so the playfield can be drawn
+// scrolled by half a cell: one column. With -smooth, a frame is drawn
+// halfway between ticks with the world moved that extra column, which
+// doubles the apparent frame rate when the game is slow. Glyphs cut in half
+// by the playfield edges or by the gopher keep their exposed half as a
+// one-column block in the tile's colour.
+
+// halfMsg asks for the in-between frame of the tick it belongs to
+type halfMsg struct{ gen int }
+
+func (m model) halfAfter(d time.Duration, gen int) tea.Cmd {
+	return m.after(d, func(time.Time) tea.Msg { return halfMsg{gen} })
+}
+
+// halfColours colour the blocks standing in for the halves of cut wide
+// glyphs; a cut glyph not listed here is left out
+var halfColours = map[string]lipgloss.Color{
+	groundChar: "94",
+	wallChar:   "124",
+	"⬜":        "15",
+	"🟨":        "11",
+	"🟦":        "12",
+}
+
+// smooth reports whether in-between frames are drawn. They're decorative,
+// so reduced motion and the battery saver turn them off. They need a colour
+// terminal, since the blocks only read as part of a tile in its colour, and
+// they're skipped in the dark, where nothing past the flashlight visibly
+// moves anyway, and whenever the world isn't scrolling.
+func (m model) smooth() bool {
+	return m.cfg.Smooth && m.decorative() && !m.narrow() && lipgloss.ColorProfile() != termenv.Ascii &&
+		m.live() && !m.inIntro() && !m.dark() && !m.clinging && !m.lockStalled()
+}
+
+// halfShown reports whether this frame is an in-between one
+func (m model) halfShown() bool { return m.half && m.smooth() && !m.hitboxes }
+
+// nextTick schedules the wakeup after one that ran n steps and, when
+// smooth, the frame in between; catching up on several steps at once
+// moves the world too far for a half step
+func (m model) nextTick(n int) tea.Cmd {
+	d := m.tickDur() * time.Duration(n)
+	if !m.smooth() || n > 1 {
+		return m.tickAfter(d, m.tickGen)
+	}
+	return tea.Batch(m.tickAfter(d, m.tickGen), m.halfAfter(d/2, m.tickGen))
+}
+
+// halfStep joins a row of cells drawn half a cell further left. cells has
+// one more entry than the playfield is wide, for the cell scrolling in;
+// sprite, if not "", is the gopher, which doesn't scroll.
+func halfStep(cells []string, sprite string) string {
+	var b strings.Builder
+	n := len(cells) - 1
+	b.WriteString(half(cells[0], false))
+	for i := 1; i < n; i++ {
+		switch {
+		case sprite != "" && i == playerCol:
+			b.WriteString(half(cells[i], true))
+			b.WriteString(sprite)
+		case sprite != "" && i == playerCol+1:
+			b.WriteString(half(cells[i], false))
+		default:
+			b.WriteString(cells[i])
+		}
+	}
+	b.WriteString(half(cells[n], true))
+	return b.String()
+}
+
+// half is the left or right column of a two-column cell
+func half(cell string, left bool) string {
+	if r := []rune(cell); len(r) == 2 && lipgloss.Width(cell) == 2 {
+		if left {
+			return string(r[0]) // two narrow characters, such as stamped text
+		}
+		return string(r[1])
+	}
+	c, ok := halfColours[cell]
+	if !ok {
+		return " "
+	}
+	return lipgloss.NewStyle().Foreground(c).Render("█")
+}
@@ -0,0 +1,215 @@
+package gopherdash
+
+import (
+	"math"
+	"math/rand"
+)
+
+// ----------------------------------------------------------------------------
+// OBSTACLE STREAM
+// ----------------------------------------------------------------------------
+
+const (
+	spawnChance = 0.12 // chance a free world cell gets a hazard
+
+	// a jump keeps the gopher off the ground for jumpCells steps and lands on
+	// the next, so back-to-back jumps repeat every jumpCells+1 cells
+	jumpCells = 6
+)
+
+// spawner generates a run's hazards cell by cell in world coordinates, where
+// world cell w reaches the screen at column w-dist, a chunk at a time (see
+// biomes.go). The stream only depends on the seed – never on the terminal
+// width or on how far ahead it has been generated – so a seed always
+// produces the same course; a director (see director.go) adds how the run's
+// been played.
+type spawner struct {
+	rng    *rand.Rand // the current chunk's
+	seed   int64
+	chunk  int          // the chunk next is in, whose numbers rng gives
+	biome  Biome        // and its biome
+	biomes []Biome      // the biomes chunks draw from
+	draws  int          // numbers taken from rng in the chunk; replaying them restores it
+	pace   pace         // the director's shape for the chunk (see director.go)
+	paces  map[int]pace // and for chunks still to come, by index
+	next   int          // first world cell not yet decided
+	last   int          // world cell of the most recent hazard
+	end    int          // the last cell it covers; hazards wider than a cell go on past last
+	tight  int          // slack in the jump rhythm used up by recent close hazards
+	air    int          // steps a jump stays airborne; jumpCells unless modifiers change it
+	arc    []int        // the jump's height on each of those steps, in rows
+	big    bool         // rocks take two cells (the big-obstacles modifier)
+	zones  bool         // multiplier zones, thicker with hazards (see zones.go)
+
+	chance float64 // that a free cell gets a hazard; rises with prestige loops
+}
+
+// newSpawner starts a stream at world cell start whose first grace cells are
+// guaranteed free of hazards
+func newSpawner(seed int64, start, grace int) spawner {
+	s := spawner{
+		seed:   seed,
+		biomes: biomes,
+		next:   start,
+		last:   start - minGapCells, // first cell already passes the gap check
+		end:    start - minGapCells,
+		air:    jumpCells,
+		arc:    jumpArc(jumpVel, gravity, math.MaxInt, 1),
+
+		chance: spawnChance,
+	}
+	s.enter(chunkOf(start))
+	s.keepClear(grace)
+	return s
+}
+
+// enter starts deciding chunk c: its own random numbers and its biome
+func (s *spawner) enter(c int) {
+	s.chunk, s.draws = c, 0
+	s.rng = rand.New(rand.NewSource(chunkSeed(s.seed, c)))
+	s.biome = biomeOf(s.seed, c, s.biomes)
+	s.pace = s.paces[c]
+	delete(s.paces, c)
+}
+
+// shape sets chunk c's pace. The chunk before may have left a hazard
+// hanging over into c already, which takes no numbers, so c's cells still
+// to decide are all it shapes either way.
+func (s *spawner) shape(c int, p pace) {
+	if c == s.chunk {
+		s.pace = p
+		return
+	}
+	if s.paces == nil {
+		s.paces = map[int]pace{}
+	}
+	s.paces[c] = p
+}
+
+// settle enters the chunk next has moved on to, if it has
+func (s *spawner) settle() {
+	if c := chunkOf(s.next); c != s.chunk {
+		s.enter(c)
+	}
+}
+
+// roll draws the stream's next random number
+func (s *spawner) roll() float64 {
+	s.draws++
+	return s.rng.Float64()
+}
+
+// skipDraws fast-forwards rng past n numbers already drawn
+func (s *spawner) skipDraws(n int) {
+	for s.draws < n {
+		s.roll()
+	}
+}
+
+// keepClear skips the next n undecided cells without placing anything
+func (s *spawner) keepClear(n int) {
+	s.next += max(n, 0)
+	s.settle()
+}
+
+// fill decides every world cell up to (not including) upTo and returns the
+// hazards placed, in world coordinates
+func (s *spawner) fill(upTo int) []obstacle {
+	var out []obstacle
+	defer s.settle()
+	for ; s.next < upTo; s.next++ {
+		s.settle()
+		if s.next-s.end < minGapCells || !s.fair(s.next, rock{}) { // keep spacing fair
+			continue
+		}
+		chance := s.chance * s.biome.Density() * s.pace.at(s.next-s.chunk*chunkCells)
+		if s.zoned(s.next) {
+			chance *= zoneDensity
+		}
+		if s.roll() < chance {
+			k := pickKind(s.biome, s.roll())
+			if !s.fair(s.next, k) {
+				continue // no jump from here clears it
+			}
+			out = append(out, obstacle{s.next, k})
+			s.place(s.next, k)
+			if _, ok := k.(rock); ok && s.big {
+				s.next++ // the rock's second cell; the tally keeps the next jump fair
+				out = append(out, obstacle{s.next, k})
+				s.place(s.next, k)
+			}
+			s.next = s.end // the loop moves on past the hazard
+		}
+	}
+	return out
+}
+
+// Each hazard needs its own jump once they are minGapCells apart, and a gap
+// shorter than the jump rhythm hands the next jump less room to take off:
+// after air-1 such gaps in a row the gopher would have to land on a
+// hazard. Hazards that only part of the arc clears – wide ones, which the
+// gopher has to stay up over end to end, and tall ones – narrow the cells
+// their own jump can take off from, and the latest of those sets the
+// earliest landing after it. fair reports whether a hazard of kind k at x
+// still leaves room; place keeps the tally.
+func (s *spawner) fair(x int, k ObstacleKind) bool {
+	first, last, ok := s.takeoff(k)
+	return ok && max(s.tight+s.air+1-(x-s.last), s.air-last) <= s.air-first
+}
+
+func (s *spawner) place(x int, k ObstacleKind) {
+	_, last, _ := s.takeoff(k)
+	s.tight = max(s.tight+s.air+1-(x-s.last), s.air-last, 0)
+	s.last, s.end = x, x+k.Width()-1
+}
+
+// takeoff is the range of steps before reaching a hazard of kind k a jump
+// can start and clear it, counted back from the hazard's first cell
+func (s *spawner) takeoff(k ObstacleKind) (first, last int, ok bool) {
+	w, need := k.Width(), k.Height()
+	for step := 1; step+w-1 <= len(s.arc); step++ {
+		high := true
+		for _, h := range s.arc[step-1 : step+w-1] {
+			high = high && h >= need
+		}
+		if high {
+			if !ok {
+				first, ok = step, true
+			}
+			last = step
+		}
+	}
+	return first, last, ok
+}
+
+// fillObstacles tops the obstacle list up to the spawn horizon
+func (m *model) fillObstacles() {
+	if m.gameCols == 0 {
+		return
+	}
+	upTo := m.direct(m.camera().toWorld(m.spawnHorizon()))
+	from := m.spawn.next
+	for _, ob := range m.spawn.fill(upTo) {
+		if !m.buildable(ob.extent()) {
+			continue // too close to a slope or a platform edge
+		}
+		m.logDebug("spawn", "x", ob.x, "kind", ob.kind.Name())
+		m.obstacles = append(m.obstacles, ob)
+	}
+	m.placeTiles(from-tileReach, m.spawn.next-tileReach)
+}
+
+// wave places n rocks gap cells apart straight after whatever the stream
+// has already decided
+func (s *spawner) wave(n, gap int) []obstacle {
+	out := make([]obstacle, 0, n)
+	x := max(s.next, s.last+gap)
+	for i := 0; i < n; i++ {
+		out = append(out, obstacle{x, rock{}})
+		s.place(x, rock{})
+		x += gap
+	}
+	s.next = s.last + 1
+	s.settle()
+	return out
+}
@@ -0,0 +1,329 @@
+package gopherdash
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// courseCells is how much of each generated course the properties look at
+const courseCells = 600
+
+var quickCfg = &quick.Config{MaxCount: 2000}
+
+// course generates the first courseCells world cells for seed
+func course(seed int64) []obstacle {
+	s := newSpawner(seed, playerCol+1, defaultGraceCells)
+	return s.fill(playerCol + 1 + courseCells)
+}
+
+func TestSpawnerGapsAndOverlaps(t *testing.T) {
+	prop := func(seed int64) bool {
+		obs := course(seed)
+		for i := 1; i < len(obs); i++ {
+			if obs[i].x-obs[i-1].extent().hi < minGapCells {
+				t.Logf("seed %d: hazards at %d and %d", seed, obs[i-1].x, obs[i].x)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(prop, quickCfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpawnerGrace(t *testing.T) {
+	prop := func(seed int64) bool {
+		obs := course(seed)
+		return len(obs) == 0 || obs[0].x >= playerCol+1+defaultGraceCells
+	}
+	if err := quick.Check(prop, quickCfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The stream must not depend on how far ahead it is generated at a time,
+// which is what the terminal width decides.
+func TestSpawnerWidthIndependent(t *testing.T) {
+	prop := func(seed int64, widths []uint8) bool {
+		want := course(seed)
+		s := newSpawner(seed, playerCol+1, defaultGraceCells)
+		var got []obstacle
+		upTo := playerCol + 1
+		for i := 0; upTo < playerCol+1+courseCells; i++ {
+			w := 10
+			if len(widths) > 0 {
+				w = 10 + int(widths[i%len(widths)])
+			}
+			upTo = min(upTo+w, playerCol+1+courseCells)
+			got = append(got, s.fill(upTo)...)
+		}
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(prop, quickCfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpawnerClearable(t *testing.T) {
+	prop := func(seed int64) bool {
+		obs := course(seed)
+		if d, ok := clearable(obs, courseCells); !ok {
+			t.Logf("seed %d: no way past distance %d", seed, d)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(prop, quickCfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// arc is the part of the gopher's state that decides what a jump does next
+type arc struct{ playerY, velY int }
+
+// clearable is the jump-arc validator: it runs the real game step over obs
+// with every possible choice of jumping or not at each step, keeping the
+// distinct states that survive. Jump buffering and coyote time are off, so
+// a course that passes is fair without them. It returns the distance
+// reached and whether that is the whole course.
+func clearable(obs []obstacle, dist int) (int, bool) {
+	cfg := defaultConfig()
+	cfg.JumpBuffer, cfg.Coyote = 0, 0
+	const rows = 10
+	states := map[arc]bool{{rows - 2, 0}: true}
+	now := time.Now()
+	first := 0
+	for d := 0; d < dist; d++ {
+		// the hazards the step can reach
+		for first < len(obs) && obs[first].extent().hi < d+playerCol {
+			first++
+		}
+		last := first
+		for last < len(obs) && obs[last].x <= d+playerCol+1 {
+			last++
+		}
+		next := map[arc]bool{}
+		for s := range states {
+			for _, press := range []bool{false, true} {
+				m := model{
+					cfg:       cfg,
+					ghost:     true,
+					gameRows:  rows,
+					dist:      d,
+					playerY:   s.playerY,
+					velY:      s.velY,
+					frameDur:  startFrame,
+					obstacles: append([]obstacle(nil), obs[first:last]...),
+				}
+				if press {
+					m.pressJump()
+				}
+				m.step(now)
+				if !m.gameOver {
+					next[arc{m.playerY, m.velY}] = true
+				}
+			}
+		}
+		if len(next) == 0 {
+			return d, false
+		}
+		states = next
+	}
+	return dist, true
+}
+
+// Logs and wide holes come up on ordinary courses, and the validator
+// above has them to clear.
+func TestSpawnerWideHazards(t *testing.T) {
+	seen := map[string]bool{}
+	for seed := int64(1); seed <= 50; seed++ {
+		for _, ob := range course(seed) {
+			if ob.kind.Width() > 1 {
+				seen[ob.kind.Name()] = true
+			}
+		}
+	}
+	for _, k := range []ObstacleKind{fallenLog{}, wideHole{}} {
+		if !seen[k.Name()] {
+			t.Errorf("no %s in 50 courses", k.Name())
+		}
+	}
+	if _, ok := clearable([]obstacle{{20, fallenLog{}}, {28, wideHole{}}}, 60); !ok {
+		t.Error("a log and a wide hole minGapCells apart should be clearable")
+	}
+}
+
+func TestSpawnerTakeoff(t *testing.T) {
+	classic := jumpArc(jumpVel, gravity, math.MaxInt, 1) // 3 5 6 6 5 3
+	low := []int{1, 2, 3, 3, 2, 1}
+	for _, tc := range []struct {
+		arc         []int
+		kind        ObstacleKind
+		first, last int
+		ok          bool
+	}{
+		{classic, rock{}, 1, 6, true},
+		{classic, fallenLog{}, 1, 4, true},
+		{classic, wideHole{}, 1, 5, true},
+		{classic, rockStack{3}, 1, 6, true},
+		{low, rockStack{2}, 2, 5, true},
+		{low, rockStack{3}, 3, 4, true}, // the top of the jump only
+		{low, fallenLog{}, 1, 4, true},
+		{[]int{1, 2, 2, 1}, rockStack{3}, 0, 0, false},
+	} {
+		s := spawner{arc: tc.arc, air: len(tc.arc)}
+		first, last, ok := s.takeoff(tc.kind)
+		if first != tc.first || last != tc.last || ok != tc.ok {
+			t.Errorf("%s over %v: take off %d to %d steps before (%v), want %d to %d (%v)",
+				tc.kind.Name(), tc.arc, first, last, ok, tc.first, tc.last, tc.ok)
+		}
+	}
+}
+
+// Stacks only come up where the run's jump gets over them.
+func TestSpawnerStacks(t *testing.T) {
+	for _, tc := range []struct {
+		arc  []int
+		tall bool
+	}{
+		{jumpArc(jumpVel, gravity, math.MaxInt, 1), true},
+		{[]int{1, 2, 2, 2, 2, 1}, false},
+	} {
+		seen := map[string]bool{}
+		for seed := int64(1); seed <= 100; seed++ {
+			s := newSpawner(seed, playerCol+1, defaultGraceCells)
+			s.arc, s.air = tc.arc, len(tc.arc)
+			for _, ob := range s.fill(playerCol + 1 + courseCells) {
+				seen[ob.kind.Name()] = true
+			}
+		}
+		if !seen["stack"] || seen["tall stack"] != tc.tall {
+			t.Errorf("jump %v: stacks %v, tall stacks %v; want tall stacks %v",
+				tc.arc, seen["stack"], seen["tall stack"], tc.tall)
+		}
+	}
+}
+
+// Impossible courses must fail the validator, or the property above proves
+// nothing.
+func TestClearableRejects(t *testing.T) {
+	// a jump clears six cells and must land before the next one, so a long
+	// enough chain of hazards exactly minGapCells apart drifts into a landing
+	var chain []obstacle
+	for i := 0; i < 8; i++ {
+		chain = append(chain, obstacle{20 + i*minGapCells, rock{}})
+	}
+	var wide []obstacle
+	for x := 20; x < 27; x++ {
+		wide = append(wide, obstacle{x, hole{}})
+	}
+	cases := map[string][]obstacle{"chain of rocks": chain, "wide hole": wide}
+	for name, obs := range cases {
+		if _, ok := clearable(obs, 60); ok {
+			t.Errorf("%s: validator accepted an impossible course", name)
+		}
+	}
+	if _, ok := clearable([]obstacle{{20, rock{}}, {26, rock{}}}, 60); !ok {
+		t.Error("two rocks minGapCells apart should be clearable")
+	}
+}
+
+// terrainModel is a run on terrain with the gopher standing just before
+// world cell x
+func terrainModel(t *testing.T, at func(m model, x int) bool) model {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Terrain, cfg.Seed = 0, true, 1
+	m, _ := clockedModel(t, cfg)
+	for x := playerCol + 1 + cfg.GraceCells; x < 200*terrainSegment; x++ {
+		if at(m, x) {
+			m.dist = x - 1 - playerCol
+			m.obstacles = nil
+			m.playerY = m.groundRow()
+			return m
+		}
+	}
+	t.Fatal("no such terrain in 200 segments; pick another seed")
+	return m
+}
+
+// TestTerrainWall runs straight into a wall: too tall to step up, it ends
+// the run
+func TestTerrainWall(t *testing.T) {
+	m := terrainModel(t, func(m model, x int) bool {
+		return m.terrainAt(x) == wallHeight && m.terrainAt(x-1) == 0
+	})
+	for i := 0; i < 3 && !m.gameOver; i++ {
+		m.step(m.now())
+		clearHazards(&m)
+	}
+	if !m.gameOver || m.cause != wallCause {
+		t.Fatalf("ran on into the wall (game over %v, cause %q)", m.gameOver, m.cause)
+	}
+}
+
+// TestTerrainRise walks up a hill a row at a time without jumping
+func TestTerrainRise(t *testing.T) {
+	m := terrainModel(t, func(m model, x int) bool {
+		return m.terrainAt(x) == 1 && m.terrainAt(x-1) == 0
+	})
+	top := 0
+	for i := 0; i < 4*rampCells; i++ {
+		m.step(m.now())
+		clearHazards(&m)
+		if m.gameOver {
+			t.Fatalf("died (%s) walking up a one-row rise, %d cells in", m.cause, i)
+		}
+		if m.playerY != m.groundRow() {
+			t.Fatalf("%d cells in: at row %d, the ground is at %d", i, m.playerY, m.groundRow())
+		}
+		top = max(top, m.groundUnder())
+	}
+	if top < 2 {
+		t.Errorf("climbed to %d rows, want the plateau", top)
+	}
+}
+
+// TestTerrainHazards keeps the stream's hazards off slopes and edges: each
+// sits on level ground with no rise within a jump ahead of it
+func TestTerrainHazards(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Terrain = 0, true
+	dropped := 0
+	for seed := int64(1); seed <= 20; seed++ {
+		cfg.Seed = seed
+		m, _ := clockedModel(t, cfg)
+		for m.spawn.next < playerCol+1+courseCells {
+			m.fillObstacles()
+			m.dist += m.gameCols
+		}
+		dropped += len(course(seed)) - len(m.obstacles)
+		for _, ob := range m.obstacles {
+			c := ob.extent()
+			h := m.terrainAt(c.lo)
+			for x := c.lo - 1; x <= c.hi+1; x++ {
+				if m.terrainAt(x) != h {
+					t.Fatalf("seed %d: %s at %d on uneven ground", seed, ob.kind.Name(), ob.x)
+				}
+			}
+			for x := c.hi + 1; x <= c.hi+jumpCells+1; x++ {
+				if m.terrainAt(x) > h {
+					t.Fatalf("seed %d: %s at %d, a rise at %d", seed, ob.kind.Name(), ob.x, x)
+				}
+			}
+		}
+	}
+	if dropped <= 0 {
+		t.Error("terrain dropped no hazards from the stream")
+	}
+}
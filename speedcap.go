@@ -0,0 +1,69 @@
+package gopherdash
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// SPEED FLOOR AND CEILING (-min-tick, -max-tick)
+// ----------------------------------------------------------------------------
+
+// A run speeds up a little every step, and for players with slower
+// reactions it gets too fast long before they're done with it. -min-tick
+// puts a ceiling on the ramp: a step never comes sooner than that many
+// milliseconds after the last. -max-tick is the floor: a step never comes
+// later, so a run can start faster than usual. Either may be used without
+// the other.
+//
+// A capped run isn't up against the same game as everyone else's, so it
+// sets no high score or weekly place; it's ranked in score tables of its
+// own instead, its mode marked "+capped". Races run both sides at the
+// usual speed.
+
+// capped reports whether the run's speed is held by a floor or a ceiling
+func (m model) capped() bool {
+	return (m.cfg.MinTick > 0 || m.cfg.MaxTick > 0) && !m.ghost && !m.saver
+}
+
+// capFrame holds the frame duration d between the run's caps
+func (m model) capFrame(d time.Duration) time.Duration {
+	if !m.capped() {
+		return d
+	}
+	if m.cfg.MinTick > 0 {
+		d = max(d, time.Duration(m.cfg.MinTick)*time.Millisecond)
+	}
+	if m.cfg.MaxTick > 0 {
+		d = min(d, time.Duration(m.cfg.MaxTick)*time.Millisecond)
+	}
+	return d
+}
+
+// validTicks checks the caps make sense together
+func validTicks(c config) error {
+	switch {
+	case c.MinTick < 0 || c.MaxTick < 0:
+		return fmt.Errorf("-min-tick and -max-tick can't be negative")
+	case c.MaxTick > 0 && c.MinTick > c.MaxTick:
+		return fmt.Errorf("-min-tick %d is over -max-tick %d", c.MinTick, c.MaxTick)
+	}
+	return nil
+}
+
+// capHUD shows the caps, lit while the run is being held at one
+func (m model) capHUD() string {
+	var caps []string
+	if m.cfg.MinTick > 0 {
+		caps = append(caps, fmt.Sprintf("≥%dms", m.cfg.MinTick))
+	}
+	if m.cfg.MaxTick > 0 {
+		caps = append(caps, fmt.Sprintf("≤%dms", m.cfg.MaxTick))
+	}
+	label := "Capped " + strings.Join(caps, " ")
+	if held := time.Duration(float64(m.frameDur) * m.character().Accel); m.capFrame(held) != held {
+		return bannerStyle.Render(label)
+	}
+	return label
+}
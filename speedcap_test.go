@@ -0,0 +1,58 @@
+package gopherdash
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidTicks(t *testing.T) {
+	for _, tc := range []struct {
+		min, max int
+		ok       bool
+	}{
+		{0, 0, true},
+		{60, 0, true},
+		{0, 30, true},
+		{40, 40, true},
+		{60, 30, false},
+		{-1, 0, false},
+	} {
+		cfg := defaultConfig()
+		cfg.MinTick, cfg.MaxTick = tc.min, tc.max
+		if err := validTicks(cfg); (err == nil) != tc.ok {
+			t.Errorf("-min-tick %d -max-tick %d: %v", tc.min, tc.max, err)
+		}
+	}
+}
+
+// The ramp stops at the ceiling, the run is tabled apart, and it sets no
+// high score.
+func TestSpeedCap(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.MinTick = 0, 40
+	m, _ := clockedModel(t, cfg)
+	clearHazards(&m)
+	for range 200 {
+		m.step(m.now())
+		clearHazards(&m)
+	}
+	if m.frameDur != 40*time.Millisecond {
+		t.Errorf("steps of %v after 200, want the 40ms ceiling", m.frameDur)
+	}
+	if mode := m.scoreMode(); mode != "endless+capped" {
+		t.Errorf("scored as %q", mode)
+	}
+
+	best := m.profile.HighScore
+	m.dist = best + 100
+	m.setGameOver("rock")
+	if m.profile.HighScore != best || m.newRecord {
+		t.Errorf("a capped run set a high score of %d", m.profile.HighScore)
+	}
+
+	cfg.MinTick, cfg.MaxTick = 0, 30
+	m, _ = clockedModel(t, cfg)
+	if m.frameDur != 30*time.Millisecond {
+		t.Errorf("started at %v under a 30ms floor", m.frameDur)
+	}
+}
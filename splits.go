@@ -0,0 +1,144 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// SPEED-RUN SPLITS
+// ----------------------------------------------------------------------------
+
+const (
+	splitsFile = ".gopherdash_splits"
+	splitEvery = 100 // distance between splits
+	deltaFlash = 3 * time.Second
+)
+
+// splitBook is the record for one mode/seed: the splits of the personal-best
+// run and the fastest time ever seen for each individual segment
+type splitBook struct {
+	PBDist int             `json:"pb_dist"`
+	PB     []time.Duration `json:"pb"`   // cumulative, one per split
+	Best   []time.Duration `json:"best"` // per segment
+}
+
+// splitStore maps a mode/seed key to its book
+type splitStore map[string]splitBook
+
+func splitsPath() string { return dataPath(splitsFile) }
+
+func loadSplits() splitStore {
+	st := splitStore{}
+	if data, err := os.ReadFile(splitsPath()); err == nil {
+		_ = json.Unmarshal(data, &st)
+	}
+	return st
+}
+
+func saveSplits(st splitStore) {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(splitsPath(), data, 0o644)
+}
+
+// splitKey identifies the mode and course the splits belong to
+func (m model) splitKey() string {
+	mode := "endless"
+	if m.cfg.Practice {
+		mode = "practice"
+	}
+	if _, ok := m.cfg.fixedSeed(); ok {
+		return fmt.Sprintf("%s/seed-%d", mode, m.seed)
+	}
+	return mode + "/random"
+}
+
+// stepTimer adds the wall time since the previous gameplay step and takes a
+// split on every splitEvery distance; pauses and countdowns are skipped
+// because they reset lastStep
+func (m *model) stepTimer(now time.Time) {
+	if !m.cfg.Timer {
+		return
+	}
+	m.tickClock(now)
+	if m.dist > 0 && m.dist%splitEvery == 0 {
+		m.splits = append(m.splits, m.runTime)
+		m.splitAt = now
+	}
+}
+
+// tickClock runs the speed-run clock up to now without moving on a cell,
+// as when the gopher is clinging to a wall
+func (m *model) tickClock(now time.Time) {
+	if !m.lastStep.IsZero() {
+		m.runTime += now.Sub(m.lastStep)
+	}
+	m.lastStep = now
+}
+
+// finishSplits folds the run's splits into its book and saves it
+func (m *model) finishSplits() {
+	if !m.cfg.Timer || len(m.splits) == 0 {
+		return
+	}
+	key := m.splitKey()
+	book := m.splitBook[key]
+	for i := range m.splits {
+		seg := segment(m.splits, i)
+		if i >= len(book.Best) {
+			book.Best = append(book.Best, seg)
+		} else if seg < book.Best[i] {
+			book.Best[i] = seg
+		}
+	}
+	if m.dist > book.PBDist {
+		book.PBDist = m.dist
+		book.PB = append([]time.Duration(nil), m.splits...)
+	}
+	m.splitBook[key] = book
+	saveSplits(m.splitBook)
+}
+
+// segment is the length of the i-th segment of cumulative splits
+func segment(splits []time.Duration, i int) time.Duration {
+	if i == 0 {
+		return splits[0]
+	}
+	return splits[i] - splits[i-1]
+}
+
+// timerHUD renders the run clock and, for a few seconds after each split,
+// the delta against the personal best ("★" marks a best-ever segment)
+func (m model) timerHUD() string {
+	s := "Time " + clock(m.runTime)
+	n := len(m.splits)
+	if n == 0 || m.now().Sub(m.splitAt) > deltaFlash {
+		return s
+	}
+	s += fmt.Sprintf("   %d:", n*splitEvery)
+	book := m.splitBook[m.splitKey()]
+	if n <= len(book.PB) {
+		d := m.splits[n-1] - book.PB[n-1]
+		sign := "+"
+		if d < 0 {
+			sign, d = "-", -d
+		}
+		s += fmt.Sprintf(" %s%.2fs", sign, d.Seconds())
+	} else {
+		s += " " + clock(m.splits[n-1])
+	}
+	if n > len(book.Best) || segment(m.splits, n-1) < book.Best[n-1] {
+		s += " ★"
+	}
+	return s
+}
+
+// clock formats a duration as m:ss.s
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%d:%04.1f", int(d.Minutes()), d.Seconds()-60*float64(int(d.Minutes())))
+}
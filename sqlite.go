@@ -0,0 +1,409 @@
+package gopherdash
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // cgo-free driver, registered as "sqlite"
+)
+
+// ----------------------------------------------------------------------------
+// SQLITE STORE (`-store sqlite`)
+// ----------------------------------------------------------------------------
+
+// For heavy players the sqlite store keeps everything in one database file
+// (-db, .gopherdash.db next to the binary by default) in place of the
+// profile, stats and history files. Every run is kept, not just the last
+// maxHistory, indexed by when it ended, so the history is a query for the
+// latest runs rather than a whole-file read, and the stats are a table of
+// counts updated in place. The last and best replays are kept as blobs. A
+// new database starts with whatever the files held.
+
+const (
+	dbFile = ".gopherdash.db"
+	dbTime = "2006-01-02T15:04:05.000000000Z" // UTC, fixed width so it sorts as text
+)
+
+const dbSchema = `
+CREATE TABLE IF NOT EXISTS profile (
+	id         INTEGER PRIMARY KEY CHECK (id = 1),
+	version    INTEGER NOT NULL,
+	high_score INTEGER NOT NULL,
+	mac        TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS achievements (
+	name TEXT PRIMARY KEY,
+	day  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS calibration (
+	id         INTEGER PRIMARY KEY CHECK (id = 1),
+	latency_ms INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS mod_bests (
+	mods  TEXT PRIMARY KEY,
+	score INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS stats (
+	kind   TEXT NOT NULL, -- runs, deaths, sessions, play_time, cause, speed, distance or met
+	bucket TEXT NOT NULL,
+	count  INTEGER NOT NULL,
+	PRIMARY KEY (kind, bucket)
+);
+CREATE TABLE IF NOT EXISTS runs (
+	id       INTEGER PRIMARY KEY,
+	distance INTEGER NOT NULL,
+	jumps    INTEGER NOT NULL,
+	bonus    INTEGER NOT NULL,
+	cause    TEXT NOT NULL,
+	speed    REAL NOT NULL,
+	mods     TEXT NOT NULL,
+	at       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS runs_at ON runs (at);
+CREATE TABLE IF NOT EXISTS sessions (
+	at       TEXT PRIMARY KEY,
+	runs     INTEGER NOT NULL,
+	best     INTEGER NOT NULL,
+	distance INTEGER NOT NULL,
+	played   INTEGER NOT NULL -- nanoseconds
+);
+CREATE TABLE IF NOT EXISTS replays (
+	name TEXT PRIMARY KEY, -- last, best, daily or yesterday
+	data BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS changes (
+	what TEXT PRIMARY KEY, -- profile or stats
+	at   TEXT NOT NULL
+);
+`
+
+func dbPath(cfg config) string {
+	if cfg.DB != "" {
+		return cfg.DB
+	}
+	return dataPath(dbFile)
+}
+
+// sqliteStore keeps the saves in a SQLite database
+type sqliteStore struct{ db *sql.DB }
+
+// openSQLite opens, and if need be creates, the database at path
+func openSQLite(path string) (*sqliteStore, error) {
+	_, statErr := os.Stat(path)
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(2000)&_pragma=journal_mode(wal)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(dbSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("database %s: %w", path, err)
+	}
+	s := &sqliteStore{db}
+	if errors.Is(statErr, os.ErrNotExist) {
+		s.importFiles()
+	}
+	return s, nil
+}
+
+// importFiles seeds a new database with the saves in the data directory
+func (s *sqliteStore) importFiles() {
+	var f fileStore
+	withSaveLock(func() {
+		if p, ok := f.readProfile(); ok {
+			_ = s.writeProfile(p)
+		}
+		if st := f.readStats(); st.Runs > 0 {
+			_ = s.writeStats(st)
+		}
+		for _, r := range f.readRuns() {
+			_ = s.addRun(r)
+		}
+		for _, name := range replayNames {
+			if data, ok := f.readReplay(name); ok {
+				_ = s.writeReplay(name, data)
+			}
+		}
+	})
+}
+
+// the database has its own locking, but a read-modify-write spans several
+// statements, so instances still take the data directory's lock
+func (s *sqliteStore) lock(fn func()) { withSaveLock(fn) }
+
+func (s *sqliteStore) readProfile() (profile, bool) {
+	p := profile{Version: profileVersion}
+	err := s.db.QueryRow(`SELECT version, high_score, mac FROM profile WHERE id = 1`).
+		Scan(&p.Version, &p.HighScore, &p.MAC)
+	if err != nil || p.HighScore < 0 {
+		return profile{Version: profileVersion}, false
+	}
+	if rows, err := s.db.Query(`SELECT name, day FROM achievements`); err == nil {
+		for rows.Next() {
+			var name, day string
+			if rows.Scan(&name, &day) == nil {
+				if p.Achievements == nil {
+					p.Achievements = map[string]string{}
+				}
+				p.Achievements[name] = day
+			}
+		}
+		rows.Close()
+	}
+	if rows, err := s.db.Query(`SELECT mods, score FROM mod_bests`); err == nil {
+		for rows.Next() {
+			var key string
+			var score int
+			if rows.Scan(&key, &score) == nil {
+				if p.ModBests == nil {
+					p.ModBests = map[string]int{}
+				}
+				p.ModBests[key] = score
+			}
+		}
+		rows.Close()
+	}
+	_ = s.db.QueryRow(`SELECT latency_ms FROM calibration WHERE id = 1`).Scan(&p.LatencyMS)
+	return p, true
+}
+
+func (s *sqliteStore) writeProfile(p profile) error {
+	return s.tx("profile", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO profile (id, version, high_score, mac) VALUES (1, ?, ?, ?)`,
+			p.Version, p.HighScore, p.MAC); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO calibration (id, latency_ms) VALUES (1, ?)`,
+			p.LatencyMS); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM achievements`); err != nil {
+			return err
+		}
+		for name, day := range p.Achievements {
+			if _, err := tx.Exec(`INSERT INTO achievements (name, day) VALUES (?, ?)`, name, day); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`DELETE FROM mod_bests`); err != nil {
+			return err
+		}
+		for key, score := range p.ModBests {
+			if _, err := tx.Exec(`INSERT INTO mod_bests (mods, score) VALUES (?, ?)`, key, score); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// a database is never in an older profile format
+func (s *sqliteStore) upgradeProfile(p profile, _ int) { _ = s.writeProfile(p) }
+
+func (s *sqliteStore) readStats() stats {
+	var st stats
+	rows, err := s.db.Query(`SELECT kind, bucket, count FROM stats`)
+	if err != nil {
+		return st
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind, bucket string
+		var n int
+		if rows.Scan(&kind, &bucket, &n) != nil {
+			continue
+		}
+		switch kind {
+		case "runs":
+			st.Runs = n
+		case "deaths":
+			st.Deaths = n
+		case "sessions":
+			st.Sessions = n
+		case "play_time":
+			st.PlayTime = time.Duration(n)
+		case "cause":
+			st.ByCause = addCount(st.ByCause, bucket, n)
+		case "speed":
+			st.BySpeed = addCount(st.BySpeed, bucket, n)
+		case "distance":
+			st.ByDistance = addCount(st.ByDistance, bucket, n)
+		case "met":
+			st.Met = addCount(st.Met, bucket, n)
+		}
+	}
+	st.Recent = s.readSessions()
+	return st
+}
+
+// readSessions is the stats' latest sessions, oldest first
+func (s *sqliteStore) readSessions() []sessionRecord {
+	rows, err := s.db.Query(`SELECT runs, best, distance, played, at FROM sessions ORDER BY at`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []sessionRecord
+	for rows.Next() {
+		var r sessionRecord
+		var at string
+		if rows.Scan(&r.Runs, &r.Best, &r.Distance, &r.Played, &at) != nil {
+			continue
+		}
+		r.At, _ = time.Parse(dbTime, at)
+		out = append(out, r)
+	}
+	return out
+}
+
+func addCount(m map[string]int, key string, n int) map[string]int {
+	if m == nil {
+		m = map[string]int{}
+	}
+	m[key] = n
+	return m
+}
+
+func (s *sqliteStore) writeStats(st stats) error {
+	return s.tx("stats", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM stats`); err != nil {
+			return err
+		}
+		put := func(kind, bucket string, n int) error {
+			_, err := tx.Exec(`INSERT INTO stats (kind, bucket, count) VALUES (?, ?, ?)`, kind, bucket, n)
+			return err
+		}
+		if err := put("runs", "", st.Runs); err != nil {
+			return err
+		}
+		if err := put("deaths", "", st.Deaths); err != nil {
+			return err
+		}
+		if err := put("sessions", "", st.Sessions); err != nil {
+			return err
+		}
+		if err := put("play_time", "", int(st.PlayTime)); err != nil {
+			return err
+		}
+		for kind, counts := range map[string]map[string]int{
+			"cause": st.ByCause, "speed": st.BySpeed, "distance": st.ByDistance, "met": st.Met,
+		} {
+			for bucket, n := range counts {
+				if err := put(kind, bucket, n); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := tx.Exec(`DELETE FROM sessions`); err != nil {
+			return err
+		}
+		for _, r := range st.Recent {
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO sessions (at, runs, best, distance, played)
+				VALUES (?, ?, ?, ?, ?)`, r.At.UTC().Format(dbTime), r.Runs, r.Best, r.Distance, int64(r.Played)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// readRuns is the latest maxHistory runs, oldest first
+func (s *sqliteStore) readRuns() []runRecord {
+	rows, err := s.db.Query(`SELECT distance, jumps, bonus, cause, speed, mods, at
+		FROM runs ORDER BY at DESC, id DESC LIMIT ?`, maxHistory)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var runs []runRecord
+	for rows.Next() {
+		var r runRecord
+		var mods, at string
+		if rows.Scan(&r.Distance, &r.Jumps, &r.Bonus, &r.Cause, &r.Speed, &mods, &at) != nil {
+			continue
+		}
+		if mods != "" {
+			r.Mods = strings.Split(mods, ",")
+		}
+		r.At, _ = time.Parse(dbTime, at)
+		runs = append(runs, r)
+	}
+	slices.Reverse(runs)
+	return runs
+}
+
+// addRun keeps every run; only readRuns stops at maxHistory
+func (s *sqliteStore) addRun(r runRecord) error {
+	_, err := s.db.Exec(`INSERT INTO runs (distance, jumps, bonus, cause, speed, mods, at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.Distance, r.Jumps, r.Bonus, r.Cause, r.Speed, strings.Join(r.Mods, ","),
+		r.At.UTC().Format(dbTime))
+	return err
+}
+
+func (s *sqliteStore) readReplay(name string) ([]byte, bool) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM replays WHERE name = ?`, name).Scan(&data)
+	return data, err == nil
+}
+
+func (s *sqliteStore) writeReplay(name string, data []byte) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO replays (name, data) VALUES (?, ?)`, name, data)
+	return err
+}
+
+func (s *sqliteStore) changed() saveWatch {
+	var w saveWatch
+	rows, err := s.db.Query(`SELECT what, at FROM changes`)
+	if err != nil {
+		return w
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var what, at string
+		if rows.Scan(&what, &at) != nil {
+			continue
+		}
+		t, _ := time.Parse(dbTime, at)
+		switch what {
+		case "profile":
+			w.profile = t
+		case "stats":
+			w.stats = t
+		}
+	}
+	return w
+}
+
+// integrity runs sqlite's own consistency check, returning "ok" or the
+// first thing it found wrong (see fsck.go)
+func (s *sqliteStore) integrity() string {
+	var res string
+	if err := s.db.QueryRow(`PRAGMA integrity_check`).Scan(&res); err != nil {
+		return err.Error()
+	}
+	return res
+}
+
+// tx runs fn in a transaction and notes that what changed, for other
+// instances watching the database
+func (s *sqliteStore) tx(what string, fn func(*sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO changes (what, at) VALUES (?, ?)`,
+		what, time.Now().UTC().Format(dbTime)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
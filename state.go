@@ -0,0 +1,227 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// STATE DUMPS (debugging & bug reports)
+// ----------------------------------------------------------------------------
+
+const noticeFor = 3 * time.Second // how long a HUD notice stays up
+
+// gameState is everything needed to put a run back exactly where it was:
+// unlike an autosave the spawner is restored draw for draw, so the course
+// ahead is the one the player was about to see
+type gameState struct {
+	Seed      int64           `json:"seed"`
+	Dist      int             `json:"dist"`
+	Jumps     int             `json:"jumps"`
+	Bonus     int             `json:"bonus,omitempty"`
+	Boost     int             `json:"boost,omitempty"`
+	Fox       int             `json:"fox"`
+	FoxCalm   int             `json:"fox_calm,omitempty"`
+	Ammo      int             `json:"ammo"`
+	Acorns    []acorn         `json:"acorns,omitempty"` // in flight
+	Height    int             `json:"height"`           // rows above the running line
+	VelY      int             `json:"vel_y"`
+	VelFx     int             `json:"vel_fx,omitempty"`
+	SubY      int             `json:"sub_y,omitempty"`
+	JumpBuf   int             `json:"jump_buf"`
+	Coyote    int             `json:"coyote"`
+	Clinging  bool            `json:"clinging,omitempty"`
+	Slide     int             `json:"slide,omitempty"`
+	AirJumps  int             `json:"air_jumps,omitempty"`
+	Hits      int             `json:"hits,omitempty"`
+	Points    int             `json:"points,omitempty"` // scored under the config's rules
+	Combo     int             `json:"combo,omitempty"`
+	Cleared   int             `json:"cleared,omitempty"`
+	Loop      int             `json:"loop,omitempty"` // prestige loops
+	Banked    int             `json:"banked,omitempty"`
+	Mods      []string        `json:"mods,omitempty"`
+	Perks     []string        `json:"perks,omitempty"`
+	Ledge     string          `json:"ledge,omitempty"`
+	FrameDur  time.Duration   `json:"frame_dur"`
+	Speed     float64         `json:"speed"` // FrameDur as a multiple of the start speed; informational
+	Rows      int             `json:"rows"`
+	Cols      int             `json:"cols"`
+	Obstacles []savedObstacle `json:"obstacles"`
+	Spawner   spawnerState    `json:"spawner"`
+	Director  *director       `json:"director,omitempty"`
+	GameOver  bool            `json:"game_over"`
+	Cause     string          `json:"cause,omitempty"`
+	Config    config          `json:"config"`
+	SavedAt   time.Time       `json:"saved_at"`
+}
+
+type spawnerState struct {
+	Seed  int64        `json:"seed"`
+	Draws int          `json:"draws"`
+	Next  int          `json:"next"`
+	Last  int          `json:"last"`
+	End   int          `json:"end,omitempty"`
+	Tight int          `json:"tight"`
+	Pace  pace         `json:"pace"`
+	Paces map[int]pace `json:"paces,omitempty"`
+}
+
+// MarshalJSON encodes the run in progress
+func (m model) MarshalJSON() ([]byte, error) {
+	st := gameState{
+		Seed:     m.seed,
+		Dist:     m.dist,
+		Jumps:    m.jumps,
+		Bonus:    m.bonus,
+		Boost:    m.boostLeft,
+		Fox:      m.fox,
+		FoxCalm:  m.foxCalm,
+		Ammo:     m.ammo,
+		Acorns:   m.acorns,
+		Height:   m.gameRows - 2 - m.playerY,
+		VelY:     m.velY,
+		VelFx:    m.velFx,
+		SubY:     m.subY,
+		JumpBuf:  m.jumpBuf,
+		Coyote:   m.coyote,
+		Clinging: m.clinging,
+		Slide:    m.slide,
+		AirJumps: m.airJumps,
+		Hits:     m.hits,
+		Points:   m.points,
+		Combo:    m.combo,
+		Cleared:  m.cleared,
+		Loop:     m.loop,
+		Banked:   m.banked,
+		Mods:     m.mods,
+		Perks:    m.perks,
+		Ledge:    m.ledge,
+		FrameDur: m.frameDur,
+		Speed:    speedFactor(m.frameDur),
+		Rows:     m.gameRows,
+		Cols:     m.gameCols,
+		Spawner: spawnerState{m.spawn.seed, m.spawn.draws, m.spawn.next, m.spawn.last, m.spawn.end, m.spawn.tight,
+			m.spawn.pace, m.spawn.paces},
+		Director: m.dir,
+		GameOver: m.gameOver,
+		Cause:    m.cause,
+		Config:   m.cfg.redacted(),
+		SavedAt:  m.now(),
+	}
+	for _, ob := range m.obstacles {
+		st.Obstacles = append(st.Obstacles, savedObstacle{ob.x, ob.kind.Name()})
+	}
+	return json.Marshal(st)
+}
+
+// UnmarshalJSON replaces the run in m with a decoded one; the rest of the
+// model (terminal size, history, stats) is left alone
+func (m *model) UnmarshalJSON(data []byte) error {
+	var st gameState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	if st.Rows < 2 || st.FrameDur <= 0 {
+		return errors.New("state has no playfield")
+	}
+	st.Config.SyncSecret, st.Config.GistToken = m.cfg.SyncSecret, m.cfg.GistToken // dumps leave them out
+	m.cfg = st.Config
+	m.seed = st.Seed
+	m.dist = st.Dist
+	m.jumps = st.Jumps
+	m.bonus, m.boostLeft = st.Bonus, st.Boost
+	m.fox, m.foxCalm = st.Fox, st.FoxCalm
+	m.ammo, m.acorns = st.Ammo, st.Acorns
+	if m.fox <= 0 && !st.GameOver {
+		m.fox = foxStart
+	}
+	// the next resize keeps the height above the ground
+	m.gameRows, m.gameCols = st.Rows, st.Cols
+	m.playerY = st.Rows - 2 - st.Height
+	m.velY, m.velFx, m.subY = st.VelY, st.VelFx, st.SubY
+	m.jumpBuf = st.JumpBuf
+	m.coyote = st.Coyote
+	m.clinging, m.slide = st.Clinging, st.Slide
+	m.airJumps, m.hits = st.AirJumps, st.Hits
+	m.rules = rulesByName(m.cfg.Scoring)
+	m.points, m.combo, m.cleared = st.Points, st.Combo, st.Cleared
+	m.loop, m.banked = st.Loop, st.Banked
+	m.ledge = st.Ledge
+	m.frameDur = st.FrameDur
+	m.gameOver = st.GameOver
+	m.cause = st.Cause
+	m.spawn = newSpawner(st.Spawner.Seed, st.Spawner.Next, 0)
+	m.spawn.skipDraws(st.Spawner.Draws)
+	m.spawn.last, m.spawn.tight = st.Spawner.Last, st.Spawner.Tight
+	m.spawn.end = max(st.Spawner.End, st.Spawner.Last)
+	m.spawn.pace, m.spawn.paces = st.Spawner.Pace, st.Spawner.Paces
+	m.dir = st.Director
+	m.mods = st.Mods
+	m.perks, m.perkOffer = st.Perks, nil
+	m.fitSpawner()
+	m.obstacles = nil
+	for _, ob := range st.Obstacles {
+		k, ok := kindByName(ob.Typ)
+		if !ok {
+			return fmt.Errorf("unknown obstacle kind %q", ob.Typ)
+		}
+		m.obstacles = append(m.obstacles, obstacle{ob.X, k})
+	}
+	return nil
+}
+
+// dumpState writes the current state next to the binary and says where
+func (m *model) dumpState() {
+	name := dataPath(fmt.Sprintf(".gopherdash_state-%s.json", time.Now().Format("20060102-150405")))
+	data, err := json.MarshalIndent(*m, "", "  ")
+	if err == nil {
+		err = os.WriteFile(name, data, 0o644)
+	}
+	if err != nil {
+		m.notify(fmt.Sprintf("State dump failed: %v", err))
+		return
+	}
+	m.notify("State saved to " + name)
+}
+
+// loadState starts the session from a dump written by dumpState or a crash
+// report; the run opens paused so there is time to see where it stands
+func (m *model) loadState(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// a crash report carries its state in a field of its own
+	var rep crashReport
+	if json.Unmarshal(data, &rep) == nil && rep.Panic != "" {
+		if len(rep.State) == 0 {
+			return fmt.Errorf("%s: the crash report has no game state", path)
+		}
+		data = rep.State
+	}
+	session := m.cfg
+	if err := json.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	// side services were started from this session's settings
+	m.cfg.Twitch, m.cfg.MetricsAddr = session.Twitch, session.MetricsAddr
+	m.offer, m.tape = nil, nil
+	m.paused, m.pauseWhy = true, "loaded "+path
+	return nil
+}
+
+// notify shows msg in the HUD for a few seconds
+func (m *model) notify(msg string) {
+	m.notice, m.noticeAt = msg, m.now()
+}
+
+// hudNotice is the current notice, if it hasn't expired
+func (m model) hudNotice() string {
+	if m.notice == "" || m.now().Sub(m.noticeAt) > noticeFor {
+		return ""
+	}
+	return m.notice
+}
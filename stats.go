@@ -0,0 +1,180 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// STATS
+// ----------------------------------------------------------------------------
+
+const (
+	statsFile   = ".gopherdash_stats"
+	distBucket  = 100 // distance bucket width for the death breakdown
+	distBuckets = 6   // last bucket is open-ended ("500+")
+)
+
+// speed brackets, as multiples of the starting speed
+var speedBrackets = []struct {
+	label string
+	upTo  float64
+}{
+	{"<1.25x", 1.25},
+	{"1.25-1.5x", 1.5},
+	{"1.5-2x", 2},
+	{"2x+", 0}, // open-ended
+}
+
+// stats aggregates every run ever played; unlike the history it is never
+// trimmed, so the breakdowns cover the player's whole career
+type stats struct {
+	Runs       int            `json:"runs"`
+	Deaths     int            `json:"deaths"`
+	ByCause    map[string]int `json:"by_cause"`
+	BySpeed    map[string]int `json:"by_speed"`
+	ByDistance map[string]int `json:"by_distance"`
+	Met        map[string]int `json:"met,omitempty"` // hazards and tiles met, by name (see dex.go)
+
+	// sessions, from launch to quit (see session.go)
+	Sessions int             `json:"sessions,omitempty"`
+	PlayTime time.Duration   `json:"play_time,omitempty"`
+	Recent   []sessionRecord `json:"recent_sessions,omitempty"` // the latest sessionKeep
+}
+
+func statsPath() string { return dataPath(statsFile) }
+
+func loadStats(s Store) stats {
+	st := s.readStats()
+	if st.ByCause == nil {
+		st.ByCause = map[string]int{}
+	}
+	if st.BySpeed == nil {
+		st.BySpeed = map[string]int{}
+	}
+	if st.ByDistance == nil {
+		st.ByDistance = map[string]int{}
+	}
+	return st
+}
+
+func saveStats(s Store, st stats) { _ = s.writeStats(st) }
+
+func (f fileStore) readStats() stats {
+	var st stats
+	if data, err := os.ReadFile(f.path(statsFile)); err == nil {
+		_ = json.Unmarshal(data, &st)
+	}
+	return st
+}
+
+func (f fileStore) writeStats(st stats) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path(statsFile), data, 0o644)
+}
+
+// speedFactor expresses a frame duration as a multiple of the starting speed
+func speedFactor(frame time.Duration) float64 {
+	if frame <= 0 {
+		return 1
+	}
+	return float64(startFrame) / float64(frame)
+}
+
+func speedBracket(speed float64) string {
+	for _, b := range speedBrackets {
+		if b.upTo == 0 || speed < b.upTo {
+			return b.label
+		}
+	}
+	return speedBrackets[len(speedBrackets)-1].label
+}
+
+func distanceBucket(dist int) string {
+	i := min(dist/distBucket, distBuckets-1)
+	if i == distBuckets-1 {
+		return fmt.Sprintf("%d+", i*distBucket)
+	}
+	return fmt.Sprintf("%d-%d", i*distBucket, (i+1)*distBucket-1)
+}
+
+// add records a finished run; quitting counts as a run but not a death
+func (st *stats) add(r runRecord) {
+	st.Runs++
+	if r.Cause == "quit" {
+		return
+	}
+	st.Deaths++
+	st.ByCause[r.Cause]++
+	st.BySpeed[speedBracket(r.Speed)]++
+	st.ByDistance[distanceBucket(r.Distance)]++
+}
+
+// ----------------------------------------------------------------------------
+// STATS SCREEN
+// ----------------------------------------------------------------------------
+
+// bar is one row of a horizontal bar chart
+type bar struct {
+	label string
+	n     int
+}
+
+// barChart renders rows as "label ████ n" scaled to fit width columns
+func barChart(rows []bar, width int) []string {
+	labelW, hi := 0, 0
+	for _, r := range rows {
+		labelW = max(labelW, len(r.label))
+		hi = max(hi, r.n)
+	}
+	barW := max(width-labelW-6, 1)
+	out := make([]string, len(rows))
+	for i, r := range rows {
+		n := 0
+		if hi > 0 {
+			n = r.n * barW / hi
+		}
+		out[i] = fmt.Sprintf("%-*s %s %d", labelW, r.label, strings.Repeat("█", n), r.n)
+	}
+	return out
+}
+
+// statsLines lays out the cause-of-death breakdown for a pane width wide
+func (st stats) statsLines(width int) []string {
+	lines := []string{fmt.Sprintf("Runs: %d   Deaths: %d", st.Runs, st.Deaths)}
+	if st.Sessions > 0 {
+		lines[0] += fmt.Sprintf("   Sessions: %d   Played: %s", st.Sessions, st.PlayTime.Round(time.Minute))
+	}
+	lines = append(lines, "")
+
+	causes := make([]bar, 0, len(st.ByCause))
+	for c, n := range st.ByCause {
+		causes = append(causes, bar{c, n})
+	}
+	sort.Slice(causes, func(i, j int) bool { return causes[i].n > causes[j].n })
+	lines = append(lines, "Killed by")
+	lines = append(lines, barChart(causes, width)...)
+
+	speeds := make([]bar, len(speedBrackets))
+	for i, b := range speedBrackets {
+		speeds[i] = bar{b.label, st.BySpeed[b.label]}
+	}
+	lines = append(lines, "", "Speed at death")
+	lines = append(lines, barChart(speeds, width)...)
+
+	dists := make([]bar, distBuckets)
+	for i := range dists {
+		label := distanceBucket(i * distBucket)
+		dists[i] = bar{label, st.ByDistance[label]}
+	}
+	lines = append(lines, "", "Distance at death")
+	lines = append(lines, barChart(dists, width)...)
+	return lines
+}
@@ -0,0 +1,199 @@
+package gopherdash
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// SAVE STORE (profile, stats and run history)
+// ----------------------------------------------------------------------------
+
+// The profile, with the high score, the lifetime stats, the run history and
+// the replays of the last and best runs are kept in a store chosen with
+// -store: "file", the files next to the binary, "sqlite", one database for
+// all of them (see sqlite.go), or "memory", which keeps them only for the
+// life of the process, for tests and hosted instances that mustn't touch
+// the disk. The smaller saves (splits, death heatmaps, weekly boards, the
+// streak, the autosave) are always files.
+
+// Store keeps the player's saves. Reads and writes are plain loads and
+// replacements; lock brackets a read-modify-write against other instances
+// sharing the store. Each game holds its own, so a host running several
+// can give each player theirs; FileStore, SQLiteStore and MemoryStore make
+// one.
+type Store interface {
+	lock(fn func())
+
+	// readProfile returns the profile as saved, possibly in an older
+	// format, or false if there's none or it can't be read
+	readProfile() (profile, bool)
+	writeProfile(p profile) error
+	// upgradeProfile replaces the saved profile, last written as version
+	// from, with its migration p
+	upgradeProfile(p profile, from int)
+
+	readStats() stats
+	writeStats(st stats) error
+	// readRuns is the latest maxHistory runs, oldest first
+	readRuns() []runRecord
+	addRun(r runRecord) error
+	// readReplay and writeReplay keep the tapes of the last and best runs,
+	// and the best daily runs, named in replayNames, as encoded by saveReplay
+	readReplay(name string) ([]byte, bool)
+	writeReplay(name string, data []byte) error
+
+	// changed reports when the profile and stats were last written
+	changed() saveWatch
+}
+
+// FileStore keeps the saves as files in the data directory, shared with
+// any other instance using it
+func FileStore() Store { return fileStore{} }
+
+// MemoryStore keeps the saves only for the life of the process
+func MemoryStore() Store { return &memoryStore{} }
+
+// SQLiteStore keeps the saves in the SQLite database at path, creating it
+// if need be
+func SQLiteStore(path string) (Store, error) {
+	s, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// openStore opens the store cfg asks for
+func openStore(cfg config) (Store, error) {
+	switch cfg.Store {
+	case "", "file":
+		return FileStore(), nil
+	case "memory":
+		return MemoryStore(), nil
+	case "sqlite":
+		return SQLiteStore(dbPath(cfg))
+	default:
+		return nil, fmt.Errorf("store %q: want file, sqlite or memory", cfg.Store)
+	}
+}
+
+// fileStore keeps each save in its own file in dir, or the data directory
+// if that's empty; its methods sit with the file formats in profile.go,
+// stats.go and history.go
+type fileStore struct{ dir string }
+
+// path places one of the store's files
+func (f fileStore) path(name string) string {
+	if f.dir == "" {
+		return dataPath(name)
+	}
+	return filepath.Join(f.dir, name)
+}
+
+func (f fileStore) lock(fn func()) { withLockFile(f.path(lockFile), fn) }
+
+// memoryStore keeps the saves for the life of the process. Everything
+// going in or out is copied, so models never share maps or slices.
+type memoryStore struct {
+	held sync.Mutex // taken by lock
+	mu   sync.Mutex // guards the fields below
+
+	profile *profile
+	stats   stats
+	runs    []runRecord
+	replays map[string][]byte
+	watch   saveWatch
+}
+
+func (s *memoryStore) lock(fn func()) {
+	s.held.Lock()
+	defer s.held.Unlock()
+	fn()
+}
+
+func (s *memoryStore) readProfile() (profile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.profile == nil {
+		return profile{Version: profileVersion}, false
+	}
+	return s.profile.clone(), true
+}
+
+func (s *memoryStore) writeProfile(p profile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p = p.clone()
+	s.profile, s.watch.profile = &p, time.Now()
+	return nil
+}
+
+func (s *memoryStore) upgradeProfile(p profile, _ int) { _ = s.writeProfile(p) }
+
+func (s *memoryStore) readStats() stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats.clone()
+}
+
+func (s *memoryStore) writeStats(st stats) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats, s.watch.stats = st.clone(), time.Now()
+	return nil
+}
+
+func (s *memoryStore) readRuns() []runRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.runs)
+}
+
+func (s *memoryStore) addRun(r runRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = trimHistory(append(s.runs, r))
+	return nil
+}
+
+func (s *memoryStore) readReplay(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.replays[name]
+	return slices.Clone(data), ok
+}
+
+func (s *memoryStore) writeReplay(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.replays == nil {
+		s.replays = map[string][]byte{}
+	}
+	s.replays[name] = slices.Clone(data)
+	return nil
+}
+
+func (s *memoryStore) changed() saveWatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watch
+}
+
+// clone copies p's maps
+func (p profile) clone() profile {
+	p.Achievements, p.ModBests = maps.Clone(p.Achievements), maps.Clone(p.ModBests)
+	return p
+}
+
+// clone copies st's maps
+func (st stats) clone() stats {
+	st.ByCause, st.BySpeed, st.ByDistance = maps.Clone(st.ByCause), maps.Clone(st.BySpeed),
+		maps.Clone(st.ByDistance)
+	st.Met, st.Recent = maps.Clone(st.Met), slices.Clone(st.Recent)
+	return st
+}
@@ -0,0 +1,151 @@
+package gopherdash
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sqliteAt opens the database at path as the store, closing it when the
+// test ends
+func sqliteAt(t *testing.T, path string) *sqliteStore {
+	s, err := openSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+	return s
+}
+
+// someRuns are n runs a minute apart, every other one modified
+func someRuns(n int) []runRecord {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	runs := make([]runRecord, n)
+	for i := range runs {
+		runs[i] = runRecord{Distance: 10 * i, Jumps: i, Bonus: i % 3, Cause: "cactus",
+			Speed: 1.25, At: start.Add(time.Duration(i) * time.Minute)}
+		if i%2 == 1 {
+			runs[i].Mods = []string{"fog", "ice"}
+		}
+	}
+	return runs
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	p := profile{Version: profileVersion, HighScore: 321, MAC: "abc",
+		Achievements: map[string]string{"Halloween": "2026-10-31"},
+		ModBests:     map[string]int{"fog+ice": 200}}
+	st := stats{Runs: 12, Deaths: 11,
+		ByCause:    map[string]int{"cactus": 8, "bird": 3},
+		BySpeed:    map[string]int{"1.0x": 11},
+		ByDistance: map[string]int{"0-99": 4, "100-199": 7},
+		Sessions:   3, PlayTime: 95 * time.Minute,
+		Recent: []sessionRecord{
+			{Runs: 5, Best: 240, Distance: 800, Played: 20 * time.Minute, At: time.Date(2026, 5, 1, 13, 0, 0, 0, time.UTC)},
+			{Runs: 7, Best: 310, Distance: 1400, Played: 75 * time.Minute, At: time.Date(2026, 5, 2, 9, 30, 0, 0, time.UTC)},
+		}}
+	runs := someRuns(maxHistory + 5)
+	tape := []byte(`{"format": 1, "seed": 5}`)
+
+	for _, tc := range []struct {
+		name string
+		open func(t *testing.T) Store
+	}{
+		{"file", func(*testing.T) Store { return fileStore{} }},
+		{"memory", func(*testing.T) Store { return &memoryStore{} }},
+		{"sqlite", func(t *testing.T) Store { return sqliteAt(t, filepath.Join(dataDir, "saves.db")) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			isolateSaves(t)
+			s := tc.open(t)
+			if _, ok := s.readProfile(); ok {
+				t.Error("a new store has a profile")
+			}
+			if _, ok := s.readReplay(replayBest); ok {
+				t.Error("a new store has a replay")
+			}
+			if err := s.writeProfile(p); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.writeStats(st); err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range runs {
+				if err := s.addRun(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.writeReplay(replayBest, tape); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, ok := s.readProfile(); !ok || !reflect.DeepEqual(got, p) {
+				t.Errorf("profile %+v, want %+v", got, p)
+			}
+			if got := s.readStats(); !reflect.DeepEqual(got, st) {
+				t.Errorf("stats %+v, want %+v", got, st)
+			}
+			if got, want := s.readRuns(), runs[len(runs)-maxHistory:]; !reflect.DeepEqual(got, want) {
+				t.Errorf("read %d runs from %v, want the latest %d from %v",
+					len(got), got[0].At, len(want), want[0].At)
+			}
+			if got, _ := s.readReplay(replayBest); string(got) != string(tape) {
+				t.Errorf("best replay %s", got)
+			}
+			if _, ok := s.readReplay(replayLast); ok {
+				t.Error("found a last replay that was never written")
+			}
+			if w := s.changed(); w.profile.IsZero() || w.stats.IsZero() {
+				t.Errorf("no change noted: %+v", w)
+			}
+		})
+	}
+}
+
+func TestSQLiteKeepsEveryRun(t *testing.T) {
+	isolateSaves(t)
+	path := filepath.Join(dataDir, "saves.db")
+	s := sqliteAt(t, path)
+	for _, r := range someRuns(maxHistory + 5) {
+		_ = s.addRun(r)
+	}
+	_ = s.writeProfile(profile{Version: profileVersion, HighScore: 77})
+	s.db.Close()
+
+	s = sqliteAt(t, path) // reopened, not seeded again
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM runs`).Scan(&n); err != nil || n != maxHistory+5 {
+		t.Errorf("the database holds %d runs (%v), want %d", n, err, maxHistory+5)
+	}
+	if p, _ := s.readProfile(); p.HighScore != 77 {
+		t.Errorf("reopened with best %d", p.HighScore)
+	}
+}
+
+func TestSQLiteStartsFromFiles(t *testing.T) {
+	isolateSaves(t)
+	var f fileStore
+	p := profile{Version: profileVersion, HighScore: 55, ModBests: map[string]int{"fog": 30}}
+	_ = f.writeProfile(p)
+	_ = f.writeStats(stats{Runs: 3, Deaths: 3, ByCause: map[string]int{"bird": 3}})
+	runs := someRuns(3)
+	for _, r := range runs {
+		_ = f.addRun(r)
+	}
+	_ = f.writeReplay(replayLast, []byte("tape"))
+
+	s := sqliteAt(t, filepath.Join(dataDir, "saves.db"))
+	if got, _ := s.readProfile(); !reflect.DeepEqual(got, p) {
+		t.Errorf("profile %+v, want %+v", got, p)
+	}
+	if got := s.readStats(); got.Runs != 3 || got.ByCause["bird"] != 3 {
+		t.Errorf("stats %+v", got)
+	}
+	if got := s.readRuns(); !reflect.DeepEqual(got, runs) {
+		t.Errorf("runs %+v, want %+v", got, runs)
+	}
+	if got, _ := s.readReplay(replayLast); string(got) != "tape" {
+		t.Errorf("last replay %q", got)
+	}
+}
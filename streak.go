@@ -0,0 +1,103 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// STREAK MODE (hardcore)
+// ----------------------------------------------------------------------------
+
+// With -streak every run has to get past a target distance. Beating it adds
+// one to the streak and raises the target; falling short, or quitting a run
+// part-way, ends the streak and the target starts over. The first target is
+// the distance your runs typically reach, taken from the lifetime stats.
+const (
+	streakFile  = ".gopherdash_streak"
+	streakFloor = 100 // lowest target, for new players
+	streakRise  = 10  // percent the target grows with each run in the streak
+	streakRound = 10  // targets are multiples of this
+)
+
+// streak is persisted in streakFile
+type streak struct {
+	Length int       `json:"length"` // runs beaten in a row
+	Best   int       `json:"best"`   // longest streak so far
+	Target int       `json:"target"` // distance the next run has to beat
+	At     time.Time `json:"at,omitempty"`
+}
+
+func streakPath() string { return dataPath(streakFile) }
+
+func loadStreak() streak {
+	var s streak
+	if data, err := os.ReadFile(streakPath()); err == nil {
+		_ = json.Unmarshal(data, &s)
+	}
+	return s
+}
+
+// typicalDistance is the median distance runs end at, read off the stats'
+// distance buckets: the midpoint of the bucket the middle death falls in
+func (st stats) typicalDistance() int {
+	seen := 0
+	for i := range distBuckets {
+		seen += st.ByDistance[distanceBucket(i*distBucket)]
+		if seen*2 > st.Deaths {
+			return i*distBucket + distBucket/2
+		}
+	}
+	return 0
+}
+
+// streakTarget is the distance to beat with length runs already in the
+// streak
+func streakTarget(st stats, length int) int {
+	base := max(st.typicalDistance(), streakFloor)
+	t := base * (100 + streakRise*length) / 100
+	return (t + streakRound - 1) / streakRound * streakRound
+}
+
+// recordStreak settles the streak at the end of a run and reports whether
+// the run beat its target
+func (m *model) recordStreak(at time.Time) (beat bool) {
+	withSaveLock(func() {
+		s := loadStreak()
+		if s.Target == 0 {
+			s.Target = m.streak.Target // the target this run was shown
+		}
+		beat = m.dist > s.Target
+		if beat {
+			s.Length++
+			s.Best = max(s.Best, s.Length)
+		} else {
+			s.Length = 0
+		}
+		s.Target, s.At = streakTarget(m.stats, s.Length), at
+		m.streak = s
+		if data, err := json.MarshalIndent(s, "", "  "); err == nil {
+			_ = os.WriteFile(streakPath(), data, 0o644)
+		}
+	})
+	m.logInfo("streak", "beat", beat, "length", m.streak.Length, "target", m.streak.Target)
+	return beat
+}
+
+// streakHUD shows the streak and how far the current run is off its target
+func (m model) streakHUD() string {
+	if m.dist > m.streak.Target {
+		return fmt.Sprintf("Streak %d ✓", m.streak.Length)
+	}
+	return fmt.Sprintf("Streak %d · beat %d", m.streak.Length, m.streak.Target)
+}
+
+// streakLine is the game-over line for a streak run
+func (m model) streakLine() string {
+	if m.streakBeat {
+		return fmt.Sprintf("Streak %d! Next target %d", m.streak.Length, m.streak.Target)
+	}
+	return fmt.Sprintf("Streak over (best %d); the target is back to %d", m.streak.Best, m.streak.Target)
+}
@@ -0,0 +1,226 @@
+package gopherdash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// SHARED SAVES (several instances, one data directory)
+// ----------------------------------------------------------------------------
+
+// Any number of instances (tmux panes, say) can share the save files next
+// to the binary. Writes are read-modify-write under a lock file, so runs
+// finished in one pane are added to what the others wrote rather than
+// replacing it. Each instance also polls the files and picks up a better
+// high score set elsewhere. Both the polling and the logging of finished
+// runs happen in commands, so Update never waits on the disk or the lock.
+
+const (
+	lockFile   = ".gopherdash_lock"
+	lockStale  = 5 * time.Second // a lock this old with no PID in it was left by a crash
+	lockGiveUp = time.Second     // write unlocked rather than lose a save
+	lockPoll   = 10 * time.Millisecond
+	watchEvery = time.Second
+)
+
+// watchMsg asks the model to look for changes made by other instances
+type watchMsg struct{}
+
+// sharedMsg is what readShared found changed; nil fields are unchanged
+type sharedMsg struct {
+	watch   saveWatch
+	logged  int // the model's logged count when the read began
+	profile *profile
+	stats   *stats
+	history []runRecord
+}
+
+// loggedMsg is the history and stats once a run has been written on top
+// of what other instances saved
+type loggedMsg struct {
+	logged  int
+	stats   stats
+	history []runRecord
+}
+
+// saveWatch remembers when the shared files last changed
+type saveWatch struct {
+	profile, stats time.Time
+}
+
+func lockPath() string { return dataPath(lockFile) }
+
+// withSaveLock runs fn while holding the data directory's lock file
+func withSaveLock(fn func()) { withLockFile(lockPath(), fn) }
+
+// withLockFile runs fn while holding the lock file at path. A lock whose
+// holder has died is taken over; after lockGiveUp fn runs anyway.
+func withLockFile(path string, fn func()) {
+	deadline := time.Now().Add(lockGiveUp)
+	for time.Now().Before(deadline) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			defer os.Remove(path)
+			break
+		}
+		if !os.IsExist(err) {
+			break // no lock to be had here (a read-only directory, say)
+		}
+		if lockAbandoned(path) {
+			_ = os.Remove(path)
+			continue
+		}
+		time.Sleep(lockPoll)
+	}
+	fn()
+}
+
+// lockAbandoned reports whether the lock file at path was left by an
+// instance that is no longer running
+func lockAbandoned(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false // released meanwhile
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		// the holder is between creating the file and writing its PID, or
+		// died there
+		st, err := os.Stat(path)
+		return err == nil && time.Since(st.ModTime()) > lockStale
+	}
+	return !processAlive(pid)
+}
+
+// processAlive reports whether a process with the given PID is running
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false // on Windows the lookup itself fails for a dead process
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM) // EPERM: another user's
+}
+
+func modTime(path string) time.Time {
+	st, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return st.ModTime()
+}
+
+func (f fileStore) changed() saveWatch {
+	return saveWatch{modTime(f.path(profileFile)), modTime(f.path(statsFile))}
+}
+
+func (m model) watchTick() tea.Cmd {
+	return m.after(watchEvery, func(time.Time) tea.Msg { return watchMsg{} })
+}
+
+// readShared reads whatever another instance has changed in s since seen
+func readShared(s Store, seen saveWatch) sharedMsg {
+	msg := sharedMsg{watch: s.changed()}
+	if !msg.watch.profile.Equal(seen.profile) {
+		p := loadProfile(s)
+		msg.profile = &p
+	}
+	if !msg.watch.stats.Equal(seen.stats) {
+		st := loadStats(s)
+		msg.stats, msg.history = &st, loadHistory(s)
+	}
+	return msg
+}
+
+// watchShared reads the shared saves off the Update goroutine
+func (m model) watchShared() tea.Cmd {
+	s, seen, logged := m.saves, m.watch, m.logged
+	return func() tea.Msg {
+		msg := readShared(s, seen)
+		msg.logged = logged
+		return msg
+	}
+}
+
+// applyShared takes in what another instance changed. Stats read before
+// this instance logged its latest run would lose that run, so they wait
+// for the next look.
+func (m *model) applyShared(msg sharedMsg) {
+	if p := msg.profile; p != nil && p.HighScore > m.profile.HighScore {
+		m.profile, m.verified = *p, p.verified()
+		m.notify(fmt.Sprintf("New best of %d set in another window", p.HighScore))
+		m.logInfo("best updated elsewhere", "best", p.HighScore)
+	}
+	if msg.stats != nil && msg.logged != m.logged {
+		msg.watch.stats = m.watch.stats
+	} else if msg.stats != nil {
+		m.stats, m.history = *msg.stats, msg.history
+	}
+	m.watch = msg.watch
+}
+
+// refreshShared reloads whatever another instance has changed since the
+// last look, there and then
+func (m *model) refreshShared() {
+	msg := readShared(m.saves, m.watch)
+	msg.logged = m.logged
+	m.applyShared(msg)
+}
+
+// logRun adds a finished run to the history and the lifetime stats. The
+// model has it straight away; writing it on top of whatever other instances
+// have added is a command, queued for flushSaves.
+func (m *model) logRun(r runRecord) {
+	m.logged++
+	m.stats = m.stats.clone()
+	m.stats.add(r)
+	m.stats.addMet(m.met)
+	m.history = trimHistory(append(slices.Clone(m.history), r))
+	s, logged, met := m.saves, m.logged, m.met
+	m.met = nil
+	m.unsaved = append(m.unsaved, func() tea.Msg {
+		msg := loggedMsg{logged: logged}
+		s.lock(func() {
+			msg.history = appendRun(s, r)
+			msg.stats = loadStats(s)
+			msg.stats.add(r)
+			msg.stats.addMet(met)
+			saveStats(s, msg.stats)
+		})
+		return msg
+	})
+}
+
+// applyLogged takes in the history and stats a logged run was written into,
+// unless the model has logged another since
+func (m *model) applyLogged(msg loggedMsg) {
+	if msg.logged == m.logged {
+		m.stats, m.history = msg.stats, msg.history
+	}
+}
+
+// flushSaves runs the writes logRun queued ahead of cmd, in order, so a
+// quit that follows them can't cut them short
+func (m model) flushSaves(cmd tea.Cmd) (model, tea.Cmd) {
+	if len(m.unsaved) == 0 {
+		return m, cmd
+	}
+	cmds := append(m.unsaved, cmd)
+	m.unsaved = nil
+	return m, tea.Sequence(cmds...)
+}
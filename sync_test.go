@@ -0,0 +1,95 @@
+package gopherdash
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestSaveLock only takes over a lock whose holder is gone, and gives up
+// waiting on a live one after lockGiveUp
+func TestSaveLock(t *testing.T) {
+	gone := exec.Command(os.Args[0], "-test.run=^$")
+	if err := gone.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name   string
+		holder string // the lock file's contents
+		age    time.Duration
+		waits  bool
+	}{
+		{"held by a running instance", strconv.Itoa(os.Getpid()) + "\n", 0, true},
+		{"held by a running instance for ages", strconv.Itoa(os.Getpid()) + "\n", time.Hour, true},
+		{"left by an instance that exited", strconv.Itoa(gone.Process.Pid) + "\n", 0, false},
+		{"no PID yet", "", 0, true},
+		{"no PID, long abandoned", "", time.Hour, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			isolateSaves(t)
+			if err := os.WriteFile(lockPath(), []byte(tc.holder), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			then := time.Now().Add(-tc.age)
+			_ = os.Chtimes(lockPath(), then, then)
+			start := time.Now()
+			ran := false
+			withSaveLock(func() { ran = true })
+			if !ran {
+				t.Fatal("fn never ran")
+			}
+			if waited := time.Since(start) >= lockGiveUp; waited != tc.waits {
+				t.Errorf("waited out the lock: %v, want %v", waited, tc.waits)
+			}
+			_, err := os.Stat(lockPath())
+			if held := err == nil; held != tc.waits {
+				t.Errorf("lock file there afterwards: %v, want %v", held, tc.waits)
+			}
+		})
+	}
+}
+
+// TestLogRunDeferred keeps the disk out of Update: a finished run is in the
+// model at once and written by the command it queues, and reads begun
+// before a later run can't take that run away again
+func TestLogRunDeferred(t *testing.T) {
+	m, _ := clockedModel(t, defaultConfig())
+	look := m.watchShared()
+	m.dist = 50
+	m.setGameOver("rock")
+	if m.stats.Runs != 1 || len(m.history) != 1 {
+		t.Fatalf("model has %d runs, %d in the history", m.stats.Runs, len(m.history))
+	}
+	if loadStats(m.saves).Runs != 0 || len(m.unsaved) != 1 {
+		t.Fatalf("written already, or not queued (%d queued)", len(m.unsaved))
+	}
+	write := m.unsaved[0]
+
+	next, cmd := m.Update(look())
+	m = next.(model)
+	if cmd == nil || len(m.unsaved) != 0 {
+		t.Fatalf("the queued write wasn't handed to Bubble Tea (%d left)", len(m.unsaved))
+	}
+	if m.stats.Runs != 1 {
+		t.Errorf("a look from before the run left %d runs", m.stats.Runs)
+	}
+
+	written := write()
+	if st := loadStats(m.saves); st.Runs != 1 || st.ByCause["rock"] != 1 || len(loadHistory(m.saves)) != 1 {
+		t.Fatalf("saved stats %+v", st)
+	}
+	m.restart()
+	m.dist = 20
+	m.setGameOver("log")
+	write = m.unsaved[0]
+	next, _ = m.Update(written)
+	if m = next.(model); m.stats.Runs != 2 || len(m.history) != 2 {
+		t.Errorf("the first run's write, landing after the second run, left %d runs", m.stats.Runs)
+	}
+	next, _ = m.Update(write())
+	if m = next.(model); m.stats.Runs != 2 || m.stats.ByCause["log"] != 1 || len(loadHistory(m.saves)) != 2 {
+		t.Errorf("after both writes: %d runs, %+v", m.stats.Runs, m.stats.ByCause)
+	}
+}
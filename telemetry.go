@@ -0,0 +1,224 @@
+package gopherdash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// TELEMETRY (`gopherdash telemetry [preview|send|clear]`, opt-in)
+// ----------------------------------------------------------------------------
+
+// Nothing is collected unless the player opts in with telemetry set in the
+// config file (or -telemetry). Then the game keeps a batch of totals in
+// .gopherdash_telemetry: runs, how far they went and what ended them,
+// sessions and the terminal each was played in, and crashes. There's no
+// install ID, seed or time of day in it, only the day the batch started,
+// and it never leaves the machine on its own: `gopherdash telemetry send`
+// shows the exact payload, asks before posting it to telemetry_url (or
+// -url), and starts a new batch once it's been taken. `preview` shows the
+// payload without sending and `clear` throws the batch away.
+
+const (
+	telemetryFile    = ".gopherdash_telemetry"
+	telemetryFormat  = 1
+	telemetryTimeout = 10 * time.Second
+)
+
+// telemetryBatch is what's been counted since the last send
+type telemetryBatch struct {
+	Since     string         `json:"since,omitempty"` // the day counting started, e.g. "2026-03-01"
+	Sessions  int            `json:"sessions"`
+	Runs      int            `json:"runs"`
+	Distance  int            `json:"distance"` // over every run
+	Longest   int            `json:"longest"`
+	ByCause   map[string]int `json:"by_cause,omitempty"`
+	Terminals map[string]int `json:"terminals,omitempty"` // sessions by TERM_PROGRAM, or TERM
+	Crashes   int            `json:"crashes"`
+}
+
+// telemetryPayload is what's sent: the batch and the build it came from
+type telemetryPayload struct {
+	Format  int    `json:"format"`
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	telemetryBatch
+}
+
+func telemetryPath() string { return dataPath(telemetryFile) }
+
+func loadTelemetry() telemetryBatch {
+	var b telemetryBatch
+	if data, err := os.ReadFile(telemetryPath()); err == nil {
+		_ = json.Unmarshal(data, &b)
+	}
+	return b
+}
+
+func saveTelemetry(b telemetryBatch) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(telemetryPath(), data, 0o644)
+}
+
+// empty reports whether nothing's been counted
+func (b telemetryBatch) empty() bool { return b.Sessions == 0 && b.Runs == 0 && b.Crashes == 0 }
+
+// tally adds to the batch on disk with fn, if the player has opted in
+func tally(cfg config, now time.Time, fn func(b *telemetryBatch)) {
+	if !cfg.Telemetry || cfg.Store == "memory" {
+		return
+	}
+	withSaveLock(func() {
+		b := loadTelemetry()
+		if b.Since == "" {
+			b.Since = now.Format(time.DateOnly)
+		}
+		fn(&b)
+		saveTelemetry(b)
+	})
+}
+
+// tallySession counts a session and the terminal it's in
+func tallySession(cfg config) {
+	tally(cfg, time.Now(), func(b *telemetryBatch) {
+		b.Sessions++
+		if b.Terminals == nil {
+			b.Terminals = map[string]int{}
+		}
+		b.Terminals[terminalName()]++
+	})
+}
+
+// tallyRun counts the run that just ended of cause
+func (m model) tallyRun(cause string) {
+	tally(m.cfg, m.now(), func(b *telemetryBatch) {
+		b.Runs++
+		b.Distance += m.dist
+		b.Longest = max(b.Longest, m.dist)
+		if b.ByCause == nil {
+			b.ByCause = map[string]int{}
+		}
+		b.ByCause[cause]++
+	})
+}
+
+// tallyCrash counts a recovered panic
+func (m model) tallyCrash() {
+	tally(m.cfg, time.Now(), func(b *telemetryBatch) { b.Crashes++ })
+}
+
+// terminalName is the terminal the game's in, as far as the environment
+// says, with nothing that could tell one machine from another
+func terminalName() string {
+	for _, env := range []string{"TERM_PROGRAM", "TERM"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return "unknown"
+}
+
+// payload is b as it would be sent
+func (b telemetryBatch) payload() ([]byte, error) {
+	return json.MarshalIndent(telemetryPayload{
+		Format:         telemetryFormat,
+		Version:        buildVersion(),
+		OS:             runtime.GOOS + "/" + runtime.GOARCH,
+		telemetryBatch: b,
+	}, "", "  ")
+}
+
+// sendTelemetry posts a payload to url
+func sendTelemetry(url string, payload []byte) error {
+	client := &http.Client{Timeout: telemetryTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("POST %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// telemetryMain runs `gopherdash telemetry [preview|send|clear]`
+func telemetryMain(args []string) int {
+	return telemetryCommand(args, os.Stdin, os.Stdout)
+}
+
+// telemetryCommand is telemetryMain reading answers from in and writing to out
+func telemetryCommand(args []string, in io.Reader, out io.Writer) int {
+	cfg := loadConfig()
+	action := "status"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("gopherdash telemetry", flag.ContinueOnError)
+	yes := fs.Bool("y", false, "send without asking first")
+	fs.StringVar(&cfg.TelemetryURL, "url", cfg.TelemetryURL, "where send posts the payload")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	b := loadTelemetry()
+	payload, err := b.payload()
+	if err != nil {
+		return exitCode(err)
+	}
+	switch action {
+	case "status":
+		state := "off: nothing is collected (set telemetry in the config file to opt in)"
+		if cfg.Telemetry {
+			state = "on: counted locally, sent only with `gopherdash telemetry send`"
+		}
+		fmt.Fprintln(out, "Telemetry is", state)
+		fmt.Fprintf(out, "Batch in %s: %d sessions, %d runs, %d crashes\n", telemetryPath(), b.Sessions, b.Runs, b.Crashes)
+	case "preview":
+		fmt.Fprintf(out, "%s\n", payload)
+	case "send":
+		if b.empty() {
+			fmt.Fprintln(out, "Nothing to send.")
+			return 0
+		}
+		if cfg.TelemetryURL == "" {
+			fmt.Fprintln(out, "telemetry: nowhere to send it; set telemetry_url in the config file or pass -url")
+			return exitUsage
+		}
+		fmt.Fprintf(out, "%s\n", payload)
+		if !*yes {
+			fmt.Fprintf(out, "Send this to %s? [y/N] ", cfg.TelemetryURL)
+			answer, _ := bufio.NewReader(in).ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				fmt.Fprintln(out, "Not sent.")
+				return 0
+			}
+		}
+		if err := sendTelemetry(cfg.TelemetryURL, payload); err != nil {
+			return exitCode(err)
+		}
+		withSaveLock(func() { _ = os.Remove(telemetryPath()) })
+		fmt.Fprintln(out, "Sent, thank you! Counting starts over.")
+	case "clear":
+		if err := os.Remove(telemetryPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return exitCode(err)
+		}
+		fmt.Fprintln(out, "Batch cleared.")
+	default:
+		fmt.Fprintf(out, "telemetry: unknown action %q (want preview, send or clear)\n", action)
+		return exitUsage
+	}
+	return 0
+}
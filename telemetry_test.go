@@ -0,0 +1,66 @@
+package gopherdash
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTelemetryOptIn(t *testing.T) {
+	isolateSaves(t)
+	cfg := defaultConfig()
+	tallySession(cfg)
+	if _, err := os.Stat(telemetryPath()); !os.IsNotExist(err) {
+		t.Fatal("counted without opting in")
+	}
+
+	cfg.Telemetry = true
+	t.Setenv("TERM_PROGRAM", "WezTerm")
+	tallySession(cfg)
+	m := model{cfg: cfg, dist: 240}
+	m.tallyRun("rock")
+	m.dist = 90
+	m.tallyRun("hole")
+	m.tallyCrash()
+	b := loadTelemetry()
+	if b.Sessions != 1 || b.Runs != 2 || b.Distance != 330 || b.Longest != 240 || b.Crashes != 1 {
+		t.Errorf("batch %+v", b)
+	}
+	if b.ByCause["rock"] != 1 || b.Terminals["WezTerm"] != 1 || b.Since == "" {
+		t.Errorf("batch %+v", b)
+	}
+}
+
+// send shows the payload, posts nothing unless told to, and starts a new
+// batch once it's been taken.
+func TestTelemetrySend(t *testing.T) {
+	isolateSaves(t)
+	var got []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	saveTelemetry(telemetryBatch{Sessions: 2, Runs: 5, ByCause: map[string]int{"rock": 5}})
+	payload, _ := loadTelemetry().payload()
+
+	var out bytes.Buffer
+	if code := telemetryCommand([]string{"send", "-url", srv.URL}, strings.NewReader("n\n"), &out); code != 0 || got != nil {
+		t.Fatalf("declined: exit %d, posted %s", code, got)
+	}
+	if !strings.Contains(out.String(), string(payload)) {
+		t.Errorf("no preview before asking:\n%s", out.String())
+	}
+	if code := telemetryCommand([]string{"send", "-url", srv.URL}, strings.NewReader("y\n"), &out); code != 0 {
+		t.Fatalf("accepted: exit %d\n%s", code, out.String())
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("posted %s, previewed %s", got, payload)
+	}
+	if !loadTelemetry().empty() {
+		t.Error("the batch was kept after sending")
+	}
+}
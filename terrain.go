@@ -0,0 +1,148 @@
+package gopherdash
+
+import "time"
+
+// ----------------------------------------------------------------------------
+// TERRAIN (slopes & platforms)
+// ----------------------------------------------------------------------------
+
+const (
+	terrainSegment = 40 // world cells per terrain feature
+	rampCells      = 2  // a slope climbs one row every rampCells cells
+	maxStep        = 1  // the gopher walks up a rise this high; more is a wall
+	platformRise   = 2  // platforms have to be jumped onto
+	wallHeight     = 8  // too tall to jump; climbed by wall-jumping
+	clingSlide     = 3  // ticks per row a clinging gopher slides down
+	wallChar       = "🧱"
+	wallCause      = "wall"
+)
+
+// terrainAt is the ground height of world cell x in rows above the base
+// running line. Like the obstacle stream it depends only on the run's seed,
+// so saves, state dumps and lockstep races need nothing extra to agree on
+// it. The world is cut into terrainSegment-cell stretches and each one is
+// flat, a hill of gentle slopes, a raised platform or, now and then, a wall
+// in otherwise flat ground.
+func (m model) terrainAt(x int) int {
+	start := playerCol + 1 + m.cfg.GraceCells
+	if !m.cfg.Terrain || x < start {
+		return 0
+	}
+	seg, off := x/terrainSegment, x%terrainSegment
+	if seg*terrainSegment < start {
+		return 0 // the grace stretch stays flat
+	}
+	h := terrainHash(m.seed, seg)
+	switch h % 4 {
+	case 0, 1:
+		if (h>>12)%3 == 0 && off >= 18 && off < 21 {
+			return wallHeight
+		}
+	case 2: // hill: up, a plateau of 2 or 3 rows, back down
+		if off < 8 || off > 31 {
+			return 0
+		}
+		return min((off-8)/rampCells+1, (31-off)/rampCells+1, 2+int(h>>8)%2)
+	case 3: // platform with sheer sides
+		if off >= 12 && off < 28 {
+			return platformRise
+		}
+	}
+	return 0
+}
+
+// terrainHash mixes a seed and a segment index (splitmix64)
+func terrainHash(seed int64, seg int) uint64 {
+	z := uint64(seed) + uint64(seg+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// groundUnder is the terrain height under the gopher
+func (m model) groundUnder() int {
+	return m.terrainAt(m.camera().toWorld(playerCol))
+}
+
+// groundRow is the row the gopher stands on at its column
+func (m model) groundRow() int { return m.gameRows - 2 - m.groundUnder() }
+
+// buildable reports whether the stream may put a hazard on world cells c:
+// level ground, with no rise in the next jump's reach that the hazard's own
+// jump would have to clear as well, and no drop just behind it that the
+// gopher could still be falling from
+func (m model) buildable(c span) bool {
+	if !m.cfg.Terrain {
+		return true
+	}
+	h := m.terrainAt(c.lo)
+	for x := c.lo - 1; x <= c.hi+1; x++ {
+		if m.terrainAt(x) != h {
+			return false
+		}
+	}
+	for d := 1; d <= m.spawn.air+1; d++ {
+		if m.terrainAt(c.hi+d) > h || m.terrainAt(c.lo-d) > h+maxStep {
+			return false
+		}
+	}
+	return true
+}
+
+// meetTerrain settles the gopher on the ground after physics. alt0 is its
+// height above the base line before the step; running into a rise it can't
+// step up ends the run.
+func (m *model) meetTerrain(alt0 int) {
+	g := m.groundUnder()
+	row := m.gameRows - 2 - g
+	if m.playerY < row {
+		return // in the air
+	}
+	if alt := m.gameRows - 2 - m.playerY; alt < g && g-alt0 > maxStep {
+		m.logInfo("collision", "kind", wallCause, "ground", g, "hit", "fatal")
+		m.playerY = m.gameRows - 2 - alt0
+		m.halt()
+		m.setGameOver(wallCause)
+		return
+	}
+	m.playerY = row
+	m.halt()
+}
+
+// stepCling replaces a normal step while the gopher is against a wall, and
+// reports whether it did. Jumping or falling into a rise too high to step
+// up catches the gopher on its face instead of ending the run: the world
+// stops scrolling, the gopher slowly slides down, and another jump press
+// (see pressJump) kicks it up the wall. Sliding all the way down leaves it
+// standing in front of the wall, which it then runs into.
+func (m *model) stepCling(now time.Time) bool {
+	if !m.clinging {
+		if !m.cfg.Terrain || (m.grounded() && m.velY >= 0) {
+			return false
+		}
+		ahead := m.terrainAt(m.camera().toWorld(playerCol) + 1)
+		alt := m.gameRows - 2 - m.playerY - (m.velY + m.character().Gravity)
+		if alt >= ahead || ahead-m.groundUnder() <= maxStep {
+			return false // clears it, or can walk up it
+		}
+		m.clinging, m.slide = true, 0
+		m.halt()
+		m.playerY = min(m.gameRows-2-alt, m.groundRow())
+		m.logInfo("clinging", "wall", ahead, "height", m.gameRows-2-m.playerY)
+	} else if m.slide++; m.slide >= clingSlide {
+		m.slide = 0
+		m.playerY++
+	}
+	if m.playerY >= m.groundRow() {
+		m.playerY, m.clinging = m.groundRow(), false // slid to the foot of the wall
+	}
+	m.tickClock(now)
+	return true
+}
+
+// wallJump kicks a clinging gopher up and off the wall
+func (m *model) wallJump() {
+	m.logDebug("wall jump")
+	m.clinging = false
+	m.jump()
+}
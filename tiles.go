@@ -0,0 +1,129 @@
+package gopherdash
+
+import "fmt"
+
+// ----------------------------------------------------------------------------
+// GROUND TILES (springboards & speed pads)
+// ----------------------------------------------------------------------------
+
+const (
+	springVel   = -5 // launch speed off a springboard; a normal jump is jumpVel
+	springCells = 8  // steps a springboard launch stays airborne
+	boostTicks  = 40 // how long a speed pad doubles the score rate
+	tileOdds    = 80 // about one eligible cell in tileOdds gets a tile
+	tileSalt    = 0x7117e5
+
+	// tileReach is how far past its own cell a tile needs the course
+	// decided: a springboard's landing cell and the take-off after it
+	tileReach = springCells + 2
+)
+
+// Tiles are ground cells the gopher lands on rather than hazards it
+// avoids. They are ObstacleKinds with no spawn weight, so they ride along
+// with the obstacle list (saves, state dumps, the radar, pruning) without
+// changing any seed's hazards; placeTiles lays them out separately.
+var tileKinds = []ObstacleKind{springboard{}, speedPad{}, acornPickup{}}
+
+// springboard launches a higher, longer jump when landed or run on
+type springboard struct{}
+
+func (springboard) Name() string          { return "springboard" }
+func (springboard) Sprite() (string, int) { return "🟨", 0 }
+func (springboard) Radar() string         { return "⇑ " }
+func (springboard) Width() int            { return 1 }
+func (springboard) Height() int           { return 1 }
+func (springboard) Advance(x int) int     { return x }
+func (springboard) Weight() float64       { return 0 }
+func (springboard) Death(dist int) string { return fmt.Sprintf("Stopped at %d", dist) }
+func (springboard) Collides(p player) hit {
+	if p.height == 0 {
+		return launch
+	}
+	return miss
+}
+
+// speedPad doubles the score rate for boostTicks
+type speedPad struct{}
+
+func (speedPad) Name() string          { return "speed pad" }
+func (speedPad) Sprite() (string, int) { return "🟦", 0 }
+func (speedPad) Radar() string         { return "» " }
+func (speedPad) Width() int            { return 1 }
+func (speedPad) Height() int           { return 1 }
+func (speedPad) Advance(x int) int     { return x }
+func (speedPad) Weight() float64       { return 0 }
+func (speedPad) Death(dist int) string { return fmt.Sprintf("Stopped at %d", dist) }
+func (speedPad) Collides(p player) hit {
+	if p.height == 0 {
+		return boost
+	}
+	return miss
+}
+
+// placeTiles decides the tiles for world cells [from, upTo). Hazards have to
+// be known tileReach cells past a cell before it can get a springboard, so
+// callers pass a range that far behind the spawner. Like the terrain, the
+// choice is a hash of the seed and the cell.
+func (m *model) placeTiles(from, upTo int) {
+	for x := max(from, playerCol+1+m.cfg.GraceCells); x < upTo; x++ {
+		h := terrainHash(m.seed^tileSalt, x)
+		if h%tileOdds != 0 || !m.tileFits(x) {
+			continue
+		}
+		kind := tileKinds[(h>>16)%uint64(len(tileKinds))]
+		if _, ok := kind.(springboard); ok && !m.springClear(x) {
+			continue
+		}
+		m.logDebug("spawn", "x", x, "kind", kind.Name())
+		m.obstacles = append(m.obstacles, obstacle{x, kind})
+	}
+}
+
+// tileFits reports whether x is free, level ground
+func (m model) tileFits(x int) bool {
+	for _, ob := range m.obstacles {
+		if ob.extent().overlaps(span{x, x}) {
+			return false
+		}
+	}
+	return m.buildable(span{x, x})
+}
+
+// springClear reports whether a launch from x lands on clear, level ground
+// with room for the next jump
+func (m model) springClear(x int) bool {
+	for _, ob := range m.obstacles {
+		if ob.extent().overlaps(span{x + 1, x + tileReach}) {
+			return false
+		}
+	}
+	for nx := x + 1; nx <= x+tileReach; nx++ {
+		if m.terrainAt(nx) != m.terrainAt(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// score is what a run is ranked by: distance plus speed-pad bonus under
+// classic rules, weighed and topped up with points under others (see
+// scoring.go), then multiplied up and added to the score banked by any
+// prestige loops
+func (m model) score() int {
+	return m.banked + m.loopScore()*m.loopMultiplier()/100
+}
+
+// loopScore is what the current prestige loop has scored, before its
+// multiplier (see prestige.go)
+func (m model) loopScore() int {
+	r := m.scoring()
+	return r.Distance*m.dist + r.SpeedPad*m.bonus + m.points
+}
+
+// stepBoost pays out a running speed pad
+func (m *model) stepBoost() {
+	if m.boostLeft > 0 {
+		m.boostLeft--
+		m.bonus++
+	}
+}
@@ -0,0 +1,108 @@
+package gopherdash
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// TWITCH CHAT (read-only IRC)
+// ----------------------------------------------------------------------------
+
+const (
+	twitchAddr    = "irc.chat.twitch.tv:6667"
+	twitchRetry   = 10 * time.Second
+	twitchTimeout = 5 * time.Minute // Twitch PINGs roughly every 5 minutes
+)
+
+// chatVoteMsg is a chaos vote cast by a chat message such as "!speed"
+type chatVoteMsg struct{ event string }
+
+// chatStatusMsg reports the state of the chat connection for the HUD
+type chatStatusMsg struct{ status string }
+
+// runTwitch joins channel anonymously and forwards votes until ctx ends,
+// reconnecting after errors
+func runTwitch(ctx context.Context, channel string, send func(tea.Msg)) {
+	channel = strings.ToLower(strings.TrimPrefix(channel, "#"))
+	for {
+		err := readTwitch(ctx, channel, send)
+		if ctx.Err() != nil {
+			return
+		}
+		send(chatStatusMsg{fmt.Sprintf("chat offline (%v)", err)})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(twitchRetry):
+		}
+	}
+}
+
+func readTwitch(ctx context.Context, channel string, send func(tea.Msg)) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", twitchAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close() // unblocks the scanner
+		case <-done:
+		}
+	}()
+
+	// justinfanNNNN is Twitch's anonymous read-only login
+	nick := fmt.Sprintf("justinfan%d", 10000+time.Now().UnixNano()%90000)
+	fmt.Fprintf(conn, "NICK %s\r\nJOIN #%s\r\n", nick, channel)
+	send(chatStatusMsg{"#" + channel})
+
+	sc := bufio.NewScanner(conn)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(twitchTimeout))
+		if !sc.Scan() {
+			if err := sc.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("connection closed")
+		}
+		line := sc.Text()
+		if strings.HasPrefix(line, "PING") {
+			fmt.Fprintf(conn, "PONG%s\r\n", strings.TrimPrefix(line, "PING"))
+			continue
+		}
+		if ev, ok := parseVote(line); ok {
+			send(chatVoteMsg{ev})
+		}
+	}
+}
+
+// parseVote extracts a chaos vote from a raw PRIVMSG line
+func parseVote(line string) (string, bool) {
+	// :nick!nick@nick.tmi.twitch.tv PRIVMSG #channel :!speed
+	i := strings.Index(line, " PRIVMSG ")
+	if i < 0 {
+		return "", false
+	}
+	j := strings.Index(line[i:], " :")
+	if j < 0 {
+		return "", false
+	}
+	text := strings.ToLower(strings.TrimSpace(line[i+j+2:]))
+	for _, ev := range chaosEvents {
+		if text == "!"+ev.name {
+			return ev.name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,175 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----------------------------------------------------------------------------
+// UPDATE CHECK (newer releases on GitHub)
+// ----------------------------------------------------------------------------
+
+// With check_updates set in the config file the game asks GitHub for the
+// latest release while the first run starts, and if it's newer than this
+// build says so in the HUD and on the game-over screen. -check-update does
+// the same from the command line and exits. Nothing is ever downloaded or
+// installed; that's left to go install or the release page.
+
+// version is this build's version, set at link time with
+//
+//	go build -ldflags "-X github.com/krisfur/gopherdash.version=v1.2.3" ./cmd/gopherdash
+//
+// go install …@v1.2.3 records it in the build info instead
+var version = ""
+
+const updateTimeout = 5 * time.Second
+
+// releasesAPI is where the latest release is looked up
+var releasesAPI = "https://api.github.com/repos/krisfur/gopherdash/releases/latest"
+
+// release is the part of the GitHub release API the check reads
+type release struct {
+	Tag string `json:"tag_name"`
+	URL string `json:"html_url"`
+}
+
+// updateMsg reports a release newer than this build
+type updateMsg struct{ rel release }
+
+// buildVersion is the version this binary was built as, or "dev" for a
+// build from a checkout
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+	return "dev"
+}
+
+// latestRelease asks GitHub for the newest published release
+func latestRelease() (release, error) {
+	client := &http.Client{Timeout: updateTimeout}
+	req, err := http.NewRequest(http.MethodGet, releasesAPI, nil)
+	if err != nil {
+		return release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return release{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return release{}, fmt.Errorf("GET: %s", resp.Status)
+	}
+	var rel release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&rel); err != nil {
+		return release{}, err
+	}
+	if rel.Tag == "" {
+		return release{}, fmt.Errorf("GitHub didn't say which release is latest")
+	}
+	return rel, nil
+}
+
+// newerVersion reports whether tag is a later release than current, a
+// release counting as later than its own pre-releases; dev builds and tags
+// that aren't vMAJOR.MINOR.PATCH never compare as newer
+func newerVersion(tag, current string) bool {
+	a, ok := parseVersion(tag)
+	if !ok {
+		return false
+	}
+	b, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return prerelease(current) && !prerelease(tag)
+}
+
+// prerelease reports whether a version has a pre-release suffix, as in
+// v1.2.0-rc.1
+func prerelease(s string) bool {
+	s, _, _ = strings.Cut(s, "+")
+	return strings.Contains(s, "-")
+}
+
+// parseVersion reads vMAJOR.MINOR.PATCH, ignoring any pre-release or build
+// suffix
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+	s, ok := strings.CutPrefix(s, "v")
+	if !ok {
+		return v, false
+	}
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// checkUpdate looks for a newer release in the background, sending an
+// updateMsg if there is one; failures only go to the log
+func checkUpdate(send func(tea.Msg)) {
+	rel, err := latestRelease()
+	if err != nil {
+		logger.Warn("update check failed", "err", err)
+		return
+	}
+	if newerVersion(rel.Tag, buildVersion()) {
+		send(updateMsg{rel})
+	}
+}
+
+// checkUpdateMain runs `gopherdash -check-update`
+func checkUpdateMain() int {
+	current := buildVersion()
+	rel, err := latestRelease()
+	if err != nil {
+		fmt.Println("Couldn't check for updates:", err)
+		return exitError
+	}
+	switch {
+	case newerVersion(rel.Tag, current):
+		fmt.Printf("Gopher-Dash %s is out (you have %s): %s\n", rel.Tag, current, rel.URL)
+	case current == "dev":
+		fmt.Printf("The latest release is %s; this is a development build\n", rel.Tag)
+	default:
+		fmt.Printf("Gopher-Dash %s is up to date\n", current)
+	}
+	return 0
+}
+
+// updateLine is the game-over screen's version line
+func (m model) updateLine() string {
+	if m.newer != nil {
+		return fmt.Sprintf("gopherdash %s · %s is out", buildVersion(), m.newer.Tag)
+	}
+	return "gopherdash " + buildVersion()
+}
@@ -0,0 +1,77 @@
+package gopherdash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewerVersion(t *testing.T) {
+	for _, tc := range []struct {
+		tag, current string
+		newer        bool
+	}{
+		{"v1.2.4", "v1.2.3", true},
+		{"v1.10.0", "v1.9.9", true},
+		{"v2.0.0", "v1.99.99", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.2", "v1.2.3", false},
+		{"v1.3.0-rc.1", "v1.2.3", true},
+		{"v1.2.3", "v1.2.3-rc.1", true},
+		{"v1.2.3-rc.2", "v1.2.3-rc.1", false}, // pre-releases aren't ordered
+		{"v1.2.3-rc.1", "v1.2.3", false},
+		{"v1.2.3+build.7", "v1.2.3", false},
+		{"v1.2.3", "v1.2.3+build.7", false},
+		{"v1.3.0", "dev", false},
+		{"v1.3.0", "v0.0.0-20260101120000-abcdef123456", true}, // a pseudo-version
+		{"1.3.0", "v1.2.0", false},
+		{"v1.3", "v1.2.0", false},
+		{"v1.3.0.1", "v1.2.0", false},
+		{"v1.x.0", "v1.2.0", false},
+		{"nightly", "v1.2.0", false},
+		{"", "v1.2.0", false},
+	} {
+		if got := newerVersion(tc.tag, tc.current); got != tc.newer {
+			t.Errorf("newerVersion(%q, %q) = %v, want %v", tc.tag, tc.current, got, tc.newer)
+		}
+	}
+}
+
+// TestUpdateNotice looks up the latest release on a fake API, and tells the
+// player only when it's newer
+func TestUpdateNotice(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name": "v1.5.0", "html_url": "https://github.com/krisfur/gopherdash/releases/tag/v1.5.0"}`))
+	}))
+	defer srv.Close()
+	oldAPI, oldVersion := releasesAPI, version
+	t.Cleanup(func() { releasesAPI, version = oldAPI, oldVersion })
+	releasesAPI = srv.URL
+
+	for _, tc := range []struct {
+		build  string
+		notice bool
+	}{
+		{"v1.4.2", true},
+		{"v1.5.0", false},
+		{"dev", false},
+	} {
+		version = tc.build
+		var sent []tea.Msg
+		checkUpdate(func(msg tea.Msg) { sent = append(sent, msg) })
+		if got := len(sent) == 1; got != tc.notice {
+			t.Errorf("build %s: sent %v", tc.build, sent)
+			continue
+		}
+		if !tc.notice {
+			continue
+		}
+		m := tinyModel()
+		next, _ := m.Update(sent[0])
+		if m = next.(model); m.updateLine() != "gopherdash v1.4.2 · v1.5.0 is out" {
+			t.Errorf("game-over line %q", m.updateLine())
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package gopherdash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// WEEKLY CHALLENGE
+// ----------------------------------------------------------------------------
+
+const (
+	weeklyFile = ".gopherdash_weekly"
+	weeklyKeep = 10 // scores kept per week
+	weeklySalt = 0x3ee1c
+)
+
+// weekOf names t's ISO week, e.g. "2024-W23"
+func weekOf(t time.Time) string {
+	y, w := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", y, w)
+}
+
+// weeklySeed is the same for everyone in a given ISO week, e.g. 202423; it
+// can't clash with a daily seed, which has eight digits
+func weeklySeed(t time.Time) int64 {
+	y, w := t.ISOWeek()
+	return int64(y*100 + w)
+}
+
+// weeklyMods are the week's modifiers: a non-empty set drawn from the
+// registry's weekly rotation by a hash of the week, so everyone gets the
+// same ones
+func weeklyMods(t time.Time) []string {
+	var pool []string
+	for _, md := range modifiers {
+		if md.weekly {
+			pool = append(pool, md.name)
+		}
+	}
+	h := terrainHash(weeklySeed(t)^weeklySalt, 0)
+	mask := h%(1<<len(pool)-1) + 1
+	var mods []string
+	for i, name := range pool {
+		if mask&(1<<i) != 0 {
+			mods = append(mods, name)
+		}
+	}
+	return mods
+}
+
+// weeklyBoard is one week's leaderboard, best first
+type weeklyBoard struct {
+	Seed   int64         `json:"seed"`
+	Mods   []string      `json:"mods"`
+	Scores []weeklyScore `json:"scores"`
+}
+
+type weeklyScore struct {
+	Score int       `json:"score"`
+	At    time.Time `json:"at"`
+}
+
+func weeklyPath() string { return dataPath(weeklyFile) }
+
+// loadWeekly reads every week's board, keyed by weekOf
+func loadWeekly() map[string]weeklyBoard {
+	boards := map[string]weeklyBoard{}
+	if data, err := os.ReadFile(weeklyPath()); err == nil {
+		_ = json.Unmarshal(data, &boards)
+	}
+	return boards
+}
+
+// recordWeekly adds a weekly-challenge run to its week's board and returns
+// its place, 0 if it didn't make the board
+func (m *model) recordWeekly(at time.Time) (place int) {
+	week := weekOf(at)
+	withSaveLock(func() {
+		boards := loadWeekly()
+		b := boards[week]
+		b.Seed, b.Mods = m.seed, m.mods
+		if b.Scores, place = rankScore(b.Scores, weeklyScore{m.score(), at}, weeklyKeep); place == 0 {
+			return
+		}
+		boards[week] = b
+		if data, err := json.MarshalIndent(boards, "", "  "); err == nil {
+			_ = os.WriteFile(weeklyPath(), data, 0o644)
+		}
+	})
+	m.logInfo("weekly run", "week", week, "place", place)
+	return place
+}
+
+// rankScore inserts s into scores, best first, keeping at most keep of
+// them; place is where it went, 0 if it didn't make the cut
+func rankScore(scores []weeklyScore, s weeklyScore, keep int) (kept []weeklyScore, place int) {
+	i := 0
+	for i < len(scores) && scores[i].Score >= s.Score {
+		i++ // ties go to whoever got there first
+	}
+	if i >= keep {
+		return scores, 0
+	}
+	scores = slices.Insert(scores, i, s)
+	return scores[:min(len(scores), keep)], i + 1
+}
+
+// weeklyHUD names the week and its modifiers
+func (m model) weeklyHUD() string {
+	return fmt.Sprintf("Weekly %s: %s", weekOf(time.Now()), m.modsLabel())
+}
+
+// weeklyLine is the game-over line for a weekly run
+func (m model) weeklyLine() string {
+	if m.weeklyPlace == 0 {
+		return fmt.Sprintf("Weekly %s: off the top %d", weekOf(time.Now()), weeklyKeep)
+	}
+	return fmt.Sprintf("Weekly %s: #%d on your board", weekOf(time.Now()), m.weeklyPlace)
+}
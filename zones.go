@@ -0,0 +1,80 @@
+package gopherdash
+
+import "github.com/charmbracelet/lipgloss"
+
+// ----------------------------------------------------------------------------
+// MULTIPLIER ZONES (-zones)
+// ----------------------------------------------------------------------------
+
+// With -zones the odd chunk (see biomes.go) has a stretch of track where
+// everything scored counts double, and hazards come half as thick again.
+// Zones are a hash of the course's seed and the chunk, like the tiles, so
+// a seed always has them in the same places and both sides of a race see
+// the same ones; the director (see director.go) paces the stream inside
+// them as anywhere else. A zone's cells have a background of their own,
+// and a banner calls it out zoneWarn cells before the gopher gets there.
+
+const (
+	zoneOdds    = 4   // about one chunk in zoneOdds has a zone
+	zoneCells   = 20  // how long a zone is
+	zoneDensity = 1.5 // times the spawn chance inside one
+	zoneWarn    = 12  // cells ahead of the gopher a zone is announced
+	zoneSalt    = 0x2073e5
+)
+
+var zoneStyle = lipgloss.NewStyle().Background(lipgloss.Color("58"))
+
+// zoneIn is chunk c's zone in the world of seed, if it has one; the first
+// chunk, with the grace stretch, never does
+func zoneIn(seed int64, c int) (span, bool) {
+	h := terrainHash(seed^zoneSalt, c)
+	if c <= 0 || h%zoneOdds != 0 {
+		return span{}, false
+	}
+	lo := c*chunkCells + int(h>>16%uint64(chunkCells-zoneCells))
+	return span{lo, lo + zoneCells - 1}, true
+}
+
+// zoned reports whether world cell x is in a zone of the stream's
+func (s *spawner) zoned(x int) bool {
+	if !s.zones {
+		return false
+	}
+	z, ok := zoneIn(s.seed, chunkOf(x))
+	return ok && z.overlaps(span{x, x})
+}
+
+// inZone reports whether the gopher is in a zone
+func (m model) inZone() bool { return m.spawn.zoned(m.camera().toWorld(playerCol)) }
+
+// stepZone doubles whatever a step in a zone scored, from a loop score of
+// before, and announces the zone coming up
+func (m *model) stepZone(before int) {
+	if !m.spawn.zones {
+		return
+	}
+	if m.inZone() && !m.gameOver { // a crash's score is already recorded
+		m.points += max(m.loopScore()-before, 0)
+	}
+	x := m.camera().toWorld(playerCol) + zoneWarn
+	if z, ok := zoneIn(m.spawn.seed, chunkOf(x)); ok && z.lo == x {
+		m.banner, m.bannerAt = "×2 zone ahead!", m.now()
+	}
+}
+
+// applyZones gives the empty cells over a zone its background
+func (m model) applyZones(rows [][]string, cam camera) {
+	for x := range rows[0] {
+		if !m.spawn.zoned(cam.toWorld(x)) {
+			continue
+		}
+		for y := range rows {
+			if rows[y][x] == "  " {
+				rows[y][x] = zoneStyle.Render("  ")
+			}
+		}
+	}
+}
+
+// zoneHUD is the badge while the gopher is in a zone
+func (m model) zoneHUD() string { return bannerStyle.Render("×2 ZONE") }
@@ -0,0 +1,87 @@
+package gopherdash
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZoneIn(t *testing.T) {
+	zones, chunks := 0, 0
+	for seed := int64(1); seed <= 20; seed++ {
+		if _, ok := zoneIn(seed, 0); ok {
+			t.Errorf("seed %d: a zone in the grace stretch", seed)
+		}
+		for c := 1; c <= 20; c++ {
+			chunks++
+			z, ok := zoneIn(seed, c)
+			if !ok {
+				continue
+			}
+			zones++
+			if z.hi-z.lo+1 != zoneCells || chunkOf(z.lo) != c || chunkOf(z.hi) != c {
+				t.Errorf("seed %d: chunk %d's zone is %+v", seed, c, z)
+			}
+		}
+	}
+	if share := float64(zones) / float64(chunks); share < 0.1 || share > 0.4 {
+		t.Errorf("zones in %d of %d chunks", zones, chunks)
+	}
+}
+
+// Zones are thicker with hazards than the same cells without.
+func TestZoneDensity(t *testing.T) {
+	count := func(zones bool) int {
+		n := 0
+		for seed := int64(1); seed <= 50; seed++ {
+			s := newSpawner(seed, playerCol+1, defaultGraceCells)
+			s.zones = zones
+			probe := s
+			probe.zones = true
+			for _, ob := range s.fill(playerCol + 1 + courseCells) {
+				if probe.zoned(ob.x) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	if on, off := count(true), count(false); on <= off {
+		t.Errorf("%d hazards in zones, %d in the same cells without them", on, off)
+	}
+}
+
+func TestZoneScoring(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Countdown, cfg.Zones, cfg.Seed = 0, true, 9
+	m, _ := clockedModel(t, cfg)
+	var z span
+	for i := 1; ; i++ {
+		if zc, ok := zoneIn(m.spawn.seed, i); ok {
+			z = zc
+			break
+		}
+	}
+	m.spawn.chance = 0
+	stepTo := func(x int) int {
+		m.obstacles = nil
+		m.dist = x - playerCol - 1
+		before := m.score()
+		m.step(m.now())
+		return m.score() - before
+	}
+
+	stepTo(z.lo - zoneWarn)
+	if !strings.Contains(m.banner, "zone ahead") {
+		t.Errorf("no warning %d cells out: banner %q", zoneWarn, m.banner)
+	}
+	if got := stepTo(z.lo); got != 2 || !m.inZone() {
+		t.Errorf("a step into the zone scored %d", got)
+	}
+	m.banner = "" // the warning, or a milestone's
+	if !strings.Contains(m.View(), "ZONE") {
+		t.Error("no zone badge in the HUD")
+	}
+	if got := stepTo(z.hi + 1); got != 1 || m.inZone() {
+		t.Errorf("a step out of the zone scored %d", got)
+	}
+}